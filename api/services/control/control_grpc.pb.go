@@ -28,6 +28,7 @@ const (
 	Control_Info_FullMethodName               = "/moby.buildkit.v1.Control/Info"
 	Control_ListenBuildHistory_FullMethodName = "/moby.buildkit.v1.Control/ListenBuildHistory"
 	Control_UpdateBuildHistory_FullMethodName = "/moby.buildkit.v1.Control/UpdateBuildHistory"
+	Control_DiffBuildHistory_FullMethodName   = "/moby.buildkit.v1.Control/DiffBuildHistory"
 )
 
 // ControlClient is the client API for Control service.
@@ -43,6 +44,7 @@ type ControlClient interface {
 	Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
 	ListenBuildHistory(ctx context.Context, in *BuildHistoryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BuildHistoryEvent], error)
 	UpdateBuildHistory(ctx context.Context, in *UpdateBuildHistoryRequest, opts ...grpc.CallOption) (*UpdateBuildHistoryResponse, error)
+	DiffBuildHistory(ctx context.Context, in *DiffBuildHistoryRequest, opts ...grpc.CallOption) (*DiffBuildHistoryResponse, error)
 }
 
 type controlClient struct {
@@ -173,6 +175,16 @@ func (c *controlClient) UpdateBuildHistory(ctx context.Context, in *UpdateBuildH
 	return out, nil
 }
 
+func (c *controlClient) DiffBuildHistory(ctx context.Context, in *DiffBuildHistoryRequest, opts ...grpc.CallOption) (*DiffBuildHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffBuildHistoryResponse)
+	err := c.cc.Invoke(ctx, Control_DiffBuildHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlServer is the server API for Control service.
 // All implementations should embed UnimplementedControlServer
 // for forward compatibility.
@@ -186,6 +198,7 @@ type ControlServer interface {
 	Info(context.Context, *InfoRequest) (*InfoResponse, error)
 	ListenBuildHistory(*BuildHistoryRequest, grpc.ServerStreamingServer[BuildHistoryEvent]) error
 	UpdateBuildHistory(context.Context, *UpdateBuildHistoryRequest) (*UpdateBuildHistoryResponse, error)
+	DiffBuildHistory(context.Context, *DiffBuildHistoryRequest) (*DiffBuildHistoryResponse, error)
 }
 
 // UnimplementedControlServer should be embedded to have
@@ -222,6 +235,9 @@ func (UnimplementedControlServer) ListenBuildHistory(*BuildHistoryRequest, grpc.
 func (UnimplementedControlServer) UpdateBuildHistory(context.Context, *UpdateBuildHistoryRequest) (*UpdateBuildHistoryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method UpdateBuildHistory not implemented")
 }
+func (UnimplementedControlServer) DiffBuildHistory(context.Context, *DiffBuildHistoryRequest) (*DiffBuildHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DiffBuildHistory not implemented")
+}
 func (UnimplementedControlServer) testEmbeddedByValue() {}
 
 // UnsafeControlServer may be embedded to opt out of forward compatibility for this service.
@@ -372,6 +388,24 @@ func _Control_UpdateBuildHistory_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Control_DiffBuildHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffBuildHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).DiffBuildHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Control_DiffBuildHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).DiffBuildHistory(ctx, req.(*DiffBuildHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Control_ServiceDesc is the grpc.ServiceDesc for Control service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -399,6 +433,10 @@ var Control_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateBuildHistory",
 			Handler:    _Control_UpdateBuildHistory_Handler,
 		},
+		{
+			MethodName: "DiffBuildHistory",
+			Handler:    _Control_DiffBuildHistory_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
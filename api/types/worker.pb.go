@@ -115,6 +115,9 @@ type GCPolicy struct {
 	ReservedSpace int64 `protobuf:"varint,3,opt,name=reservedSpace,proto3" json:"reservedSpace,omitempty"`
 	MaxUsedSpace  int64 `protobuf:"varint,5,opt,name=maxUsedSpace,proto3" json:"maxUsedSpace,omitempty"`
 	MinFreeSpace  int64 `protobuf:"varint,6,opt,name=minFreeSpace,proto3" json:"minFreeSpace,omitempty"`
+	// tenant limits the policy to the build cache of a tenant, "*" applies
+	// it to every tenant separately
+	Tenant        string `protobuf:"bytes,7,opt,name=tenant,proto3" json:"tenant,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -191,6 +194,13 @@ func (x *GCPolicy) GetMinFreeSpace() int64 {
 	return 0
 }
 
+func (x *GCPolicy) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
 type BuildkitVersion struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	Package           string                 `protobuf:"bytes,1,opt,name=package,proto3" json:"package,omitempty"`
@@ -343,14 +353,15 @@ const file_github_com_moby_buildkit_api_types_worker_proto_rawDesc = "" +
 	"CDIDevices\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc8\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe0\x01\n" +
 	"\bGCPolicy\x12\x10\n" +
 	"\x03all\x18\x01 \x01(\bR\x03all\x12\"\n" +
 	"\fkeepDuration\x18\x02 \x01(\x03R\fkeepDuration\x12\x18\n" +
 	"\afilters\x18\x04 \x03(\tR\afilters\x12$\n" +
 	"\rreservedSpace\x18\x03 \x01(\x03R\rreservedSpace\x12\"\n" +
 	"\fmaxUsedSpace\x18\x05 \x01(\x03R\fmaxUsedSpace\x12\"\n" +
-	"\fminFreeSpace\x18\x06 \x01(\x03R\fminFreeSpace\"\x8f\x01\n" +
+	"\fminFreeSpace\x18\x06 \x01(\x03R\fminFreeSpace\x12\x16\n" +
+	"\x06tenant\x18\a \x01(\tR\x06tenant\"\x8f\x01\n" +
 	"\x0fBuildkitVersion\x12\x18\n" +
 	"\apackage\x18\x01 \x01(\tR\apackage\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
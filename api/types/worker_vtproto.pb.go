@@ -76,6 +76,7 @@ func (m *GCPolicy) CloneVT() *GCPolicy {
 	r.ReservedSpace = m.ReservedSpace
 	r.MaxUsedSpace = m.MaxUsedSpace
 	r.MinFreeSpace = m.MinFreeSpace
+	r.Tenant = m.Tenant
 	if rhs := m.Filters; rhs != nil {
 		tmpContainer := make([]string, len(rhs))
 		copy(tmpContainer, rhs)
@@ -253,6 +254,9 @@ func (this *GCPolicy) EqualVT(that *GCPolicy) bool {
 	if this.MinFreeSpace != that.MinFreeSpace {
 		return false
 	}
+	if this.Tenant != that.Tenant {
+		return false
+	}
 	return string(this.unknownFields) == string(that.unknownFields)
 }
 
@@ -463,6 +467,13 @@ func (m *GCPolicy) MarshalToSizedBufferVT(dAtA []byte) (int, error) {
 		i -= len(m.unknownFields)
 		copy(dAtA[i:], m.unknownFields)
 	}
+	if len(m.Tenant) > 0 {
+		i -= len(m.Tenant)
+		copy(dAtA[i:], m.Tenant)
+		i = protohelpers.EncodeVarint(dAtA, i, uint64(len(m.Tenant)))
+		i--
+		dAtA[i] = 0x3a
+	}
 	if m.MinFreeSpace != 0 {
 		i = protohelpers.EncodeVarint(dAtA, i, uint64(m.MinFreeSpace))
 		i--
@@ -716,6 +727,10 @@ func (m *GCPolicy) SizeVT() (n int) {
 	if m.MinFreeSpace != 0 {
 		n += 1 + protohelpers.SizeOfVarint(uint64(m.MinFreeSpace))
 	}
+	l = len(m.Tenant)
+	if l > 0 {
+		n += 1 + l + protohelpers.SizeOfVarint(uint64(l))
+	}
 	n += len(m.unknownFields)
 	return n
 }
@@ -1279,6 +1294,38 @@ func (m *GCPolicy) UnmarshalVT(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tenant", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return protohelpers.ErrIntOverflow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tenant = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := protohelpers.Skip(dAtA[iNdEx:])
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"io"
+
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/archive"
+	"github.com/moby/buildkit/session"
+	"github.com/pkg/errors"
+)
+
+// WriteLayerDiff writes the uncompressed tar of the changes of the layer
+// compared to its parent to w. The diff is generated from the snapshots with
+// the walking differ, independent of the differ that created the blob of the
+// layer.
+func (sr *immutableRef) WriteLayerDiff(ctx context.Context, w io.Writer, s session.Group) error {
+	if isTypeWindows(sr) {
+		return errors.New("generating the diff of windows layers is not supported")
+	}
+
+	var lowerRef, upperRef *immutableRef
+	switch sr.kind() {
+	case Diff:
+		lowerRef, upperRef = sr.diffParents.lower, sr.diffParents.upper
+	case Layer, BaseLayer:
+		lowerRef, upperRef = sr.layerParent, sr
+	default:
+		return errors.Errorf("cannot generate the diff of merged ref %s", sr.ID())
+	}
+
+	mountRef := func(ref *immutableRef, f func(root string) error) error {
+		if ref == nil {
+			return mount.WithReadonlyTempMount(ctx, nil, f)
+		}
+		m, err := ref.Mount(ctx, true, s)
+		if err != nil {
+			return err
+		}
+		mounts, release, err := m.Mount()
+		if err != nil {
+			return err
+		}
+		if release != nil {
+			defer release()
+		}
+		return mount.WithReadonlyTempMount(ctx, mounts, f)
+	}
+	return mountRef(lowerRef, func(lowerRoot string) error {
+		return mountRef(upperRef, func(upperRoot string) error {
+			return archive.WriteDiff(ctx, w, lowerRoot, upperRoot)
+		})
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/moby/buildkit/util/disk"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/sys/user"
 	digest "github.com/opencontainers/go-digest"
 	imagespecidentity "github.com/opencontainers/image-spec/identity"
@@ -286,7 +287,7 @@ func (cm *cacheManager) GetByBlob(ctx context.Context, desc ocispecs.Descriptor,
 		cacheMetadata: md,
 	}
 
-	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
+	if err := initializeMetadata(ctx, rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
 		return nil, err
 	}
 
@@ -505,7 +506,7 @@ func (cm *cacheManager) getRecord(ctx context.Context, id string, opts ...RefOpt
 		}
 	}
 
-	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
+	if err := initializeMetadata(ctx, rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
 		return nil, err
 	}
 
@@ -656,7 +657,7 @@ func (cm *cacheManager) New(ctx context.Context, s ImmutableRef, sess session.Gr
 	}
 
 	opts = append(opts, withSnapshotID(snapshotID))
-	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
+	if err := initializeMetadata(ctx, rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
 		return nil, err
 	}
 
@@ -794,7 +795,7 @@ func (cm *cacheManager) createMergeRef(ctx context.Context, parents parentRefs,
 		refs:          make(map[ref]struct{}),
 	}
 
-	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
+	if err := initializeMetadata(ctx, rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
 		return nil, err
 	}
 
@@ -997,7 +998,7 @@ func (cm *cacheManager) createDiffRef(ctx context.Context, parents parentRefs, d
 		refs:          make(map[ref]struct{}),
 	}
 
-	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
+	if err := initializeMetadata(ctx, rec.cacheMetadata, rec.parentRefs, opts...); err != nil {
 		return nil, err
 	}
 
@@ -1015,7 +1016,7 @@ func (cm *cacheManager) createDiffRef(ctx context.Context, parents parentRefs, d
 func (cm *cacheManager) Prune(ctx context.Context, ch chan client.UsageInfo, opts ...client.PruneInfo) error {
 	cm.muPrune.Lock()
 
-	for _, opt := range opts {
+	for _, opt := range cm.expandTenants(opts) {
 		if err := cm.prune(ctx, ch, opt); err != nil {
 			cm.muPrune.Unlock()
 			return err
@@ -1050,7 +1051,7 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 
 	totalSize := int64(0)
 	if opt.MaxUsedSpace != 0 || opt.ReservedSpace != 0 || opt.MinFreeSpace != 0 {
-		du, err := cm.DiskUsage(ctx, client.DiskUsageInfo{})
+		du, err := cm.DiskUsage(ctx, client.DiskUsageInfo{Tenant: opt.Tenant})
 		if err != nil {
 			return err
 		}
@@ -1077,6 +1078,7 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 		keepDuration: opt.KeepDuration,
 		keepBytes:    calculateKeepBytes(totalSize, dstat, opt),
 		totalSize:    totalSize,
+		tenant:       opt.Tenant,
 	}
 	for {
 		releasedSize, releasedCount, err := cm.pruneOnce(ctx, ch, popt)
@@ -1087,6 +1089,37 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 	}
 }
 
+// expandTenants replaces the policies applying to every tenant with a policy
+// for each tenant owning records.
+func (cm *cacheManager) expandTenants(opts []client.PruneInfo) []client.PruneInfo {
+	if !slices.ContainsFunc(opts, func(opt client.PruneInfo) bool { return opt.Tenant == tenant.Any }) {
+		return opts
+	}
+
+	cm.mu.Lock()
+	tenants := map[string]struct{}{}
+	for _, cr := range cm.records {
+		if id := cr.GetTenant(); id != "" {
+			tenants[id] = struct{}{}
+		}
+	}
+	cm.mu.Unlock()
+	ids := slices.Sorted(maps.Keys(tenants))
+
+	out := make([]client.PruneInfo, 0, len(opts))
+	for _, opt := range opts {
+		if opt.Tenant != tenant.Any {
+			out = append(out, opt)
+			continue
+		}
+		for _, id := range ids {
+			opt.Tenant = id
+			out = append(out, opt)
+		}
+	}
+	return out
+}
+
 func calculateKeepBytes(totalSize int64, dstat disk.DiskStat, opt client.PruneInfo) int64 {
 	// 0 values are special, and means we have no keep cap
 	if opt.MaxUsedSpace == 0 && opt.ReservedSpace == 0 && opt.MinFreeSpace == 0 {
@@ -1145,6 +1178,11 @@ func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo,
 			continue
 		}
 
+		if opt.tenant != "" && cr.GetTenant() != opt.tenant {
+			cr.mu.Unlock()
+			continue
+		}
+
 		if len(cr.refs) == 0 {
 			recordType := cr.GetRecordType()
 			if recordType == "" {
@@ -1370,6 +1408,7 @@ type cacheUsageInfo struct {
 	recordType  client.UsageRecordType
 	shared      bool
 	parentChain []digest.Digest
+	tenant      string
 }
 
 func (cm *cacheManager) DiskUsage(ctx context.Context, opt client.DiskUsageInfo) ([]*client.UsageInfo, error) {
@@ -1403,6 +1442,7 @@ func (cm *cacheManager) DiskUsage(ctx context.Context, opt client.DiskUsageInfo)
 			doubleRef:   cr.equalImmutable != nil,
 			recordType:  cr.GetRecordType(),
 			parentChain: cr.layerDigestChain(),
+			tenant:      cr.GetTenant(),
 		}
 		if c.recordType == "" {
 			c.recordType = client.UsageRecordTypeRegular
@@ -1456,6 +1496,9 @@ func (cm *cacheManager) DiskUsage(ctx context.Context, opt client.DiskUsageInfo)
 
 	var du []*client.UsageInfo
 	for id, cr := range m {
+		if opt.Tenant != "" && cr.tenant != opt.Tenant {
+			continue
+		}
 		c := &client.UsageInfo{
 			ID:          id,
 			Mutable:     cr.mutable,
@@ -1543,6 +1586,14 @@ func WithDescription(descr string) RefOption {
 	}
 }
 
+// WithTenant sets the tenant owning the record. Records created with a
+// context scoped to a tenant are owned by that tenant by default.
+func WithTenant(id string) RefOption {
+	return func(m *cacheMetadata) error {
+		return m.queueTenant(id)
+	}
+}
+
 func WithRecordType(t client.UsageRecordType) RefOption {
 	return func(m *cacheMetadata) error {
 		return m.queueRecordType(t)
@@ -1585,7 +1636,7 @@ func withSnapshotID(id string) RefOption {
 	})
 }
 
-func initializeMetadata(m *cacheMetadata, parents parentRefs, opts ...RefOption) error {
+func initializeMetadata(ctx context.Context, m *cacheMetadata, parents parentRefs, opts ...RefOption) error {
 	if tm := m.GetCreatedAt(); !tm.IsZero() {
 		return nil
 	}
@@ -1620,6 +1671,10 @@ func initializeMetadata(m *cacheMetadata, parents parentRefs, opts ...RefOption)
 		return err
 	}
 
+	if err := m.queueTenant(tenant.FromContext(ctx)); err != nil {
+		return err
+	}
+
 	for _, opt := range opts {
 		if fn, ok := opt.(func(*cacheMetadata) error); ok {
 			if err := fn(m); err != nil {
@@ -1672,6 +1727,7 @@ type pruneOpt struct {
 
 	keepBytes int64
 	totalSize int64
+	tenant    string
 }
 
 type deleteRecord struct {
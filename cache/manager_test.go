@@ -49,6 +49,7 @@ import (
 	"github.com/moby/buildkit/util/iohelper"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/overlay"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/winlayers"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -968,6 +969,71 @@ func TestPrune(t *testing.T) {
 	require.Equal(t, 0, len(dirs))
 }
 
+func TestTenantPrune(t *testing.T) {
+	t.Parallel()
+	ctx := namespaces.WithNamespace(context.Background(), "buildkit-test")
+
+	tmpdir := t.TempDir()
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, snapshotter.Close())
+	})
+
+	co, cleanup, err := newCacheManager(ctx, t, cmOpt{
+		snapshotter:     snapshotter,
+		snapshotterName: "native",
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	cm := co.manager
+
+	ctxA := tenant.WithID(ctx, "a")
+	ctxB := tenant.WithID(ctx, "b")
+
+	for _, ctx := range []context.Context{ctxA, ctxA, ctxB, ctx} {
+		active, err := cm.New(ctx, nil, nil, CachePolicyRetain)
+		require.NoError(t, err)
+		snap, err := active.Commit(ctx)
+		require.NoError(t, err)
+		require.NoError(t, snap.Release(ctx))
+	}
+
+	checkDiskUsage(ctx, t, cm, 0, 4)
+
+	du, err := cm.DiskUsage(ctx, client.DiskUsageInfo{Tenant: "a"})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(du))
+
+	du, err = cm.DiskUsage(ctx, client.DiskUsageInfo{Tenant: "b"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(du))
+
+	// prune of a tenant leaves the records of other tenants
+	buf := pruneResultBuffer()
+	err = cm.Prune(ctx, buf.C, client.PruneInfo{Tenant: "a"})
+	buf.close()
+	require.NoError(t, err)
+
+	require.Equal(t, 2, len(buf.all))
+	checkDiskUsage(ctx, t, cm, 0, 2)
+
+	// prune of every tenant leaves the records without a tenant
+	buf = pruneResultBuffer()
+	err = cm.Prune(ctx, buf.C, client.PruneInfo{Tenant: tenant.Any})
+	buf.close()
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(buf.all))
+	checkDiskUsage(ctx, t, cm, 0, 1)
+
+	du, err = cm.DiskUsage(ctx, client.DiskUsageInfo{Tenant: "b"})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(du))
+}
+
 func TestLazyCommit(t *testing.T) {
 	t.Parallel()
 
@@ -2399,6 +2465,66 @@ func TestMountReadOnly(t *testing.T) {
 	}
 }
 
+func TestWriteLayerDiff(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS != "linux" {
+		t.Skipf("unsupported GOOS: %s", runtime.GOOS)
+	}
+
+	ctx := namespaces.WithNamespace(context.Background(), "buildkit-test")
+
+	tmpdir := t.TempDir()
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	require.NoError(t, err)
+
+	co, cleanup, err := newCacheManager(ctx, t, cmOpt{
+		snapshotter:     snapshotter,
+		snapshotterName: "native",
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	cm := co.manager
+
+	commit := func(parent ImmutableRef, a fstest.Applier) ImmutableRef {
+		mutRef, err := cm.New(ctx, parent, nil)
+		require.NoError(t, err)
+		mntable, err := mutRef.Mount(ctx, false, nil)
+		require.NoError(t, err)
+		mnts, release, err := mntable.Mount()
+		require.NoError(t, err)
+		require.NoError(t, mount.WithTempMount(ctx, mnts, a.Apply))
+		require.NoError(t, release())
+		ref, err := mutRef.Commit(ctx)
+		require.NoError(t, err)
+		return ref
+	}
+
+	lower := commit(nil, fstest.CreateFile("foo", []byte("foo"), 0644))
+	defer lower.Release(ctx)
+	upper := commit(lower, fstest.Apply(
+		fstest.CreateFile("bar", []byte("bar"), 0644),
+		fstest.Remove("foo"),
+	))
+	defer upper.Release(ctx)
+
+	var buf bytes.Buffer
+	require.NoError(t, upper.WriteLayerDiff(ctx, &buf, nil))
+	files := map[string]string{}
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		dt, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(dt)
+	}
+	require.Equal(t, map[string]string{"bar": "bar", ".wh.foo": ""}, files)
+}
+
 func TestLoadBrokenParents(t *testing.T) {
 	// Test that a ref that has a parent that can't be loaded will not result in any leaks
 	// of other parent refs
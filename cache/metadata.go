@@ -22,6 +22,7 @@ const keyLastUsedAt = "cache.lastUsedAt"
 const keyUsageCount = "cache.usageCount"
 const keyLayerType = "cache.layerType"
 const keyRecordType = "cache.recordType"
+const keyTenant = "cache.tenant"
 const keyCommitted = "snapshot.committed"
 const keyParent = "cache.parent"
 const keyMergeParents = "cache.mergeParents"
@@ -180,6 +181,17 @@ func (md *cacheMetadata) queueRecordType(value client.UsageRecordType) error {
 	return md.queueValue(keyRecordType, value, "")
 }
 
+func (md *cacheMetadata) GetTenant() string {
+	return md.GetString(keyTenant)
+}
+
+func (md *cacheMetadata) queueTenant(id string) error {
+	if id == "" {
+		return nil
+	}
+	return md.queueValue(keyTenant, id, "")
+}
+
 func (md *cacheMetadata) SetCreatedAt(tm time.Time) error {
 	return md.setTime(keyCreatedAt, tm, "")
 }
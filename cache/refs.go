@@ -4,6 +4,7 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"path/filepath"
@@ -68,6 +69,7 @@ type ImmutableRef interface {
 	GetRemotes(ctx context.Context, createIfNeeded bool, cfg config.RefConfig, all bool, s session.Group) ([]*solver.Remote, error)
 	LayerChain() RefList
 	FileList(ctx context.Context, s session.Group) ([]string, error)
+	WriteLayerDiff(ctx context.Context, w io.Writer, s session.Group) error
 }
 
 type MutableRef interface {
@@ -1559,7 +1561,11 @@ func (sr *mutableRef) commit() (_ *immutableRef, rerr error) {
 		}
 	}
 
-	if err := initializeMetadata(rec.cacheMetadata, rec.parentRefs); err != nil {
+	if err := md.queueTenant(sr.GetTenant()); err != nil {
+		return nil, err
+	}
+
+	if err := initializeMetadata(context.TODO(), rec.cacheMetadata, rec.parentRefs); err != nil {
 		return nil, err
 	}
 
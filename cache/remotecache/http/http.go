@@ -0,0 +1,562 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/labels"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/moby/buildkit/cache/remotecache"
+	v1 "github.com/moby/buildkit/cache/remotecache/v1"
+	cacheimporttypes "github.com/moby/buildkit/cache/remotecache/v1/types"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/tracing"
+	"github.com/moby/buildkit/version"
+	"github.com/moby/buildkit/worker"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	attrURL               = "url"
+	attrPrefix            = "prefix"
+	attrManifestsPrefix   = "manifests_prefix"
+	attrBlobsPrefix       = "blobs_prefix"
+	attrName              = "name"
+	attrAuthHeaderSecret  = "auth_header_secret"
+	attrAuthTokenSecret   = "auth_token_secret"
+	attrUploadParallelism = "upload_parallelism"
+)
+
+type Config struct {
+	URL               *url.URL
+	Prefix            string
+	ManifestsPrefix   string
+	BlobsPrefix       string
+	Names             []string
+	AuthHeaderSecret  string
+	AuthTokenSecret   string
+	UploadParallelism int
+}
+
+func getConfig(attrs map[string]string) (Config, error) {
+	urlStr, ok := attrs[attrURL]
+	if !ok || urlStr == "" {
+		return Config{}, errors.Errorf("url not set for http cache")
+	}
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "invalid url %q for http cache", urlStr)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Config{}, errors.Errorf("unsupported url scheme %q for http cache", u.Scheme)
+	}
+
+	manifestsPrefix, ok := attrs[attrManifestsPrefix]
+	if !ok {
+		manifestsPrefix = "manifests/"
+	}
+
+	blobsPrefix, ok := attrs[attrBlobsPrefix]
+	if !ok {
+		blobsPrefix = "blobs/"
+	}
+
+	names := []string{"buildkit"}
+	if name, ok := attrs[attrName]; ok {
+		if splittedNames := strings.Split(name, ";"); len(splittedNames) > 0 {
+			names = splittedNames
+		}
+	}
+
+	authHeaderSecret := attrs[attrAuthHeaderSecret]
+	authTokenSecret := attrs[attrAuthTokenSecret]
+	if authHeaderSecret != "" && authTokenSecret != "" {
+		return Config{}, errors.Errorf("%s and %s are mutually exclusive", attrAuthHeaderSecret, attrAuthTokenSecret)
+	}
+
+	uploadParallelism := 4
+	if v, ok := attrs[attrUploadParallelism]; ok {
+		i, err := strconv.Atoi(v)
+		if err != nil || i <= 0 {
+			return Config{}, errors.Errorf("upload_parallelism must be a positive integer")
+		}
+		uploadParallelism = i
+	}
+
+	return Config{
+		URL:               u,
+		Prefix:            attrs[attrPrefix],
+		ManifestsPrefix:   manifestsPrefix,
+		BlobsPrefix:       blobsPrefix,
+		Names:             names,
+		AuthHeaderSecret:  authHeaderSecret,
+		AuthTokenSecret:   authTokenSecret,
+		UploadParallelism: uploadParallelism,
+	}, nil
+}
+
+// ResolveCacheExporterFunc for "http" cache exporter.
+func ResolveCacheExporterFunc(sm *session.Manager) remotecache.ResolveCacheExporterFunc {
+	return func(ctx context.Context, g session.Group, attrs map[string]string) (remotecache.Exporter, error) {
+		config, err := getConfig(attrs)
+		if err != nil {
+			return nil, err
+		}
+		client, err := newClient(ctx, sm, g, config)
+		if err != nil {
+			return nil, err
+		}
+		cc := v1.NewCacheChains()
+		return &exporter{CacheExporterTarget: cc, chains: cc, client: client, config: config}, nil
+	}
+}
+
+var _ remotecache.Exporter = &exporter{}
+
+type exporter struct {
+	solver.CacheExporterTarget
+	chains *v1.CacheChains
+	client *client
+	config Config
+}
+
+func (*exporter) Name() string {
+	return "exporting cache to HTTP server"
+}
+
+func (*exporter) Config() remotecache.Config {
+	return remotecache.Config{
+		Compression: compression.New(compression.Default),
+	}
+}
+
+func (e *exporter) Finalize(ctx context.Context) (map[string]string, error) {
+	cacheConfig, descs, err := e.chains.Marshal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eg, groupCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(e.config.UploadParallelism)
+
+	for i, l := range cacheConfig.Layers {
+		eg.Go(func() error {
+			dgstPair, ok := descs[l.Blob]
+			if !ok {
+				return errors.Errorf("missing blob %s", l.Blob)
+			}
+			if dgstPair.Descriptor.Annotations == nil {
+				return errors.Errorf("invalid descriptor without annotations")
+			}
+			v, ok := dgstPair.Descriptor.Annotations[labels.LabelUncompressed]
+			if !ok {
+				return errors.Errorf("invalid descriptor without uncompressed annotation")
+			}
+			diffID, err := digest.Parse(v)
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse uncompressed annotation")
+			}
+
+			key := e.client.blobKey(dgstPair.Descriptor.Digest)
+			exists, _, err := e.client.exists(groupCtx, key)
+			if err != nil {
+				return errors.Wrapf(err, "failed to check blob presence in cache")
+			}
+			bklog.G(groupCtx).Debugf("layer %s exists = %t", key, exists)
+
+			if !exists {
+				layerDone := progress.OneOff(groupCtx, fmt.Sprintf("writing layer %s", l.Blob))
+				ra, err := dgstPair.Provider.ReaderAt(groupCtx, dgstPair.Descriptor)
+				if err != nil {
+					return layerDone(errors.Wrap(err, "error reading layer blob from provider"))
+				}
+				defer ra.Close()
+				if err := e.client.put(groupCtx, key, io.NewSectionReader(ra, 0, ra.Size()), ra.Size()); err != nil {
+					return layerDone(errors.Wrap(err, "error writing layer blob"))
+				}
+				layerDone(nil)
+			}
+
+			la := &cacheimporttypes.LayerAnnotations{
+				DiffID:    diffID,
+				Size:      dgstPair.Descriptor.Size,
+				MediaType: dgstPair.Descriptor.MediaType,
+			}
+			if v, ok := dgstPair.Descriptor.Annotations["buildkit/createdat"]; ok {
+				var t time.Time
+				if err := (&t).UnmarshalText([]byte(v)); err != nil {
+					return err
+				}
+				la.CreatedAt = t.UTC()
+			}
+			cacheConfig.Layers[i].Annotations = la
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	dt, err := json.Marshal(cacheConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range e.config.Names {
+		if err := e.client.put(ctx, e.client.manifestKey(name), bytes.NewReader(dt), int64(len(dt))); err != nil {
+			return nil, errors.Wrapf(err, "error writing manifest: %s", name)
+		}
+	}
+	return nil, nil
+}
+
+// ResolveCacheImporterFunc for "http" cache importer.
+func ResolveCacheImporterFunc(sm *session.Manager) remotecache.ResolveCacheImporterFunc {
+	return func(ctx context.Context, g session.Group, attrs map[string]string) (remotecache.Importer, ocispecs.Descriptor, error) {
+		config, err := getConfig(attrs)
+		if err != nil {
+			return nil, ocispecs.Descriptor{}, err
+		}
+		client, err := newClient(ctx, sm, g, config)
+		if err != nil {
+			return nil, ocispecs.Descriptor{}, err
+		}
+		return &importer{client: client, config: config}, ocispecs.Descriptor{}, nil
+	}
+}
+
+var _ remotecache.Importer = &importer{}
+
+type importer struct {
+	client *client
+	config Config
+}
+
+func (i *importer) Resolve(ctx context.Context, _ ocispecs.Descriptor, id string, w worker.Worker) (solver.CacheManager, error) {
+	cc, err := i.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keysStorage, resultStorage, err := v1.NewCacheKeyStorage(cc, w)
+	if err != nil {
+		return nil, err
+	}
+
+	return solver.NewCacheManager(ctx, id, keysStorage, resultStorage), nil
+}
+
+func (i *importer) load(ctx context.Context) (*v1.CacheChains, error) {
+	var config cacheimporttypes.CacheConfig
+	found, err := i.client.getManifest(ctx, i.client.manifestKey(i.config.Names[0]), &config)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return v1.NewCacheChains(), nil
+	}
+
+	allLayers := v1.DescriptorProvider{}
+	for _, l := range config.Layers {
+		dpp, err := i.makeDescriptorProviderPair(l)
+		if err != nil {
+			return nil, err
+		}
+		allLayers[l.Blob] = *dpp
+	}
+
+	cc := v1.NewCacheChains()
+	if err := v1.ParseConfig(config, allLayers, cc); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+func (i *importer) makeDescriptorProviderPair(l cacheimporttypes.CacheLayer) (*v1.DescriptorProviderPair, error) {
+	if l.Annotations == nil {
+		return nil, errors.Errorf("cache layer with missing annotations")
+	}
+	if l.Annotations.DiffID == "" {
+		return nil, errors.Errorf("cache layer with missing diffid")
+	}
+	annotations := map[string]string{}
+	annotations[labels.LabelUncompressed] = l.Annotations.DiffID.String()
+	if !l.Annotations.CreatedAt.IsZero() {
+		txt, err := l.Annotations.CreatedAt.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		annotations["buildkit/createdat"] = string(txt)
+	}
+	desc := ocispecs.Descriptor{
+		MediaType:   l.Annotations.MediaType,
+		Digest:      l.Blob,
+		Size:        l.Annotations.Size,
+		Annotations: annotations,
+	}
+	return &v1.DescriptorProviderPair{
+		Descriptor:   desc,
+		Provider:     contentutil.FromFetcher(i.client),
+		InfoProvider: i.client,
+	}, nil
+}
+
+type client struct {
+	*http.Client
+	base            *url.URL
+	prefix          string
+	blobsPrefix     string
+	manifestsPrefix string
+	authorization   string
+}
+
+func newClient(ctx context.Context, sm *session.Manager, g session.Group, config Config) (*client, error) {
+	c := &client{
+		Client:          tracing.DefaultClient,
+		base:            config.URL,
+		prefix:          config.Prefix,
+		blobsPrefix:     config.BlobsPrefix,
+		manifestsPrefix: config.ManifestsPrefix,
+	}
+
+	secretID, token := config.AuthHeaderSecret, false
+	if config.AuthTokenSecret != "" {
+		secretID, token = config.AuthTokenSecret, true
+	}
+	if secretID == "" {
+		return c, nil
+	}
+	if sm == nil || g == nil {
+		return nil, errors.Errorf("http cache auth secret %s requires session", secretID)
+	}
+	err := sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+		dt, err := secrets.GetSecret(ctx, caller, secretID)
+		if err != nil {
+			return err
+		}
+		c.authorization = string(dt)
+		if token {
+			c.authorization = "Bearer " + c.authorization
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve http cache auth secret %s", secretID)
+	}
+	return c, nil
+}
+
+func (c *client) manifestKey(name string) string {
+	return c.prefix + c.manifestsPrefix + name
+}
+
+func (c *client) blobKey(dgst digest.Digest) string {
+	return c.prefix + c.blobsPrefix + dgst.String()
+}
+
+func (c *client) url(key string) string {
+	u := *c.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(key, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+func (c *client) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url(key), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", version.UserAgent())
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	return req, nil
+}
+
+func (c *client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return resp, nil
+}
+
+func (c *client) exists(ctx context.Context, key string) (bool, int64, error) {
+	req, err := c.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return false, 0, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, 0, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, resp.ContentLength, nil
+	default:
+		return false, 0, statusError(req, resp)
+	}
+}
+
+func (c *client) get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errors.Wrapf(cerrdefs.ErrNotFound, "%s", key)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, statusError(req, resp)
+	}
+	return resp.Body, nil
+}
+
+func (c *client) put(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	err := c.putOnce(ctx, key, body, size)
+	var se *httpStatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusConflict {
+		return err
+	}
+	// WebDAV servers reply with 409 Conflict when the parent collection
+	// does not exist yet. Create it and retry the upload once.
+	if err := c.mkcolAll(ctx, path.Dir(key)); err != nil {
+		return err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	return c.putOnce(ctx, key, body, size)
+}
+
+func (c *client) putOnce(ctx context.Context, key string, body io.Reader, size int64) error {
+	req, err := c.newRequest(ctx, http.MethodPut, key, io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return statusError(req, resp)
+	}
+	return nil
+}
+
+func (c *client) mkcolAll(ctx context.Context, dir string) error {
+	var p string
+	for part := range strings.SplitSeq(strings.Trim(dir, "/"), "/") {
+		if part == "" || part == "." {
+			continue
+		}
+		p += part + "/"
+		req, err := c.newRequest(ctx, "MKCOL", p, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusCreated, http.StatusOK, http.StatusMethodNotAllowed:
+			// 405 is returned when the collection already exists
+		default:
+			return statusError(req, resp)
+		}
+	}
+	return nil
+}
+
+func (c *client) getManifest(ctx context.Context, key string, config *cacheimporttypes.CacheConfig) (bool, error) {
+	rc, err := c.get(ctx, key)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer rc.Close()
+
+	decoder := json.NewDecoder(rc)
+	if err := decoder.Decode(config); err != nil {
+		return false, errors.WithStack(err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return false, errors.Errorf("unexpected data after JSON object")
+	}
+	return true, nil
+}
+
+// Fetch implements remotes.Fetcher
+func (c *client) Fetch(ctx context.Context, desc ocispecs.Descriptor) (io.ReadCloser, error) {
+	return c.get(ctx, c.blobKey(desc.Digest))
+}
+
+// Info implements content.InfoProvider
+func (c *client) Info(ctx context.Context, dgst digest.Digest) (content.Info, error) {
+	exists, size, err := c.exists(ctx, c.blobKey(dgst))
+	if err != nil {
+		return content.Info{}, err
+	}
+	if !exists {
+		return content.Info{}, errors.Wrapf(cerrdefs.ErrNotFound, "blob %s", dgst)
+	}
+	return content.Info{Digest: dgst, Size: size}, nil
+}
+
+type httpStatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %s", e.Method, e.URL, e.Status)
+}
+
+func statusError(req *http.Request, resp *http.Response) error {
+	u := *req.URL
+	u.User = nil
+	return errors.WithStack(&httpStatusError{
+		Method:     req.Method,
+		URL:        u.String(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+	})
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/labels"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/contentutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// davServer is a minimal in-memory WebDAV-like blob server that requires
+// parent collections to exist before PUT, like nginx without
+// create_full_put_path.
+type davServer struct {
+	mu          sync.Mutex
+	files       map[string][]byte
+	collections map[string]struct{}
+	auth        string
+	requests    []string
+}
+
+func newDavServer(auth string) *davServer {
+	return &davServer{
+		files:       map[string][]byte{},
+		collections: map[string]struct{}{"/": {}},
+		auth:        auth,
+	}
+}
+
+func (s *davServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if s.auth != "" && r.Header.Get("Authorization") != s.auth {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		dt, ok := s.files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(dt)))
+		if r.Method == http.MethodGet {
+			w.Write(dt)
+		}
+	case http.MethodPut:
+		if _, ok := s.collections[path.Dir(r.URL.Path)+"/"]; !ok && path.Dir(r.URL.Path) != "/" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		dt, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.files[r.URL.Path] = dt
+		w.WriteHeader(http.StatusCreated)
+	case "MKCOL":
+		p := strings.TrimSuffix(r.URL.Path, "/") + "/"
+		if _, ok := s.collections[p]; ok {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.collections[p] = struct{}{}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	_, err := getConfig(map[string]string{})
+	require.ErrorContains(t, err, "url not set")
+
+	_, err = getConfig(map[string]string{"url": "ftp://example.com"})
+	require.ErrorContains(t, err, "unsupported url scheme")
+
+	_, err = getConfig(map[string]string{
+		"url":                "http://example.com",
+		"auth_header_secret": "a",
+		"auth_token_secret":  "b",
+	})
+	require.ErrorContains(t, err, "mutually exclusive")
+
+	_, err = getConfig(map[string]string{"url": "http://example.com", "upload_parallelism": "0"})
+	require.ErrorContains(t, err, "upload_parallelism")
+
+	cfg, err := getConfig(map[string]string{
+		"url":    "http://example.com/cache",
+		"prefix": "proj/",
+		"name":   "main;pr-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "proj/", cfg.Prefix)
+	require.Equal(t, "blobs/", cfg.BlobsPrefix)
+	require.Equal(t, "manifests/", cfg.ManifestsPrefix)
+	require.Equal(t, []string{"main", "pr-1"}, cfg.Names)
+	require.Equal(t, 4, cfg.UploadParallelism)
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.TODO()
+
+	srv := newDavServer("Bearer s3cr3t")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	config, err := getConfig(map[string]string{
+		"url":    ts.URL + "/cache",
+		"prefix": "proj/",
+		"name":   "main;pr-1",
+	})
+	require.NoError(t, err)
+
+	newTestClient := func() *client {
+		c, err := newClient(ctx, nil, nil, config)
+		require.NoError(t, err)
+		c.authorization = "Bearer s3cr3t"
+		return c
+	}
+
+	buf := contentutil.NewBuffer()
+	blob := []byte("layer-data")
+	blobDesc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageLayerGzip,
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+		Annotations: map[string]string{
+			labels.LabelUncompressed: digest.FromString("uncompressed").String(),
+		},
+	}
+	require.NoError(t, content.WriteBlob(ctx, buf, "blob", strings.NewReader(string(blob)), blobDesc))
+
+	ex, err := ResolveCacheExporterFunc(nil)(ctx, nil, map[string]string{"url": ts.URL})
+	require.NoError(t, err)
+	e := ex.(*exporter)
+	e.client = newTestClient()
+	e.config = config
+
+	foo, ok, err := e.chains.Add(digest.FromString("foo"), nil, nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, err = e.chains.Add(digest.FromString("bar"), [][]solver.CacheLink{{{Src: foo}}}, []solver.CacheExportResult{{
+		CreatedAt: time.Now(),
+		Result: &solver.Remote{
+			Descriptors: []ocispecs.Descriptor{blobDesc},
+			Provider:    buf,
+		},
+	}})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = e.Finalize(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, blob, srv.files["/cache/proj/blobs/"+blobDesc.Digest.String()])
+	require.Contains(t, srv.files, "/cache/proj/manifests/main")
+	require.Contains(t, srv.files, "/cache/proj/manifests/pr-1")
+
+	// exporting again must not upload the existing blob again
+	srv.requests = nil
+	_, err = e.Finalize(ctx)
+	require.NoError(t, err)
+	for _, r := range srv.requests {
+		require.NotEqual(t, "PUT /cache/proj/blobs/"+blobDesc.Digest.String(), r)
+	}
+
+	im := &importer{client: newTestClient(), config: config}
+	cc, err := im.load(ctx)
+	require.NoError(t, err)
+	cfg, descs, err := cc.Marshal(ctx)
+	require.NoError(t, err)
+	require.Len(t, cfg.Layers, 1)
+	require.Len(t, cfg.Records, 2)
+
+	pair, ok := descs[blobDesc.Digest]
+	require.True(t, ok)
+	info, err := pair.Info(ctx, blobDesc.Digest)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(blob)), info.Size)
+	dt, err := content.ReadBlob(ctx, pair.Provider, pair.Descriptor)
+	require.NoError(t, err)
+	require.Equal(t, blob, dt)
+
+	// missing manifest results in empty cache
+	config.Names = []string{"missing"}
+	im = &importer{client: newTestClient(), config: config}
+	cc, err = im.load(ctx)
+	require.NoError(t, err)
+	cfg, _, err = cc.Marshal(ctx)
+	require.NoError(t, err)
+	require.Empty(t, cfg.Records)
+
+	// wrong credentials surface the server status
+	c := newTestClient()
+	c.authorization = "Bearer wrong"
+	_, _, err = c.exists(ctx, c.manifestKey("main"))
+	require.ErrorContains(t, err, "401")
+}
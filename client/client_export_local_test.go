@@ -19,6 +19,7 @@ import (
 	controlapi "github.com/moby/buildkit/api/services/control"
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/exporter/util/resourcereport"
 	gateway "github.com/moby/buildkit/frontend/gateway/client"
 	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
 	"github.com/moby/buildkit/identity"
@@ -129,7 +130,7 @@ func testExportLocalModeCopyKeepsStaleDestinationFiles(t *testing.T, sb integrat
 }
 
 func testExportLocalModeCopyMultiPlatformKeepsAllPlatforms(t *testing.T, sb integration.Sandbox) {
-	testExportLocalModeMultiPlatformKeepsAllPlatforms(t, sb, false)
+	testExportLocalModeMultiPlatformKeepsAllPlatforms(t, sb, LocalExporterModeCopy)
 }
 
 func testExportLocalModeDeleteRemovesStaleDestinationFiles(t *testing.T, sb integration.Sandbox) {
@@ -175,7 +176,7 @@ func testExportLocalModeDeleteRemovesStaleDestinationFiles(t *testing.T, sb inte
 }
 
 func testExportLocalModeDeleteMultiPlatformKeepsAllPlatforms(t *testing.T, sb integration.Sandbox) {
-	testExportLocalModeMultiPlatformKeepsAllPlatforms(t, sb, true)
+	testExportLocalModeMultiPlatformKeepsAllPlatforms(t, sb, LocalExporterModeDelete)
 }
 
 func testExportLocalModeInvalid(t *testing.T, sb integration.Sandbox) {
@@ -205,7 +206,127 @@ func testExportLocalModeInvalid(t *testing.T, sb integration.Sandbox) {
 	require.ErrorContains(t, err, `invalid local exporter mode "backup"`)
 }
 
-func testExportLocalModeMultiPlatformKeepsAllPlatforms(t *testing.T, sb integration.Sandbox, deleteMode bool) {
+func testExportLocalModeSyncMultiPlatformKeepsAllPlatforms(t *testing.T, sb integration.Sandbox) {
+	testExportLocalModeMultiPlatformKeepsAllPlatforms(t, sb, LocalExporterModeSync)
+}
+
+func testExportLocalModeSyncTransfersChangedFiles(t *testing.T, sb integration.Sandbox) {
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	destDir := t.TempDir()
+	err = os.WriteFile(filepath.Join(destDir, "stale.txt"), []byte("stale"), 0600)
+	require.NoError(t, err)
+
+	export := func(content string) {
+		st := llb.Scratch().File(
+			llb.Mkfile("unchanged.txt", 0600, []byte("unchanged")),
+		).File(
+			llb.Mkfile("changed.txt", 0600, []byte(content)),
+		)
+		def, err := st.Marshal(sb.Context())
+		require.NoError(t, err)
+
+		_, err = c.Solve(sb.Context(), def, SolveOpt{
+			Exports: []ExportEntry{
+				{
+					Type:      ExporterLocal,
+					OutputDir: destDir,
+					Attrs: map[string]string{
+						"mode": "sync",
+					},
+				},
+			},
+		}, nil)
+		require.NoError(t, err)
+	}
+
+	export("v1")
+
+	_, err = os.Stat(filepath.Join(destDir, "stale.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	dt, err := os.ReadFile(filepath.Join(destDir, "changed.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(dt))
+	fi1, err := os.Stat(filepath.Join(destDir, "unchanged.txt"))
+	require.NoError(t, err)
+
+	export("v2-longer")
+
+	dt, err = os.ReadFile(filepath.Join(destDir, "changed.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "v2-longer", string(dt))
+
+	// unchanged file is not rewritten
+	fi2, err := os.Stat(filepath.Join(destDir, "unchanged.txt"))
+	require.NoError(t, err)
+	require.True(t, os.SameFile(fi1, fi2))
+	dt, err = os.ReadFile(filepath.Join(destDir, "unchanged.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "unchanged", string(dt))
+}
+
+func testExportLocalResourceReport(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	st := llb.Image("busybox:latest").Run(
+		llb.Shlex(`sh -c "echo -n foo > /out/foo"`),
+		llb.WithCustomName("write foo"),
+	).AddMount("/out", llb.Scratch())
+	def, err := st.Marshal(sb.Context())
+	require.NoError(t, err)
+
+	export := func() resourcereport.Report {
+		destDir := t.TempDir()
+		_, err = c.Solve(sb.Context(), def, SolveOpt{
+			Exports: []ExportEntry{
+				{
+					Type:      ExporterLocal,
+					OutputDir: destDir,
+					Attrs: map[string]string{
+						"resource-report": "true",
+					},
+				},
+			},
+		}, nil)
+		require.NoError(t, err)
+
+		dt, err := os.ReadFile(filepath.Join(destDir, "foo"))
+		require.NoError(t, err)
+		require.Equal(t, "foo", string(dt))
+
+		dt, err = os.ReadFile(filepath.Join(destDir, "resource-report.json"))
+		require.NoError(t, err)
+		var r resourcereport.Report
+		require.NoError(t, json.Unmarshal(dt, &r))
+		return r
+	}
+
+	findStep := func(r resourcereport.Report) resourcereport.Step {
+		for _, s := range r.Steps {
+			if s.Name == "write foo" {
+				return s
+			}
+		}
+		require.Fail(t, "step not found in resource report", "%+v", r)
+		return resourcereport.Step{}
+	}
+
+	step := findStep(export())
+	require.False(t, step.Cached)
+	require.NotEmpty(t, step.CacheMiss)
+	require.Positive(t, step.WallTimeNanos)
+
+	step = findStep(export())
+	require.True(t, step.Cached)
+	require.Empty(t, step.CacheMiss)
+}
+
+func testExportLocalModeMultiPlatformKeepsAllPlatforms(t *testing.T, sb integration.Sandbox, mode LocalExporterMode) {
 	workers.CheckFeatureCompat(t, sb, workers.FeatureOCIExporter, workers.FeatureMultiPlatform)
 	c, err := New(sb.Context(), sb.Address())
 	require.NoError(t, err)
@@ -282,9 +403,10 @@ func testExportLocalModeMultiPlatformKeepsAllPlatforms(t *testing.T, sb integrat
 	}
 
 	attrs := map[string]string{}
-	if deleteMode {
-		attrs["mode"] = "delete"
+	if mode != LocalExporterModeCopy {
+		attrs["mode"] = string(mode)
 	}
+	deleteMode := mode != LocalExporterModeCopy
 
 	_, err = c.Build(sb.Context(), SolveOpt{
 		Exports: []ExportEntry{
@@ -86,9 +86,12 @@ var allTests = []func(t *testing.T, sb integration.Sandbox){
 	testExportLocalModeCopyMultiPlatformKeepsAllPlatforms,
 	testExportLocalModeDeleteRemovesStaleDestinationFiles,
 	testExportLocalModeDeleteMultiPlatformKeepsAllPlatforms,
+	testExportLocalModeSyncTransfersChangedFiles,
+	testExportLocalModeSyncMultiPlatformKeepsAllPlatforms,
 	testExportLocalModeInvalid,
 	testExportLocalNoPlatformSplit,
 	testExportLocalNoPlatformSplitOverwrite,
+	testExportLocalResourceReport,
 	testExporterTargetExists,
 	testMultipleExporters,
 	testSessionExporter,
@@ -256,6 +259,7 @@ func TestClientGatewayIntegration(t *testing.T) {
 		testClientGatewayContainerPID1Fail,
 		testClientGatewayContainerPID1Tty,
 		testClientGatewayContainerSignal,
+		testClientGatewayDebugger,
 		testClientGatewayExecError,
 		testClientGatewayExecFileActionError,
 		testClientGatewaySlowCacheExecError,
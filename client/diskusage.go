@@ -74,6 +74,8 @@ type DiskUsageOption interface {
 type DiskUsageInfo struct {
 	Filter   []string
 	AgeLimit time.Duration
+	// Tenant limits the records to the build cache of a tenant.
+	Tenant string
 }
 
 type UsageRecordType string
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/frontend/gateway/debugger"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/testutil/integration"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// testClientGatewayDebugger is testing that a build can be stopped at a
+// breakpoint set by source location and that the inputs of the vertex can be
+// inspected through the debugger service
+func testClientGatewayDebugger(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	ctx := sb.Context()
+
+	c, err := New(ctx, sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	// unix socket paths are limited in length
+	dir, err := os.MkdirTemp("", "buildkit-debugger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	addr := "unix://" + filepath.Join(dir, "debugger.sock")
+
+	d := debugger.New()
+	l, err := debugger.Listen(addr)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	d.Register(server)
+	go server.Serve(l)
+	defer server.Stop()
+
+	conn, err := debugger.Dial(addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	dc := debugger.NewDebuggerClient(conn)
+
+	lines := func(line int32) []*pb.Range {
+		return []*pb.Range{{Start: &pb.Position{Line: line}, End: &pb.Position{Line: line}}}
+	}
+	sm := llb.NewSourceMap(nil, "Dockerfile", "Dockerfile", []byte("FROM busybox\nRUN echo foo > /foo\nRUN cat /foo\n"))
+	st := llb.Image("busybox:latest", sm.Location(lines(1)))
+	st = st.Run(llb.Shlex(`sh -c "echo foo > /foo"`), sm.Location(lines(2))).Root()
+	st = st.Run(llb.Shlex("cat /foo"), llb.AddEnv("FOO", "bar"), sm.Location(lines(3))).Root()
+
+	product := "buildkit_test"
+	b := func(ctx context.Context, c client.Client) (*client.Result, error) {
+		def, err := st.Marshal(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal state")
+		}
+		res, err := c.Solve(ctx, client.SolveRequest{
+			Definition: def.ToPB(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := d.Run(ctx, c, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := c.Build(ctx, SolveOpt{}, product, b, nil)
+		errCh <- err
+	}()
+
+	w, err := dc.Wait(ctx, &debugger.WaitRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, w.Stop)
+	require.Empty(t, w.Stop.Inputs)
+
+	_, err = dc.SetBreakpoints(ctx, &debugger.SetBreakpointsRequest{
+		Breakpoints: []*debugger.Breakpoint{{Filename: "Dockerfile", Line: 3}},
+	})
+	require.NoError(t, err)
+	_, err = dc.Continue(ctx, &debugger.ContinueRequest{})
+	require.NoError(t, err)
+
+	w, err = dc.Wait(ctx, &debugger.WaitRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, w.Stop)
+	require.Equal(t, "cat /foo", w.Stop.Name)
+	require.Equal(t, int32(3), w.Stop.Breakpoint.GetLine())
+	require.Len(t, w.Stop.Inputs, 1)
+
+	rd, err := dc.ReadDir(ctx, &debugger.ReadDirRequest{Path: "/", IncludePattern: "foo"})
+	require.NoError(t, err)
+	require.Len(t, rd.Entries, 1)
+	require.Equal(t, "foo", rd.Entries[0].Path)
+
+	exec := func(args ...string) (string, uint32) {
+		stream, err := dc.Exec(ctx, &debugger.ExecRequest{Args: args})
+		require.NoError(t, err)
+		var out bytes.Buffer
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				t.Fatal("exec did not exit")
+			}
+			require.NoError(t, err)
+			out.Write(resp.Stdout)
+			out.Write(resp.Stderr)
+			if resp.Exited {
+				return out.String(), resp.ExitCode
+			}
+		}
+	}
+	out, code := exec("cat", "/foo")
+	require.Equal(t, "foo\n", out)
+	require.Equal(t, uint32(0), code)
+
+	// commands run with the environment of the vertex
+	out, code = exec("sh", "-c", "echo $FOO; exit 3")
+	require.Equal(t, "bar\n", out)
+	require.Equal(t, uint32(3), code)
+
+	_, err = dc.Continue(ctx, &debugger.ContinueRequest{})
+	require.NoError(t, err)
+	w, err = dc.Wait(ctx, &debugger.WaitRequest{})
+	require.NoError(t, err)
+	require.True(t, w.Done)
+	require.Empty(t, w.Error)
+
+	require.NoError(t, <-errCh)
+
+	checkAllReleasable(t, c, sb, true)
+}
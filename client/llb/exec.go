@@ -5,6 +5,7 @@ import (
 	_ "crypto/sha256" // for opencontainers/go-digest
 	"fmt"
 	"net"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -61,6 +62,7 @@ type ExecOp struct {
 	secrets     []SecretInfo
 	ssh         []SSHInfo
 	cdiDevices  []CDIDeviceInfo
+	services    []ServiceInfo
 }
 
 func (e *ExecOp) AddMount(target string, source Output, opt ...MountOption) Output {
@@ -127,6 +129,23 @@ func (e *ExecOp) Validate(ctx context.Context, c *Constraints) error {
 			}
 		}
 	}
+	names := map[string]struct{}{}
+	for _, s := range e.services {
+		if !serviceNameRegexp.MatchString(s.Name) {
+			return errors.Errorf("invalid service name %q", s.Name)
+		}
+		if _, ok := names[s.Name]; ok {
+			return errors.Errorf("duplicate service name %q", s.Name)
+		}
+		names[s.Name] = struct{}{}
+		out := s.State.Output()
+		if out == nil {
+			return errors.Errorf("service %s requires a root filesystem", s.Name)
+		}
+		if err := out.Vertex(ctx, c).Validate(ctx, c); err != nil {
+			return err
+		}
+	}
 	e.isValidated = true
 	return nil
 }
@@ -328,12 +347,22 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 				break
 			}
 		}
+		for _, s := range e.secrets {
+			if s.CacheDigest {
+				addCap(&e.constraints, pb.CapExecSecretCacheDigest)
+				break
+			}
+		}
 	}
 
 	if len(e.ssh) > 0 {
 		addCap(&e.constraints, pb.CapExecMountSSH)
 	}
 
+	if len(e.services) > 0 {
+		addCap(&e.constraints, pb.CapExecServices)
+	}
+
 	if len(e.cdiDevices) > 0 {
 		addCap(&e.constraints, pb.CapExecMetaCDI)
 		cd := make([]*pb.CDIDevice, len(e.cdiDevices))
@@ -435,9 +464,11 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 	for _, s := range e.secrets {
 		if s.Env != nil {
 			peo.Secretenv = append(peo.Secretenv, &pb.SecretEnv{
-				ID:       s.ID,
-				Name:     *s.Env,
-				Optional: s.Optional,
+				ID:          s.ID,
+				Name:        *s.Env,
+				Optional:    s.Optional,
+				NoRedact:    s.NoRedact,
+				CacheDigest: s.CacheDigest,
 			})
 		}
 		if s.Target != nil {
@@ -446,17 +477,47 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 				Dest:      *s.Target,
 				MountType: pb.MountType_SECRET,
 				SecretOpt: &pb.SecretOpt{
-					ID:       s.ID,
-					Uid:      uint32(s.UID),
-					Gid:      uint32(s.GID),
-					Optional: s.Optional,
-					Mode:     uint32(s.Mode),
+					ID:          s.ID,
+					Uid:         uint32(s.UID),
+					Gid:         uint32(s.GID),
+					Optional:    s.Optional,
+					Mode:        uint32(s.Mode),
+					NoRedact:    s.NoRedact,
+					CacheDigest: s.CacheDigest,
 				},
 			}
 			peo.Mounts = append(peo.Mounts, pm)
 		}
 	}
 
+	for _, s := range e.services {
+		inp, err := s.State.Output().ToInput(ctx, c)
+		if err != nil {
+			return "", nil, nil, nil, err
+		}
+		inputIndex := pb.InputIndex(len(pop.Inputs))
+		newInput := true
+		for i, inp2 := range pop.Inputs {
+			if inp.EqualVT(inp2) {
+				inputIndex = pb.InputIndex(i)
+				newInput = false
+				break
+			}
+		}
+		if newInput {
+			pop.Inputs = append(pop.Inputs, inp)
+		}
+		smeta, err := serviceMeta(ctx, s, c)
+		if err != nil {
+			return "", nil, nil, nil, err
+		}
+		peo.Services = append(peo.Services, &pb.Service{
+			Name:  s.Name,
+			Input: int64(inputIndex),
+			Meta:  smeta,
+		})
+	}
+
 	for _, s := range e.ssh {
 		pm := &pb.Mount{
 			Input:     int64(pb.Empty),
@@ -500,10 +561,46 @@ func (e *ExecOp) Inputs() (inputs []Output) {
 			}
 		}
 	}
+	for _, s := range e.services {
+		if out := s.State.Output(); out != nil {
+			if _, ok := seen[out]; !ok {
+				inputs = append(inputs, out)
+				seen[out] = struct{}{}
+			}
+		}
+	}
 
 	return
 }
 
+func serviceMeta(ctx context.Context, s ServiceInfo, c *Constraints) (*pb.Meta, error) {
+	args, err := getArgs(s.State)(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.Errorf("arguments are required for service %s", s.Name)
+	}
+	env, err := getEnv(s.State)(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	cwd, err := getDir(s.State)(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	user, err := getUser(s.State)(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Meta{
+		Args: args,
+		Env:  env.ToArray(),
+		Cwd:  cwd,
+		User: user,
+	}, nil
+}
+
 func (e *ExecOp) getMountIndexFn(m *mount) func() (pb.OutputIndex, error) {
 	return func() (pb.OutputIndex, error) {
 		// make sure mounts are sorted
@@ -683,6 +780,28 @@ type CDIDeviceInfo struct {
 	Optional bool
 }
 
+// AddService is a RunOption that starts a service container from st for the
+// duration of the exec. The service shares the network namespace of the exec
+// and is reachable by name. The arguments, environment, working directory and
+// user of the service are read from st after applying opts, eg. [Shlex] or
+// [AddEnv]. Options that don't modify the state, like mounts, are ignored.
+func AddService(name string, st State, opts ...RunOption) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		si := &ExecInfo{State: st}
+		for _, o := range opts {
+			o.SetRunOption(si)
+		}
+		ei.Services = append(ei.Services, ServiceInfo{Name: name, State: si.State})
+	})
+}
+
+type ServiceInfo struct {
+	Name  string
+	State State
+}
+
+var serviceNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+
 func ValidExitCodes(codes ...int) RunOption {
 	return runOptionFunc(func(ei *ExecInfo) {
 		ei.State = validExitCodes(codes...)(ei.State)
@@ -806,12 +925,29 @@ type SecretInfo struct {
 	UID      int
 	GID      int
 	Optional bool
+	// NoRedact disables replacing the secret value in the logs of the exec
+	NoRedact bool
+	// CacheDigest includes a digest of the secret value in the cache key
+	CacheDigest bool
 }
 
 var SecretOptional = secretOptionFunc(func(si *SecretInfo) {
 	si.Optional = true
 })
 
+// SecretNoRedact disables replacing the secret value with "***" in the logs
+// of the exec.
+var SecretNoRedact = secretOptionFunc(func(si *SecretInfo) {
+	si.NoRedact = true
+})
+
+// SecretCacheDigest includes a digest of the secret value, keyed by the
+// daemon, in the cache key of the exec. Changing the value of the secret
+// invalidates the cache of the exec.
+var SecretCacheDigest = secretOptionFunc(func(si *SecretInfo) {
+	si.CacheDigest = true
+})
+
 func SecretID(id string) SecretOption {
 	return secretOptionFunc(func(si *SecretInfo) {
 		si.ID = id
@@ -875,6 +1011,7 @@ type ExecInfo struct {
 	Secrets        []SecretInfo
 	SSH            []SSHInfo
 	CDIDevices     []CDIDeviceInfo
+	Services       []ServiceInfo
 }
 
 type MountInfo struct {
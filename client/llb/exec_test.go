@@ -3,8 +3,10 @@ package llb
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -124,6 +126,43 @@ func TestLinuxResourcesNotInCacheKey(t *testing.T) {
 	require.Equal(t, def1.Def, def3.Def, "resource limits vs no limits should produce same digest")
 }
 
+func TestTimeoutMarshal(t *testing.T) {
+	t.Parallel()
+
+	st1 := Image("busybox:latest").
+		Run(Shlex("sleep 1"), WithTimeout(10*time.Minute)).Root()
+
+	st2 := Image("busybox:latest").
+		Run(Shlex("sleep 1")).Root()
+
+	def1, err := st1.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	def2, err := st2.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	// the timeout is not part of the cache key
+	require.Equal(t, def1.Def, def2.Def)
+
+	var found bool
+	for _, md := range def1.Metadata {
+		if md.Timeout == 0 {
+			continue
+		}
+		found = true
+		require.Equal(t, 10*time.Minute, md.Timeout)
+	}
+	require.True(t, found, "Timeout not found in OpMetadata")
+
+	var hasCap bool
+	for _, md := range def1.Metadata {
+		if md.Caps[pb.CapMetaTimeout] {
+			hasCap = true
+		}
+	}
+	require.True(t, hasCap)
+}
+
 func TestLinuxResourcesMerge(t *testing.T) {
 	t.Parallel()
 
@@ -172,3 +211,83 @@ func TestExecOpMarshalConsistency(t *testing.T) {
 		prevDef = def.Def
 	}
 }
+
+func TestExecServicesMarshal(t *testing.T) {
+	t.Parallel()
+
+	db := Image("postgres:16").
+		Dir("/var/lib/postgresql").
+		Run(Shlex("true")).Root()
+
+	st := Image("busybox:latest").
+		Run(Shlex("pg_isready -h db"), AddService("db", db,
+			Shlex("postgres -c fsync=off"),
+			AddEnv("POSTGRES_PASSWORD", "test"),
+			User("postgres"),
+		)).Root()
+
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, idx := last(t, arr)
+	require.Equal(t, 0, idx)
+
+	exec := m[dgst].Op.(*pb.Op_Exec).Exec
+	require.Len(t, exec.Services, 1)
+	svc := exec.Services[0]
+	require.Equal(t, "db", svc.Name)
+	require.Equal(t, []string{"postgres", "-c", "fsync=off"}, svc.Meta.Args)
+	require.Contains(t, svc.Meta.Env, "POSTGRES_PASSWORD=test")
+	require.Equal(t, "/var/lib/postgresql", svc.Meta.Cwd)
+	require.Equal(t, "postgres", svc.Meta.User)
+
+	// the service root filesystem is an input of the exec
+	inputs := m[dgst].Inputs
+	require.Len(t, inputs, 2)
+	require.Equal(t, int64(1), svc.Input)
+	_, ok := m[inputs[svc.Input].Digest].Op.(*pb.Op_Exec)
+	require.True(t, ok)
+	require.Contains(t, def.Metadata[digest.Digest(dgst)].Caps, pb.CapExecServices)
+}
+
+func TestExecServicesInvalid(t *testing.T) {
+	t.Parallel()
+
+	svc := Image("redis")
+	args := Shlex("redis-server")
+
+	for _, tc := range []struct {
+		name string
+		opts []RunOption
+		err  string
+	}{
+		{
+			name: "invalid name",
+			opts: []RunOption{AddService("my_db", svc, args)},
+			err:  "invalid service name",
+		},
+		{
+			name: "duplicate name",
+			opts: []RunOption{AddService("db", svc, args), AddService("db", svc, args)},
+			err:  "duplicate service name",
+		},
+		{
+			name: "no rootfs",
+			opts: []RunOption{AddService("db", Scratch(), args)},
+			err:  "requires a root filesystem",
+		},
+		{
+			name: "no args",
+			opts: []RunOption{AddService("db", Image("redis"))},
+			err:  "arguments are required for service db",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			st := Image("busybox:latest").Run(append([]RunOption{Shlex("true")}, tc.opts...)...).Root()
+			_, err := st.Marshal(context.TODO())
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
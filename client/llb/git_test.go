@@ -117,6 +117,28 @@ func TestGit(t *testing.T) {
 				"git.checkoutbundle":   "true",
 			},
 		},
+		{
+			name:       "sparse paths",
+			st:         Git("github.com/foo/bar.git", "ref:subdir", GitSparsePaths("a", "b/c")),
+			identifier: "git://github.com/foo/bar.git#ref:subdir",
+			attrs: map[string]string{
+				"git.authheadersecret": "GIT_AUTH_HEADER",
+				"git.authtokensecret":  "GIT_AUTH_TOKEN",
+				"git.fullurl":          "https://github.com/foo/bar.git",
+				"git.sparsepaths":      `["a","b/c"]`,
+			},
+		},
+		{
+			name:       "lfs",
+			st:         Git("github.com/foo/bar.git", "ref", GitLFS()),
+			identifier: "git://github.com/foo/bar.git#ref",
+			attrs: map[string]string{
+				"git.authheadersecret": "GIT_AUTH_HEADER",
+				"git.authtokensecret":  "GIT_AUTH_TOKEN",
+				"git.fullurl":          "https://github.com/foo/bar.git",
+				"git.lfs":              "true",
+			},
+		},
 	}
 
 	for _, tc := range tcases {
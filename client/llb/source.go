@@ -503,6 +503,17 @@ func Git(url, fragment string, opts ...GitOption) State {
 		addCap(&gi.Constraints, pb.CapSourceGitCheckoutBundle)
 	}
 
+	if len(gi.SparsePaths) > 0 {
+		dt, _ := json.Marshal(gi.SparsePaths) // empty on error
+		attrs[pb.AttrGitSparsePaths] = string(dt)
+		addCap(&gi.Constraints, pb.CapSourceGitSparsePaths)
+	}
+
+	if gi.LFS {
+		attrs[pb.AttrGitLFS] = "true"
+		addCap(&gi.Constraints, pb.CapSourceGitLFS)
+	}
+
 	addCap(&gi.Constraints, pb.CapSourceGit)
 
 	source := NewSource("git://"+id, attrs, gi.Constraints)
@@ -536,6 +547,8 @@ type GitInfo struct {
 	BundleOCIStoreID   string
 	CheckoutBundle     bool
 	FetchByCommit      bool
+	SparsePaths        []string
+	LFS                bool
 }
 
 func GitRef(v string) GitOption {
@@ -550,6 +563,26 @@ func GitSubDir(v string) GitOption {
 	})
 }
 
+// GitSparsePaths limits the checkout to the given directories, relative to
+// the subdir if one is set. Other files are not fetched from the remote and the
+// cache key only depends on the contents of the selected directories, so
+// commits that only change other parts of the repository do not invalidate
+// the cache. It can not be combined with [KeepGitDir].
+func GitSparsePaths(paths ...string) GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.SparsePaths = append(gi.SparsePaths, paths...)
+	})
+}
+
+// GitLFS replaces the Git LFS pointer files in the checkout with the LFS
+// objects they point to. The objects are fetched from the LFS server of the
+// remote with the same credentials as the repository.
+func GitLFS() GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.LFS = true
+	})
+}
+
 func GitSkipSubmodules() GitOption {
 	return gitOptionFunc(func(gi *GitInfo) {
 		gi.SkipSubmodules = true
@@ -973,6 +1006,11 @@ func HTTP(url string, opts ...HTTPOption) State {
 		attrs[pb.AttrHTTPAuthHeaderSecret] = hi.AuthHeaderSecret
 		addCap(&hi.Constraints, pb.CapSourceHTTPAuth)
 	}
+	if len(hi.Mirrors) > 0 {
+		dt, _ := json.Marshal(hi.Mirrors) // empty on error
+		attrs[pb.AttrHTTPMirrors] = string(dt)
+		addCap(&hi.Constraints, pb.CapSourceHTTPMirrors)
+	}
 	if hi.Header != nil {
 		hi.Header.setAttrs(attrs)
 		addCap(&hi.Constraints, pb.CapSourceHTTPHeader)
@@ -1029,6 +1067,7 @@ type HTTPInfo struct {
 	AuthHeaderSecret string
 	Header           *HTTPHeader
 	Signature        *HTTPSignatureInfo
+	Mirrors          []string
 }
 
 type HTTPOption interface {
@@ -1047,6 +1086,15 @@ func Checksum(dgst digest.Digest) HTTPOption {
 	})
 }
 
+// Mirrors returns an [HTTPOption] that sets the URLs the content is downloaded
+// from, in order, if the download from the source URL fails. The content from
+// all URLs is verified against the [Checksum], which is required.
+func Mirrors(urls ...string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.Mirrors = append(hi.Mirrors, urls...)
+	})
+}
+
 func Chmod(perm os.FileMode) FileInfoOption {
 	return fileInfoOptFunc(func(fi *fileInfo) {
 		fi.Perm = int(perm) & 0777
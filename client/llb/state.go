@@ -8,6 +8,7 @@ import (
 	"net"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/containerd/platforms"
 	"github.com/moby/buildkit/identity"
@@ -181,6 +182,9 @@ func (s State) Marshal(ctx context.Context, co ...ConstraintsOpt) (*Definition,
 		if m.ExportCache != nil {
 			md.Caps[pb.CapMetaExportCache] = true
 		}
+		if m.Timeout != 0 {
+			md.Caps[pb.CapMetaTimeout] = true
+		}
 	}
 
 	def.Metadata[dgst] = md
@@ -297,6 +301,7 @@ func (s State) Run(ro ...RunOption) ExecState {
 	exec.secrets = ei.Secrets
 	exec.ssh = ei.SSH
 	exec.cdiDevices = ei.CDIDevices
+	exec.services = ei.Services
 
 	return ExecState{
 		State: s.WithOutput(exec.Output()),
@@ -607,6 +612,10 @@ func mergeMetadata(m1, m2 OpMetadata) OpMetadata {
 		m1.LinuxResources = m2.LinuxResources
 	}
 
+	if m2.Timeout != 0 {
+		m1.Timeout = m2.Timeout
+	}
+
 	return m1
 }
 
@@ -690,6 +699,7 @@ type OpMetadata struct {
 	Caps           map[apicaps.CapID]bool `json:"caps,omitempty"`
 	ProgressGroup  *pb.ProgressGroup      `json:"progress_group,omitempty"`
 	LinuxResources *pb.LinuxResources     `json:"linux_resources,omitempty"`
+	Timeout        time.Duration          `json:"timeout,omitempty"`
 }
 
 func NewOpMetadata(mpb *pb.OpMetadata) OpMetadata {
@@ -710,6 +720,7 @@ func (m OpMetadata) ToPB() *pb.OpMetadata {
 		Caps:           caps,
 		ProgressGroup:  m.ProgressGroup,
 		LinuxResources: m.LinuxResources,
+		Timeout:        int64(m.Timeout),
 	}
 }
 
@@ -731,6 +742,7 @@ func (m *OpMetadata) FromPB(mpb *pb.OpMetadata) {
 	}
 	m.ProgressGroup = mpb.ProgressGroup
 	m.LinuxResources = mpb.LinuxResources
+	m.Timeout = time.Duration(mpb.Timeout)
 }
 
 func Platform(p ocispecs.Platform) ConstraintsOpt {
@@ -767,6 +779,14 @@ func WithLinuxResources(res LinuxResources) ConstraintsOpt {
 	})
 }
 
+// WithTimeout sets the maximum duration the vertex is allowed to run for.
+// The timeout is applied via OpMetadata and does not affect the cache key.
+func WithTimeout(d time.Duration) ConstraintsOpt {
+	return constraintsOptFunc(func(c *Constraints) {
+		c.Metadata.Timeout = d
+	})
+}
+
 func ensureLinuxResources(c *Constraints) *pb.LinuxResources {
 	if c.Metadata.LinuxResources == nil {
 		c.Metadata.LinuxResources = &pb.LinuxResources{}
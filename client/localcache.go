@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	contentlocal "github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/docker/go-units"
+	"github.com/moby/buildkit/client/ociindex"
+	"github.com/moby/buildkit/util/bklog"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	localCacheAttrKeepTags = "keep-tags"
+	localCacheAttrMaxAge   = "max-age"
+	localCacheAttrMaxSize  = "max-size"
+
+	localCacheAttrGracePeriod = "grace-period"
+)
+
+// LocalCachePruneOpt defines the retention policy applied to a local cache
+// directory by PruneLocalCache.
+type LocalCachePruneOpt struct {
+	// KeepTags keeps only the N most recently exported manifests in
+	// index.json. Zero disables the limit.
+	KeepTags int
+	// MaxAge removes manifests that were exported longer ago than this
+	// duration. Zero disables the limit.
+	MaxAge time.Duration
+	// MaxSize removes the oldest manifests until the total size of the
+	// remaining referenced blobs is below this limit. The newest manifest is
+	// always kept. Zero disables the limit.
+	MaxSize int64
+	// GracePeriod keeps unreferenced blobs that were written more recently
+	// than this duration, so blobs of exports that have not updated
+	// index.json yet are not removed. Zero removes all unreferenced blobs.
+	GracePeriod time.Duration
+}
+
+func (opt LocalCachePruneOpt) isZero() bool {
+	return opt.KeepTags == 0 && opt.MaxAge == 0 && opt.MaxSize == 0
+}
+
+// LocalCachePruneResult describes what was removed by PruneLocalCache.
+type LocalCachePruneResult struct {
+	RemovedManifests []ocispecs.Descriptor
+	RemovedBlobs     []digest.Digest
+	Reclaimed        int64
+	Size             int64
+}
+
+func parseLocalCachePruneOpt(attrs map[string]string) (LocalCachePruneOpt, error) {
+	var opt LocalCachePruneOpt
+	if v, ok := attrs[localCacheAttrKeepTags]; ok {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return opt, errors.Errorf("invalid %s value %q", localCacheAttrKeepTags, v)
+		}
+		opt.KeepTags = i
+	}
+	if v, ok := attrs[localCacheAttrMaxAge]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return opt, errors.Errorf("invalid %s value %q", localCacheAttrMaxAge, v)
+		}
+		opt.MaxAge = d
+	}
+	if v, ok := attrs[localCacheAttrMaxSize]; ok {
+		i, err := units.RAMInBytes(v)
+		if err != nil || i < 0 {
+			return opt, errors.Errorf("invalid %s value %q", localCacheAttrMaxSize, v)
+		}
+		opt.MaxSize = i
+	}
+	if v, ok := attrs[localCacheAttrGracePeriod]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return opt, errors.Errorf("invalid %s value %q", localCacheAttrGracePeriod, v)
+		}
+		opt.GracePeriod = d
+	}
+	return opt, nil
+}
+
+// PruneLocalCache applies the retention policy to the local cache directory
+// at storePath and deletes all blobs that are no longer reachable from the
+// manifests in index.json. The index is locked for the whole operation.
+func PruneLocalCache(ctx context.Context, storePath string, opt LocalCachePruneOpt) (*LocalCachePruneResult, error) {
+	cs, err := contentlocal.NewStore(storePath)
+	if err != nil {
+		return nil, err
+	}
+	return pruneCacheStore(ctx, cs, storePath, opt)
+}
+
+func pruneCacheStore(ctx context.Context, cs content.Store, storePath string, opt LocalCachePruneOpt) (*LocalCachePruneResult, error) {
+	res := &LocalCachePruneResult{}
+	err := ociindex.NewStoreIndex(storePath).Update(func(idx *ocispecs.Index) error {
+		blobs := map[digest.Digest]content.Info{}
+		if err := cs.Walk(ctx, func(info content.Info) error {
+			blobs[info.Digest] = info
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "failed to walk content store")
+		}
+
+		type entry struct {
+			index   int
+			desc    ocispecs.Descriptor
+			created time.Time
+		}
+		// newest manifests first
+		entries := make([]entry, 0, len(idx.Manifests))
+		for i, m := range idx.Manifests {
+			entries = append(entries, entry{index: i, desc: m, created: manifestCreated(m, blobs)})
+		}
+		slices.SortStableFunc(entries, func(a, b entry) int {
+			return b.created.Compare(a.created)
+		})
+		manifests := make([]ocispecs.Descriptor, len(entries))
+		for i, e := range entries {
+			manifests[i] = e.desc
+		}
+
+		keep := len(entries)
+		if opt.KeepTags > 0 && keep > opt.KeepTags {
+			keep = opt.KeepTags
+		}
+		if opt.MaxAge > 0 {
+			for i := range keep {
+				if time.Since(entries[i].created) > opt.MaxAge {
+					keep = i
+					break
+				}
+			}
+		}
+
+		referenced, err := referencedBlobs(ctx, cs, manifests[:keep])
+		if err != nil {
+			return err
+		}
+		if opt.MaxSize > 0 {
+			for keep > 1 && referencedSize(referenced, blobs) > opt.MaxSize {
+				keep--
+				referenced, err = referencedBlobs(ctx, cs, manifests[:keep])
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if keep < len(entries) {
+			removed := make(map[int]struct{}, len(entries)-keep)
+			for _, e := range entries[keep:] {
+				removed[e.index] = struct{}{}
+				res.RemovedManifests = append(res.RemovedManifests, e.desc)
+			}
+			var kept []ocispecs.Descriptor
+			for i, m := range idx.Manifests {
+				if _, ok := removed[i]; !ok {
+					kept = append(kept, m)
+				}
+			}
+			idx.Manifests = kept
+		}
+
+		for dgst, info := range blobs {
+			if _, ok := referenced[dgst]; ok {
+				res.Size += info.Size
+				continue
+			}
+			if opt.GracePeriod > 0 && time.Since(info.CreatedAt) < opt.GracePeriod {
+				res.Size += info.Size
+				continue
+			}
+			if err := cs.Delete(ctx, dgst); err != nil {
+				bklog.G(ctx).WithError(err).Warnf("failed to delete blob %s", dgst)
+				res.Size += info.Size
+				continue
+			}
+			res.RemovedBlobs = append(res.RemovedBlobs, dgst)
+			res.Reclaimed += info.Size
+		}
+		slices.Sort(res.RemovedBlobs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// manifestCreated returns the time a manifest was added to the index,
+// falling back to the modification time of the manifest blob for entries
+// written by older versions.
+func manifestCreated(desc ocispecs.Descriptor, blobs map[digest.Digest]content.Info) time.Time {
+	if v, ok := desc.Annotations[ocispecs.AnnotationCreated]; ok {
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	}
+	return blobs[desc.Digest].CreatedAt
+}
+
+func referencedBlobs(ctx context.Context, cs content.Store, manifests []ocispecs.Descriptor) (map[digest.Digest]struct{}, error) {
+	var mu sync.Mutex
+	referenced := make(map[digest.Digest]struct{})
+	childrenHandler := images.ChildrenHandler(cs)
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
+		mu.Lock()
+		referenced[desc.Digest] = struct{}{}
+		mu.Unlock()
+		return childrenHandler(ctx, desc)
+	})
+	if err := images.Dispatch(ctx, handler, nil, manifests...); err != nil {
+		return nil, errors.Wrap(err, "failed to collect referenced blobs")
+	}
+	return referenced, nil
+}
+
+func referencedSize(referenced map[digest.Digest]struct{}, blobs map[digest.Digest]content.Info) int64 {
+	var size int64
+	for dgst := range referenced {
+		size += blobs[dgst].Size
+	}
+	return size
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	contentlocal "github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/moby/buildkit/client/ociindex"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type testCacheManifest struct {
+	manifest digest.Digest
+	layer    digest.Digest
+}
+
+// putCacheManifest writes a manifest with a unique config and a layer of the
+// given size and tags it in index.json as exported at created.
+func putCacheManifest(ctx context.Context, t *testing.T, dir string, cs content.Store, tag string, layerSize int, created time.Time) testCacheManifest {
+	t.Helper()
+	configData := fmt.Appendf(nil, `{"tag":%q}`, tag)
+	layerData := make([]byte, layerSize)
+	copy(layerData, tag)
+
+	configDgst := writeBlob(ctx, t, cs, configData)
+	layerDgst := writeBlob(ctx, t, cs, layerData)
+	m := ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    ocispecs.Descriptor{Digest: configDgst, Size: int64(len(configData)), MediaType: "application/vnd.buildkit.cacheconfig.v0"},
+		Layers:    []ocispecs.Descriptor{{Digest: layerDgst, Size: int64(len(layerData))}},
+	}
+	mData, err := json.Marshal(m)
+	require.NoError(t, err)
+	mDgst := writeBlob(ctx, t, cs, mData)
+
+	idx := ociindex.NewStoreIndex(dir)
+	require.NoError(t, idx.Put(ocispecs.Descriptor{
+		Digest:    mDgst,
+		Size:      int64(len(mData)),
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Annotations: map[string]string{
+			ocispecs.AnnotationCreated: created.UTC().Format(time.RFC3339Nano),
+		},
+	}, ociindex.Tag(tag)))
+	return testCacheManifest{manifest: mDgst, layer: layerDgst}
+}
+
+func indexTags(t *testing.T, dir string) []string {
+	t.Helper()
+	idx, err := ociindex.NewStoreIndex(dir).Read()
+	require.NoError(t, err)
+	var tags []string
+	for _, m := range idx.Manifests {
+		tags = append(tags, m.Annotations[ocispecs.AnnotationRefName])
+	}
+	return tags
+}
+
+func TestPruneLocalCacheKeepTags(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	cs, err := contentlocal.NewStore(dir)
+	require.NoError(t, err)
+
+	now := time.Now()
+	m1 := putCacheManifest(ctx, t, dir, cs, "v1", 10, now.Add(-3*time.Hour))
+	m2 := putCacheManifest(ctx, t, dir, cs, "v2", 10, now.Add(-2*time.Hour))
+	m3 := putCacheManifest(ctx, t, dir, cs, "v3", 10, now.Add(-1*time.Hour))
+
+	res, err := PruneLocalCache(ctx, dir, LocalCachePruneOpt{KeepTags: 2})
+	require.NoError(t, err)
+	require.Len(t, res.RemovedManifests, 1)
+	require.Equal(t, m1.manifest, res.RemovedManifests[0].Digest)
+	require.Len(t, res.RemovedBlobs, 3)
+	require.Equal(t, []string{"v2", "v3"}, indexTags(t, dir))
+
+	remaining := listDigests(ctx, t, cs)
+	require.Len(t, remaining, 6)
+	require.NotContains(t, remaining, m1.manifest)
+	require.NotContains(t, remaining, m1.layer)
+	require.Contains(t, remaining, m2.layer)
+	require.Contains(t, remaining, m3.layer)
+}
+
+func TestPruneLocalCacheMaxAge(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	cs, err := contentlocal.NewStore(dir)
+	require.NoError(t, err)
+
+	now := time.Now()
+	putCacheManifest(ctx, t, dir, cs, "old", 10, now.Add(-48*time.Hour))
+	m2 := putCacheManifest(ctx, t, dir, cs, "new", 10, now.Add(-time.Minute))
+
+	_, err = PruneLocalCache(ctx, dir, LocalCachePruneOpt{MaxAge: 24 * time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, []string{"new"}, indexTags(t, dir))
+	require.Contains(t, listDigests(ctx, t, cs), m2.layer)
+}
+
+func TestPruneLocalCacheMaxSize(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	cs, err := contentlocal.NewStore(dir)
+	require.NoError(t, err)
+
+	now := time.Now()
+	putCacheManifest(ctx, t, dir, cs, "v1", 1000, now.Add(-3*time.Hour))
+	putCacheManifest(ctx, t, dir, cs, "v2", 1000, now.Add(-2*time.Hour))
+	putCacheManifest(ctx, t, dir, cs, "v3", 1000, now.Add(-1*time.Hour))
+
+	res, err := PruneLocalCache(ctx, dir, LocalCachePruneOpt{MaxSize: 3000})
+	require.NoError(t, err)
+	require.Equal(t, []string{"v2", "v3"}, indexTags(t, dir))
+	require.LessOrEqual(t, res.Size, int64(3000))
+
+	// the newest manifest is kept even if it exceeds the limit
+	_, err = PruneLocalCache(ctx, dir, LocalCachePruneOpt{MaxSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, []string{"v3"}, indexTags(t, dir))
+}
+
+func TestPruneLocalCacheGracePeriod(t *testing.T) {
+	ctx := t.Context()
+	dir, cs, _ := setupCacheStore(ctx, t,
+		[]byte(`{"test":"config"}`),
+		[][]byte{[]byte("layer1-data")},
+		"latest",
+	)
+	orphanDgst := writeBlob(ctx, t, cs, []byte("in-flight-export"))
+
+	res, err := PruneLocalCache(ctx, dir, LocalCachePruneOpt{GracePeriod: time.Hour})
+	require.NoError(t, err)
+	require.Empty(t, res.RemovedBlobs)
+	require.Contains(t, listDigests(ctx, t, cs), orphanDgst)
+
+	res, err = PruneLocalCache(ctx, dir, LocalCachePruneOpt{})
+	require.NoError(t, err)
+	require.Equal(t, []digest.Digest{orphanDgst}, res.RemovedBlobs)
+}
+
+func TestPruneLocalCacheLocked(t *testing.T) {
+	ctx := t.Context()
+	dir, _, _ := setupCacheStore(ctx, t,
+		[]byte(`{"test":"config"}`),
+		nil,
+		"latest",
+	)
+
+	err := ociindex.NewStoreIndex(dir).Update(func(*ocispecs.Index) error {
+		_, err := PruneLocalCache(ctx, dir, LocalCachePruneOpt{})
+		return err
+	})
+	require.ErrorContains(t, err, "could not lock")
+}
+
+func TestParseLocalCachePruneOpt(t *testing.T) {
+	opt, err := parseLocalCachePruneOpt(map[string]string{
+		"keep-tags": "3",
+		"max-age":   "72h",
+		"max-size":  "1GB",
+	})
+	require.NoError(t, err)
+	require.Equal(t, LocalCachePruneOpt{KeepTags: 3, MaxAge: 72 * time.Hour, MaxSize: 1 << 30}, opt)
+
+	opt, err = parseLocalCachePruneOpt(map[string]string{"dest": "/tmp"})
+	require.NoError(t, err)
+	require.True(t, opt.isZero())
+	require.Zero(t, opt.GracePeriod)
+
+	opt, err = parseLocalCachePruneOpt(map[string]string{"keep-tags": "1", "grace-period": "30m"})
+	require.NoError(t, err)
+	require.Equal(t, LocalCachePruneOpt{KeepTags: 1, GracePeriod: 30 * time.Minute}, opt)
+
+	_, err = parseLocalCachePruneOpt(map[string]string{"keep-tags": "-1"})
+	require.Error(t, err)
+	_, err = parseLocalCachePruneOpt(map[string]string{"max-age": "forever"})
+	require.Error(t, err)
+	_, err = parseLocalCachePruneOpt(map[string]string{"max-size": "big"})
+	require.Error(t, err)
+	_, err = parseLocalCachePruneOpt(map[string]string{"grace-period": "-1h"})
+	require.Error(t, err)
+}
@@ -79,6 +79,32 @@ func (s StoreIndex) Read() (*ocispecs.Index, error) {
 }
 
 func (s StoreIndex) Put(desc ocispecs.Descriptor, names ...NameOrTag) error {
+	return s.update(true, func(idx *ocispecs.Index) error {
+		namesp := make([]*NameOrTag, 0, len(names))
+		for _, n := range names {
+			namesp = append(namesp, &n)
+		}
+		if len(names) == 0 {
+			namesp = append(namesp, nil)
+		}
+
+		for _, name := range namesp {
+			if err := insertDesc(idx, desc, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update calls fn with the current index while holding an exclusive lock on
+// the store and writes back the modified index. The lock is held until fn
+// returns, so fn can safely remove content that is no longer referenced.
+func (s StoreIndex) Update(fn func(*ocispecs.Index) error) error {
+	return s.update(false, fn)
+}
+
+func (s StoreIndex) update(create bool, fn func(*ocispecs.Index) error) error {
 	// lock the store to prevent concurrent access
 	lock := flock.New(s.lockPath)
 	locked, err := lock.TryLock()
@@ -93,20 +119,24 @@ func (s StoreIndex) Put(desc ocispecs.Descriptor, names ...NameOrTag) error {
 		os.RemoveAll(s.lockPath)
 	}()
 
-	// create the oci-layout file
-	layout := ocispecs.ImageLayout{
-		Version: ocispecs.ImageLayoutVersion,
-	}
-	layoutData, err := json.Marshal(layout)
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(s.layoutPath, layoutData, 0644); err != nil {
-		return err
+	flags := os.O_RDWR
+	if create {
+		// create the oci-layout file
+		layout := ocispecs.ImageLayout{
+			Version: ocispecs.ImageLayoutVersion,
+		}
+		layoutData, err := json.Marshal(layout)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(s.layoutPath, layoutData, 0644); err != nil {
+			return err
+		}
+		flags |= os.O_CREATE
 	}
 
 	// modify the index file
-	idxFile, err := os.OpenFile(s.indexPath, os.O_RDWR|os.O_CREATE, 0644)
+	idxFile, err := os.OpenFile(s.indexPath, flags, 0644)
 	if err != nil {
 		return errors.Wrapf(err, "could not open %s", s.indexPath)
 	}
@@ -125,18 +155,8 @@ func (s StoreIndex) Put(desc ocispecs.Descriptor, names ...NameOrTag) error {
 
 	setOCIIndexDefaults(&idx)
 
-	namesp := make([]*NameOrTag, 0, len(names))
-	for _, n := range names {
-		namesp = append(namesp, &n)
-	}
-	if len(names) == 0 {
-		namesp = append(namesp, nil)
-	}
-
-	for _, name := range namesp {
-		if err = insertDesc(&idx, desc, name); err != nil {
-			return err
-		}
+	if err := fn(&idx); err != nil {
+		return err
 	}
 
 	idxData, err = json.Marshal(idx)
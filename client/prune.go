@@ -74,6 +74,10 @@ type PruneInfo struct {
 	ReservedSpace int64 `json:"reservedSpace"`
 	MaxUsedSpace  int64 `json:"maxUsedSpace"`
 	MinFreeSpace  int64 `json:"minFreeSpace"`
+
+	// Tenant limits pruning to the build cache of a tenant. The value "*"
+	// applies the policy separately to every tenant.
+	Tenant string `json:"tenant,omitempty"`
 }
 
 type pruneOptionFunc func(*PruneInfo)
@@ -10,11 +10,9 @@ import (
 	"slices"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/containerd/containerd/v2/core/content"
-	"github.com/containerd/containerd/v2/core/images"
 	contentlocal "github.com/containerd/containerd/v2/plugins/content/local"
 	controlapi "github.com/moby/buildkit/api/services/control"
 	"github.com/moby/buildkit/client/llb"
@@ -35,6 +33,7 @@ import (
 	fstypes "github.com/tonistiigi/fsutil/types"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type SolveOpt struct {
@@ -58,6 +57,16 @@ type SolveOpt struct {
 	SourcePolicyProvider  session.Attachable
 	ProxyNetwork          bool
 	Ref                   string
+	// Deadline is the time the whole solve must complete by. Vertexes still
+	// running at the deadline are killed and the solve fails with a timeout
+	// error.
+	Deadline time.Time
+	// Priority is the priority class of the solve when waiting for worker
+	// and registry slots: low, normal or high.
+	Priority string
+	// Tenant groups solves sharing a fair share of worker and registry
+	// slots.
+	Tenant string
 }
 
 type ExportEntry struct {
@@ -197,9 +206,12 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 							return nil, err
 						}
 					}
-					if mode == LocalExporterModeDelete {
+					switch mode {
+					case LocalExporterModeDelete:
 						syncTargets = append(syncTargets, filesync.WithFSSyncDirDelete(exID, ex.OutputDir))
-					} else {
+					case LocalExporterModeSync:
+						syncTargets = append(syncTargets, filesync.WithFSSyncDirSync(exID, ex.OutputDir))
+					default:
 						syncTargets = append(syncTargets, filesync.WithFSSyncDir(exID, ex.OutputDir))
 					}
 				} else {
@@ -329,10 +341,15 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			CompatibilityVersion:    int64(opt.CompatibilityVersion),
 			SourcePolicy:            opt.SourcePolicy,
 			ProxyNetwork:            opt.ProxyNetwork,
+			Priority:                opt.Priority,
+			Tenant:                  opt.Tenant,
 		}
 		if opt.SourcePolicyProvider != nil {
 			sopt.SourcePolicySession = s.ID()
 		}
+		if !opt.Deadline.IsZero() {
+			sopt.Deadline = timestamppb.New(opt.Deadline)
+		}
 
 		resp, err := c.ControlClient().Solve(ctx, sopt)
 		if err != nil {
@@ -406,6 +423,11 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 		if err = json.Unmarshal([]byte(manifestDescJSON), &manifestDesc); err != nil {
 			return nil, err
 		}
+		// record the export time so retention policies can order manifests
+		if manifestDesc.Annotations == nil {
+			manifestDesc.Annotations = map[string]string{}
+		}
+		manifestDesc.Annotations[ocispecs.AnnotationCreated] = time.Now().UTC().Format(time.RFC3339Nano)
 		for storePath, tag := range cacheOpt.storesToUpdate {
 			idx := ociindex.NewStoreIndex(storePath)
 			if err := idx.Put(manifestDesc, ociindex.Tag(tag)); err != nil {
@@ -437,10 +459,18 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			}
 		}
 	}
-	// Reset cache stores that have reset=true — delete unreferenced blobs
-	for _, ref := range cacheOpt.storesToReset {
-		if err := resetCacheStore(ctx, ref.store, ref.path); err != nil {
-			bklog.G(ctx).WithError(err).Warn("failed to reset cache store")
+	// Apply the retention policies of cache stores and reset the stores that
+	// have reset=true
+	for _, ref := range cacheOpt.storesToPrune {
+		if !ref.opt.isZero() {
+			if _, err := pruneCacheStore(ctx, ref.store, ref.path, ref.opt); err != nil {
+				bklog.G(ctx).WithError(err).Warn("failed to prune cache store")
+			}
+		}
+		if ref.reset {
+			if err := resetCacheStore(ctx, ref.store, ref.path); err != nil {
+				bklog.G(ctx).WithError(err).Warn("failed to reset cache store")
+			}
 		}
 	}
 	return res, nil
@@ -449,41 +479,8 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 // resetCacheStore deletes all blobs not referenced by any manifest in
 // index.json. Referenced blobs are always preserved.
 func resetCacheStore(ctx context.Context, cs content.Store, storePath string) error {
-	idx := ociindex.NewStoreIndex(storePath)
-	index, err := idx.Read()
-	if err != nil {
-		return errors.Wrap(err, "reset: failed to read index.json")
-	}
-
-	var mu sync.Mutex
-	referenced := make(map[digest.Digest]struct{})
-	childrenHandler := images.ChildrenHandler(cs)
-	handler := images.HandlerFunc(func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
-		mu.Lock()
-		referenced[desc.Digest] = struct{}{}
-		mu.Unlock()
-		return childrenHandler(ctx, desc)
-	})
-	if err := images.Dispatch(ctx, handler, nil, index.Manifests...); err != nil {
-		return errors.Wrap(err, "reset: failed to collect referenced blobs")
-	}
-
-	var toDelete []digest.Digest
-	if err := cs.Walk(ctx, func(info content.Info) error {
-		if _, ok := referenced[info.Digest]; !ok {
-			toDelete = append(toDelete, info.Digest)
-		}
-		return nil
-	}); err != nil {
-		return errors.Wrap(err, "reset: failed to walk content store")
-	}
-
-	for _, dgst := range toDelete {
-		if err := cs.Delete(ctx, dgst); err != nil {
-			bklog.G(ctx).WithError(err).Warnf("reset: failed to delete blob %s", dgst)
-		}
-	}
-	return nil
+	_, err := pruneCacheStore(ctx, cs, storePath, LocalCachePruneOpt{})
+	return errors.Wrap(err, "reset")
 }
 
 func prepareSyncedFiles(def *llb.Definition, localMounts map[string]fsutil.FS) (filesync.StaticDirSource, error) {
@@ -533,13 +530,15 @@ func prepareSyncedFiles(def *llb.Definition, localMounts map[string]fsutil.FS) (
 type cacheStoreRef struct {
 	path  string
 	store content.Store
+	opt   LocalCachePruneOpt
+	reset bool
 }
 
 type cacheOptions struct {
 	options        controlapi.CacheOptions
 	contentStores  map[string]content.Store // key: ID of content store ("local:" + csDir)
 	storesToUpdate map[string]string        // key: path to content store, value: tag
-	storesToReset  []cacheStoreRef          // cache stores with reset=true
+	storesToPrune  []cacheStoreRef          // cache stores with reset=true or a retention policy
 	frontendAttrs  map[string]string
 }
 
@@ -548,7 +547,7 @@ func parseCacheOptions(ctx context.Context, isGateway bool, opt SolveOpt) (*cach
 		cacheExports []*controlapi.CacheOptionsEntry
 		cacheImports []*controlapi.CacheOptionsEntry
 	)
-	var storesToReset []cacheStoreRef
+	var storesToPrune []cacheStoreRef
 	contentStores := make(map[string]content.Store)
 	storesToUpdate := make(map[string]string)
 	frontendAttrs := make(map[string]string)
@@ -574,14 +573,20 @@ func parseCacheOptions(ctx context.Context, isGateway bool, opt SolveOpt) (*cach
 			// TODO(AkihiroSuda): support custom index JSON path and tag
 			storesToUpdate[csDir] = tag
 
+			var reset bool
 			if v, ok := ex.Attrs["reset"]; ok {
 				b, err := strconv.ParseBool(v)
 				if err != nil {
 					return nil, errors.Wrapf(err, "failed to parse reset attribute")
 				}
-				if b {
-					storesToReset = append(storesToReset, cacheStoreRef{path: csDir, store: cs})
-				}
+				reset = b
+			}
+			pruneOpt, err := parseLocalCachePruneOpt(ex.Attrs)
+			if err != nil {
+				return nil, err
+			}
+			if reset || !pruneOpt.isZero() {
+				storesToPrune = append(storesToPrune, cacheStoreRef{path: csDir, store: cs, opt: pruneOpt, reset: reset})
 			}
 		}
 		if ex.Type == "registry" {
@@ -662,7 +667,7 @@ func parseCacheOptions(ctx context.Context, isGateway bool, opt SolveOpt) (*cach
 		},
 		contentStores:  contentStores,
 		storesToUpdate: storesToUpdate,
-		storesToReset:  storesToReset,
+		storesToPrune:  storesToPrune,
 		frontendAttrs:  frontendAttrs,
 	}
 	return &res, nil
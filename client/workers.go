@@ -70,6 +70,7 @@ func fromAPIGCPolicy(in []*apitypes.GCPolicy) []PruneInfo {
 			ReservedSpace: p.ReservedSpace,
 			MaxUsedSpace:  p.MaxUsedSpace,
 			MinFreeSpace:  p.MinFreeSpace,
+			Tenant:        p.Tenant,
 		})
 	}
 	return out
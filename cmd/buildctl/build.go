@@ -18,6 +18,7 @@ import (
 	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
 	"github.com/moby/buildkit/frontend"
 	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/frontend/gateway/debugger"
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
@@ -30,6 +31,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v3"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 )
 
 var buildCommand = &cli.Command{
@@ -86,7 +88,7 @@ var buildCommand = &cli.Command{
 		},
 		&cli.StringSliceFlag{
 			Name:  "secret",
-			Usage: "Secret value exposed to the build. Format id=secretname,src=filepath",
+			Usage: "Secret value exposed to the build. Format id=secretname,src=filepath or id=secretname,type=exec,cmd=helper[,args=...][,timeout=30s]",
 		},
 		&cli.StringSliceFlag{
 			Name:  "allow",
@@ -108,14 +110,42 @@ var buildCommand = &cli.Command{
 			Name:  "proxy-network",
 			Usage: "Run build with proxy network enforcement",
 		},
+		&cli.StringFlag{
+			Name:  "proxy-egress-policy-file",
+			Usage: "Read proxy network egress allowlist from a JSON file",
+		},
+		&cli.StringFlag{
+			Name:  "proxy-replay",
+			Usage: "Serve proxy network requests from materials of a previous build, e.g. --proxy-replay store=<oci-layout id>,provenance=path/to/provenance.json",
+		},
 		&cli.StringFlag{
 			Name:  "ref-file",
 			Usage: "Write build ref to a file",
 		},
+		&cli.DurationFlag{
+			Name:  "deadline",
+			Usage: "Fail the build if it doesn't complete within the duration, e.g. 1h",
+		},
+		&cli.StringFlag{
+			Name:  "priority",
+			Usage: "Priority class of the build when waiting for worker slots (low, normal, high)",
+		},
+		&cli.StringFlag{
+			Name:  "tenant",
+			Usage: "Share worker slots fairly with other builds of the same tenant",
+		},
 		&cli.StringSliceFlag{
 			Name:  "registry-auth-tlscontext",
 			Usage: "Overwrite TLS configuration when authenticating with registries, e.g. --registry-auth-tlscontext host=https://myserver:2376,insecure=false,ca=/path/to/my/ca.crt,cert=/path/to/my/cert.crt,key=/path/to/my/key.crt",
 		},
+		&cli.StringFlag{
+			Name:  "on-failure",
+			Usage: "Start an interactive shell in the container of a failed step, e.g. shell,cmd=/bin/bash (default cmd /bin/sh)",
+		},
+		&cli.StringFlag{
+			Name:  "debugger",
+			Usage: "Serve a debugger on the address that stops the build before its first step, e.g. unix:///tmp/buildctl-debug.sock. Attach with buildctl debug attach",
+		},
 		&cli.StringFlag{
 			Name:  "debug-json-cache-metrics",
 			Usage: "Where to output json cache metrics, use 'stdout' or 'stderr' for standard (error) output.",
@@ -193,6 +223,26 @@ func buildAction(clicontext *cli.Command) error {
 		return err
 	}
 
+	onFailure, err := build.ParseOnFailure(clicontext.String("on-failure"))
+	if err != nil {
+		return err
+	}
+
+	var dbg *debugger.Debugger
+	if addr := clicontext.String("debugger"); addr != "" {
+		l, err := debugger.Listen(addr)
+		if err != nil {
+			return errors.Wrap(err, "failed to listen for debugger")
+		}
+		dbg = debugger.New()
+		server := grpc.NewServer()
+		dbg.Register(server)
+		go server.Serve(l)
+		defer server.Stop()
+
+		bklog.L.Infof("debugger listening on %s, attach with buildctl debug attach %s", addr, addr)
+	}
+
 	attachable := []session.Attachable{authprovider.NewDockerAuthProvider(authprovider.DockerAuthProviderConfig{
 		AuthConfigProvider: authprovider.LoadAuthConfig(dockerConfig),
 		TLSConfigs:         tlsConfigs,
@@ -265,6 +315,11 @@ func buildAction(clicontext *cli.Command) error {
 		SourcePolicy:        srcPol,
 		ProxyNetwork:        clicontext.Bool("proxy-network"),
 		Ref:                 ref,
+		Priority:            clicontext.String("priority"),
+		Tenant:              clicontext.String("tenant"),
+	}
+	if d := clicontext.Duration("deadline"); d > 0 {
+		solveOpt.Deadline = time.Now().Add(d)
 	}
 
 	solveOpt.FrontendAttrs, err = build.ParseOpt(clicontext.StringSlice("opt"))
@@ -272,6 +327,22 @@ func buildAction(clicontext *cli.Command) error {
 		return errors.Wrap(err, "invalid opt")
 	}
 
+	if egressFile := clicontext.String("proxy-egress-policy-file"); egressFile != "" {
+		b, err := os.ReadFile(egressFile)
+		if err != nil {
+			return err
+		}
+		solveOpt.FrontendAttrs["proxy-egress-policy"] = string(b)
+	}
+
+	if replay := clicontext.String("proxy-replay"); replay != "" {
+		v, err := build.ParseProxyReplay(replay)
+		if err != nil {
+			return errors.Wrap(err, "invalid proxy-replay")
+		}
+		solveOpt.FrontendAttrs["proxy-replay"] = v
+	}
+
 	solveOpt.LocalMounts, err = build.ParseLocal(clicontext.StringSlice("local"))
 	if err != nil {
 		return errors.Wrap(err, "invalid local")
@@ -310,6 +381,7 @@ func buildAction(clicontext *cli.Command) error {
 	if err != nil {
 		return err
 	}
+	pauser, _ := pw.(progresswriter.Pauser)
 
 	if traceEnc != nil {
 		traceCh := make(chan *client.SolveStatus)
@@ -367,6 +439,9 @@ func buildAction(clicontext *cli.Command) error {
 		sreq := gateway.SolveRequest{
 			Frontend:    solveOpt.Frontend,
 			FrontendOpt: solveOpt.FrontendAttrs,
+			// the result must be evaluated while the build function runs so
+			// that the mounts of a failed step are still available
+			Evaluate: onFailure != nil,
 		}
 
 		sreq.CacheImports = make([]frontend.CacheOptionsEntry, len(solveOpt.CacheImports))
@@ -388,7 +463,15 @@ func buildAction(clicontext *cli.Command) error {
 				}
 			}
 			res, err := c.Solve(ctx, sreq)
+			if err == nil && dbg != nil && res != nil {
+				err = dbg.Run(ctx, c, res)
+			}
 			if err != nil {
+				if onFailure != nil {
+					if err := runFailureShell(ctx, c, err, onFailure, pauser); err != nil {
+						bklog.G(ctx).Warnf("failed to run on-failure shell: %v", err)
+					}
+				}
 				return nil, err
 			}
 			if isSubRequest && res != nil {
@@ -0,0 +1,53 @@
+package build
+
+import (
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/go-csvvalue"
+)
+
+const defaultShell = "/bin/sh"
+
+// OnFailure is the action to take when a build step fails
+type OnFailure struct {
+	// Cmd is the command to run in the container of the failed step
+	Cmd []string
+}
+
+// ParseOnFailure parses --on-failure, e.g. "shell,cmd=/bin/bash".
+// It returns nil if val is empty.
+func ParseOnFailure(val string) (*OnFailure, error) {
+	if val == "" {
+		return nil, nil
+	}
+	fields, err := csvvalue.Fields(val, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse csv on-failure")
+	}
+	if len(fields) == 0 || fields[0] != "shell" {
+		return nil, errors.Errorf("unsupported on-failure action %q, only shell is supported", val)
+	}
+	of := &OnFailure{Cmd: []string{defaultShell}}
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid field '%s' must be a key=value pair", field)
+		}
+		switch strings.ToLower(key) {
+		case "cmd":
+			args, err := shlex.Split(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse cmd '%s'", value)
+			}
+			if len(args) == 0 {
+				return nil, errors.New("on-failure cmd must not be empty")
+			}
+			of.Cmd = args
+		default:
+			return nil, errors.Errorf("unexpected key '%s' in '%s'", key, field)
+		}
+	}
+	return of, nil
+}
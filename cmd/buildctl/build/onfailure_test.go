@@ -0,0 +1,30 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOnFailure(t *testing.T) {
+	of, err := ParseOnFailure("")
+	require.NoError(t, err)
+	require.Nil(t, of)
+
+	of, err = ParseOnFailure("shell")
+	require.NoError(t, err)
+	require.Equal(t, &OnFailure{Cmd: []string{"/bin/sh"}}, of)
+
+	of, err = ParseOnFailure(`shell,"cmd=/bin/bash -l"`)
+	require.NoError(t, err)
+	require.Equal(t, &OnFailure{Cmd: []string{"/bin/bash", "-l"}}, of)
+
+	_, err = ParseOnFailure("exit")
+	require.ErrorContains(t, err, "unsupported on-failure action")
+
+	_, err = ParseOnFailure("shell,cmd=")
+	require.ErrorContains(t, err, "must not be empty")
+
+	_, err = ParseOnFailure("shell,user=root")
+	require.ErrorContains(t, err, "unexpected key")
+}
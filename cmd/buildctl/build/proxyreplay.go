@@ -0,0 +1,101 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/moby/buildkit/util/network"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/go-csvvalue"
+)
+
+// ParseProxyReplay parses --proxy-replay and returns the replay config for
+// the proxy-replay frontend attribute.
+func ParseProxyReplay(val string) (string, error) {
+	fields, err := csvvalue.Fields(val, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse csv proxy replay")
+	}
+
+	var cfg network.ProxyReplayConfig
+	var provenanceFile string
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return "", errors.Errorf("invalid field '%s' must be a key=value pair", field)
+		}
+		switch strings.ToLower(key) {
+		case "store":
+			cfg.Store = value
+		case "provenance":
+			provenanceFile = value
+		default:
+			return "", errors.Errorf("unexpected key '%s' in '%s'", key, field)
+		}
+	}
+	if cfg.Store == "" {
+		return "", errors.New("proxy replay requires store=<oci-layout id>")
+	}
+	if provenanceFile == "" {
+		return "", errors.New("proxy replay requires provenance=<file>")
+	}
+
+	dt, err := os.ReadFile(provenanceFile)
+	if err != nil {
+		return "", err
+	}
+	cfg.Materials, err = provenanceHTTPMaterials(dt)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read materials from %s", provenanceFile)
+	}
+
+	dt, err = json.Marshal(cfg)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(dt), nil
+}
+
+type provenanceMaterial struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenanceMaterials struct {
+	// Predicate is set when the file is an in-toto statement.
+	Predicate       *provenanceMaterials `json:"predicate"`
+	BuildDefinition struct {
+		ResolvedDependencies []provenanceMaterial `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	// Materials is the SLSA v0.2 form of the dependencies.
+	Materials []provenanceMaterial `json:"materials"`
+}
+
+// provenanceHTTPMaterials returns the HTTP(S) dependencies of a SLSA v1 or
+// v0.2 provenance predicate or statement.
+func provenanceHTTPMaterials(dt []byte) ([]network.ProxyReplayMaterial, error) {
+	var p provenanceMaterials
+	if err := json.Unmarshal(dt, &p); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if p.Predicate != nil {
+		p = *p.Predicate
+	}
+	var out []network.ProxyReplayMaterial
+	for _, m := range append(p.BuildDefinition.ResolvedDependencies, p.Materials...) {
+		if !strings.HasPrefix(m.URI, "http://") && !strings.HasPrefix(m.URI, "https://") {
+			continue
+		}
+		v, ok := m.Digest[string(digest.SHA256)]
+		if !ok {
+			continue
+		}
+		out = append(out, network.ProxyReplayMaterial{
+			URL:    m.URI,
+			Digest: digest.NewDigestFromEncoded(digest.SHA256, v),
+		})
+	}
+	return out, nil
+}
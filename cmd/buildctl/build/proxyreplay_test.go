@@ -0,0 +1,52 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/util/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProxyReplay(t *testing.T) {
+	dir := t.TempDir()
+	slsa1 := filepath.Join(dir, "v1.json")
+	require.NoError(t, os.WriteFile(slsa1, []byte(`{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicate": {
+			"buildDefinition": {
+				"resolvedDependencies": [
+					{"uri": "pkg:docker/alpine@latest", "digest": {"sha256": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}},
+					{"uri": "https://example.com/file", "digest": {"sha256": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}}
+				]
+			}
+		}
+	}`), 0600))
+	slsa02 := filepath.Join(dir, "v02.json")
+	require.NoError(t, os.WriteFile(slsa02, []byte(`{
+		"materials": [
+			{"uri": "http://example.com/other", "digest": {"sha256": "cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"}}
+		]
+	}`), 0600))
+
+	v, err := ParseProxyReplay("store=materials,provenance=" + slsa1)
+	require.NoError(t, err)
+	cfg, err := network.ParseProxyReplayConfig([]byte(v))
+	require.NoError(t, err)
+	require.Equal(t, "materials", cfg.Store)
+	require.Equal(t, []network.ProxyReplayMaterial{
+		{URL: "https://example.com/file", Digest: "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},
+	}, cfg.Materials)
+
+	v, err = ParseProxyReplay("provenance=" + slsa02 + ",store=materials")
+	require.NoError(t, err)
+	require.Contains(t, v, `"url":"http://example.com/other"`)
+
+	_, err = ParseProxyReplay("provenance=" + slsa1)
+	require.ErrorContains(t, err, "requires store")
+	_, err = ParseProxyReplay("store=materials")
+	require.ErrorContains(t, err, "requires provenance")
+	_, err = ParseProxyReplay("store=materials,foo=bar")
+	require.ErrorContains(t, err, "unexpected key")
+}
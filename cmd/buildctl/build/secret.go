@@ -2,7 +2,9 @@ package build
 
 import (
 	"strings"
+	"time"
 
+	"github.com/google/shlex"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/secrets/secretsprovider"
 	"github.com/pkg/errors"
@@ -43,7 +45,7 @@ func parseSecret(val string) (*secretsprovider.Source, error) {
 		key = strings.ToLower(key)
 		switch key {
 		case "type":
-			if value != "file" && value != "env" {
+			if value != "file" && value != "env" && value != "exec" {
 				return nil, errors.Errorf("unsupported secret type %q", value)
 			}
 			typ = value
@@ -53,6 +55,19 @@ func parseSecret(val string) (*secretsprovider.Source, error) {
 			fs.FilePath = value
 		case "env":
 			fs.Env = value
+		case "cmd":
+			fs.Cmd = value
+		case "args":
+			args, err := shlex.Split(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse args '%s'", value)
+			}
+			fs.Args = append(fs.Args, args...)
+		case "timeout":
+			fs.Timeout, err = time.ParseDuration(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid timeout '%s'", value)
+			}
 		default:
 			return nil, errors.Errorf("unexpected key '%s' in '%s'", key, field)
 		}
@@ -61,5 +76,12 @@ func parseSecret(val string) (*secretsprovider.Source, error) {
 		fs.Env = fs.FilePath
 		fs.FilePath = ""
 	}
+	if typ == "exec" {
+		if fs.Cmd == "" {
+			return nil, errors.Errorf("secret type exec requires cmd")
+		}
+	} else if fs.Cmd != "" || len(fs.Args) > 0 || fs.Timeout != 0 {
+		return nil, errors.Errorf("cmd, args and timeout are only supported for secret type exec")
+	}
 	return &fs, nil
 }
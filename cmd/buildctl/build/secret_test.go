@@ -0,0 +1,33 @@
+package build
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSecret(t *testing.T) {
+	s, err := parseSecret(`id=token,type=exec,cmd=/usr/bin/helper,"args=--scope 'build and push'",timeout=5s`)
+	require.NoError(t, err)
+	require.Equal(t, &secretsprovider.Source{
+		ID:      "token",
+		Cmd:     "/usr/bin/helper",
+		Args:    []string{"--scope", "build and push"},
+		Timeout: 5 * time.Second,
+	}, s)
+
+	s, err = parseSecret("id=token,type=env,src=TOKEN")
+	require.NoError(t, err)
+	require.Equal(t, &secretsprovider.Source{ID: "token", Env: "TOKEN"}, s)
+
+	_, err = parseSecret("id=token,type=exec")
+	require.ErrorContains(t, err, "requires cmd")
+
+	_, err = parseSecret("id=token,src=token.txt,cmd=/usr/bin/helper")
+	require.ErrorContains(t, err, "only supported for secret type exec")
+
+	_, err = parseSecret("id=token,type=exec,cmd=helper,timeout=soon")
+	require.ErrorContains(t, err, "invalid timeout")
+}
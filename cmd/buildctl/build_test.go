@@ -46,6 +46,26 @@ func testBuildWithLocalFiles(t *testing.T, sb integration.Sandbox) {
 	require.NoError(t, err)
 }
 
+func testBuildOnFailureShell(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+
+	st := llb.Image("busybox").
+		Run(llb.Shlex("sh -c 'echo -n on-failure-marker > /marker && exit 1'"))
+
+	rdr, err := marshal(sb.Context(), st.Root())
+	require.NoError(t, err)
+
+	cmd := sb.Cmd("build --progress=plain --on-failure=shell,cmd='cat /marker'")
+	cmd.Stdin = rdr
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+
+	err = cmd.Run()
+	require.Error(t, err)
+	// the shell runs in a container with the rootfs of the failed step
+	require.Contains(t, stdout.String(), "on-failure-marker")
+}
+
 func testBuildLocalExporter(t *testing.T, sb integration.Sandbox) {
 	img := integration.UnixOrWindows("busybox", "nanoserver:latest")
 	cmdStr := integration.UnixOrWindows(
@@ -20,6 +20,7 @@ func TestCLIIntegration(t *testing.T) {
 	integration.Run(t, integration.TestFuncs(
 		testDiskUsage,
 		testBuildWithLocalFiles,
+		testBuildOnFailureShell,
 		testBuildLocalExporter,
 		testBuildContainerdExporter,
 		testBuildMetadataFile,
@@ -18,5 +18,7 @@ var debugCommand = &cli.Command{
 		debug.CtlCommand,
 		debug.GetCommand,
 		debug.HistoriesCommand,
+		debug.DiffBuildsCommand,
+		debug.AttachCommand,
 	},
 }
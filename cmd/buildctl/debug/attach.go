@@ -0,0 +1,252 @@
+package debug
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/shlex"
+	"github.com/moby/buildkit/frontend/gateway/debugger"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+)
+
+var AttachCommand = &cli.Command{
+	Name:      "attach",
+	Usage:     "attach to the debugger of a build started with buildctl build --debugger",
+	ArgsUsage: "ADDRESS",
+	Action:    commandAction(attach),
+}
+
+const attachHelp = `Commands:
+  break <line>|<file>:<line>|<digest>  add a breakpoint
+  breakpoints                          list breakpoints
+  delete [<n>]                         delete breakpoint n or all breakpoints
+  continue, c                          continue to the next breakpoint
+  step, s                              stop before the next vertex
+  info                                 show the vertex the build is stopped before
+  ls [-i <input>] [<path>]             list a directory of an input of the vertex
+  exec [-i <input>] <cmd> [<args>...]  run a command with the inputs of the vertex
+  quit, q                              detach from the build
+`
+
+func attach(clicontext *cli.Command) error {
+	if clicontext.Args().Len() != 1 {
+		return errors.Errorf("debugger address must be specified")
+	}
+	conn, err := debugger.Dial(clicontext.Args().First())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := appcontext.Context()
+	a := &attacher{c: debugger.NewDebuggerClient(conn)}
+	if err := a.wait(ctx); err != nil {
+		return err
+	}
+
+	s := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("(buildkit) ")
+		if !s.Scan() {
+			fmt.Println()
+			return s.Err()
+		}
+		args, err := shlex.Split(s.Text())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "quit" || args[0] == "q" {
+			return nil
+		}
+		if err := a.run(ctx, args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+type attacher struct {
+	c debugger.DebuggerClient
+}
+
+func (a *attacher) run(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "break", "b":
+		if len(args) != 2 {
+			return errors.Errorf("usage: break <line>|<file>:<line>|<digest>")
+		}
+		bp, err := parseBreakpoint(args[1])
+		if err != nil {
+			return err
+		}
+		bps, err := a.c.ListBreakpoints(ctx, &debugger.ListBreakpointsRequest{})
+		if err != nil {
+			return err
+		}
+		_, err = a.c.SetBreakpoints(ctx, &debugger.SetBreakpointsRequest{
+			Breakpoints: append(bps.Breakpoints, bp),
+		})
+		return err
+	case "breakpoints":
+		bps, err := a.c.ListBreakpoints(ctx, &debugger.ListBreakpointsRequest{})
+		if err != nil {
+			return err
+		}
+		for i, bp := range bps.Breakpoints {
+			fmt.Printf("%d: %s\n", i, formatBreakpoint(bp))
+		}
+		return nil
+	case "delete":
+		var bps []*debugger.Breakpoint
+		if len(args) > 1 {
+			resp, err := a.c.ListBreakpoints(ctx, &debugger.ListBreakpointsRequest{})
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 || n >= len(resp.Breakpoints) {
+				return errors.Errorf("invalid breakpoint %q", args[1])
+			}
+			bps = append(resp.Breakpoints[:n], resp.Breakpoints[n+1:]...)
+		}
+		_, err := a.c.SetBreakpoints(ctx, &debugger.SetBreakpointsRequest{Breakpoints: bps})
+		return err
+	case "continue", "c", "step", "s":
+		if _, err := a.c.Continue(ctx, &debugger.ContinueRequest{Step: args[0] == "step" || args[0] == "s"}); err != nil {
+			return err
+		}
+		return a.wait(ctx)
+	case "info":
+		return a.wait(ctx)
+	case "ls":
+		input, args, err := parseInput(args[1:])
+		if err != nil {
+			return err
+		}
+		p := "/"
+		if len(args) > 0 {
+			p = args[0]
+		}
+		resp, err := a.c.ReadDir(ctx, &debugger.ReadDirRequest{Input: input, Path: p})
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, ' ', 0)
+		for _, st := range resp.Entries {
+			name := st.Path
+			if st.Linkname != "" {
+				name += " -> " + st.Linkname
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", os.FileMode(st.Mode), st.Uid, st.Gid, st.Size, name)
+		}
+		return tw.Flush()
+	case "exec":
+		input, args, err := parseInput(args[1:])
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return errors.Errorf("usage: exec [-i <input>] <cmd> [<args>...]")
+		}
+		stream, err := a.c.Exec(ctx, &debugger.ExecRequest{Input: input, Args: args})
+		if err != nil {
+			return err
+		}
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			os.Stdout.Write(resp.Stdout)
+			os.Stderr.Write(resp.Stderr)
+			if resp.Exited && resp.ExitCode != 0 {
+				fmt.Printf("exit code: %d\n", resp.ExitCode)
+			}
+		}
+	case "help", "h":
+		fmt.Print(attachHelp)
+		return nil
+	default:
+		return errors.Errorf("unknown command %q, use help to list commands", args[0])
+	}
+}
+
+func (a *attacher) wait(ctx context.Context) error {
+	resp, err := a.c.Wait(ctx, &debugger.WaitRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Done {
+		if resp.Error != "" {
+			fmt.Printf("build failed: %s\n", resp.Error)
+		} else {
+			fmt.Println("build finished evaluating breakpoints")
+		}
+		return nil
+	}
+	st := resp.Stop
+	fmt.Printf("stopped before %s %s\n", st.Digest, st.Name)
+	for _, loc := range st.Locations {
+		for _, r := range loc.Ranges {
+			fmt.Printf("  at %s:%d\n", loc.Filename, r.GetStart().GetLine())
+		}
+	}
+	if st.Breakpoint != nil {
+		fmt.Printf("  breakpoint %s\n", formatBreakpoint(st.Breakpoint))
+	}
+	for i, inp := range st.Inputs {
+		fmt.Printf("  input %d: %s %s\n", i, inp.Digest, inp.Name)
+	}
+	return nil
+}
+
+func parseBreakpoint(s string) (*debugger.Breakpoint, error) {
+	if strings.HasPrefix(s, "sha256:") {
+		return &debugger.Breakpoint{Digest: s}, nil
+	}
+	bp := &debugger.Breakpoint{}
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		bp.Filename, s = s[:i], s[i+1:]
+	}
+	line, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || line <= 0 {
+		return nil, errors.Errorf("invalid breakpoint line %q", s)
+	}
+	bp.Line = int32(line)
+	return bp, nil
+}
+
+func formatBreakpoint(bp *debugger.Breakpoint) string {
+	if bp.Digest != "" {
+		return bp.Digest
+	}
+	if bp.Filename != "" {
+		return fmt.Sprintf("%s:%d", bp.Filename, bp.Line)
+	}
+	return strconv.Itoa(int(bp.Line))
+}
+
+func parseInput(args []string) (int64, []string, error) {
+	if len(args) < 2 || args[0] != "-i" {
+		return 0, args, nil
+	}
+	input, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return 0, nil, errors.Errorf("invalid input %q", args[1])
+	}
+	return input, args[2:], nil
+}
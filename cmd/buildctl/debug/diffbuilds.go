@@ -0,0 +1,86 @@
+package debug
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+)
+
+var DiffBuildsCommand = &cli.Command{
+	Name:      "diff-builds",
+	Usage:     "explain why two builds did not share cache",
+	ArgsUsage: "<ref> <compare-ref>",
+	Action:    commandAction(diffBuilds),
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Format the output using the given Go template, e.g, '{{json .}}'",
+		},
+	},
+}
+
+func diffBuilds(clicontext *cli.Command) error {
+	args := clicontext.Args()
+	if args.Len() != 2 {
+		return errors.Errorf("two build refs must be specified")
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	ctx := appcontext.Context()
+	resp, err := c.ControlClient().DiffBuildHistory(ctx, &controlapi.DiffBuildHistoryRequest{
+		Ref:        args.Get(0),
+		CompareRef: args.Get(1),
+	})
+	if err != nil {
+		return err
+	}
+
+	if format := clicontext.String("format"); format != "" {
+		tmpl, err := bccommon.ParseTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(clicontext.Root().Writer, resp); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(clicontext.Root().Writer, "\n")
+		return err
+	}
+	return printVertexDiffs(clicontext.Root().Writer, resp.Vertices)
+}
+
+func printVertexDiffs(w io.Writer, vertices []*controlapi.BuildHistoryVertexDiff) error {
+	if len(vertices) == 0 {
+		_, err := fmt.Fprintln(w, "no cache differences found")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
+	for i, v := range vertices {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "%s\n", v.Name)
+		fmt.Fprintf(tw, "  digest:\t%s\t%s\n", v.Digest, v.CompareDigest)
+		for _, inp := range v.Inputs {
+			fmt.Fprintf(tw, "  %s:\t%s\t%s\n", inp.Key, valueOrNone(inp.Value), valueOrNone(inp.CompareValue))
+		}
+	}
+	return tw.Flush()
+}
+
+func valueOrNone(v string) string {
+	if v == "" {
+		return "<none>"
+	}
+	return v
+}
@@ -1,14 +1,21 @@
 package debug
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"text/tabwriter"
 	"time"
 
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/content/proxy"
 	controlapi "github.com/moby/buildkit/api/services/control"
 	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/util/appcontext"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v3"
 )
@@ -44,6 +51,7 @@ func histories(clicontext *cli.Command) error {
 		if err != nil {
 			return err
 		}
+		store := proxy.NewContentStore(c.ContentClient())
 		for {
 			ev, err := resp.Recv()
 			if errors.Is(err, io.EOF) {
@@ -51,7 +59,14 @@ func histories(clicontext *cli.Command) error {
 			} else if err != nil {
 				return err
 			}
-			if err := tmpl.Execute(clicontext.Root().Writer, ev); err != nil {
+			hev := &historyEvent{BuildHistoryEvent: ev}
+			if ev.Record != nil && ev.Record.CacheInfo != nil {
+				hev.CacheInfo, err = readCacheInfo(ctx, store, ev.Record.CacheInfo)
+				if err != nil {
+					return errors.Wrapf(err, "failed to read cache info of %s", ev.Record.Ref)
+				}
+			}
+			if err := tmpl.Execute(clicontext.Root().Writer, hev); err != nil {
 				return err
 			}
 			if _, err = fmt.Fprintf(clicontext.Root().Writer, "\n"); err != nil {
@@ -63,6 +78,35 @@ func histories(clicontext *cli.Command) error {
 	return printRecordsTable(clicontext.Root().Writer, resp)
 }
 
+// historyEvent is a build history event with the attachments of the record
+// that are useful for inspecting the build.
+type historyEvent struct {
+	*controlapi.BuildHistoryEvent
+	// CacheInfo describes the cache keys of each vertex and why it was
+	// executed instead of loaded from cache.
+	CacheInfo []solver.VertexCacheInfo `json:"cacheInfo,omitempty"`
+}
+
+func readCacheInfo(ctx context.Context, store content.Provider, desc *controlapi.Descriptor) ([]solver.VertexCacheInfo, error) {
+	dgst, err := digest.Parse(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	dt, err := content.ReadBlob(ctx, store, ocispecs.Descriptor{
+		Digest:    dgst,
+		Size:      desc.Size,
+		MediaType: desc.MediaType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var info []solver.VertexCacheInfo
+	if err := json.Unmarshal(dt, &info); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return info, nil
+}
+
 func printRecordsTable(w io.Writer, eventReceiver controlapi.Control_ListenBuildHistoryClient) error {
 	tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
 	fmt.Fprintln(tw, "TYPE\tREF\tCREATED\tCOMPLETED\tGENERATION\tPINNED")
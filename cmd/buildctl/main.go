@@ -108,6 +108,7 @@ func main() {
 		diskUsageCommand,
 		pruneCommand,
 		pruneHistoriesCommand,
+		pruneCacheCommand,
 		buildCommand,
 		debugCommand,
 		dialStdioCommand,
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/console"
+	"github.com/moby/buildkit/cmd/buildctl/build"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/iohelper"
+	"github.com/moby/buildkit/util/progress/progresswriter"
+	"github.com/pkg/errors"
+)
+
+// runFailureShell runs the command of the on-failure action in a container
+// with the rootfs and mounts of the exec step that failed with solveErr and
+// attaches it to the local terminal. It must be called before the gateway
+// build function returns, as that releases the mounts of the failed step.
+func runFailureShell(ctx context.Context, c gateway.Client, solveErr error, of *build.OnFailure, pauser progresswriter.Pauser) error {
+	var se *errdefs.SolveError
+	if !errors.As(solveErr, &se) {
+		return nil
+	}
+	op, ok := se.Op.GetOp().(*pb.Op_Exec)
+	if !ok {
+		return nil
+	}
+	exec := op.Exec
+
+	mounts := make([]gateway.Mount, 0, len(exec.Mounts))
+	for i, m := range exec.Mounts {
+		var resultID string
+		if i < len(se.MountIDs) {
+			resultID = se.MountIDs[i]
+		}
+		mounts = append(mounts, gateway.Mount{
+			Selector:  m.Selector,
+			Dest:      m.Dest,
+			ResultID:  resultID,
+			Readonly:  m.Readonly,
+			MountType: m.MountType,
+			CacheOpt:  m.CacheOpt,
+			SecretOpt: m.SecretOpt,
+			SSHOpt:    m.SSHOpt,
+		})
+	}
+
+	ctr, err := c.NewContainer(ctx, gateway.NewContainerRequest{
+		Mounts:      mounts,
+		NetMode:     exec.Network,
+		Platform:    se.Op.Platform,
+		Constraints: se.Op.Constraints,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create container for failed step")
+	}
+	defer ctr.Release(context.WithoutCancel(ctx))
+
+	if pauser != nil {
+		resume := pauser.Pause()
+		defer resume()
+	}
+
+	con, err := console.ConsoleFromFile(os.Stdin)
+	tty := err == nil
+	if tty {
+		if err := con.SetRaw(); err != nil {
+			return errors.Wrap(err, "failed to set terminal to raw mode")
+		}
+		defer con.Reset()
+	}
+
+	fmt.Fprintf(os.Stderr, "\r\nRunning %s in the failed step %q, exit the shell to continue\r\n", strings.Join(of.Cmd, " "), strings.Join(exec.Meta.Args, " "))
+
+	meta := exec.Meta
+	proc, err := ctr.Start(ctx, gateway.StartRequest{
+		Args:                      of.Cmd,
+		Env:                       meta.Env,
+		SecretEnv:                 exec.Secretenv,
+		User:                      meta.User,
+		Cwd:                       meta.Cwd,
+		Tty:                       tty,
+		Stdin:                     io.NopCloser(os.Stdin),
+		Stdout:                    &iohelper.NopWriteCloser{Writer: os.Stdout},
+		Stderr:                    &iohelper.NopWriteCloser{Writer: os.Stderr},
+		SecurityMode:              exec.Security,
+		RemoveMountStubsRecursive: meta.RemoveMountStubsRecursive,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to start %s", strings.Join(of.Cmd, " "))
+	}
+
+	resize := func() {
+		size, err := con.Size()
+		if err != nil {
+			return
+		}
+		if err := proc.Resize(ctx, gateway.WinSize{Rows: uint32(size.Height), Cols: uint32(size.Width)}); err != nil {
+			bklog.G(ctx).Debugf("failed to resize terminal: %v", err)
+		}
+	}
+	if tty {
+		resize()
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, append(forwardSignals, resizeSignals...)...)
+	defer signal.Stop(sigCh)
+
+	waitCh := make(chan error, 1)
+	go func() {
+		waitCh <- proc.Wait()
+	}()
+	for {
+		select {
+		case err := <-waitCh:
+			if err != nil {
+				// the exit status of the shell is not an error of the build
+				bklog.G(ctx).Debugf("on-failure shell exited: %v", err)
+			}
+			return nil
+		case sig := <-sigCh:
+			if isResizeSignal(sig) {
+				if tty {
+					resize()
+				}
+				continue
+			}
+			if s, ok := sig.(syscall.Signal); ok {
+				if err := proc.Signal(ctx, s); err != nil {
+					bklog.G(ctx).Debugf("failed to forward signal %s: %v", sig, err)
+				}
+			}
+		}
+	}
+}
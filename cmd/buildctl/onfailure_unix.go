@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+var forwardSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+var resizeSignals = []os.Signal{syscall.SIGWINCH}
+
+func isResizeSignal(sig os.Signal) bool {
+	return sig == syscall.SIGWINCH
+}
@@ -0,0 +1,12 @@
+package main
+
+import "os"
+
+var forwardSignals = []os.Signal{os.Interrupt}
+
+// resize notifications are not delivered as signals on Windows
+var resizeSignals []os.Signal
+
+func isResizeSignal(os.Signal) bool {
+	return false
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/docker/go-units"
+	"github.com/moby/buildkit/client"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	tunits "github.com/tonistiigi/units"
+	"github.com/urfave/cli/v3"
+)
+
+var pruneCacheCommand = &cli.Command{
+	Name:   "prune-cache",
+	Usage:  "clean up exported cache",
+	Action: commandAction(pruneCache),
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "local",
+			Usage: "Local cache directory written by --export-cache type=local",
+		},
+		&cli.IntFlag{
+			Name:  "keep-tags",
+			Usage: "Keep only the N most recently exported cache manifests",
+		},
+		&cli.DurationFlag{
+			Name:  "max-age",
+			Usage: "Remove cache manifests exported longer ago than this limit",
+		},
+		&cli.StringFlag{
+			Name:  "max-size",
+			Usage: "Remove the oldest cache manifests until the cache is below this limit (e.g. 10GB)",
+		},
+		&cli.DurationFlag{
+			Name:  "grace-period",
+			Usage: "Keep unreferenced blobs written more recently than this limit",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Format the output using the given Go template, e.g, '{{json .}}'",
+		},
+	},
+}
+
+func pruneCache(clicontext *cli.Command) error {
+	dir := clicontext.String("local")
+	if dir == "" {
+		return errors.New("--local is required")
+	}
+
+	opt := client.LocalCachePruneOpt{
+		KeepTags:    int(clicontext.Int("keep-tags")),
+		MaxAge:      clicontext.Duration("max-age"),
+		GracePeriod: clicontext.Duration("grace-period"),
+	}
+	if opt.KeepTags < 0 {
+		return errors.Errorf("invalid --keep-tags value %d", opt.KeepTags)
+	}
+	if v := clicontext.String("max-size"); v != "" {
+		size, err := units.RAMInBytes(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --max-size value %q", v)
+		}
+		opt.MaxSize = size
+	}
+
+	res, err := client.PruneLocalCache(bccommon.CommandContext(clicontext), dir, opt)
+	if err != nil {
+		return err
+	}
+
+	if format := clicontext.String("format"); format != "" {
+		tmpl, err := bccommon.ParseTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(clicontext.Root().Writer, res); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(clicontext.Root().Writer, "\n")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
+	for _, m := range res.RemovedManifests {
+		name := m.Annotations[ocispecs.AnnotationRefName]
+		if name == "" {
+			name = "<none>"
+		}
+		fmt.Fprintf(tw, "Removed:\t%s\t%s\n", name, m.Digest)
+	}
+	fmt.Fprintf(tw, "Removed blobs:\t%d\n", len(res.RemovedBlobs))
+	fmt.Fprintf(tw, "Reclaimed:\t%.2f\n", tunits.Bytes(res.Reclaimed))
+	fmt.Fprintf(tw, "Total:\t%.2f\n", tunits.Bytes(res.Size))
+	return tw.Flush()
+}
@@ -55,6 +55,59 @@ type Config struct {
 	ProvenanceEnvDir string `toml:"provenanceEnvDir"`
 
 	Cache CacheConfig `toml:"cache"`
+
+	Tenant TenantConfig `toml:"tenant"`
+
+	Authz AuthzConfig `toml:"authz"`
+}
+
+// AuthzConfig restricts what clients of the control API can do. Requests are
+// authorized by the first rule matching the identity of the client, clients
+// not matching any rule are denied. Authorization is disabled if there are no
+// rules.
+type AuthzConfig struct {
+	Rules []AuthzRule `toml:"rule"`
+}
+
+type AuthzRule struct {
+	// UIDs and GIDs match clients connected over a unix socket by the user
+	// and primary group of the client process.
+	UIDs []int `toml:"uids"`
+	GIDs []int `toml:"gids"`
+	// Subjects match clients by the subject of their mTLS client
+	// certificate, either the full distinguished name (e.g. "CN=ci,O=example")
+	// or the common name. A rule without any identities matches all clients.
+	Subjects []string `toml:"subjects"`
+
+	// Methods are the allowed methods of the control API (e.g. "Solve"),
+	// all methods are allowed if unset. Other gRPC services are matched by
+	// the full method name (e.g. "/grpc.health.v1.Health/Check").
+	Methods []string `toml:"methods"`
+	// Entitlements are the entitlements the client can request, in addition
+	// to being allowed by insecure-entitlements.
+	Entitlements []string `toml:"entitlements"`
+	// Frontends are the allowed frontends, all frontends are allowed if
+	// unset. The list also applies to frontends requested by other frontends
+	// or by client-side builds, e.g. gateway.v0 for a Dockerfile with a
+	// syntax directive. Builds of LLB definitions without a frontend are
+	// always allowed.
+	Frontends []string `toml:"frontends"`
+	// Exporters are the allowed exporter types, all exporters are allowed if
+	// unset.
+	Exporters []string `toml:"exporters"`
+}
+
+// TenantConfig configures isolation between the tenants of the daemon. The
+// tenant of a request is the common name of its mTLS client certificate.
+type TenantConfig struct {
+	// Enabled scopes the build cache, build history and disk usage of
+	// requests to their tenant. Requests without a client certificate are
+	// not scoped and can access the data of all tenants.
+	Enabled bool `toml:"enabled"`
+
+	// ShareBaseImages shares the layers of pulled images between tenants
+	// instead of pulling them separately for each tenant.
+	ShareBaseImages bool `toml:"shareBaseImages"`
 }
 
 type CacheConfig struct {
@@ -112,6 +165,10 @@ type GCConfig struct {
 	GCMaxUsedSpace  DiskSpace  `toml:"maxUsedSpace"`
 	GCMinFreeSpace  DiskSpace  `toml:"minFreeSpace"`
 	GCPolicy        []GCPolicy `toml:"gcpolicy"`
+
+	// GCTenantMaxUsedSpace is the storage quota of each tenant when tenant
+	// isolation is enabled.
+	GCTenantMaxUsedSpace DiskSpace `toml:"tenantMaxUsedSpace"`
 }
 
 type NetworkConfig struct {
@@ -211,6 +268,10 @@ type GCPolicy struct {
 	// MinFreeSpace is the target amount of free disk space the garbage collector will attempt to leave.
 	// However, it will never let the available space fall below ReservedSpace.
 	MinFreeSpace DiskSpace `toml:"minFreeSpace"`
+
+	// Tenant limits the policy to the build cache of a single tenant. The
+	// value "*" applies the policy separately to every tenant.
+	Tenant string `toml:"tenant"`
 }
 
 type DNSConfig struct {
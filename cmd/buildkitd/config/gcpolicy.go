@@ -69,13 +69,21 @@ func (d *DiskSpace) UnmarshalText(textb []byte) error {
 const defaultCap int64 = 2e9 // 2GB
 
 func DefaultGCPolicy(cfg GCConfig, dstat disk.DiskStat) []GCPolicy {
+	var policy []GCPolicy
+	if cfg.GCTenantMaxUsedSpace != (DiskSpace{}) {
+		// keep the build cache of every tenant under its quota
+		policy = append(policy, GCPolicy{
+			Tenant:       "*",
+			MaxUsedSpace: cfg.GCTenantMaxUsedSpace,
+		})
+	}
 	if cfg.IsUnset() {
 		cfg.GCReservedSpace = cfg.GCKeepStorage
 	}
 	if cfg.IsUnset() {
 		cfg = DetectDefaultGCCap(dstat)
 	}
-	return []GCPolicy{
+	return append(policy, []GCPolicy{
 		// if build cache uses more than 512MB delete the most easily reproducible data after it has not been used for 2 days
 		{
 			Filters:      []string{"type==source.local", "type==exec.cachemount", "type==source.git.checkout"},
@@ -102,7 +110,7 @@ func DefaultGCPolicy(cfg GCConfig, dstat disk.DiskStat) []GCPolicy {
 			ReservedSpace: cfg.GCReservedSpace,
 			MaxUsedSpace:  cfg.GCMaxUsedSpace,
 		},
-	}
+	}...)
 }
 
 func stripQuotes(s string) string {
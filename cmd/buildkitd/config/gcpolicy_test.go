@@ -41,3 +41,19 @@ func TestDefaultGCPolicyFiltersMatch(t *testing.T) {
 	require.Falsef(t, f.Match(adapt(client.UsageRecordTypeRegular)),
 		"first default GC policy should not match record type %q", client.UsageRecordTypeRegular)
 }
+
+func TestDefaultGCPolicyTenantQuota(t *testing.T) {
+	dstat := disk.DiskStat{Total: 1e12}
+	policies := DefaultGCPolicy(GCConfig{}, dstat)
+	for _, rule := range policies {
+		require.Empty(t, rule.Tenant)
+	}
+
+	quota := DiskSpace{Bytes: 10e9}
+	tenantPolicies := DefaultGCPolicy(GCConfig{GCTenantMaxUsedSpace: quota}, dstat)
+	require.Len(t, tenantPolicies, len(policies)+1)
+
+	// the quota of each tenant is enforced before the global policies
+	require.Equal(t, GCPolicy{Tenant: "*", MaxUsedSpace: quota}, tenantPolicies[0])
+	require.Equal(t, policies, tenantPolicies[1:])
+}
@@ -29,12 +29,27 @@ cert="mycert.pem"
 [otel]
 socketPath="/tmp/otel-grpc.sock"
 
+[tenant]
+enabled=true
+shareBaseImages=true
+
+[[authz.rule]]
+uids=[1000]
+subjects=["CN=ci"]
+methods=["Solve","Status"]
+entitlements=["network.host"]
+frontends=["dockerfile.v0"]
+exporters=["image"]
+[[authz.rule]]
+methods=["DiskUsage"]
+
 [worker.oci]
 enabled=true
 snapshotter="overlay"
 rootless=true
 gc=false
 gckeepstorage=123456789
+tenantMaxUsedSpace="5GB"
 [worker.oci.labels]
 foo="bar"
 "aa.bb.cc"="baz"
@@ -62,6 +77,7 @@ keepDuration="24h"
 reservedSpace="10GB"
 maxUsedSpace="80%"
 minFreeSpace="10%"
+tenant="*"
 
 [registry."docker.io"]
 mirrors=["hub.docker.io"]
@@ -97,12 +113,25 @@ searchDomains=["example.com"]
 
 	require.Equal(t, "/tmp/otel-grpc.sock", cfg.OTEL.SocketPath)
 
+	require.True(t, cfg.Tenant.Enabled)
+	require.True(t, cfg.Tenant.ShareBaseImages)
+
+	require.Len(t, cfg.Authz.Rules, 2)
+	require.Equal(t, []int{1000}, cfg.Authz.Rules[0].UIDs)
+	require.Equal(t, []string{"CN=ci"}, cfg.Authz.Rules[0].Subjects)
+	require.Equal(t, []string{"Solve", "Status"}, cfg.Authz.Rules[0].Methods)
+	require.Equal(t, []string{"network.host"}, cfg.Authz.Rules[0].Entitlements)
+	require.Equal(t, []string{"dockerfile.v0"}, cfg.Authz.Rules[0].Frontends)
+	require.Equal(t, []string{"image"}, cfg.Authz.Rules[0].Exporters)
+	require.Equal(t, []string{"DiskUsage"}, cfg.Authz.Rules[1].Methods)
+
 	require.NotNil(t, cfg.Workers.OCI.Enabled)
 	require.Equal(t, int64(123456789), cfg.Workers.OCI.GCKeepStorage.Bytes)
 	require.Equal(t, true, *cfg.Workers.OCI.Enabled)
 	require.Equal(t, "overlay", cfg.Workers.OCI.Snapshotter)
 	require.Equal(t, true, cfg.Workers.OCI.Rootless)
 	require.Equal(t, false, *cfg.Workers.OCI.GC)
+	require.Equal(t, int64(5*1024*1024*1024), cfg.Workers.OCI.GCTenantMaxUsedSpace.Bytes)
 
 	require.Equal(t, "bar", cfg.Workers.OCI.Labels["foo"])
 	require.Equal(t, "baz", cfg.Workers.OCI.Labels["aa.bb.cc"])
@@ -137,6 +166,8 @@ searchDomains=["example.com"]
 	require.Equal(t, int64(10*1024*1024*1024), cfg.Workers.Containerd.GCPolicy[3].ReservedSpace.Bytes)
 	require.Equal(t, int64(80), cfg.Workers.Containerd.GCPolicy[3].MaxUsedSpace.Percentage)
 	require.Equal(t, int64(10), cfg.Workers.Containerd.GCPolicy[3].MinFreeSpace.Percentage)
+	require.Equal(t, "*", cfg.Workers.Containerd.GCPolicy[3].Tenant)
+	require.Equal(t, "", cfg.Workers.Containerd.GCPolicy[2].Tenant)
 
 	require.Equal(t, true, *cfg.Registries["docker.io"].PlainHTTP)
 	require.Equal(t, true, *cfg.Registries["docker.io"].Insecure)
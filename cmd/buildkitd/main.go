@@ -26,6 +26,7 @@ import (
 	"github.com/moby/buildkit/cache/remotecache"
 	"github.com/moby/buildkit/cache/remotecache/azblob"
 	"github.com/moby/buildkit/cache/remotecache/gha"
+	httpremotecache "github.com/moby/buildkit/cache/remotecache/http"
 	inlineremotecache "github.com/moby/buildkit/cache/remotecache/inline"
 	localremotecache "github.com/moby/buildkit/cache/remotecache/local"
 	registryremotecache "github.com/moby/buildkit/cache/remotecache/registry"
@@ -33,6 +34,7 @@ import (
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/cmd/buildkitd/config"
 	"github.com/moby/buildkit/control"
+	"github.com/moby/buildkit/control/authz"
 	"github.com/moby/buildkit/executor/oci"
 	"github.com/moby/buildkit/frontend"
 	dockerfile "github.com/moby/buildkit/frontend/dockerfile/builder"
@@ -53,10 +55,12 @@ import (
 	"github.com/moby/buildkit/util/disk"
 	"github.com/moby/buildkit/util/grpcerrors"
 	_ "github.com/moby/buildkit/util/grpcutil/encoding/proto"
+	"github.com/moby/buildkit/util/peercred"
 	"github.com/moby/buildkit/util/profiler"
 	"github.com/moby/buildkit/util/resolver"
 	"github.com/moby/buildkit/util/resolver/limited"
 	"github.com/moby/buildkit/util/stack"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/tracing"
 	_ "github.com/moby/buildkit/util/tracing/childprocess"
 	"github.com/moby/buildkit/util/tracing/detect"
@@ -353,10 +357,29 @@ func main() {
 			otelgrpc.WithMeterProvider(mp),
 			otelgrpc.WithPropagators(propagators),
 		)
+		unaryInterceptors := []grpc.UnaryServerInterceptor{unaryInterceptor, grpcerrors.UnaryServerInterceptor}
+		streamInterceptors := []grpc.StreamServerInterceptor{grpcerrors.StreamServerInterceptor}
+		authzPolicy, err := authz.New(cfg.Authz.Rules)
+		if err != nil {
+			return err
+		}
+		if authzPolicy != nil {
+			unaryInterceptors = append(unaryInterceptors, authzPolicy.UnaryServerInterceptor)
+			streamInterceptors = append(streamInterceptors, authzPolicy.StreamServerInterceptor)
+		}
+		if cfg.Tenant.Enabled {
+			if cfg.GRPC.TLS.CA == "" {
+				return errors.New("tenant isolation requires client certificates, set grpc.tls.ca")
+			}
+			unaryInterceptors = append(unaryInterceptors, tenant.UnaryServerInterceptor)
+			streamInterceptors = append(streamInterceptors, tenant.StreamServerInterceptor)
+		}
 		opts := []grpc.ServerOption{
+			// identifies clients by their unix credentials or TLS certificate
+			grpc.Creds(peercred.NewServerCredentials()),
 			grpc.StatsHandler(statsHandler),
-			grpc.ChainUnaryInterceptor(unaryInterceptor, grpcerrors.UnaryServerInterceptor),
-			grpc.StreamInterceptor(grpcerrors.StreamServerInterceptor),
+			grpc.ChainUnaryInterceptor(unaryInterceptors...),
+			grpc.ChainStreamInterceptor(streamInterceptors...),
 			grpc.MaxRecvMsgSize(defaults.DefaultMaxRecvMsgSize),
 			grpc.MaxSendMsgSize(defaults.DefaultMaxSendMsgSize),
 		}
@@ -926,6 +949,7 @@ func newController(ctx context.Context, c *cli.Command, cfg *config.Config, mp m
 		"gha":      gha.ResolveCacheExporterFunc(cfg.Cache.GHA, verifierProvider),
 		"s3":       s3remotecache.ResolveCacheExporterFunc(),
 		"azblob":   azblob.ResolveCacheExporterFunc(),
+		"http":     httpremotecache.ResolveCacheExporterFunc(sessionManager),
 	}
 	remoteCacheImporterFuncs := map[string]remotecache.ResolveCacheImporterFunc{
 		"registry": registryremotecache.ResolveCacheImporterFunc(sessionManager, w.ContentStore(), resolverFn),
@@ -933,6 +957,7 @@ func newController(ctx context.Context, c *cli.Command, cfg *config.Config, mp m
 		"gha":      gha.ResolveCacheImporterFunc(cfg.Cache.GHA, verifierProvider),
 		"s3":       s3remotecache.ResolveCacheImporterFunc(),
 		"azblob":   azblob.ResolveCacheImporterFunc(),
+		"http":     httpremotecache.ResolveCacheImporterFunc(sessionManager),
 	}
 
 	if cfg.CDI.Disabled == nil || !*cfg.CDI.Disabled {
@@ -960,6 +985,7 @@ func newController(ctx context.Context, c *cli.Command, cfg *config.Config, mp m
 		ContentStore:              w.ContentStore(),
 		HistoryConfig:             cfg.History,
 		ProxyNetwork:              cfg.ProxyNetwork,
+		ShareBaseImages:           cfg.Tenant.ShareBaseImages,
 		GarbageCollect:            w.GarbageCollect,
 		GracefulStop:              ctx.Done(),
 		ProvenanceEnv:             provenanceEnv,
@@ -1053,6 +1079,7 @@ func getGCPolicy(cfg config.GCConfig, root string) []client.PruneInfo {
 			ReservedSpace: rule.ReservedSpace.AsBytes(dstat),
 			MaxUsedSpace:  rule.MaxUsedSpace.AsBytes(dstat),
 			MinFreeSpace:  rule.MinFreeSpace.AsBytes(dstat),
+			Tenant:        rule.Tenant,
 		})
 	}
 	return out
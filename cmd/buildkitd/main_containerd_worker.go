@@ -14,6 +14,7 @@ import (
 	"github.com/moby/buildkit/cmd/buildkitd/config"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/disk"
+	"github.com/moby/buildkit/util/fairqueue"
 	"github.com/moby/buildkit/util/network/cniprovider"
 	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/worker"
@@ -23,7 +24,6 @@ import (
 	"github.com/pelletier/go-toml/v2"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v3"
-	"golang.org/x/sync/semaphore"
 )
 
 const (
@@ -309,9 +309,10 @@ func containerdWorkerInitializer(c *cli.Command, common workerInitializerOpt) ([
 		},
 	}
 
-	var parallelismSem *semaphore.Weighted
+	var parallelismSem *fairqueue.Semaphore
 	if cfg.MaxParallelism > 0 {
-		parallelismSem = semaphore.NewWeighted(int64(cfg.MaxParallelism))
+		parallelismSem = fairqueue.New(int64(cfg.MaxParallelism))
+		parallelismSem.Name = "worker"
 	}
 
 	snapshotter := defaults.DefaultSnapshotter
@@ -0,0 +1,225 @@
+// Package authz authorizes requests to the control API based on the identity
+// of the client, either the user of a process connected over a unix socket or
+// the subject of a mTLS client certificate.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/peercred"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// health checks are allowed for every client
+const healthService = "/grpc.health.v1.Health/"
+
+// Identity is the identity of a client of the control API.
+type Identity struct {
+	// Unix is set for clients connected over a unix socket.
+	Unix *peercred.UnixInfo
+	// Subject is the distinguished name of the verified client certificate.
+	Subject string
+	// CommonName is the common name of the verified client certificate.
+	CommonName string
+}
+
+func (id Identity) String() string {
+	var parts []string
+	if id.Unix != nil {
+		parts = append(parts, fmt.Sprintf("uid=%d gid=%d", id.Unix.UID, id.Unix.GID))
+	}
+	if id.Subject != "" {
+		parts = append(parts, fmt.Sprintf("subject=%q", id.Subject))
+	}
+	if len(parts) == 0 {
+		return "anonymous client"
+	}
+	return strings.Join(parts, " ")
+}
+
+// IdentityFromContext returns the identity of the gRPC peer of ctx.
+func IdentityFromContext(ctx context.Context) Identity {
+	var id Identity
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return id
+	}
+	switch info := p.AuthInfo.(type) {
+	case peercred.UnixInfo:
+		id.Unix = &info
+	case credentials.TLSInfo:
+		if chains := info.State.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			cert := chains[0][0]
+			id.Subject = cert.Subject.String()
+			id.CommonName = cert.Subject.CommonName
+		}
+	}
+	return id
+}
+
+// Policy authorizes requests by the first rule matching the identity of the
+// client.
+type Policy struct {
+	rules []config.AuthzRule
+}
+
+// New returns a policy for the rules. It returns nil if there are no rules,
+// a nil policy allows every request.
+func New(rules []config.AuthzRule) (*Policy, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	for i, r := range rules {
+		for _, m := range r.Methods {
+			if !strings.HasPrefix(m, "/") && !isControlMethod(m) {
+				return nil, errors.Errorf("authz rule %d: unknown control method %q", i, m)
+			}
+		}
+		for _, e := range r.Entitlements {
+			if _, _, err := entitlements.Parse(e); err != nil {
+				return nil, errors.Wrapf(err, "authz rule %d", i)
+			}
+		}
+	}
+	return &Policy{rules: rules}, nil
+}
+
+func isControlMethod(name string) bool {
+	for _, m := range controlapi.Control_ServiceDesc.Methods {
+		if m.MethodName == name {
+			return true
+		}
+	}
+	for _, s := range controlapi.Control_ServiceDesc.Streams {
+		if s.StreamName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) rule(id Identity) (*config.AuthzRule, bool) {
+	for i := range p.rules {
+		if matches(&p.rules[i], id) {
+			return &p.rules[i], true
+		}
+	}
+	return nil, false
+}
+
+func matches(r *config.AuthzRule, id Identity) bool {
+	if len(r.UIDs) == 0 && len(r.GIDs) == 0 && len(r.Subjects) == 0 {
+		return true
+	}
+	if id.Unix != nil && (slices.Contains(r.UIDs, id.Unix.UID) || slices.Contains(r.GIDs, id.Unix.GID)) {
+		return true
+	}
+	if id.Subject != "" && slices.ContainsFunc(r.Subjects, func(s string) bool {
+		return s == id.Subject || s == id.CommonName
+	}) {
+		return true
+	}
+	return false
+}
+
+func methodAllowed(r *config.AuthzRule, fullMethod string) bool {
+	if len(r.Methods) == 0 {
+		return true
+	}
+	name, ok := strings.CutPrefix(fullMethod, "/"+controlapi.Control_ServiceDesc.ServiceName+"/")
+	return slices.ContainsFunc(r.Methods, func(m string) bool {
+		return m == fullMethod || ok && m == name
+	})
+}
+
+// Authorize returns an error if the client identified by ctx is not allowed
+// to call the method with the request.
+func (p *Policy) Authorize(ctx context.Context, fullMethod string, req any) error {
+	if p == nil || strings.HasPrefix(fullMethod, healthService) {
+		return nil
+	}
+	id := IdentityFromContext(ctx)
+	r, ok := p.rule(id)
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "no authorization rule matches %s", id)
+	}
+	if !methodAllowed(r, fullMethod) {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", id, fullMethod)
+	}
+	if req, ok := req.(*controlapi.SolveRequest); ok {
+		if err := authorizeSolve(r, req); err != nil {
+			return status.Errorf(codes.PermissionDenied, "%s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func authorizeSolve(r *config.AuthzRule, req *controlapi.SolveRequest) error {
+	for _, e := range req.Entitlements {
+		name, _, _ := strings.Cut(e, "=")
+		if !slices.Contains(r.Entitlements, e) && !slices.Contains(r.Entitlements, name) {
+			return errors.Errorf("entitlement %s is not allowed", e)
+		}
+	}
+	if req.Frontend != "" && len(r.Frontends) > 0 && !slices.Contains(r.Frontends, req.Frontend) {
+		return errors.Errorf("frontend %s is not allowed", req.Frontend)
+	}
+	if len(r.Exporters) > 0 {
+		types := make([]string, 0, len(req.Exporters)+1)
+		for _, exp := range req.Exporters {
+			types = append(types, exp.Type)
+		}
+		if req.ExporterDeprecated != "" {
+			types = append(types, req.ExporterDeprecated)
+		}
+		for _, t := range types {
+			if !slices.Contains(r.Exporters, t) {
+				return errors.Errorf("exporter %s is not allowed", t)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Policy) UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := p.Authorize(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	if _, ok := req.(*controlapi.SolveRequest); ok {
+		ctx = p.withAllowedFrontends(ctx)
+	}
+	return handler(ctx, req)
+}
+
+// withAllowedFrontends passes the frontends allowed for the client to the
+// solver, which checks them for every frontend the build requests, not only
+// the frontend of the SolveRequest.
+func (p *Policy) withAllowedFrontends(ctx context.Context) context.Context {
+	if p == nil {
+		return ctx
+	}
+	r, ok := p.rule(IdentityFromContext(ctx))
+	if !ok || len(r.Frontends) == 0 {
+		return ctx
+	}
+	return frontend.WithAllowedFrontends(ctx, r.Frontends)
+}
+
+func (p *Policy) StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := p.Authorize(ss.Context(), info.FullMethod, nil); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
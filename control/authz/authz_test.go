@@ -0,0 +1,223 @@
+package authz
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/util/peercred"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type testControl struct {
+	controlapi.UnimplementedControlServer
+}
+
+func (testControl) DiskUsage(context.Context, *controlapi.DiskUsageRequest) (*controlapi.DiskUsageResponse, error) {
+	return &controlapi.DiskUsageResponse{}, nil
+}
+
+func (testControl) Solve(ctx context.Context, _ *controlapi.SolveRequest) (*controlapi.SolveResponse, error) {
+	resp := &controlapi.SolveResponse{}
+	if names, ok := frontend.AllowedFrontends(ctx); ok {
+		resp.ExporterResponse = map[string]string{"frontends": strings.Join(names, ",")}
+	}
+	return resp, nil
+}
+
+func (testControl) Prune(*controlapi.PruneRequest, grpc.ServerStreamingServer[controlapi.UsageRecord]) error {
+	return nil
+}
+
+func startServer(t *testing.T, l net.Listener, rules []config.AuthzRule) {
+	p, err := New(rules)
+	require.NoError(t, err)
+	server := grpc.NewServer(
+		grpc.Creds(peercred.NewServerCredentials()),
+		grpc.UnaryInterceptor(p.UnaryServerInterceptor),
+		grpc.StreamInterceptor(p.StreamServerInterceptor),
+	)
+	controlapi.RegisterControlServer(server, testControl{})
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	dt, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(dt)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+func (ca *testCA) issue(t *testing.T, subject pkix.Name, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	dt, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{dt}, PrivateKey: key}
+}
+
+func TestClientCertificates(t *testing.T) {
+	ca := newTestCA(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l = tls.NewListener(l, &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, pkix.Name{CommonName: "buildkitd"}, x509.ExtKeyUsageServerAuth)},
+		NextProtos:   []string{"h2"},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.pool,
+	})
+	startServer(t, l, []config.AuthzRule{
+		{
+			Subjects:     []string{"CN=ci,O=example"},
+			Entitlements: []string{"network.host"},
+			Frontends:    []string{"dockerfile.v0"},
+			Exporters:    []string{"image"},
+		},
+		{
+			Subjects: []string{"viewer"},
+			Methods:  []string{"DiskUsage"},
+		},
+	})
+
+	dial := func(cn string, org ...string) controlapi.ControlClient {
+		cert := ca.issue(t, pkix.Name{CommonName: cn, Organization: org}, x509.ExtKeyUsageClientAuth)
+		conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      ca.pool,
+		})))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return controlapi.NewControlClient(conn)
+	}
+	ctx := t.Context()
+
+	ci := dial("ci", "example")
+	_, err = ci.DiskUsage(ctx, &controlapi.DiskUsageRequest{})
+	require.NoError(t, err)
+	resp, err := ci.Solve(ctx, &controlapi.SolveRequest{
+		Frontend:     "dockerfile.v0",
+		Entitlements: []string{"network.host"},
+		Exporters:    []*controlapi.Exporter{{Type: "image"}},
+	})
+	require.NoError(t, err)
+	// the solver checks frontends requested during the build
+	require.Equal(t, "dockerfile.v0", resp.ExporterResponse["frontends"])
+	_, err = ci.Solve(ctx, &controlapi.SolveRequest{Entitlements: []string{"security.insecure"}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "entitlement security.insecure is not allowed")
+	_, err = ci.Solve(ctx, &controlapi.SolveRequest{Frontend: "gateway.v0"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = ci.Solve(ctx, &controlapi.SolveRequest{Exporters: []*controlapi.Exporter{{Type: "local"}}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	viewer := dial("viewer")
+	_, err = viewer.DiskUsage(ctx, &controlapi.DiskUsageRequest{})
+	require.NoError(t, err)
+	_, err = viewer.Solve(ctx, &controlapi.SolveRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	stream, err := viewer.Prune(ctx, &controlapi.PruneRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// same common name but a different subject than the ci rule
+	other := dial("ci", "other")
+	_, err = other.DiskUsage(ctx, &controlapi.DiskUsageRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "no authorization rule matches")
+}
+
+func TestUnixPeer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("unix peer credentials are only supported on linux")
+	}
+	sock := filepath.Join(t.TempDir(), "buildkitd.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	startServer(t, l, []config.AuthzRule{
+		{
+			UIDs:    []int{os.Getuid()},
+			Methods: []string{"DiskUsage"},
+		},
+	})
+
+	conn, err := grpc.NewClient("unix://"+sock, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	c := controlapi.NewControlClient(conn)
+
+	_, err = c.DiskUsage(t.Context(), &controlapi.DiskUsageRequest{})
+	require.NoError(t, err)
+	_, err = c.Solve(t.Context(), &controlapi.SolveRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "is not allowed to call /moby.buildkit.v1.Control/Solve")
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(nil)
+	require.NoError(t, err)
+	require.Nil(t, p)
+	require.NoError(t, p.Authorize(t.Context(), "/moby.buildkit.v1.Control/Solve", nil))
+
+	_, err = New([]config.AuthzRule{{Methods: []string{"Build"}}})
+	require.ErrorContains(t, err, `unknown control method "Build"`)
+
+	_, err = New([]config.AuthzRule{{Entitlements: []string{"network.none"}}})
+	require.ErrorContains(t, err, "unknown entitlement")
+
+	_, err = New([]config.AuthzRule{{Methods: []string{"Solve", "/grpc.health.v1.Health/Check"}}})
+	require.NoError(t, err)
+}
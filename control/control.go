@@ -44,8 +44,10 @@ import (
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/db"
 	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/fairqueue"
 	"github.com/moby/buildkit/util/imageutil"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/throttle"
 	"github.com/moby/buildkit/util/tracing/forwarder"
 	"github.com/moby/buildkit/util/tracing/transform"
@@ -82,6 +84,7 @@ type Opt struct {
 	ContentStore              *containerdsnapshot.Store
 	HistoryConfig             *config.HistoryConfig
 	ProxyNetwork              bool
+	ShareBaseImages           bool
 	GarbageCollect            func(context.Context) error
 	GracefulStop              <-chan struct{}
 	ProvenanceEnv             map[string]any
@@ -126,6 +129,7 @@ func NewController(opt Opt) (*Controller, error) {
 		Entitlements:     opt.Entitlements,
 		HistoryQueue:     hq,
 		ProxyNetwork:     opt.ProxyNetwork,
+		ShareBaseImages:  opt.ShareBaseImages,
 		ProvenanceEnv:    opt.ProvenanceEnv,
 		MeterProvider:    opt.MeterProvider,
 	})
@@ -204,6 +208,7 @@ func (c *Controller) DiskUsage(ctx context.Context, r *controlapi.DiskUsageReque
 		du, err := w.DiskUsage(ctx, client.DiskUsageInfo{
 			Filter:   r.Filter,
 			AgeLimit: time.Duration(r.AgeLimit),
+			Tenant:   tenant.FromContext(ctx),
 		})
 		if err != nil {
 			return nil, err
@@ -274,6 +279,7 @@ func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Contr
 					ReservedSpace: req.ReservedSpace,
 					MaxUsedSpace:  req.MaxUsedSpace,
 					MinFreeSpace:  req.MinFreeSpace,
+					Tenant:        tenant.FromContext(ctx),
 				})
 			})
 		}(w)
@@ -367,6 +373,17 @@ func (c *Controller) UpdateBuildHistory(ctx context.Context, req *controlapi.Upd
 	return &controlapi.UpdateBuildHistoryResponse{}, err
 }
 
+func (c *Controller) DiffBuildHistory(ctx context.Context, req *controlapi.DiffBuildHistoryRequest) (*controlapi.DiffBuildHistoryResponse, error) {
+	if req.Ref == "" || req.CompareRef == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "ref and compare ref are required")
+	}
+	vertices, err := c.history.Diff(ctx, req.Ref, req.CompareRef)
+	if err != nil {
+		return nil, err
+	}
+	return &controlapi.DiffBuildHistoryResponse{Vertices: vertices}, nil
+}
+
 func translateLegacySolveRequest(req *controlapi.SolveRequest) {
 	// translates ExportRef and ExportAttrs to new Exports (v0.4.0)
 	if legacyExportRef := req.Cache.ExportRefDeprecated; legacyExportRef != "" {
@@ -572,6 +589,25 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 		procs = append(procs, proc.ProvenanceProcessor(slsaVersion, params, c.opt.ProvenanceEnv))
 	}
 
+	var deadline time.Time
+	if req.Deadline != nil {
+		deadline = req.Deadline.AsTime()
+	}
+
+	priority, err := fairqueue.ParsePriority(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+	schedClass := fairqueue.Class{
+		ID:       req.Ref,
+		Tenant:   req.Tenant,
+		Priority: priority,
+	}
+	if id := tenant.FromContext(ctx); id != "" {
+		// isolated tenants share a single flow regardless of the requested one
+		schedClass.Tenant = id
+	}
+
 	resp, err := c.solver.Solve(ctx, req.Ref, req.Session, frontend.SolveRequest{
 		Frontend:       req.Frontend,
 		Definition:     req.Definition,
@@ -582,7 +618,7 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 		Exporters:             expis,
 		CacheExporters:        cacheExporters,
 		EnableSessionExporter: req.EnableSessionExporter,
-	}, entitlementsFromPB(req.Entitlements), procs, req.Internal, req.SourcePolicy, req.SourcePolicySession, req.ProxyNetwork)
+	}, entitlementsFromPB(req.Entitlements), procs, req.Internal, req.SourcePolicy, req.SourcePolicySession, req.ProxyNetwork, deadline, schedClass)
 	if err != nil {
 		return nil, err
 	}
@@ -744,6 +780,7 @@ func toPBGCPolicy(in []client.PruneInfo) []*apitypes.GCPolicy {
 			ReservedSpace: p.ReservedSpace,
 			MaxUsedSpace:  p.MaxUsedSpace,
 			MinFreeSpace:  p.MinFreeSpace,
+			Tenant:        p.Tenant,
 		})
 	}
 	return policy
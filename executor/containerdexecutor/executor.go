@@ -182,7 +182,9 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 	}
 
 	var namespace network.Namespace
-	if proxyConfig != nil {
+	if meta.NetworkNamespace != nil {
+		namespace = meta.NetworkNamespace
+	} else if proxyConfig != nil {
 		namespace, err = w.proxyProvider.NewProxy(ctx, proxyConfig)
 	} else {
 		namespace, err = provider.New(ctx, meta.Hostname, network.NamespaceOptions{})
@@ -30,6 +30,11 @@ type Meta struct {
 	SecurityMode   pb.SecurityMode
 	ValidExitCodes []int
 	Proxy          *network.ProxyConfig
+	// NetworkNamespace is an existing network namespace joined by the
+	// container instead of creating a new one from the network mode. It is
+	// closed with the container, so a namespace shared by several containers
+	// should ignore Close.
+	NetworkNamespace network.Namespace
 
 	RemoveMountStubsRecursive bool
 }
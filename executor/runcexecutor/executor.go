@@ -204,7 +204,9 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 		}
 	}
 	var namespace network.Namespace
-	if proxyConfig != nil {
+	if meta.NetworkNamespace != nil {
+		namespace = meta.NetworkNamespace
+	} else if proxyConfig != nil {
 		namespace, err = w.proxyProvider.NewProxy(ctx, proxyConfig)
 	} else {
 		namespace, err = provider.New(ctx, meta.Hostname, network.NamespaceOptions{})
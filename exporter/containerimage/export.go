@@ -220,6 +220,11 @@ func (e *imageExporterInstance) Attrs() map[string]string {
 	return e.attrs
 }
 
+// ExportsMatrixCells exports an image for every cell of a build matrix.
+func (e *imageExporterInstance) ExportsMatrixCells() bool {
+	return true
+}
+
 func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source, buildInfo exporter.ExportBuildInfo) (_ map[string]string, _ exporter.FinalizeFunc, descref exporter.DescriptorReference, err error) {
 	src = src.Clone()
 	if src.Metadata == nil {
@@ -228,6 +233,13 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 	maps.Copy(src.Metadata, e.meta)
 
 	opts := e.opts
+	cell, err := exptypes.ParseMatrixCell(src.Metadata)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if opts.ImageName, err = cell.Expand(opts.ImageName); err != nil {
+		return nil, nil, nil, err
+	}
 	as, _, err := ParseAnnotations(src.Metadata)
 	if err != nil {
 		return nil, nil, nil, err
@@ -259,17 +271,18 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 
 	resp := make(map[string]string)
 
-	if n, ok := src.Metadata["image.name"]; e.opts.ImageName == "*" && ok {
-		e.opts.ImageName = string(n)
+	imageName := opts.ImageName
+	if n, ok := src.Metadata["image.name"]; imageName == "*" && ok {
+		imageName = string(n)
 	}
 
 	nameCanonical := e.nameCanonical
-	if e.danglingPrefix != "" && (!e.danglingEmptyOnly || e.opts.ImageName == "") {
+	if e.danglingPrefix != "" && (!e.danglingEmptyOnly || imageName == "") {
 		danglingImageName := e.danglingPrefix + "@" + desc.Digest.String()
-		if e.opts.ImageName != "" {
-			e.opts.ImageName += "," + danglingImageName
+		if imageName != "" {
+			imageName += "," + danglingImageName
 		} else {
-			e.opts.ImageName = danglingImageName
+			imageName = danglingImageName
 			nameCanonical = false
 		}
 	}
@@ -277,8 +290,8 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 	// Collect names for finalize callback to push
 	var namesToPush []string
 
-	if e.opts.ImageName != "" {
-		targetNames := strings.SplitSeq(e.opts.ImageName, ",")
+	if imageName != "" {
+		targetNames := strings.SplitSeq(imageName, ",")
 		for targetName := range targetNames {
 			if e.opt.Images != nil && e.store {
 				tagDone := progress.OneOff(ctx, "naming to "+targetName)
@@ -330,6 +343,9 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 						// https://github.com/moby/buildkit/pull/4057#discussion_r1324106088
 						return nil, nil, nil, errors.New("exporter option \"rewrite-timestamp\" conflicts with \"unpack\"")
 					}
+					if opts.ReproducibleStrict {
+						return nil, nil, nil, errors.New("exporter option \"reproducible=strict\" conflicts with \"unpack\"")
+					}
 					if err := e.unpackImage(ctx, img, src, session.NewGroup(buildInfo.SessionID)); err != nil {
 						return nil, nil, nil, err
 					}
@@ -372,7 +388,7 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 				namesToPush = append(namesToPush, targetName)
 			}
 		}
-		resp[exptypes.ExporterImageNameKey] = e.opts.ImageName
+		resp[exptypes.ExporterImageNameKey] = imageName
 	}
 
 	resp[exptypes.ExporterImageDigestKey] = desc.Digest.String()
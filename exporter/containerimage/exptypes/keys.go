@@ -86,4 +86,9 @@ var (
 	// Rewrite timestamps in layers to match SOURCE_DATE_EPOCH
 	// Value: bool <true|false>
 	OptKeyRewriteTimestamp ImageExporterOptKey = "rewrite-timestamp"
+
+	// Normalize layer tars so that they only depend on the files in the layer
+	// and verify them against the layers regenerated from the snapshots.
+	// Value: string <strict>
+	OptKeyReproducible ImageExporterOptKey = "reproducible"
 )
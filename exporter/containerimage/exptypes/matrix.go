@@ -0,0 +1,177 @@
+package exptypes
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/solver/result"
+	"github.com/pkg/errors"
+)
+
+var matrixArgRegexp = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// MatrixRefID returns the key of the ref for a platform of a matrix cell.
+func MatrixRefID(cellID, platformID string) string {
+	return cellID + "/" + platformID
+}
+
+// ParseMatrix returns the build matrix of a result, or nil if the result is
+// not a build matrix.
+func ParseMatrix(meta map[string][]byte) (*Matrix, error) {
+	dt, ok := meta[ExporterMatrixKey]
+	if !ok {
+		return nil, nil
+	}
+	var m Matrix
+	if err := json.Unmarshal(dt, &m); err != nil {
+		return nil, errors.Wrap(err, "failed to parse build matrix")
+	}
+	if len(m.Cells) == 0 {
+		return nil, errors.New("invalid empty build matrix")
+	}
+	ids := make(map[string]struct{}, len(m.Cells))
+	for _, c := range m.Cells {
+		if c.ID == "" {
+			return nil, errors.New("invalid empty build matrix cell ID")
+		}
+		if _, ok := ids[c.ID]; ok {
+			return nil, errors.Errorf("duplicate build matrix cell %s", c.ID)
+		}
+		ids[c.ID] = struct{}{}
+		if len(c.Platforms) == 0 {
+			return nil, errors.Errorf("build matrix cell %s has no platforms", c.ID)
+		}
+	}
+	return &m, nil
+}
+
+// ParseMatrixCell returns the matrix cell of a result created by SplitMatrix,
+// or nil if the result is not a cell of a build matrix.
+func ParseMatrixCell(meta map[string][]byte) (*MatrixCell, error) {
+	dt, ok := meta[ExporterMatrixCellKey]
+	if !ok {
+		return nil, nil
+	}
+	var c MatrixCell
+	if err := json.Unmarshal(dt, &c); err != nil {
+		return nil, errors.Wrap(err, "failed to parse build matrix cell")
+	}
+	return &c, nil
+}
+
+// Expand replaces the {{ARG}} references to the build args of the cell in s.
+// A nil cell returns s unchanged.
+func (c *MatrixCell) Expand(s string) (string, error) {
+	if c == nil {
+		return s, nil
+	}
+	var err error
+	out := matrixArgRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		name := matrixArgRegexp.FindStringSubmatch(m)[1]
+		v, ok := c.Args[name]
+		if !ok && err == nil {
+			err = errors.Errorf("%s is not a build matrix arg in %q", name, s)
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// SplitMatrix splits a build matrix result into a result per cell, in the
+// order of the cells. The refs, attestations and metadata of each result are
+// keyed by platform ID like any other multi-platform result, and the cell is
+// stored under ExporterMatrixCellKey. It returns nil if res is not a build
+// matrix.
+func SplitMatrix[T comparable](res *result.Result[T]) ([]*result.Result[T], error) {
+	m, err := ParseMatrix(res.Metadata)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	var refIDs []string
+	for _, c := range m.Cells {
+		for _, p := range c.Platforms {
+			refIDs = append(refIDs, MatrixRefID(c.ID, p.ID))
+		}
+	}
+
+	out := make([]*result.Result[T], 0, len(m.Cells))
+	for _, c := range m.Cells {
+		single := !m.MultiPlatform && len(c.Platforms) == 1
+		r := &result.Result[T]{}
+		platformIDs := make(map[string]string, len(c.Platforms))
+		for _, p := range c.Platforms {
+			refID := MatrixRefID(c.ID, p.ID)
+			ref, ok := res.Refs[refID]
+			if !ok {
+				return nil, errors.Errorf("build matrix result has no ref for %s", refID)
+			}
+			platformIDs[refID] = p.ID
+			if single {
+				r.SetRef(ref)
+			} else {
+				r.AddRef(p.ID, ref)
+			}
+			for _, att := range res.Attestations[refID] {
+				r.AddAttestation(p.ID, att)
+			}
+		}
+
+		// copy the metadata of the result before the metadata of the refs so
+		// that the latter take precedence for single platform cells
+		var refMeta []string
+		for k, v := range res.Metadata {
+			if k == ExporterMatrixKey || k == ExporterPlatformsKey {
+				continue
+			}
+			if _, _, ok := cutRefID(k, refIDs); ok {
+				refMeta = append(refMeta, k)
+				continue
+			}
+			r.AddMeta(k, v)
+		}
+		for _, k := range refMeta {
+			key, refID, _ := cutRefID(k, refIDs)
+			pid, ok := platformIDs[refID]
+			if !ok {
+				continue
+			}
+			if single {
+				r.AddMeta(key, res.Metadata[k])
+			} else {
+				r.AddMeta(key+"/"+pid, res.Metadata[k])
+			}
+		}
+
+		dt, err := json.Marshal(Platforms{Platforms: c.Platforms})
+		if err != nil {
+			return nil, err
+		}
+		r.AddMeta(ExporterPlatformsKey, dt)
+		dt, err = json.Marshal(MatrixCell{ID: c.ID, Args: c.Args})
+		if err != nil {
+			return nil, err
+		}
+		r.AddMeta(ExporterMatrixCellKey, dt)
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// cutRefID splits a metadata key suffixed by one of the ref IDs, preferring
+// the longest matching ID.
+func cutRefID(k string, refIDs []string) (key, refID string, ok bool) {
+	for _, id := range refIDs {
+		if len(id) > len(refID) && strings.HasSuffix(k, "/"+id) {
+			refID = id
+		}
+	}
+	if refID == "" {
+		return k, "", false
+	}
+	return strings.TrimSuffix(k, "/"+refID), refID, true
+}
@@ -0,0 +1,100 @@
+package exptypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/moby/buildkit/solver/result"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMatrix(t *testing.T) {
+	amd64 := Platform{ID: "linux/amd64", Platform: ocispecs.Platform{OS: "linux", Architecture: "amd64"}}
+	arm64 := Platform{ID: "linux/arm64", Platform: ocispecs.Platform{OS: "linux", Architecture: "arm64"}}
+	m := Matrix{
+		Cells: []MatrixCell{
+			{ID: "GO_VERSION=1.22", Args: map[string]string{"GO_VERSION": "1.22"}, Platforms: []Platform{amd64, arm64}},
+			{ID: "GO_VERSION=1.23", Args: map[string]string{"GO_VERSION": "1.23"}, Platforms: []Platform{amd64, arm64}},
+		},
+	}
+	dt, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	res := &result.Result[string]{}
+	res.AddMeta(ExporterMatrixKey, dt)
+	res.AddMeta(ExporterPlatformsKey, []byte("{}"))
+	res.AddMeta("frontend.caps", []byte("caps"))
+	for _, c := range m.Cells {
+		for _, p := range c.Platforms {
+			id := MatrixRefID(c.ID, p.ID)
+			res.AddRef(id, id)
+			res.AddMeta(ExporterImageConfigKey+"/"+id, []byte(id))
+			res.AddAttestation(id, result.Attestation[string]{Ref: "att-" + id})
+		}
+	}
+
+	cells, err := SplitMatrix(res)
+	require.NoError(t, err)
+	require.Len(t, cells, 2)
+
+	c := cells[1]
+	require.Equal(t, map[string]string{
+		"linux/amd64": "GO_VERSION=1.23/linux/amd64",
+		"linux/arm64": "GO_VERSION=1.23/linux/arm64",
+	}, c.Refs)
+	require.Equal(t, "att-GO_VERSION=1.23/linux/arm64", c.Attestations["linux/arm64"][0].Ref)
+	require.Equal(t, []byte("GO_VERSION=1.23/linux/amd64"), c.Metadata[ExporterImageConfigKey+"/linux/amd64"])
+	require.Equal(t, []byte("caps"), c.Metadata["frontend.caps"])
+	require.NotContains(t, c.Metadata, ExporterMatrixKey)
+	require.NotContains(t, c.Metadata, ExporterImageConfigKey+"/GO_VERSION=1.22/linux/amd64")
+
+	ps, err := ParsePlatforms(c.Metadata)
+	require.NoError(t, err)
+	require.Equal(t, []Platform{amd64, arm64}, ps.Platforms)
+
+	cell, err := ParseMatrixCell(c.Metadata)
+	require.NoError(t, err)
+	require.Equal(t, "GO_VERSION=1.23", cell.ID)
+	name, err := cell.Expand("app:{{GO_VERSION}},app:go{{ GO_VERSION }}-latest")
+	require.NoError(t, err)
+	require.Equal(t, "app:1.23,app:go1.23-latest", name)
+	_, err = cell.Expand("app:{{VERSION}}")
+	require.ErrorContains(t, err, "VERSION is not a build matrix arg")
+
+	// not a build matrix
+	cells, err = SplitMatrix(&result.Result[string]{Ref: "ref"})
+	require.NoError(t, err)
+	require.Nil(t, cells)
+}
+
+func TestSplitMatrixSinglePlatform(t *testing.T) {
+	amd64 := Platform{ID: "linux/amd64", Platform: ocispecs.Platform{OS: "linux", Architecture: "amd64"}}
+	m := Matrix{
+		Cells: []MatrixCell{
+			{ID: "A=1", Args: map[string]string{"A": "1"}, Platforms: []Platform{amd64}},
+			{ID: "A=2", Args: map[string]string{"A": "2"}, Platforms: []Platform{amd64}},
+		},
+	}
+	dt, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	res := &result.Result[string]{}
+	res.AddMeta(ExporterMatrixKey, dt)
+	for _, c := range m.Cells {
+		id := MatrixRefID(c.ID, amd64.ID)
+		res.AddRef(id, id)
+		res.AddMeta(ExporterImageConfigKey+"/"+id, []byte(id))
+	}
+
+	cells, err := SplitMatrix(res)
+	require.NoError(t, err)
+	require.Len(t, cells, 2)
+	require.Equal(t, "A=1/linux/amd64", cells[0].Ref)
+	require.Empty(t, cells[0].Refs)
+	require.Equal(t, []byte("A=1/linux/amd64"), cells[0].Metadata[ExporterImageConfigKey])
+
+	delete(res.Refs, "A=2/linux/amd64")
+	_, err = SplitMatrix(res)
+	require.ErrorContains(t, err, "no ref for A=2/linux/amd64")
+}
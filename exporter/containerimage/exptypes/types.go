@@ -16,6 +16,8 @@ const (
 	ExporterImageDescriptorKey   = "containerimage.descriptor"
 	ExporterImageBaseConfigKey   = "containerimage.base.config"
 	ExporterPlatformsKey         = "refs.platforms"
+	ExporterMatrixKey            = "refs.matrix"
+	ExporterMatrixCellKey        = "matrix.cell"
 )
 
 // KnownRefMetadataKeys are the subset of exporter keys that can be suffixed by
@@ -34,6 +36,22 @@ type Platform struct {
 	Platform ocispecs.Platform
 }
 
+// Matrix describes the cells of a build matrix result. The refs of a cell are
+// keyed by MatrixRefID of the cell and platform IDs.
+type Matrix struct {
+	// MultiPlatform is set if the cells are exported as multi-platform images
+	// even if they only contain a single platform.
+	MultiPlatform bool
+	Cells         []MatrixCell
+}
+
+// MatrixCell is a single combination of build args of a build matrix.
+type MatrixCell struct {
+	ID        string
+	Args      map[string]string
+	Platforms []Platform `json:",omitempty"`
+}
+
 type InlineCacheEntry struct {
 	Data []byte
 }
@@ -21,6 +21,7 @@ type ImageCommitOpts struct {
 
 	ForceInlineAttestations bool // force inline attestations to be attached
 	RewriteTimestamp        bool // rewrite timestamps in layers to match the epoch
+	ReproducibleStrict      bool // normalize layer tars and verify their digests
 }
 
 func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[string]string, error) {
@@ -60,6 +61,15 @@ func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[
 			err = parseBool(&c.RefCfg.PreferNonDistributable, k, v)
 		case exptypes.OptKeyRewriteTimestamp:
 			err = parseBool(&c.RewriteTimestamp, k, v)
+		case exptypes.OptKeyReproducible:
+			switch v {
+			case "strict":
+				c.ReproducibleStrict = true
+			case "":
+				c.ReproducibleStrict = false
+			default:
+				err = errors.Errorf("invalid value %q for %s, only \"strict\" is supported", v, k)
+			}
 		default:
 			rest[k] = v
 		}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
 	"strings"
@@ -55,6 +56,8 @@ type WriterOpt struct {
 	ContentStore content.Store
 	Applier      diff.Applier
 	Differ       diff.Comparer
+	// TempDir is the directory layers are buffered in while normalizing them.
+	TempDir string
 }
 
 func NewImageWriter(opt WriterOpt) (*ImageWriter, error) {
@@ -162,8 +165,8 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 			return nil, err
 		}
 		remote := &remotes[0]
-		if opts.RewriteTimestamp {
-			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, remote, baseImg, expEpoch)
+		if opts.RewriteTimestamp || opts.ReproducibleStrict {
+			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, ref, remote, baseImg, expEpoch, session.NewGroup(sessionID))
 			if err != nil {
 				return nil, err
 			}
@@ -280,8 +283,8 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 				Provider: ic.opt.ContentStore,
 			}
 		}
-		if opts.RewriteTimestamp {
-			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, remote, baseImg, expEpoch)
+		if opts.RewriteTimestamp || opts.ReproducibleStrict {
+			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, r, remote, baseImg, expEpoch, session.NewGroup(sessionID))
 			if err != nil {
 				return nil, err
 			}
@@ -416,17 +419,24 @@ func (ic *ImageWriter) exportLayers(ctx context.Context, refCfg cacheconfig.RefC
 }
 
 // rewriteImageLayerWithEpoch rewrites the file timestamps in the layer blob to match the epoch, and returns a new descriptor that points to
-// the new blob.
+// the new blob. With a regenerate function, the layer tar is also normalized to be reproducible and verified against the
+// layer tar it returns.
 //
 // If no conversion is needed, this returns nil without error.
-func rewriteImageLayerWithEpoch(ctx context.Context, cs content.Store, desc ocispecs.Descriptor, comp compression.Config, epoch *time.Time, immDiffID digest.Digest) (*ocispecs.Descriptor, error) {
+func rewriteImageLayerWithEpoch(ctx context.Context, cs content.Store, desc ocispecs.Descriptor, comp compression.Config, epoch *time.Time, immDiffID digest.Digest, regenerate func(context.Context) (io.ReadCloser, error), tempDir string) (*ocispecs.Descriptor, error) {
 	var immDiffIDs map[digest.Digest]struct{}
 	if immDiffID != "" {
 		immDiffIDs = map[digest.Digest]struct{}{
 			immDiffID: {},
 		}
 	}
-	converterFn, err := converter.NewWithRewriteTimestamp(ctx, cs, desc, comp, epoch, immDiffIDs)
+	converterFn, err := converter.NewWithOpt(ctx, cs, desc, comp, converter.Opt{
+		RewriteTimestamp: epoch,
+		ImmutableDiffIDs: immDiffIDs,
+		Strict:           regenerate != nil,
+		Regenerate:       regenerate,
+		TempDir:          tempDir,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -436,16 +446,32 @@ func rewriteImageLayerWithEpoch(ctx context.Context, cs content.Store, desc ocis
 	return converterFn(ctx, cs, desc)
 }
 
-func (ic *ImageWriter) rewriteRemoteWithEpoch(ctx context.Context, opts *ImageCommitOpts, remote *solver.Remote, baseImg *dockerspec.DockerOCIImage, expEpoch *time.Time) (*solver.Remote, error) {
-	if expEpoch == nil {
+func (ic *ImageWriter) rewriteRemoteWithEpoch(ctx context.Context, opts *ImageCommitOpts, ref cache.ImmutableRef, remote *solver.Remote, baseImg *dockerspec.DockerOCIImage, expEpoch *time.Time, sg session.Group) (*solver.Remote, error) {
+	if !opts.RewriteTimestamp {
+		expEpoch = nil
+	} else if expEpoch == nil {
 		bklog.G(ctx).Warn("rewrite-timestamp is specified, but no source-date-epoch was found")
+	}
+	if expEpoch == nil && !opts.ReproducibleStrict {
 		return remote, nil
 	}
 	remoteDescriptors := remote.Descriptors
+	var layers cache.RefList
+	if opts.ReproducibleStrict && ref != nil {
+		// the layers are regenerated from their snapshots for verification
+		layers = ref.LayerChain()
+		defer layers.Release(context.WithoutCancel(ctx))
+		if len(layers) != len(remoteDescriptors) {
+			return nil, errors.Errorf("cannot verify layers: got %d layers for %d blobs", len(layers), len(remoteDescriptors))
+		}
+	}
 	cs := contentutil.NewStoreWithProvider(ic.opt.ContentStore, remote.Provider)
 	eg, ctx := errgroup.WithContext(ctx)
-	rewriteDone := progress.OneOff(ctx,
-		fmt.Sprintf("rewriting layers with source-date-epoch %d (%s)", expEpoch.Unix(), expEpoch.String()))
+	msg := "normalizing layers for reproducibility"
+	if expEpoch != nil {
+		msg = fmt.Sprintf("rewriting layers with source-date-epoch %d (%s)", expEpoch.Unix(), expEpoch.String())
+	}
+	rewriteDone := progress.OneOff(ctx, msg)
 	var divergedFromBase bool
 	for i, desc := range remoteDescriptors {
 		// Usually we get non-empty diffID here, but if the content was ingested via a third-party containerd client,
@@ -467,8 +493,15 @@ func (ic *ImageWriter) rewriteRemoteWithEpoch(ctx context.Context, opts *ImageCo
 			}
 			divergedFromBase = true
 		}
+		var regenerate func(context.Context) (io.ReadCloser, error)
+		if opts.ReproducibleStrict {
+			regenerate = layerDiffFunc(layers[i], sg)
+		}
 		eg.Go(func() error {
-			if rewrittenDesc, err := rewriteImageLayerWithEpoch(ctx, cs, desc, opts.RefCfg.Compression, expEpoch, immDiffID); err != nil {
+			if rewrittenDesc, err := rewriteImageLayerWithEpoch(ctx, cs, desc, opts.RefCfg.Compression, expEpoch, immDiffID, regenerate, ic.opt.TempDir); err != nil {
+				if opts.ReproducibleStrict {
+					return errors.Wrapf(err, "failed to normalize layer %d/%d", i+1, len(remoteDescriptors))
+				}
 				bklog.G(ctx).WithError(err).Warnf("failed to rewrite layer %d/%d to match source-date-epoch %d (%s)",
 					i+1, len(remoteDescriptors), expEpoch.Unix(), expEpoch.String())
 			} else if rewrittenDesc != nil {
@@ -486,6 +519,32 @@ func (ic *ImageWriter) rewriteRemoteWithEpoch(ctx context.Context, opts *ImageCo
 	}, nil
 }
 
+// layerDiffFunc returns a function that generates the tar of the layer from
+// its snapshot.
+func layerDiffFunc(layer cache.ImmutableRef, sg session.Group) func(context.Context) (io.ReadCloser, error) {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pw.CloseWithError(layer.WriteLayerDiff(ctx, pw, sg))
+		}()
+		return &layerDiffReader{PipeReader: pr, done: done}, nil
+	}
+}
+
+type layerDiffReader struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+// Close waits until the snapshots of the layer are unmounted.
+func (r *layerDiffReader) Close() error {
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
+}
+
 func (ic *ImageWriter) commitDistributionManifest(ctx context.Context, opts *ImageCommitOpts, ref cache.ImmutableRef, config []byte, remote *solver.Remote, annotations *Annotations, inlineCache *exptypes.InlineCacheEntry, epoch *time.Time, sg session.Group, baseImg *dockerspec.DockerOCIImage) (*ocispecs.Descriptor, *ocispecs.Descriptor, error) {
 	if len(config) == 0 {
 		var err error
@@ -49,6 +49,14 @@ type ExporterInstance interface {
 	)
 }
 
+// MatrixExporter is implemented by exporter instances that export each cell
+// of a build matrix separately. Export is then called once per cell with a
+// source split by exptypes.SplitMatrix. Other exporters receive the refs of
+// all cells in a single source.
+type MatrixExporter interface {
+	ExportsMatrixCells() bool
+}
+
 type ExportBuildInfo struct {
 	Ref                  string
 	InlineCache          exptypes.InlineCache
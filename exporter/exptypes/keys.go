@@ -2,6 +2,9 @@ package exptypes
 
 const (
 	ExporterEpochKey = "source.date.epoch"
+	// ExporterResourceReportKey is the metadata key of the JSON encoded
+	// resource usage report of the build.
+	ExporterResourceReportKey = "resource.report"
 )
 
 type ExporterOptKey string
@@ -13,3 +16,10 @@ var (
 	// Value: int (number of seconds since Unix epoch)
 	OptKeySourceDateEpoch ExporterOptKey = "source-date-epoch"
 )
+
+// Options keys supported by the local and tar exporters.
+var (
+	// Add a report of the resource usage of each build step to the output.
+	// Value: bool or format (json, csv)
+	OptKeyResourceReport ExporterOptKey = "resource-report"
+)
@@ -203,7 +203,9 @@ func (e *localExporterInstance) Export(ctx context.Context, inp *exporter.Source
 
 	eg, ctx := errgroup.WithContext(ctx)
 
-	if mode == client.LocalExporterModeDelete {
+	if mode == client.LocalExporterModeDelete || mode == client.LocalExporterModeSync {
+		// modes removing files from the destination need all platforms in
+		// a single transfer
 		eg.Go(func() error {
 			var outputFS fsutil.FS
 			var platformDirs []fsutil.Dir
@@ -268,8 +270,19 @@ func (e *localExporterInstance) Export(ctx context.Context, inp *exporter.Source
 				addFS(fs)
 			}
 
+			reportFS, err := ResourceReportFS(inp, now, e.opts)
+			if err != nil {
+				return err
+			}
+			if reportFS != nil {
+				addFS(reportFS)
+			}
+
 			progress, closeProgress := NewProgressHandler(ctx, "copying files")
 			defer closeProgress()
+			if mode == client.LocalExporterModeSync {
+				return filesync.SyncToCaller(ctx, outputFS, e.id, caller, progress, filesync.WithExporterMultiPlatformTransfer())
+			}
 			return filesync.CopyToCaller(ctx, outputFS, e.id, caller, progress, filesync.WithExporterMultiPlatformTransfer())
 		})
 	} else if len(platforms.Platforms) > 0 {
@@ -292,6 +305,20 @@ func (e *localExporterInstance) Export(ctx context.Context, inp *exporter.Source
 		eg.Go(export(ctx, "", inp.Ref, nil, e.opts))
 	}
 
+	if mode == client.LocalExporterModeCopy {
+		reportFS, err := ResourceReportFS(inp, now, e.opts)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if reportFS != nil {
+			eg.Go(func() error {
+				progress, closeProgress := NewProgressHandler(ctx, "copying resource report")
+				defer closeProgress()
+				return filesync.CopyToCaller(ctx, reportFS, e.id, caller, progress)
+			})
+		}
+	}
+
 	if err := eg.Wait(); err != nil {
 		return nil, nil, nil, err
 	}
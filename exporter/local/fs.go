@@ -17,7 +17,9 @@ import (
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/exporter"
 	"github.com/moby/buildkit/exporter/attestation"
+	"github.com/moby/buildkit/exporter/exptypes"
 	"github.com/moby/buildkit/exporter/util/epoch"
+	"github.com/moby/buildkit/exporter/util/resourcereport"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/result"
@@ -41,6 +43,7 @@ type CreateFSOpts struct {
 	Epoch             *epoch.Epoch
 	AttestationPrefix string
 	PlatformSplit     *bool
+	ResourceReport    resourcereport.Format
 }
 
 func (c *CreateFSOpts) UsePlatformSplit(isMap bool) bool {
@@ -73,6 +76,11 @@ func (c *CreateFSOpts) Load(opt map[string]string) (map[string]string, error) {
 			if _, err := client.ParseLocalExporterMode(v); err != nil {
 				return nil, err
 			}
+		case string(exptypes.OptKeyResourceReport):
+			c.ResourceReport, err = resourcereport.ParseFormat(v)
+			if err != nil {
+				return nil, err
+			}
 		default:
 			rest[k] = v
 		}
@@ -222,3 +230,36 @@ func CreateFS(ctx context.Context, sessionID string, k string, ref cache.Immutab
 
 	return outputFS, cleanup, nil
 }
+
+// ResourceReportFS returns a filesystem containing the resource usage report
+// of the build if one was requested.
+func ResourceReportFS(inp *exporter.Source, defaultTime time.Time, opt CreateFSOpts) (fsutil.FS, error) {
+	if opt.ResourceReport == "" {
+		return nil, nil
+	}
+	v, ok := inp.Metadata[exptypes.ExporterResourceReportKey]
+	if !ok {
+		return nil, errors.New("resource report is not available for this build")
+	}
+	var r resourcereport.Report
+	if err := json.Unmarshal(v, &r); err != nil {
+		return nil, errors.Wrap(err, "failed to parse resource report")
+	}
+	dt, err := r.Marshal(opt.ResourceReport)
+	if err != nil {
+		return nil, err
+	}
+
+	name := opt.ResourceReport.Filename()
+	st := &fstypes.Stat{
+		Mode:    0600,
+		Path:    name,
+		ModTime: defaultTime.UnixNano(),
+	}
+	if opt.Epoch != nil && opt.Epoch.Value != nil {
+		st.ModTime = opt.Epoch.Value.UnixNano()
+	}
+	fs := staticfs.NewFS()
+	fs.Add(name, st, dt)
+	return fs, nil
+}
@@ -134,6 +134,9 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 	if e.opt.Variant == VariantDocker && len(src.Refs) > 0 {
 		return nil, nil, nil, errors.Errorf("docker exporter does not currently support exporting manifest lists")
 	}
+	if _, ok := src.Metadata[exptypes.ExporterMatrixKey]; ok {
+		return nil, nil, nil, errors.Errorf("%s exporter does not support build matrix results, use the image or local exporter", e.opt.Variant)
+	}
 
 	src = src.Clone()
 	if src.Metadata == nil {
@@ -0,0 +1,154 @@
+package resourcereport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	resourcestypes "github.com/moby/buildkit/executor/resources/types"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+// ParseFormat parses the value of the resource-report exporter option. An
+// empty format means no report was requested.
+func ParseFormat(v string) (Format, error) {
+	switch v {
+	case "", "false":
+		return "", nil
+	case "true", string(FormatJSON):
+		return FormatJSON, nil
+	case string(FormatCSV):
+		return FormatCSV, nil
+	default:
+		return "", errors.Errorf("invalid resource-report value %q, must be one of true, false, json or csv", v)
+	}
+}
+
+// Filename returns the name of the report file in the exported output.
+func (f Format) Filename() string {
+	return "resource-report." + string(f)
+}
+
+// Report describes the resource usage of the steps of a build.
+type Report struct {
+	Steps []Step `json:"steps"`
+}
+
+// Step describes the resource usage of a single step of a build. Resource
+// usage is only available for steps running a process.
+type Step struct {
+	Digest    digest.Digest `json:"digest"`
+	Name      string        `json:"name"`
+	Started   *time.Time    `json:"started,omitempty"`
+	Completed *time.Time    `json:"completed,omitempty"`
+	Cached    bool          `json:"cached"`
+	// CacheMiss is the reason the step was executed instead of loaded from
+	// cache.
+	CacheMiss       string  `json:"cacheMiss,omitempty"`
+	WallTimeNanos   int64   `json:"wallTimeNanos"`
+	CPUTimeNanos    *uint64 `json:"cpuTimeNanos,omitempty"`
+	MemoryPeakBytes *uint64 `json:"memoryPeakBytes,omitempty"`
+	IOReadBytes     *uint64 `json:"ioReadBytes,omitempty"`
+	IOWriteBytes    *uint64 `json:"ioWriteBytes,omitempty"`
+}
+
+// AddSamples sets the resource usage of the step from the samples collected
+// for its process.
+func (s *Step) AddSamples(samples *resourcestypes.Samples) {
+	if samples == nil {
+		return
+	}
+	for _, sample := range samples.Samples {
+		// CPU and IO stats are cumulative so the last sample is the total
+		if st := sample.CPUStat; st != nil && st.UsageNanos != nil {
+			s.CPUTimeNanos = st.UsageNanos
+		}
+		if st := sample.IOStat; st != nil {
+			if st.ReadBytes != nil {
+				s.IOReadBytes = st.ReadBytes
+			}
+			if st.WriteBytes != nil {
+				s.IOWriteBytes = st.WriteBytes
+			}
+		}
+		if st := sample.MemoryStat; st != nil && st.Peak != nil {
+			if s.MemoryPeakBytes == nil || *st.Peak > *s.MemoryPeakBytes {
+				s.MemoryPeakBytes = st.Peak
+			}
+		}
+	}
+}
+
+// Marshal encodes the report in the given format.
+func (r *Report) Marshal(f Format) ([]byte, error) {
+	switch f {
+	case FormatJSON:
+		dt, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return dt, nil
+	case FormatCSV:
+		return r.marshalCSV()
+	default:
+		return nil, errors.Errorf("unsupported resource report format %q", f)
+	}
+}
+
+var csvHeader = []string{
+	"digest",
+	"name",
+	"cached",
+	"cacheMiss",
+	"wallTimeNanos",
+	"cpuTimeNanos",
+	"memoryPeakBytes",
+	"ioReadBytes",
+	"ioWriteBytes",
+}
+
+func (r *Report) marshalCSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, s := range r.Steps {
+		err := w.Write([]string{
+			s.Digest.String(),
+			s.Name,
+			strconv.FormatBool(s.Cached),
+			s.CacheMiss,
+			strconv.FormatInt(s.WallTimeNanos, 10),
+			formatUint(s.CPUTimeNanos),
+			formatUint(s.MemoryPeakBytes),
+			formatUint(s.IOReadBytes),
+			formatUint(s.IOWriteBytes),
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func formatUint(v *uint64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatUint(*v, 10)
+}
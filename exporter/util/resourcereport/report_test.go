@@ -0,0 +1,78 @@
+package resourcereport
+
+import (
+	"encoding/json"
+	"testing"
+
+	resourcestypes "github.com/moby/buildkit/executor/resources/types"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	for v, expected := range map[string]Format{
+		"":      "",
+		"false": "",
+		"true":  FormatJSON,
+		"json":  FormatJSON,
+		"csv":   FormatCSV,
+	} {
+		f, err := ParseFormat(v)
+		require.NoError(t, err)
+		require.Equal(t, expected, f, v)
+	}
+
+	_, err := ParseFormat("xml")
+	require.ErrorContains(t, err, "invalid resource-report value")
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	u := func(v uint64) *uint64 { return &v }
+
+	step := Step{
+		Digest:        digest.FromString("run"),
+		Name:          "[build 2/3] RUN make",
+		CacheMiss:     "no-match",
+		WallTimeNanos: 3000000000,
+	}
+	step.AddSamples(&resourcestypes.Samples{
+		Samples: []*resourcestypes.Sample{
+			{
+				CPUStat:    &resourcestypes.CPUStat{UsageNanos: u(100)},
+				MemoryStat: &resourcestypes.MemoryStat{Peak: u(2048)},
+				IOStat:     &resourcestypes.IOStat{ReadBytes: u(10), WriteBytes: u(20)},
+			},
+			{
+				CPUStat:    &resourcestypes.CPUStat{UsageNanos: u(300)},
+				MemoryStat: &resourcestypes.MemoryStat{Peak: u(1024)},
+				IOStat:     &resourcestypes.IOStat{ReadBytes: u(30), WriteBytes: u(40)},
+			},
+		},
+	})
+	require.Equal(t, u(300), step.CPUTimeNanos)
+	require.Equal(t, u(2048), step.MemoryPeakBytes)
+	require.Equal(t, u(30), step.IOReadBytes)
+	require.Equal(t, u(40), step.IOWriteBytes)
+
+	r := &Report{Steps: []Step{
+		step,
+		{Digest: digest.FromString("image"), Name: "[build 1/3] FROM alpine", Cached: true},
+	}}
+
+	dt, err := r.Marshal(FormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, `digest,name,cached,cacheMiss,wallTimeNanos,cpuTimeNanos,memoryPeakBytes,ioReadBytes,ioWriteBytes
+`+digest.FromString("run").String()+`,[build 2/3] RUN make,false,no-match,3000000000,300,2048,30,40
+`+digest.FromString("image").String()+`,[build 1/3] FROM alpine,true,,0,,,,
+`, string(dt))
+
+	dt, err = r.Marshal(FormatJSON)
+	require.NoError(t, err)
+	var r2 Report
+	require.NoError(t, json.Unmarshal(dt, &r2))
+	require.Equal(t, *r, r2)
+}
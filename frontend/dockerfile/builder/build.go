@@ -72,6 +72,10 @@ func Build(ctx context.Context, c client.Client) (_ *client.Result, err error) {
 		return nil, capsError
 	}
 
+	if err := bc.LoadLintRules(ctx); err != nil {
+		return nil, err
+	}
+
 	convertOpt := dockerfile2llb.ConvertOpt{
 		Config:       bc.Config,
 		Client:       bc,
@@ -97,6 +101,9 @@ func Build(ctx context.Context, c client.Client) (_ *client.Result, err error) {
 		Lint: func(ctx context.Context) (*lint.LintResults, error) {
 			return dockerfile2llb.DockerfileLint(ctx, src.Data, convertOpt)
 		},
+		LintFix: func(ctx context.Context) (*lint.LintResults, error) {
+			return dockerfile2llb.DockerfileLintFix(ctx, src.Data, convertOpt)
+		},
 		ConvertLLB: func(ctx context.Context) (*convertllb.Result, error) {
 			return dockerfile2llb.DockerfileConvertLLB(ctx, src.Data, convertOpt)
 		},
@@ -132,10 +139,16 @@ func Build(ctx context.Context, c client.Client) (_ *client.Result, err error) {
 
 	scanTargets := bkmaps.SyncMap[string, *dockerfile2llb.Result]{}
 
-	rb, err := bc.Build(ctx, func(ctx context.Context, platform *ocispecs.Platform, idx int) (*dockerui.BuildResult, error) {
+	rb, err := bc.Build(ctx, func(ctx context.Context, platform *ocispecs.Platform, cell *dockerui.MatrixCell, idx int) (*dockerui.BuildResult, error) {
 		opt := convertOpt
 		opt.BuildArgs = maps.Clone(opt.BuildArgs)
 		opt.TargetPlatform = platform
+		if cell != nil {
+			if opt.BuildArgs == nil {
+				opt.BuildArgs = map[string]string{}
+			}
+			maps.Copy(opt.BuildArgs, cell.Args)
+		}
 		if idx != 0 {
 			opt.Warn = nil
 		}
@@ -169,7 +182,7 @@ func Build(ctx context.Context, c client.Client) (_ *client.Result, err error) {
 		} else {
 			p = platforms.DefaultSpec()
 		}
-		id := platforms.FormatAll(platforms.Normalize(p))
+		id := cell.RefID(platforms.FormatAll(platforms.Normalize(p)))
 		scanTargets.Store(id, dfRes)
 		return &dockerui.BuildResult{
 			Reference: ref,
@@ -133,10 +133,34 @@ func DockerfileConvertLLB(ctx context.Context, dt []byte, opt ConvertOpt) (*conv
 }
 
 func DockerfileLint(ctx context.Context, dt []byte, opt ConvertOpt) (*lint.LintResults, error) {
+	return dockerfileLint(ctx, dt, opt, nil)
+}
+
+// DockerfileLintFix lints the Dockerfile like DockerfileLint and attaches
+// text edits to the warnings that can be fixed mechanically.
+func DockerfileLintFix(ctx context.Context, dt []byte, opt ConvertOpt) (*lint.LintResults, error) {
+	var fixer *linter.Fixer
+	if res, err := parser.Parse(bytes.NewReader(dt)); err == nil {
+		fixer = linter.NewFixer(dt, res)
+	}
+	return dockerfileLint(ctx, dt, opt, fixer)
+}
+
+func dockerfileLint(ctx context.Context, dt []byte, opt ConvertOpt, fixer *linter.Fixer) (*lint.LintResults, error) {
 	results := &lint.LintResults{}
 	sourceIndex := results.AddSource(opt.SourceMap)
 	opt.Warn = func(rulename, description, url, fmtmsg string, location []parser.Range) {
 		results.AddWarning(rulename, description, url, fmtmsg, sourceIndex, location)
+		if fixer == nil {
+			return
+		}
+		w := &results.Warnings[len(results.Warnings)-1]
+		for _, e := range fixer.Fix(rulename, location) {
+			w.Fixes = append(w.Fixes, lint.TextEdit{
+				Range:   toPBLocation(sourceIndex, []parser.Range{e.Range}).Ranges[0],
+				NewText: e.NewText,
+			})
+		}
 	}
 	// for lint, no target means all targets
 	if opt.Target == "" {
@@ -201,6 +225,7 @@ func newRuleLinter(dt []byte, opt *ConvertOpt) (*linter.Linter, error) {
 		}
 	}
 	lintConfig.Warn = opt.Warn
+	lintConfig.CustomRules = opt.LintRules
 	return linter.New(lintConfig), nil
 }
 
@@ -279,6 +304,7 @@ func toDispatchState(ctx context.Context, dt []byte, opt ConvertOpt) (*dispatchS
 	}
 	validateStageNames(stages, lint)
 	validateCommandCasing(stages, lint)
+	lint.RunCustomRules(dockerfile.AST)
 
 	platformOpt := buildPlatformOpt(&opt)
 	targetName := opt.Target
@@ -1080,6 +1106,7 @@ func dispatch(d *dispatchState, cmd command, opt dispatchOpt) error {
 			chmod:           c.Chmod,
 			link:            c.Link,
 			keepGitDir:      c.KeepGitDir,
+			lfs:             c.LFS,
 			checksum:        c.Checksum,
 			unpack:          c.Unpack,
 			location:        c.Location(),
@@ -1422,6 +1449,12 @@ func dispatchRun(d *dispatchState, c *instructions.RunCommand, proxy *llb.ProxyE
 	}
 	opt = append(opt, runMounts...)
 
+	runServices, err := dispatchRunServices(c, sources[len(instructions.GetMounts(c)):])
+	if err != nil {
+		return err
+	}
+	opt = append(opt, runServices...)
+
 	securityOpt, err := dispatchRunSecurity(c)
 	if err != nil {
 		return err
@@ -1438,6 +1471,15 @@ func dispatchRun(d *dispatchState, c *instructions.RunCommand, proxy *llb.ProxyE
 		opt = append(opt, networkOpt)
 	}
 
+	if timeout := instructions.GetTimeout(c); timeout > 0 {
+		if dopt.llbCaps != nil {
+			if err := dopt.llbCaps.Supports(pb.CapMetaTimeout); err != nil {
+				return errors.Wrap(err, "RUN --timeout is not supported")
+			}
+		}
+		opt = append(opt, llb.WithTimeout(timeout))
+	}
+
 	if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapExecMetaUlimit) == nil {
 		for _, u := range dopt.ulimit {
 			opt = append(opt, llb.AddUlimit(llb.UlimitName(u.Name), u.Soft, u.Hard))
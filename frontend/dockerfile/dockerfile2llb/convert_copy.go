@@ -35,6 +35,7 @@ type copyConfig struct {
 	chmod           string
 	link            bool
 	keepGitDir      *bool
+	lfs             bool
 	checksum        string
 	parents         bool
 	location        []parser.Range
@@ -170,6 +171,9 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 			if gitRef.FetchByCommit {
 				gitOptions = append(gitOptions, llb.GitFetchByCommit())
 			}
+			if cfg.lfs {
+				gitOptions = append(gitOptions, llb.GitLFS())
+			}
 
 			st := llb.Git(gitRef.Remote, "", gitOptions...)
 			opts := append([]llb.CopyOption{&llb.CopyInfo{
@@ -0,0 +1,16 @@
+//go:build !dfrunservice
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/pkg/errors"
+)
+
+func dispatchRunServices(c *instructions.RunCommand, _ []*dispatchState) ([]llb.RunOption, error) {
+	if len(instructions.GetServices(c)) > 0 {
+		return nil, errors.Errorf("service feature is only supported in Dockerfile frontend labs channel")
+	}
+	return nil, nil
+}
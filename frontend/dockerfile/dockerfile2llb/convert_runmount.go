@@ -38,6 +38,19 @@ func detectRunMount(cmd *command, allDispatchStates *dispatchStates) bool {
 			}
 			sources[i] = stn
 		}
+		// services are started from the sources following the mounts
+		for _, svc := range instructions.GetServices(c) {
+			stn, ok := allDispatchStates.findStateByName(svc.From)
+			if !ok {
+				stn = &dispatchState{
+					stage:        instructions.Stage{BaseName: svc.From},
+					deps:         make(map[*dispatchState]instructions.Command),
+					paths:        make(map[string]struct{}),
+					unregistered: true,
+				}
+			}
+			sources = append(sources, stn)
+		}
 		cmd.sources = sources
 		return true
 	}
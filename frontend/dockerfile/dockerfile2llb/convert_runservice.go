@@ -0,0 +1,25 @@
+//go:build dfrunservice
+
+package dockerfile2llb
+
+import (
+	"slices"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/pkg/errors"
+)
+
+func dispatchRunServices(c *instructions.RunCommand, sources []*dispatchState) ([]llb.RunOption, error) {
+	var out []llb.RunOption
+	for i, svc := range instructions.GetServices(c) {
+		src := sources[i]
+		// the service runs the default command of the stage or image
+		args := slices.Concat(src.image.Config.Entrypoint, src.image.Config.Cmd)
+		if len(args) == 0 {
+			return nil, errors.Errorf("service %s from %s has no ENTRYPOINT or CMD", svc.Name, svc.From)
+		}
+		out = append(out, llb.AddService(svc.Name, src.state, llb.Args(args)))
+	}
+	return out, nil
+}
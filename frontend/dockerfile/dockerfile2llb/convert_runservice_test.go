@@ -0,0 +1,53 @@
+//go:build dfrunservice
+
+package dockerfile2llb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunService(t *testing.T) {
+	t.Parallel()
+	df := `FROM scratch AS svc
+COPY server /server
+ENV PORT=8080
+ENTRYPOINT ["/server"]
+CMD ["--verbose"]
+
+FROM scratch
+COPY client /client
+RUN --service=name=api,from=svc ["/client", "http://api:8080"]
+`
+	res, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.NoError(t, err)
+
+	def, err := res.State.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	var services []*pb.Service
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.UnmarshalVT(dt))
+		if exec := op.GetExec(); exec != nil {
+			services = append(services, exec.Services...)
+		}
+	}
+	require.Len(t, services, 1)
+	require.Equal(t, "api", services[0].Name)
+	require.Equal(t, []string{"/server", "--verbose"}, services[0].Meta.Args)
+	require.Contains(t, services[0].Meta.Env, "PORT=8080")
+
+	df = `FROM scratch AS svc
+COPY server /server
+
+FROM scratch
+RUN --service=name=api,from=svc /client
+`
+	_, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.ErrorContains(t, err, "service api from svc has no ENTRYPOINT or CMD")
+}
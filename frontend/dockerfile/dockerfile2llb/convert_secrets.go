@@ -51,6 +51,12 @@ func dispatchSecret(d *dispatchState, m *instructions.Mount, loc []parser.Range)
 	if m.Env != nil {
 		opts = append(opts, llb.SecretAsEnvName(*m.Env))
 	}
+	if m.NoRedact {
+		opts = append(opts, llb.SecretNoRedact)
+	}
+	if m.CacheDigest {
+		opts = append(opts, llb.SecretCacheDigest)
+	}
 
 	if m.UID != nil || m.GID != nil || m.Mode != nil {
 		var uid, gid, mode int
@@ -222,6 +222,9 @@ func sourceDateEpochAddSource(cmd *instructions.AddCommand, env *llb.EnvList, sh
 		if gitRef.Submodules != nil && !*gitRef.Submodules {
 			gitOptions = append(gitOptions, llb.GitSkipSubmodules())
 		}
+		if cmd.LFS {
+			gitOptions = append(gitOptions, llb.GitLFS())
+		}
 		st := llb.Git(gitRef.Remote, "", gitOptions...)
 		return &st, nil
 	}
@@ -3,6 +3,9 @@ package dockerfile
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -25,6 +28,7 @@ var addGitTests = integration.TestFuncs(
 	testAddGitSHA1,
 	testAddGitSHA256,
 	testAddGitChecksumCache,
+	testAddGitLFS,
 	testGitQueryString,
 )
 
@@ -383,6 +387,108 @@ COPY --from=src /repo/unique.txt /
 	require.Equal(t, string(unique1), string(unique2), "cache should be matched and unique file content should be the same")
 }
 
+// testAddGitLFS tests Dockerfile ADD --lfs from a Git URL with files tracked by
+// Git LFS, served by a local LFS server.
+func testAddGitLFS(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows", "Git source handler submodule update not supported on Windows")
+	f := getFrontend(t, sb)
+
+	content := []byte("lfs object contents\n")
+	sum := sha256.Sum256(content)
+	oid := hex.EncodeToString(sum[:])
+	pointer := fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content))
+
+	gitDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(gitDir, "model.bin"), []byte(pointer), 0644)
+	require.NoError(t, err)
+	err = runShell(gitDir,
+		"git init",
+		"git checkout -B master",
+		"git config --local user.email test",
+		"git config --local user.name test",
+		"echo 'model.bin filter=lfs diff=lfs merge=lfs -text' >.gitattributes",
+		"git add .gitattributes model.bin",
+		"git commit -m initial",
+		"git update-server-info",
+	)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(filepath.Clean(gitDir))))
+	var serverURL string
+	mux.HandleFunc("POST /.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Objects []struct {
+				Oid  string `json:"oid"`
+				Size int64  `json:"size"`
+			} `json:"objects"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		objects := make([]map[string]any, 0, len(req.Objects))
+		for _, o := range req.Objects {
+			objects = append(objects, map[string]any{
+				"oid":  o.Oid,
+				"size": o.Size,
+				"actions": map[string]any{
+					"download": map[string]any{"href": serverURL + "/lfs/" + o.Oid},
+				},
+			})
+		}
+		w.Header().Set("Content-Type", "application/vnd.git-lfs+json")
+		json.NewEncoder(w).Encode(map[string]any{"objects": objects})
+	})
+	mux.HandleFunc("GET /lfs/{oid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("oid") != oid {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	serverURL = server.URL
+
+	c, err := client.New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	for _, lfs := range []bool{false, true} {
+		dockerfile := fmt.Sprintf(`
+FROM scratch
+ADD --lfs=%t %s/.git#master /
+`, lfs, serverURL)
+		dir := integration.Tmpdir(t,
+			fstest.CreateFile("Dockerfile", []byte(dockerfile), 0600),
+		)
+
+		destDir := t.TempDir()
+		_, err = f.Solve(sb.Context(), c, client.SolveOpt{
+			Exports: []client.ExportEntry{
+				{
+					Type:      client.ExporterLocal,
+					OutputDir: destDir,
+				},
+			},
+			LocalMounts: map[string]fsutil.FS{
+				dockerui.DefaultLocalNameDockerfile: dir,
+				dockerui.DefaultLocalNameContext:    dir,
+			},
+		}, nil)
+		require.NoError(t, err)
+
+		dt, err := os.ReadFile(filepath.Join(destDir, "model.bin"))
+		require.NoError(t, err)
+		if lfs {
+			require.Equal(t, string(content), string(dt))
+		} else {
+			require.Equal(t, pointer, string(dt))
+		}
+	}
+}
+
 // testGitQueryString tests Git URL query string parameters for Dockerfile ADD and
 // build context, including ref, branch, tag, commit, subdir, keep-git-dir, and
 // submodules options.
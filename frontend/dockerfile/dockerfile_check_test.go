@@ -53,6 +53,8 @@ var lintTests = integration.TestFuncs(
 	testDefinitionDescription,
 	testExposeProtoCasing,
 	testExposeInvalidFormat,
+	testCustomRules,
+	testLintFix,
 )
 
 func testDefinitionDescription(t *testing.T, sb integration.Sandbox) {
@@ -1617,6 +1619,184 @@ EXPOSE 127.0.0.1:80:80 [::1]:8080:8080 5000:5000 8000
 	})
 }
 
+func testCustomRules(t *testing.T, sb integration.Sandbox) {
+	rules := []byte(`{"rules": [
+	{
+		"name": "CopyChown",
+		"description": "COPY should set --chown",
+		"message": "Missing --chown for {{index .Args 0}}",
+		"instruction": {
+			"name": "copy",
+			"flags": {"chown": {"present": false}}
+		}
+	},
+	{
+		"name": "StageUser",
+		"description": "Every stage should set USER",
+		"stage": {"missing": ["user"]}
+	}
+]}`)
+	dockerfile := []byte(`
+FROM scratch AS base
+COPY --chown=1000:1000 Dockerfile /foo
+USER 1000
+
+FROM base
+COPY Dockerfile /bar
+`)
+	checkLinterWarnings(t, sb, &lintTestParams{
+		Dockerfile: dockerfile,
+		Warnings: []expectedLintWarning{
+			{
+				RuleName:    "StageUser",
+				Description: "Every stage should set USER",
+				Detail:      "Every stage should set USER",
+				Line:        6,
+				Level:       1,
+			},
+			{
+				RuleName:    "CopyChown",
+				Description: "COPY should set --chown",
+				Detail:      "Missing --chown for Dockerfile",
+				Line:        7,
+				Level:       1,
+			},
+		},
+		FrontendAttrs: map[string]string{
+			"build-arg:BUILDKIT_DOCKERFILE_CHECK_RULES": string(rules),
+		},
+	})
+
+	// rules from the context file are layered below inline rules
+	dockerfile = []byte(`
+FROM scratch
+COPY Dockerfile /foo
+`)
+	inline := []byte(`{"rules": [
+	{
+		"name": "CopyChown",
+		"description": "Copied files must have an owner",
+		"instruction": {
+			"name": "copy",
+			"flags": {"chown": {"present": false}}
+		}
+	}
+]}`)
+	checkLinterWarnings(t, sb, &lintTestParams{
+		TmpDir: integration.Tmpdir(t,
+			fstest.CreateFile("Dockerfile", dockerfile, 0o600),
+			fstest.CreateFile("lint-rules.json", rules, 0o600),
+		),
+		Warnings: []expectedLintWarning{
+			{
+				RuleName:    "CopyChown",
+				Description: "Copied files must have an owner",
+				Detail:      "Copied files must have an owner",
+				Line:        3,
+				Level:       1,
+			},
+		},
+		BuildErr:         "lint violation found for rules: CopyChown",
+		BuildErrLocation: 3,
+		FrontendAttrs: map[string]string{
+			"build-arg:BUILDKIT_DOCKERFILE_CHECK":            "skip=StageUser;error=true",
+			"build-arg:BUILDKIT_DOCKERFILE_CHECK_RULES_FILE": "lint-rules.json",
+			"build-arg:BUILDKIT_DOCKERFILE_CHECK_RULES":      string(inline),
+		},
+	})
+}
+
+func testLintFix(t *testing.T, sb integration.Sandbox) {
+	dockerfile := []byte(`FROM scratch as base
+MAINTAINER John Doe <john@example.com>
+ENV foo bar baz
+label a b
+WORKDIR app
+CMD echo hello
+ENTRYPOINT echo $HOME
+
+FROM base AS second
+WORKDIR app2
+
+FROM busybox:latest
+WORKDIR app3
+ENTRYPOINT echo hello
+`)
+	expected := []byte(`FROM scratch AS base
+LABEL org.opencontainers.image.authors="John Doe <john@example.com>"
+ENV foo="bar baz"
+LABEL a=b
+WORKDIR /app
+CMD ["echo", "hello"]
+ENTRYPOINT echo $HOME
+
+FROM base AS second
+WORKDIR app2
+
+FROM busybox:latest
+WORKDIR app3
+ENTRYPOINT echo hello
+`)
+	dir := integration.Tmpdir(t,
+		fstest.CreateFile("Dockerfile", dockerfile, 0o600),
+	)
+
+	c, err := client.New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	called := false
+	frontend := func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		res, err := c.Solve(ctx, gateway.SolveRequest{
+			FrontendOpt: map[string]string{
+				"frontend.caps": "moby.buildkit.frontend.subrequests",
+				"requestid":     "frontend.lint.fix",
+			},
+			Frontend: "dockerfile.v0",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		lintResults, err := unmarshalLintResults(res)
+		require.NoError(t, err)
+		var fixable int
+		for _, w := range lintResults.Warnings {
+			if len(w.Fixes) > 0 {
+				fixable++
+			} else {
+				// relative to the working directory of busybox and
+				// ENTRYPOINT that would append CMD in exec form
+				require.Contains(t, []string{"JSONArgsRecommended", "WorkdirRelativePath"}, w.RuleName)
+			}
+		}
+		require.Equal(t, 7, fixable)
+
+		dt, _, err := lintResults.ApplyFixes(0)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(dt))
+
+		ref, err := res.SingleRef()
+		require.NoError(t, err)
+		dt, err = ref.ReadFile(ctx, gateway.ReadRequest{
+			Filename: "Dockerfile",
+		})
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(dt))
+		called = true
+		return nil, nil
+	}
+
+	_, err = c.Build(sb.Context(), client.SolveOpt{
+		LocalMounts: map[string]fsutil.FS{
+			dockerui.DefaultLocalNameDockerfile: dir,
+			dockerui.DefaultLocalNameContext:    dir,
+		},
+	}, "", frontend, nil)
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
 func checkUnmarshal(t *testing.T, sb integration.Sandbox, lintTest *lintTestParams) {
 	destDir, err := os.MkdirTemp("", "buildkit")
 	require.NoError(t, err)
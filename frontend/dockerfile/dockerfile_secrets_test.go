@@ -1,6 +1,9 @@
 package dockerfile
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -8,6 +11,7 @@ import (
 	"github.com/containerd/continuity/fs/fstest"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/frontend/dockerui"
+	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/secrets/secretsprovider"
 	"github.com/moby/buildkit/util/testutil/integration"
@@ -21,6 +25,8 @@ var secretsTests = integration.TestFuncs(
 	testSecretRequiredWithoutValue,
 	testSecretAsEnviron,
 	testSecretAsEnvironWithFileMount,
+	testSecretRedactedInLogs,
+	testSecretCacheDigest,
 )
 
 func init() {
@@ -199,3 +205,115 @@ RUN --mount=type=secret,id=mysecret,target=/run/secrets/secret,env=SECRET_ENV [
 	}, nil)
 	require.NoError(t, err)
 }
+
+// testSecretRedactedInLogs verifies that secret values and their base64
+// encoding are replaced in the logs of a RUN step unless redact=false is set.
+func testSecretRedactedInLogs(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+	f := getFrontend(t, sb)
+
+	dockerfile := fmt.Appendf(nil, `
+FROM busybox
+RUN --mount=type=secret,id=mysecret,env=SECRET_ENV echo %[1]s && cat /run/secrets/mysecret && echo "$SECRET_ENV" | base64
+RUN --mount=type=secret,id=plain,redact=false echo %[1]s && cat /run/secrets/plain
+`, identity.NewID())
+
+	dir := integration.Tmpdir(
+		t,
+		fstest.CreateFile("Dockerfile", dockerfile, 0600),
+	)
+
+	c, err := client.New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	done := make(chan struct{})
+	status := make(chan *client.SolveStatus)
+	var logs []byte
+
+	go func() {
+		for st := range status {
+			for _, l := range st.Logs {
+				logs = append(logs, l.Data...)
+			}
+		}
+		close(done)
+	}()
+
+	_, err = f.Solve(sb.Context(), c, client.SolveOpt{
+		LocalMounts: map[string]fsutil.FS{
+			dockerui.DefaultLocalNameDockerfile: dir,
+			dockerui.DefaultLocalNameContext:    dir,
+		},
+		Session: []session.Attachable{secretsprovider.FromMap(map[string][]byte{
+			"mysecret": []byte("s3cr3t-value"),
+			"plain":    []byte("visible-value"),
+		})},
+	}, status)
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "timed out waiting for status")
+	}
+
+	require.NotContains(t, string(logs), "s3cr3t-value")
+	require.NotContains(t, string(logs), "czNjcjN0LXZhbHVlCg==")
+	require.Contains(t, string(logs), "***")
+	require.Contains(t, string(logs), "visible-value")
+}
+
+// testSecretCacheDigest verifies that changing the value of a secret mounted
+// with cache=digest invalidates the cache of the RUN step.
+func testSecretCacheDigest(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+	f := getFrontend(t, sb)
+
+	dockerfile := []byte(`
+FROM busybox AS base
+RUN --mount=type=secret,id=mysecret,cache=digest head -c 16 /dev/urandom | base64 > /out
+FROM scratch
+COPY --from=base /out /
+`)
+
+	dir := integration.Tmpdir(
+		t,
+		fstest.CreateFile("Dockerfile", dockerfile, 0600),
+	)
+
+	c, err := client.New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	build := func(value string) string {
+		destDir := t.TempDir()
+		_, err := f.Solve(sb.Context(), c, client.SolveOpt{
+			Exports: []client.ExportEntry{
+				{
+					Type:      client.ExporterLocal,
+					OutputDir: destDir,
+				},
+			},
+			LocalMounts: map[string]fsutil.FS{
+				dockerui.DefaultLocalNameDockerfile: dir,
+				dockerui.DefaultLocalNameContext:    dir,
+			},
+			Session: []session.Attachable{secretsprovider.FromMap(map[string][]byte{
+				"mysecret": []byte(value),
+			})},
+		}, nil)
+		require.NoError(t, err)
+
+		dt, err := os.ReadFile(filepath.Join(destDir, "out"))
+		require.NoError(t, err)
+		return string(dt)
+	}
+
+	out1 := build("value1")
+	out2 := build("value1")
+	require.Equal(t, out1, out2)
+
+	out3 := build("value2")
+	require.NotEqual(t, out1, out3)
+}
@@ -256,6 +256,7 @@ type AddCommand struct {
 	Link            bool
 	ExcludePatterns []string
 	KeepGitDir      *bool // whether to keep .git dir, only meaningful for git sources
+	LFS             bool  // whether to fetch LFS objects, only meaningful for git sources
 	Checksum        string
 	Unpack          *bool
 }
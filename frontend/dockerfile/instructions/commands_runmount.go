@@ -128,6 +128,12 @@ type Mount struct {
 	Mode *uint64
 	UID  *uint64
 	GID  *uint64
+	// NoRedact disables replacing the value of a secret in the logs of the
+	// command.
+	NoRedact bool
+	// CacheDigest includes a digest of the value of a secret in the cache key
+	// of the command.
+	CacheDigest bool
 }
 
 func parseMount(val string, expander SingleWordExpander) (*Mount, error) {
@@ -257,9 +263,30 @@ func parseMount(val string, expander SingleWordExpander) (*Mount, error) {
 			m.GID = &gid
 		case "env":
 			m.Env = &value
+		case "redact":
+			if m.Type != MountTypeSecret {
+				return nil, errors.Errorf("unexpected key '%s' for mount type '%s'", key, m.Type)
+			}
+			redact, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.Errorf("invalid value for %s: %s", key, value)
+			}
+			m.NoRedact = !redact
+		case "cache":
+			if m.Type != MountTypeSecret {
+				return nil, errors.Errorf("unexpected key '%s' for mount type '%s'", key, m.Type)
+			}
+			switch strings.ToLower(value) {
+			case "digest":
+				m.CacheDigest = true
+			case "none":
+				m.CacheDigest = false
+			default:
+				return nil, errors.Errorf("invalid value for %s: %s", key, value)
+			}
 		default:
 			allKeys := []string{
-				"type", "from", "source", "target", "readonly", "id", "sharing", "required", "size", "mode", "uid", "gid", "src", "dst", "destination", "ro", "rw", "readwrite", "env",
+				"type", "from", "source", "target", "readonly", "id", "sharing", "required", "size", "mode", "uid", "gid", "src", "dst", "destination", "ro", "rw", "readwrite", "env", "redact", "cache",
 			}
 			return nil, suggest.WrapError(errors.Errorf("unexpected key '%s' in '%s'", key, field), key, allKeys, true)
 		}
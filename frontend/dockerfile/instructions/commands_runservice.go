@@ -0,0 +1,109 @@
+package instructions
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/util/suggest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/go-csvvalue"
+)
+
+var servicesKey = "dockerfile/run/services"
+
+func init() {
+	parseRunPreHooks = append(parseRunPreHooks, runServicePreHook)
+	parseRunPostHooks = append(parseRunPostHooks, runServicePostHook)
+}
+
+func runServicePreHook(cmd *RunCommand, req parseRequest) error {
+	st := &serviceState{}
+	st.flag = req.flags.AddStrings("service")
+	cmd.setExternalValue(servicesKey, st)
+	return nil
+}
+
+func runServicePostHook(cmd *RunCommand, req parseRequest) error {
+	return setServiceState(cmd)
+}
+
+func setServiceState(cmd *RunCommand) error {
+	st := getServiceState(cmd)
+	if st == nil {
+		return errors.Errorf("no service state")
+	}
+	services := make([]*Service, len(st.flag.StringValues))
+	names := map[string]struct{}{}
+	for i, str := range st.flag.StringValues {
+		s, err := ParseService(str)
+		if err != nil {
+			return err
+		}
+		if _, ok := names[s.Name]; ok {
+			return errors.Errorf("duplicate service name %q", s.Name)
+		}
+		names[s.Name] = struct{}{}
+		services[i] = s
+	}
+	st.services = services
+	return nil
+}
+
+func getServiceState(cmd *RunCommand) *serviceState {
+	v := cmd.getExternalValue(servicesKey)
+	if v == nil {
+		return nil
+	}
+	return v.(*serviceState)
+}
+
+func GetServices(cmd *RunCommand) []*Service {
+	return getServiceState(cmd).services
+}
+
+type serviceState struct {
+	flag     *Flag
+	services []*Service
+}
+
+// Service is a container started from a stage or an image for the duration
+// of a RUN instruction and reachable from it by name.
+type Service struct {
+	Name string
+	From string
+}
+
+func ParseService(val string) (*Service, error) {
+	fields, err := csvvalue.Fields(val, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse csv services")
+	}
+
+	s := &Service{}
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		key = strings.ToLower(key)
+		if !ok {
+			return nil, errors.Errorf("invalid field '%s' must be a key=value pair", field)
+		}
+		switch key {
+		case "name":
+			s.Name = value
+		case "from":
+			s.From = value
+		default:
+			allKeys := []string{"name", "from"}
+			return nil, suggest.WrapError(errors.Errorf("unexpected key '%s' in '%s'", key, field), key, allKeys, true)
+		}
+	}
+
+	if s.Name == "" {
+		return nil, errors.Errorf("service name is required in '%s'", val)
+	}
+	if s.From == "" {
+		return nil, errors.Errorf("service from is required in '%s'", val)
+	}
+	if strings.Contains(s.From, "$") {
+		return nil, errors.Errorf("'from' doesn't support variable expansion, define alias stage instead")
+	}
+	return s, nil
+}
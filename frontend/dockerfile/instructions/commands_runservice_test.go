@@ -0,0 +1,55 @@
+package instructions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseService(t *testing.T) {
+	cases := []struct {
+		input       string
+		expected    *Service
+		expectedErr string
+	}{
+		{
+			input:    "name=db,from=postgres:16",
+			expected: &Service{Name: "db", From: "postgres:16"},
+		},
+		{
+			input:    "from=dbstage,Name=db",
+			expected: &Service{Name: "db", From: "dbstage"},
+		},
+		{
+			input:       "from=postgres",
+			expectedErr: "service name is required",
+		},
+		{
+			input:       "name=db",
+			expectedErr: "service from is required",
+		},
+		{
+			input:       "name=db,from=$IMAGE",
+			expectedErr: "doesn't support variable expansion",
+		},
+		{
+			input:       "name=db,form=postgres",
+			expectedErr: "unexpected key 'form'",
+		},
+		{
+			input:       "db",
+			expectedErr: "must be a key=value pair",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseService(tt.input)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package instructions
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var timeoutKey = "dockerfile/run/timeout"
+
+func init() {
+	parseRunPreHooks = append(parseRunPreHooks, runTimeoutPreHook)
+	parseRunPostHooks = append(parseRunPostHooks, runTimeoutPostHook)
+}
+
+func runTimeoutPreHook(cmd *RunCommand, req parseRequest) error {
+	st := &timeoutState{}
+	st.flag = req.flags.AddString("timeout", "")
+	cmd.setExternalValue(timeoutKey, st)
+	return nil
+}
+
+func runTimeoutPostHook(cmd *RunCommand, req parseRequest) error {
+	st := cmd.getExternalValue(timeoutKey).(*timeoutState)
+	if st == nil {
+		return errors.Errorf("no timeout state")
+	}
+
+	if st.flag.Value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(st.flag.Value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timeout %q", st.flag.Value)
+	}
+	if d <= 0 {
+		return errors.Errorf("invalid timeout %q, must be positive", st.flag.Value)
+	}
+	st.timeout = d
+
+	return nil
+}
+
+// GetTimeout returns the maximum duration the RUN command is allowed to run
+// for. Zero means no timeout.
+func GetTimeout(cmd *RunCommand) time.Duration {
+	return cmd.getExternalValue(timeoutKey).(*timeoutState).timeout
+}
+
+type timeoutState struct {
+	flag    *Flag
+	timeout time.Duration
+}
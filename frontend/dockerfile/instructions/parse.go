@@ -322,6 +322,7 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 	flChmod := req.flags.AddString("chmod", "")
 	flLink := req.flags.AddBool("link", false)
 	flKeepGitDir := req.flags.AddBool("keep-git-dir", false)
+	flLFS := req.flags.AddBool("lfs", false)
 	flChecksum := req.flags.AddString("checksum", "")
 	flUnpack := req.flags.AddBool("unpack", false)
 	flExcludes := req.flags.AddStrings("exclude")
@@ -353,6 +354,7 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 		Chmod:           flChmod.Value,
 		Link:            flLink.Value == "true",
 		KeepGitDir:      keepGit,
+		LFS:             flLFS.Value == "true",
 		Checksum:        flChecksum.Value,
 		ExcludePatterns: flExcludes.StringValues,
 		Unpack:          unpack,
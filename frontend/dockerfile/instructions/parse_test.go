@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/moby/buildkit/frontend/dockerfile/command"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
@@ -272,6 +273,32 @@ func TestRunCmdFlagsUsed(t *testing.T) {
 	require.Equal(t, []string{"mount"}, c.(*RunCommand).FlagsUsed)
 }
 
+func TestRunTimeout(t *testing.T) {
+	parse := func(dockerfile string) (*RunCommand, error) {
+		ast, err := parser.Parse(strings.NewReader(dockerfile))
+		require.NoError(t, err)
+		c, err := ParseInstruction(ast.AST.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		return c.(*RunCommand), nil
+	}
+
+	c, err := parse("RUN --timeout=10m make test")
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, GetTimeout(c))
+
+	c, err = parse("RUN make test")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), GetTimeout(c))
+
+	_, err = parse("RUN --timeout=10 make test")
+	require.ErrorContains(t, err, "invalid timeout")
+
+	_, err = parse("RUN --timeout=-1s make test")
+	require.ErrorContains(t, err, "must be positive")
+}
+
 func BenchmarkParseBuildStageName(b *testing.B) {
 	b.ReportAllocs()
 	stageNames := []string{"STAGE_NAME", "StageName", "St4g3N4m3"}
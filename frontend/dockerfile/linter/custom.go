@@ -0,0 +1,372 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
+)
+
+// CustomRules is the declarative definition format for lint rules that are
+// evaluated alongside the built-in rules.
+//
+//	{
+//	  "rules": [
+//	    {
+//	      "name": "AddRemoteChecksum",
+//	      "description": "ADD with a remote URL must set --checksum",
+//	      "instruction": {
+//	        "name": "add",
+//	        "args": {"regex": "^https?://"},
+//	        "flags": {"checksum": {"present": false}}
+//	      }
+//	    },
+//	    {
+//	      "name": "StageUser",
+//	      "description": "Every stage must set USER",
+//	      "stage": {"missing": ["user"]}
+//	    }
+//	  ]
+//	}
+type CustomRules struct {
+	Rules []*CustomRule `json:"rules"`
+}
+
+// CustomRule reports a warning for every instruction or stage matching all
+// conditions of its matcher. Exactly one of Instruction or Stage must be set.
+type CustomRule struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	// Message is an optional text/template for the warning message. The
+	// template is executed with CustomRuleMatch.
+	Message     string            `json:"message,omitempty"`
+	Instruction *InstructionMatch `json:"instruction,omitempty"`
+	Stage       *StageMatch       `json:"stage,omitempty"`
+
+	tmpl *template.Template
+}
+
+// InstructionMatch matches a single Dockerfile instruction.
+type InstructionMatch struct {
+	// Name is the instruction keyword, matched case-insensitively.
+	Name string `json:"name"`
+	// Args matches if any argument of the instruction matches.
+	Args *ValueMatch `json:"args,omitempty"`
+	// Flags matches instruction flags by name, without the leading dashes.
+	Flags map[string]*ValueMatch `json:"flags,omitempty"`
+}
+
+// StageMatch matches a build stage.
+type StageMatch struct {
+	// Name matches the stage name. Unnamed stages have an empty name.
+	Name *ValueMatch `json:"name,omitempty"`
+	// BaseImage matches the unexpanded base image of the stage. Stages
+	// based on another stage never match.
+	BaseImage *ValueMatch `json:"baseImage,omitempty"`
+	// Missing matches if any of the listed instructions is not used in the
+	// stage.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// ValueMatch matches a string value. All set conditions must hold.
+type ValueMatch struct {
+	// Present checks whether the value is set at all. It is only meaningful
+	// for flags.
+	Present  *bool  `json:"present,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+	NotRegex string `json:"notRegex,omitempty"`
+
+	re    *regexp.Regexp
+	notRe *regexp.Regexp
+}
+
+// CustomRuleMatch is passed to the Message template of a custom rule.
+type CustomRuleMatch struct {
+	Instruction string
+	Args        []string
+	Flags       []string
+	Stage       string
+	BaseImage   string
+}
+
+// ParseCustomRules parses and validates rule definitions.
+func ParseCustomRules(dt []byte) ([]*CustomRule, error) {
+	var cr CustomRules
+	dec := json.NewDecoder(bytes.NewReader(dt))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cr); err != nil {
+		return nil, errors.Wrap(err, "failed to parse lint rules")
+	}
+	names := map[string]struct{}{}
+	for _, r := range cr.Rules {
+		if r == nil {
+			return nil, errors.New("invalid empty lint rule")
+		}
+		if err := r.init(); err != nil {
+			return nil, errors.Wrapf(err, "invalid lint rule %q", r.Name)
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, errors.Errorf("duplicate lint rule %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+	}
+	return cr.Rules, nil
+}
+
+// MergeCustomRules layers rule sets on top of each other. A rule in a later
+// set replaces the rule with the same name from an earlier set.
+func MergeCustomRules(sets ...[]*CustomRule) []*CustomRule {
+	var out []*CustomRule
+	for _, set := range sets {
+		for _, r := range set {
+			if i := slices.IndexFunc(out, func(o *CustomRule) bool { return o.Name == r.Name }); i >= 0 {
+				out[i] = r
+				continue
+			}
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (rule *CustomRule) init() error {
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if (rule.Instruction == nil) == (rule.Stage == nil) {
+		return errors.New("exactly one of instruction or stage must be set")
+	}
+	if rule.Message != "" {
+		tmpl, err := template.New(rule.Name).Option("missingkey=error").Parse(rule.Message)
+		if err != nil {
+			return errors.Wrap(err, "invalid message template")
+		}
+		rule.tmpl = tmpl
+	}
+	if m := rule.Instruction; m != nil {
+		if m.Name == "" {
+			return errors.New("instruction name is required")
+		}
+		m.Name = strings.ToLower(m.Name)
+		if err := m.Args.init(); err != nil {
+			return errors.Wrap(err, "args")
+		}
+		for k, v := range m.Flags {
+			if v == nil {
+				return errors.Errorf("invalid empty match for flag %q", k)
+			}
+			if err := v.init(); err != nil {
+				return errors.Wrapf(err, "flag %q", k)
+			}
+		}
+	}
+	if m := rule.Stage; m != nil {
+		if m.Name == nil && m.BaseImage == nil && len(m.Missing) == 0 {
+			return errors.New("stage matcher requires at least one condition")
+		}
+		if err := m.Name.init(); err != nil {
+			return errors.Wrap(err, "name")
+		}
+		if err := m.BaseImage.init(); err != nil {
+			return errors.Wrap(err, "baseImage")
+		}
+		for i, v := range m.Missing {
+			m.Missing[i] = strings.ToLower(v)
+		}
+	}
+	return nil
+}
+
+func (m *ValueMatch) init() error {
+	if m == nil {
+		return nil
+	}
+	var err error
+	if m.Regex != "" {
+		if m.re, err = regexp.Compile(m.Regex); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if m.NotRegex != "" {
+		if m.notRe, err = regexp.Compile(m.NotRegex); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (m *ValueMatch) match(v string, present bool) bool {
+	if m == nil {
+		return true
+	}
+	if m.Present != nil && *m.Present != present {
+		return false
+	}
+	if !present {
+		// regular expressions only apply to values that are set
+		return m.re == nil && m.notRe == nil
+	}
+	if m.re != nil && !m.re.MatchString(v) {
+		return false
+	}
+	if m.notRe != nil && m.notRe.MatchString(v) {
+		return false
+	}
+	return true
+}
+
+func (rule *CustomRule) RuleName() string {
+	return rule.Name
+}
+
+func (rule *CustomRule) Run(warn LintWarnFunc, location []parser.Range, txt ...string) {
+	if len(txt) == 0 {
+		txt = []string{rule.Description}
+	}
+	warn(rule.Name, rule.Description, rule.URL, strings.Join(txt, " "), location)
+}
+
+func (rule *CustomRule) IsDeprecated() bool {
+	return false
+}
+
+func (rule *CustomRule) IsExperimental() bool {
+	return false
+}
+
+func (rule *CustomRule) message(m CustomRuleMatch) string {
+	if rule.tmpl == nil {
+		if rule.Description != "" {
+			return rule.Description
+		}
+		return rule.Name
+	}
+	var buf bytes.Buffer
+	if err := rule.tmpl.Execute(&buf, m); err != nil {
+		return rule.Description
+	}
+	return buf.String()
+}
+
+type customStage struct {
+	node     *parser.Node
+	name     string
+	base     string
+	fromStg  bool
+	commands []*parser.Node
+}
+
+// RunCustomRules evaluates the custom rules of the linter against the
+// parsed Dockerfile.
+func (lc *Linter) RunCustomRules(ast *parser.Node) {
+	if lc == nil || len(lc.CustomRules) == 0 || ast == nil {
+		return
+	}
+
+	var stages []*customStage
+	stageNames := map[string]struct{}{}
+	for _, n := range ast.Children {
+		if strings.EqualFold(n.Value, "from") {
+			st := &customStage{node: n}
+			args := nodeArgs(n)
+			if len(args) > 0 {
+				st.base = args[0]
+				_, st.fromStg = stageNames[strings.ToLower(st.base)]
+			}
+			if len(args) == 3 && strings.EqualFold(args[1], "as") {
+				st.name = strings.ToLower(args[2])
+				stageNames[st.name] = struct{}{}
+			}
+			stages = append(stages, st)
+			continue
+		}
+		if len(stages) > 0 {
+			stages[len(stages)-1].commands = append(stages[len(stages)-1].commands, n)
+		}
+	}
+
+	for _, rule := range lc.CustomRules {
+		if m := rule.Instruction; m != nil {
+			for _, n := range ast.Children {
+				lc.runInstructionRule(rule, m, n)
+			}
+		}
+		if m := rule.Stage; m != nil {
+			for _, st := range stages {
+				if m.matches(st) {
+					msg := rule.message(CustomRuleMatch{
+						Instruction: "from",
+						Args:        nodeArgs(st.node),
+						Flags:       st.node.Flags,
+						Stage:       st.name,
+						BaseImage:   st.base,
+					})
+					lc.Run(rule, st.node.Location(), msg)
+				}
+			}
+		}
+	}
+}
+
+func (lc *Linter) runInstructionRule(rule *CustomRule, m *InstructionMatch, n *parser.Node) {
+	if !strings.EqualFold(n.Value, m.Name) {
+		return
+	}
+	args := nodeArgs(n)
+	if m.Args != nil && !slices.ContainsFunc(args, func(a string) bool { return m.Args.match(a, true) }) {
+		return
+	}
+	flags := nodeFlags(n)
+	for k, fm := range m.Flags {
+		v, ok := flags[k]
+		if !fm.match(v, ok) {
+			return
+		}
+	}
+	msg := rule.message(CustomRuleMatch{
+		Instruction: strings.ToLower(n.Value),
+		Args:        args,
+		Flags:       n.Flags,
+	})
+	lc.Run(rule, n.Location(), msg)
+}
+
+func (m *StageMatch) matches(st *customStage) bool {
+	if !m.Name.match(st.name, st.name != "") {
+		return false
+	}
+	if m.BaseImage != nil && (st.fromStg || !m.BaseImage.match(st.base, st.base != "")) {
+		return false
+	}
+	if len(m.Missing) > 0 {
+		return slices.ContainsFunc(m.Missing, func(cmd string) bool {
+			return !slices.ContainsFunc(st.commands, func(n *parser.Node) bool {
+				return strings.EqualFold(n.Value, cmd)
+			})
+		})
+	}
+	return true
+}
+
+func nodeArgs(n *parser.Node) []string {
+	var args []string
+	for next := n.Next; next != nil; next = next.Next {
+		args = append(args, next.Value)
+	}
+	return args
+}
+
+func nodeFlags(n *parser.Node) map[string]string {
+	flags := make(map[string]string, len(n.Flags))
+	for _, f := range n.Flags {
+		k, v, _ := strings.Cut(strings.TrimLeft(f, "-"), "=")
+		flags[strings.ToLower(k)] = v
+	}
+	return flags
+}
@@ -0,0 +1,309 @@
+package linter
+
+import (
+	"encoding/json"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// TextEdit replaces the text in Range with NewText. Lines are 1-based and
+// characters are 0-based byte offsets within the line. An empty range
+// inserts NewText at the start position.
+type TextEdit struct {
+	Range   parser.Range
+	NewText string
+}
+
+// Fixer computes text edits for warnings of rules that can be fixed
+// mechanically.
+type Fixer struct {
+	lines       []string
+	children    []*parser.Node
+	nodes       map[int]*parser.Node
+	escapeToken rune
+	lower       bool
+}
+
+// NewFixer returns a Fixer for the Dockerfile source dt and its parsed AST.
+func NewFixer(dt []byte, res *parser.Result) *Fixer {
+	f := &Fixer{
+		children:    res.AST.Children,
+		nodes:       map[int]*parser.Node{},
+		escapeToken: res.EscapeToken,
+	}
+	for l := range strings.SplitSeq(string(dt), "\n") {
+		f.lines = append(f.lines, strings.TrimSuffix(l, "\r"))
+	}
+
+	// majority casing is counted the same way as for the
+	// ConsistentInstructionCasing rule, from the first stage onwards
+	var lowerCount, upperCount int
+	var inStage bool
+	for _, n := range res.AST.Children {
+		f.nodes[n.StartLine] = n
+		if strings.EqualFold(n.Value, "from") {
+			inStage = true
+		}
+		if !inStage {
+			continue
+		}
+		switch n.Value {
+		case strings.ToLower(n.Value):
+			lowerCount++
+		case strings.ToUpper(n.Value):
+			upperCount++
+		}
+	}
+	f.lower = lowerCount > upperCount
+	return f
+}
+
+// Fix returns the edits resolving a warning of rule reported at location.
+// It returns nil if the warning can't be fixed without risking a change in
+// behavior.
+func (f *Fixer) Fix(rule string, location []parser.Range) []TextEdit {
+	if len(location) == 0 {
+		return nil
+	}
+	n, ok := f.nodes[location[0].Start.Line]
+	if !ok || n.StartLine < 1 || n.StartLine > len(f.lines) {
+		return nil
+	}
+	var e *TextEdit
+	switch rule {
+	case RuleConsistentInstructionCasing.Name:
+		e = f.fixCasing(n)
+	case RuleFromAsCasing.Name:
+		e = f.fixFromAs(n)
+	case RuleLegacyKeyValueFormat.Name:
+		e = f.fixLegacyKeyValue(n)
+	case RuleJSONArgsRecommended.Name:
+		e = f.fixJSONArgs(n)
+	case RuleMaintainerDeprecated.Name:
+		e = f.fixMaintainer(n)
+	case RuleWorkdirRelativePath.Name:
+		e = f.fixWorkdir(n)
+	}
+	if e == nil {
+		return nil
+	}
+	return []TextEdit{*e}
+}
+
+type span struct {
+	start, end int
+}
+
+// keyword returns the position of the instruction keyword on its first line.
+func (f *Fixer) keyword(n *parser.Node) (string, span, bool) {
+	line := f.lines[n.StartLine-1]
+	start := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+	end := start + len(n.Value)
+	if end > len(line) || !strings.EqualFold(line[start:end], n.Value) {
+		return "", span{}, false
+	}
+	return line, span{start, end}, true
+}
+
+// rest returns the text following the keyword if the instruction is on a
+// single line and doesn't contain characters that would need escaping.
+func (f *Fixer) rest(n *parser.Node, special string) (string, span, bool) {
+	if n.StartLine != n.EndLine || len(n.Heredocs) > 0 || len(n.Flags) > 0 {
+		return "", span{}, false
+	}
+	line, kw, ok := f.keyword(n)
+	if !ok {
+		return "", span{}, false
+	}
+	rest := strings.TrimSpace(line[kw.end:])
+	if rest == "" || strings.ContainsAny(rest, special) || strings.ContainsRune(rest, f.escapeToken) {
+		return "", span{}, false
+	}
+	return rest, kw, true
+}
+
+func (f *Fixer) edit(n *parser.Node, s span, text string) *TextEdit {
+	return &TextEdit{
+		Range: parser.Range{
+			Start: parser.Position{Line: n.StartLine, Character: s.start},
+			End:   parser.Position{Line: n.StartLine, Character: s.end},
+		},
+		NewText: text,
+	}
+}
+
+// argsEdit replaces everything after the keyword to the end of the line so
+// that it doesn't conflict with a casing fix of the keyword.
+func (f *Fixer) argsEdit(n *parser.Node, kw span, text string) *TextEdit {
+	return f.edit(n, span{kw.end, len(f.lines[n.StartLine-1])}, " "+text)
+}
+
+func matchCase(keyword, s string) string {
+	if keyword == strings.ToLower(keyword) {
+		return strings.ToLower(s)
+	}
+	return strings.ToUpper(s)
+}
+
+func (f *Fixer) fixCasing(n *parser.Node) *TextEdit {
+	_, kw, ok := f.keyword(n)
+	if !ok {
+		return nil
+	}
+	if f.lower {
+		return f.edit(n, kw, strings.ToLower(n.Value))
+	}
+	return f.edit(n, kw, strings.ToUpper(n.Value))
+}
+
+func (f *Fixer) fixFromAs(n *parser.Node) *TextEdit {
+	line, kw, ok := f.keyword(n)
+	if !ok {
+		return nil
+	}
+	fields := fieldSpans(line, kw.end)
+	if len(fields) < 3 {
+		return nil
+	}
+	as := fields[len(fields)-2]
+	if !strings.EqualFold(line[as.start:as.end], "as") {
+		return nil
+	}
+	return f.edit(n, as, matchCase(n.Value, "as"))
+}
+
+func (f *Fixer) fixLegacyKeyValue(n *parser.Node) *TextEdit {
+	rest, kw, ok := f.rest(n, `"'`)
+	if !ok {
+		return nil
+	}
+	key, value, ok := strings.Cut(rest, " ")
+	if !ok {
+		key, value, ok = strings.Cut(rest, "\t")
+	}
+	value = strings.TrimSpace(value)
+	if !ok || value == "" || strings.Contains(key, "=") {
+		return nil
+	}
+	if strings.ContainsFunc(value, unicode.IsSpace) {
+		value = `"` + value + `"`
+	}
+	return f.argsEdit(n, kw, key+"="+value)
+}
+
+var shellBuiltins = []string{".", ":", "cd", "eval", "exec", "export", "set", "source", "ulimit", "umask", "unset"}
+
+func (f *Fixer) fixJSONArgs(n *parser.Node) *TextEdit {
+	// the shell form of ENTRYPOINT ignores CMD, the exec form appends it
+	if strings.EqualFold(n.Value, "entrypoint") {
+		return nil
+	}
+	rest, kw, ok := f.rest(n, "$|&;<>*?\"'`(){}[]~#!")
+	if !ok {
+		return nil
+	}
+	args := strings.Fields(rest)
+	if slices.Contains(shellBuiltins, args[0]) {
+		return nil
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		dt, err := json.Marshal(a)
+		if err != nil {
+			return nil
+		}
+		quoted[i] = string(dt)
+	}
+	return f.argsEdit(n, kw, "["+strings.Join(quoted, ", ")+"]")
+}
+
+func (f *Fixer) fixMaintainer(n *parser.Node) *TextEdit {
+	rest, kw, ok := f.rest(n, `"'$`)
+	if !ok {
+		return nil
+	}
+	label := "LABEL"
+	if f.lower {
+		label = "label"
+	}
+	return f.edit(n, span{kw.start, len(f.lines[n.StartLine-1])}, label+` org.opencontainers.image.authors="`+rest+`"`)
+}
+
+func (f *Fixer) fixWorkdir(n *parser.Node) *TextEdit {
+	// windows paths and variables may already be absolute after expansion
+	rest, kw, ok := f.rest(n, `"'$:`)
+	if !ok || strings.ContainsFunc(rest, unicode.IsSpace) || !f.rootWorkdir(n) {
+		return nil
+	}
+	start := strings.Index(f.lines[n.StartLine-1][kw.end:], rest) + kw.end
+	return f.edit(n, span{start, start + len(rest)}, path.Join("/", rest))
+}
+
+// rootWorkdir returns true if the working directory before n is "/". That is
+// only known if the stage of n is based on scratch, directly or through other
+// stages that don't set a working directory. The working directory of
+// external images is not known.
+func (f *Fixer) rootWorkdir(n *parser.Node) bool {
+	i := slices.Index(f.children, n)
+	for i >= 0 {
+		for i >= 0 && !strings.EqualFold(f.children[i].Value, "from") {
+			i--
+		}
+		if i < 0 || f.children[i].Next == nil {
+			return false
+		}
+		base := f.children[i].Next.Value
+		if strings.EqualFold(base, "scratch") {
+			return true
+		}
+		// find the stage named base before the current one
+		stage := -1
+		for j := i - 1; j >= 0; j-- {
+			from := f.children[j]
+			if !strings.EqualFold(from.Value, "from") || from.Next == nil {
+				continue
+			}
+			as := from.Next.Next
+			if as != nil && as.Next != nil && strings.EqualFold(as.Value, "as") && strings.EqualFold(as.Next.Value, base) {
+				stage = j
+				break
+			}
+		}
+		if stage < 0 {
+			return false
+		}
+		for _, c := range f.children[stage+1 : i] {
+			if strings.EqualFold(c.Value, "from") {
+				break
+			}
+			if strings.EqualFold(c.Value, "workdir") {
+				return false
+			}
+		}
+		i = stage
+	}
+	return false
+}
+
+func fieldSpans(line string, offset int) []span {
+	var spans []span
+	start := -1
+	for i, r := range line[offset:] {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				spans = append(spans, span{offset + start, offset + i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{offset + start, len(line)})
+	}
+	return spans
+}
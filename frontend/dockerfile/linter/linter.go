@@ -16,6 +16,7 @@ type Config struct {
 	ReturnAsError     bool
 	SkipAll           bool
 	SkipRules         []string
+	CustomRules       []*CustomRule
 	Warn              LintWarnFunc
 }
 
@@ -26,6 +27,7 @@ type Linter struct {
 	ReturnAsError     bool
 	SkipAll           bool
 	SkippedRules      map[string]struct{}
+	CustomRules       []*CustomRule
 	Warn              LintWarnFunc
 }
 
@@ -34,6 +36,7 @@ func New(config *Config) *Linter {
 		SkippedRules:      map[string]struct{}{},
 		ExperimentalRules: map[string]struct{}{},
 		CalledRules:       new([]string),
+		CustomRules:       config.CustomRules,
 		Warn:              config.Warn,
 	}
 	toret.SkipAll = config.SkipAll
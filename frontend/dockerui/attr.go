@@ -1,7 +1,10 @@
 package dockerui
 
 import (
+	"encoding/json"
+	"maps"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -27,6 +30,55 @@ func parsePlatforms(v string) ([]ocispecs.Platform, error) {
 	return pp, nil
 }
 
+// parseMatrix parses a JSON object of build arg names to lists of values into
+// the cells of the build matrix, one for every combination of the values.
+func parseMatrix(v string, buildArgs map[string]string) ([]*MatrixCell, error) {
+	var m map[string][]string
+	if err := json.Unmarshal([]byte(v), &m); err != nil {
+		return nil, errors.Wrap(err, "failed to parse build matrix")
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+	names := slices.Sorted(maps.Keys(m))
+	for _, name := range names {
+		if name == "" {
+			return nil, errors.New("invalid empty build matrix arg name")
+		}
+		if _, ok := buildArgs[name]; ok {
+			return nil, errors.Errorf("build arg %s is set by both the build matrix and a build-arg", name)
+		}
+		values := m[name]
+		if len(values) == 0 {
+			return nil, errors.Errorf("build matrix arg %s has no values", name)
+		}
+		if len(slices.Compact(slices.Sorted(slices.Values(values)))) != len(values) {
+			return nil, errors.Errorf("build matrix arg %s has duplicate values", name)
+		}
+	}
+
+	cells := []*MatrixCell{{Args: map[string]string{}}}
+	for _, name := range names {
+		next := make([]*MatrixCell, 0, len(cells)*len(m[name]))
+		for _, c := range cells {
+			for _, v := range m[name] {
+				args := maps.Clone(c.Args)
+				args[name] = v
+				next = append(next, &MatrixCell{Args: args})
+			}
+		}
+		cells = next
+	}
+	for _, c := range cells {
+		ids := make([]string, 0, len(names))
+		for _, name := range names {
+			ids = append(ids, name+"="+c.Args[name])
+		}
+		c.ID = strings.Join(ids, ",")
+	}
+	return cells, nil
+}
+
 func parseResolveMode(v string) (llb.ResolveMode, error) {
 	switch v {
 	case pb.AttrImageResolveModeDefault, "":
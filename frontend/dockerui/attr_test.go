@@ -0,0 +1,36 @@
+package dockerui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatrix(t *testing.T) {
+	cells, err := parseMatrix(`{"GO_VERSION":["1.22","1.23"],"DEBIAN":["bookworm"]}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*MatrixCell{
+		{ID: "DEBIAN=bookworm,GO_VERSION=1.22", Args: map[string]string{"DEBIAN": "bookworm", "GO_VERSION": "1.22"}},
+		{ID: "DEBIAN=bookworm,GO_VERSION=1.23", Args: map[string]string{"DEBIAN": "bookworm", "GO_VERSION": "1.23"}},
+	}, cells)
+	require.Equal(t, "DEBIAN=bookworm,GO_VERSION=1.22/linux/amd64", cells[0].RefID("linux/amd64"))
+
+	var cell *MatrixCell
+	require.Equal(t, "linux/amd64", cell.RefID("linux/amd64"))
+
+	cells, err = parseMatrix(`{}`, nil)
+	require.NoError(t, err)
+	require.Nil(t, cells)
+
+	_, err = parseMatrix(`{"GO_VERSION":[]}`, nil)
+	require.ErrorContains(t, err, "has no values")
+
+	_, err = parseMatrix(`{"GO_VERSION":["1.22","1.22"]}`, nil)
+	require.ErrorContains(t, err, "duplicate values")
+
+	_, err = parseMatrix(`{"GO_VERSION":["1.22"]}`, map[string]string{"GO_VERSION": "1.21"})
+	require.ErrorContains(t, err, "set by both the build matrix and a build-arg")
+
+	_, err = parseMatrix(`["GO_VERSION"]`, nil)
+	require.ErrorContains(t, err, "failed to parse build matrix")
+}
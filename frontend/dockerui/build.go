@@ -25,7 +25,9 @@ type BuildResult struct {
 	Epoch     *time.Time
 }
 
-type BuildFunc func(ctx context.Context, platform *ocispecs.Platform, idx int) (*BuildResult, error)
+// BuildFunc builds the result for a target platform and, if a build matrix
+// was requested, a cell of the matrix. cell is nil otherwise.
+type BuildFunc func(ctx context.Context, platform *ocispecs.Platform, cell *MatrixCell, idx int) (*BuildResult, error)
 
 func (bc *Client) Build(ctx context.Context, fn BuildFunc) (*ResultBuilder, error) {
 	res := client.NewResult()
@@ -37,15 +39,33 @@ func (bc *Client) Build(ctx context.Context, fn BuildFunc) (*ResultBuilder, erro
 	if len(targets) == 0 {
 		targets = append(targets, nil)
 	}
+	cells := bc.Matrix
+	var matrix *exptypes.Matrix
+	if len(cells) > 0 {
+		matrix = &exptypes.Matrix{
+			MultiPlatform: bc.MultiPlatformRequested,
+			Cells:         make([]exptypes.MatrixCell, len(cells)),
+		}
+		for i, c := range cells {
+			matrix.Cells[i] = exptypes.MatrixCell{
+				ID:        c.ID,
+				Args:      c.Args,
+				Platforms: make([]exptypes.Platform, len(targets)),
+			}
+		}
+	} else {
+		cells = []*MatrixCell{nil}
+	}
 	expPlatforms := &exptypes.Platforms{
-		Platforms: make([]exptypes.Platform, len(targets)),
+		Platforms: make([]exptypes.Platform, len(cells)*len(targets)),
 	}
 
 	eg, ctx := errgroup.WithContext(ctx)
 
-	for i, tp := range targets {
+	for i := range expPlatforms.Platforms {
+		cell, tp := cells[i/len(targets)], targets[i%len(targets)]
 		eg.Go(func() error {
-			buildRes, err := fn(ctx, tp, i)
+			buildRes, err := fn(ctx, tp, cell, i)
 			if err != nil {
 				return err
 			}
@@ -71,7 +91,11 @@ func (bc *Client) Build(ctx context.Context, fn BuildFunc) (*ResultBuilder, erro
 				p = platforms.DefaultSpec()
 			}
 			expPlat := makeExportPlatform(p, img.Platform)
-			if bc.MultiPlatformRequested {
+			if matrix != nil {
+				matrix.Cells[i/len(targets)].Platforms[i%len(targets)] = expPlat
+				expPlat.ID = cell.RefID(expPlat.ID)
+			}
+			if bc.MultiPlatformRequested || matrix != nil {
 				res.AddRef(expPlat.ID, ref)
 				res.AddMeta(fmt.Sprintf("%s/%s", exptypes.ExporterImageConfigKey, expPlat.ID), config)
 				if len(baseConfig) > 0 {
@@ -100,12 +124,14 @@ func (bc *Client) Build(ctx context.Context, fn BuildFunc) (*ResultBuilder, erro
 	return &ResultBuilder{
 		Result:       res,
 		expPlatforms: expPlatforms,
+		matrix:       matrix,
 	}, nil
 }
 
 type ResultBuilder struct {
 	*client.Result
 	expPlatforms *exptypes.Platforms
+	matrix       *exptypes.Matrix
 }
 
 func (rb *ResultBuilder) Finalize() (*client.Result, error) {
@@ -114,10 +140,19 @@ func (rb *ResultBuilder) Finalize() (*client.Result, error) {
 		return nil, err
 	}
 	rb.AddMeta(exptypes.ExporterPlatformsKey, dt)
+	if rb.matrix != nil {
+		dt, err := json.Marshal(rb.matrix)
+		if err != nil {
+			return nil, err
+		}
+		rb.AddMeta(exptypes.ExporterMatrixKey, dt)
+	}
 
 	return rb.Result, nil
 }
 
+// EachPlatform calls fn for every ref of the result. For a build matrix the
+// ID is the ref ID of the platform in its cell.
 func (rb *ResultBuilder) EachPlatform(ctx context.Context, fn func(ctx context.Context, id string, p ocispecs.Platform) error) error {
 	eg, ctx := errgroup.WithContext(ctx)
 	for _, p := range rb.expPlatforms.Platforms {
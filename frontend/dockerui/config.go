@@ -13,6 +13,7 @@ import (
 	"github.com/distribution/reference"
 	controlapi "github.com/moby/buildkit/api/services/control"
 	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/frontend/attestations"
 	"github.com/moby/buildkit/frontend/dockerfile/linter"
 	"github.com/moby/buildkit/frontend/gateway/client"
@@ -49,6 +50,7 @@ const (
 	keyCpusetMems       = "cpusetmems"
 	keyCacheFrom        = "cache-from"    // for registry only. deprecated in favor of keyCacheImports
 	keyCacheImports     = "cache-imports" // JSON representation of []CacheOptionsEntry
+	keyMatrix           = "matrix"        // JSON object of build arg names to lists of values
 
 	// Don't forget to update frontend documentation if you add
 	// a new build-arg: frontend/dockerfile/docs/reference.md
@@ -57,6 +59,9 @@ const (
 	keyHostnameArg          = "build-arg:BUILDKIT_SANDBOX_HOSTNAME"
 	keyDockerfileLintArg    = "build-arg:BUILDKIT_DOCKERFILE_CHECK"
 	keyContextKeepGitDirArg = "build-arg:BUILDKIT_CONTEXT_KEEP_GIT_DIR"
+
+	keyDockerfileLintRulesArg     = "build-arg:BUILDKIT_DOCKERFILE_CHECK_RULES"
+	keyDockerfileLintRulesFileArg = "build-arg:BUILDKIT_DOCKERFILE_CHECK_RULES_FILE"
 )
 
 type Config struct {
@@ -74,14 +79,32 @@ type Config struct {
 	LinuxResources   *pb.LinuxResources
 	Devices          []*pb.CDIDevice
 	LinterConfig     *linter.Config
+	LintRules        []*linter.CustomRule
 
 	CacheImports           []client.CacheOptionsEntry
 	TargetPlatforms        []ocispecs.Platform // nil means default
 	BuildPlatforms         []ocispecs.Platform
 	MultiPlatformRequested bool
+	Matrix                 []*MatrixCell // nil means no build matrix
 	SBOM                   *SBOM
 }
 
+// MatrixCell is a combination of build args of a build matrix. The Dockerfile
+// is built for every cell and target platform.
+type MatrixCell struct {
+	ID   string
+	Args map[string]string
+}
+
+// RefID returns the key of the result ref for a platform of the cell. A nil
+// cell returns the platform ID.
+func (c *MatrixCell) RefID(platformID string) string {
+	if c == nil {
+		return platformID
+	}
+	return exptypes.MatrixRefID(c.ID, platformID)
+}
+
 type Client struct {
 	Config
 	client           client.Client
@@ -93,6 +116,8 @@ type Client struct {
 	dockerignore     []byte
 	dockerignoreMu   sync.Mutex
 	dockerignoreName string
+
+	lintRulesFile string
 }
 
 type SBOM struct {
@@ -296,6 +321,12 @@ func (bc *Client) init() error {
 
 	bc.BuildArgs = filter(opts, buildArgPrefix)
 	bc.Labels = filter(opts, labelPrefix)
+	if v := opts[keyMatrix]; v != "" {
+		bc.Matrix, err = parseMatrix(v, bc.BuildArgs)
+		if err != nil {
+			return err
+		}
+	}
 	bc.CacheIDNamespace = opts[keyCacheNSArg]
 	bc.CgroupParent = opts[keyCgroupParent]
 	bc.Target = opts[keyTarget]
@@ -311,6 +342,13 @@ func (bc *Client) init() error {
 			return errors.Wrapf(err, "failed to parse %s", keyDockerfileLintArg)
 		}
 	}
+	if v, ok := opts[keyDockerfileLintRulesArg]; ok && v != "" {
+		bc.LintRules, err = linter.ParseCustomRules([]byte(v))
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse %s", keyDockerfileLintRulesArg)
+		}
+	}
+	bc.lintRulesFile = opts[keyDockerfileLintRulesFileArg]
 
 	bc.localsSessionIDs = parseLocalSessionIDs(opts)
 
@@ -574,3 +612,61 @@ func (bc *Client) dockerIgnorePatterns(ctx context.Context, bctx *buildContext)
 	}
 	return excludes, nil
 }
+
+// LoadLintRules reads the custom lint rules file set with the
+// BUILDKIT_DOCKERFILE_CHECK_RULES_FILE build-arg from the build context.
+// Rules defined inline with BUILDKIT_DOCKERFILE_CHECK_RULES take precedence
+// over rules with the same name from the file.
+func (bc *Client) LoadLintRules(ctx context.Context) error {
+	if bc.lintRulesFile == "" {
+		return nil
+	}
+	bctx, err := bc.buildContext(ctx)
+	if err != nil {
+		return err
+	}
+	filename := path.Clean(bc.lintRulesFile)
+	var st llb.State
+	if bctx.context != nil {
+		st = *bctx.context
+	} else {
+		sessionID := bc.bopts.SessionID
+		if v, ok := bc.localsSessionIDs[bctx.contextLocalName]; ok {
+			sessionID = v
+		}
+		st = llb.Local(bctx.contextLocalName,
+			llb.SessionID(sessionID),
+			llb.FollowPaths([]string{filename}),
+			llb.SharedKeyHint(bctx.contextLocalName+"-"+filename),
+			WithInternalName("load lint rules"),
+			llb.Differ(llb.DiffNone, false),
+		)
+	}
+	def, err := st.Marshal(ctx, bc.marshalOpts()...)
+	if err != nil {
+		return err
+	}
+	res, err := bc.client.Solve(ctx, client.SolveRequest{
+		Definition: def.ToPB(),
+	})
+	if err != nil {
+		return err
+	}
+	ref, err := res.SingleRef()
+	if err != nil {
+		return err
+	}
+	dt, err := ref.ReadFile(ctx, client.ReadRequest{
+		Filename: filename,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to read lint rules file %s", filename)
+	}
+	rules, err := linter.ParseCustomRules(dt)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	bc.LintRules = linter.MergeCustomRules(rules, bc.LintRules)
+	bc.lintRulesFile = ""
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"path"
 
+	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/frontend/gateway/client"
 	"github.com/moby/buildkit/frontend/subrequests"
 	"github.com/moby/buildkit/frontend/subrequests/convertllb"
@@ -22,6 +24,7 @@ type RequestHandler struct {
 	Outline     func(context.Context) (*outline.Outline, error)
 	ListTargets func(context.Context) (*targets.List, error)
 	Lint        func(context.Context) (*lint.LintResults, error)
+	LintFix     func(context.Context) (*lint.LintResults, error)
 	ConvertLLB  func(context.Context) (*convertllb.Result, error)
 	AllowOther  bool
 }
@@ -71,6 +74,18 @@ func (bc *Client) HandleSubrequest(ctx context.Context, h RequestHandler) (*clie
 			res, err := warnings.ToResult(nil)
 			return res, true, err
 		}
+	case lint.SubrequestLintFixDefinition.Name:
+		if f := h.LintFix; f != nil {
+			results, err := f(ctx)
+			if err != nil {
+				return nil, false, err
+			}
+			if results == nil {
+				return nil, true, nil
+			}
+			res, err := bc.lintFixResult(ctx, results)
+			return res, true, err
+		}
 	case convertllb.SubrequestConvertLLBDefinition.Name:
 		if f := h.ConvertLLB; f != nil {
 			result, err := f(ctx)
@@ -115,3 +130,48 @@ func describe(h RequestHandler) (*client.Result, error) {
 	}
 	return res, nil
 }
+
+// lintFixResult returns the lint results together with a reference containing
+// the fixed Dockerfile so it can be written back with the local exporter.
+func (bc *Client) lintFixResult(ctx context.Context, results *lint.LintResults) (*client.Result, error) {
+	res, err := results.ToResult(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Sources) == 0 {
+		return res, nil
+	}
+	dt, n, err := results.ApplyFixes(0)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return res, nil
+	}
+	bctx, err := bc.buildContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filename := path.Clean(path.Join("/", bctx.filename))
+	st := llb.Scratch()
+	if dir := path.Dir(filename); dir != "/" {
+		st = st.File(llb.Mkdir(dir, 0755, llb.WithParents(true)))
+	}
+	st = st.File(llb.Mkfile(filename, 0644, dt), WithInternalName("fix "+path.Base(filename)))
+	def, err := st.Marshal(ctx, bc.marshalOpts()...)
+	if err != nil {
+		return nil, err
+	}
+	r, err := bc.client.Solve(ctx, client.SolveRequest{
+		Definition: def.ToPB(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ref, err := r.SingleRef()
+	if err != nil {
+		return nil, err
+	}
+	res.SetRef(ref)
+	return res, nil
+}
@@ -37,6 +37,22 @@ type FrontendLLBBridge interface {
 
 type SolveRequest = gw.SolveRequest
 
+type allowedFrontendsKey struct{}
+
+// WithAllowedFrontends returns a context that restricts the frontends used
+// by a build solved with it, including frontends requested by other
+// frontends.
+func WithAllowedFrontends(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, allowedFrontendsKey{}, names)
+}
+
+// AllowedFrontends returns the frontends allowed by ctx. It returns false if
+// all frontends are allowed.
+func AllowedFrontends(ctx context.Context) ([]string, bool) {
+	names, ok := ctx.Value(allowedFrontendsKey{}).([]string)
+	return names, ok
+}
+
 type CacheOptionsEntry = gw.CacheOptionsEntry
 
 type WarnOpts = gw.WarnOpts
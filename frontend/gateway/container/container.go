@@ -153,6 +153,9 @@ type PreparedMounts struct {
 	Mounts         []executor.Mount
 	OutputRefs     []MountRef
 	Actives        []MountMutableRef
+	// Secrets are the values of the secret mounts that should be redacted
+	// from the logs.
+	Secrets [][]byte
 }
 
 type MountRef struct {
@@ -253,6 +256,9 @@ func PrepareMounts(ctx context.Context, mm *mounts.MountManager, cm cache.Manage
 			if mountable == nil {
 				continue
 			}
+			if dt, ok := mounts.SecretValue(mountable); ok && !m.SecretOpt.NoRedact {
+				p.Secrets = append(p.Secrets, dt)
+			}
 		case opspb.MountType_SSH:
 			var err error
 			mountable, err = mm.MountableSSH(ctx, m, g)
@@ -0,0 +1,51 @@
+package debugger
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Listen listens for debugger clients on addr, unix://<path>. The debugger
+// can read the files of the build and run commands with its secrets and has
+// no authentication, so it only listens on unix sockets that are accessible
+// by the current user.
+func Listen(addr string) (net.Listener, error) {
+	address, err := parseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, 0600); err != nil {
+		l.Close()
+		return nil, errors.Wrapf(err, "failed to restrict access to debugger socket %s", address)
+	}
+	return l, nil
+}
+
+// Dial returns a connection to the debugger listening on addr.
+func Dial(addr string) (*grpc.ClientConn, error) {
+	address, err := parseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.NewClient("unix:"+address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func parseAddr(addr string) (string, error) {
+	network, address, ok := strings.Cut(addr, "://")
+	if !ok || address == "" {
+		return "", errors.Errorf("invalid debugger address %q, expected unix://<path>", addr)
+	}
+	if network != "unix" {
+		return "", errors.Errorf("unsupported debugger address %q, the debugger only listens on unix sockets", addr)
+	}
+	return address, nil
+}
@@ -0,0 +1,374 @@
+package debugger
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/iohelper"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Debugger evaluates the result of a gateway build vertex by vertex and stops
+// before the vertices matching a breakpoint until it is continued by a client
+// of the Debugger service.
+type Debugger struct {
+	mu          sync.Mutex
+	breakpoints []*Breakpoint
+	stop        *stopState
+	done        bool
+	err         error
+	// changed is closed and replaced when the build stops, continues or is
+	// done
+	changed chan struct{}
+	resume  chan bool
+}
+
+type stopState struct {
+	*Stop
+	c    gateway.Client
+	v    *vertex
+	refs []gateway.Reference
+}
+
+func New() *Debugger {
+	return &Debugger{
+		changed: make(chan struct{}),
+		resume:  make(chan bool, 1),
+	}
+}
+
+func (d *Debugger) Register(server *grpc.Server) {
+	RegisterDebuggerServer(server, d)
+}
+
+// Run evaluates the inputs of the vertices of the refs of res in order and
+// stops before every vertex matching a breakpoint. It stops before the first
+// vertex until the build is continued. The refs of res themselves are not
+// evaluated.
+func (d *Debugger) Run(ctx context.Context, c gateway.Client, res *gateway.Result) (err error) {
+	defer func() {
+		d.mu.Lock()
+		d.done = true
+		d.err = err
+		// the build can return while it is stopped, e.g. when it is
+		// canceled, and must not be continued afterwards
+		d.stop = nil
+		d.notifyLocked()
+		d.mu.Unlock()
+	}()
+
+	g, err := loadResult(ctx, res)
+	if err != nil {
+		return err
+	}
+
+	step := true
+	for _, v := range g.vertices {
+		bp := d.match(v)
+		if bp == nil && !step {
+			continue
+		}
+		refs := make([]gateway.Reference, len(v.op.Inputs))
+		for i, inp := range v.op.Inputs {
+			def, err := v.def.inputDefinition(inp)
+			if err != nil {
+				return err
+			}
+			r, err := c.Solve(ctx, gateway.SolveRequest{
+				Definition: def,
+				Evaluate:   true,
+			})
+			if err != nil {
+				return err
+			}
+			if refs[i], err = r.SingleRef(); err != nil {
+				return err
+			}
+		}
+		step, err = d.pause(ctx, &stopState{
+			Stop: v.stop(bp),
+			c:    c,
+			v:    v,
+			refs: refs,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Debugger) match(v *vertex) *Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, bp := range d.breakpoints {
+		if v.matches(bp) {
+			return bp.CloneVT()
+		}
+	}
+	return nil
+}
+
+func (d *Debugger) pause(ctx context.Context, st *stopState) (step bool, err error) {
+	d.mu.Lock()
+	d.stop = st
+	d.notifyLocked()
+	d.mu.Unlock()
+
+	select {
+	case step := <-d.resume:
+		return step, nil
+	case <-ctx.Done():
+		return false, context.Cause(ctx)
+	}
+}
+
+func (d *Debugger) notifyLocked() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+func (d *Debugger) stopped() (*stopState, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stop == nil {
+		return nil, status.Error(codes.FailedPrecondition, "build is not stopped")
+	}
+	return d.stop, nil
+}
+
+func (d *Debugger) Wait(ctx context.Context, req *WaitRequest) (*WaitResponse, error) {
+	for {
+		d.mu.Lock()
+		if d.stop != nil || d.done {
+			resp := &WaitResponse{Done: d.done}
+			if d.stop != nil {
+				resp.Stop = d.stop.Stop.CloneVT()
+			}
+			if d.err != nil {
+				resp.Error = d.err.Error()
+			}
+			d.mu.Unlock()
+			return resp, nil
+		}
+		ch := d.changed
+		d.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+}
+
+func (d *Debugger) Continue(ctx context.Context, req *ContinueRequest) (*ContinueResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stop == nil {
+		return nil, status.Error(codes.FailedPrecondition, "build is not stopped")
+	}
+	d.stop = nil
+	d.notifyLocked()
+	// resume is only full if Run has not received the previous value because
+	// it is returning, so the send must not block while holding d.mu
+	select {
+	case d.resume <- req.Step:
+	default:
+	}
+	return &ContinueResponse{}, nil
+}
+
+func (d *Debugger) ListBreakpoints(ctx context.Context, req *ListBreakpointsRequest) (*ListBreakpointsResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resp := &ListBreakpointsResponse{}
+	for _, bp := range d.breakpoints {
+		resp.Breakpoints = append(resp.Breakpoints, bp.CloneVT())
+	}
+	return resp, nil
+}
+
+func (d *Debugger) SetBreakpoints(ctx context.Context, req *SetBreakpointsRequest) (*SetBreakpointsResponse, error) {
+	for _, bp := range req.Breakpoints {
+		if bp.Digest == "" && bp.Line <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "breakpoint requires a digest or a line")
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints = d.breakpoints[:0]
+	for _, bp := range req.Breakpoints {
+		d.breakpoints = append(d.breakpoints, bp.CloneVT())
+	}
+	return &SetBreakpointsResponse{}, nil
+}
+
+func (d *Debugger) ReadDir(ctx context.Context, req *ReadDirRequest) (*ReadDirResponse, error) {
+	st, err := d.stopped()
+	if err != nil {
+		return nil, err
+	}
+	ref, err := st.input(req.Input)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ref.ReadDir(ctx, gateway.ReadDirRequest{
+		Path:           req.Path,
+		IncludePattern: req.IncludePattern,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ReadDirResponse{Entries: entries}, nil
+}
+
+func (d *Debugger) Exec(req *ExecRequest, stream Debugger_ExecServer) error {
+	ctx := stream.Context()
+	st, err := d.stopped()
+	if err != nil {
+		return err
+	}
+	if len(req.Args) == 0 {
+		return status.Error(codes.InvalidArgument, "no command specified")
+	}
+	ctrReq, startReq, err := st.container(req)
+	if err != nil {
+		return err
+	}
+
+	ctr, err := st.c.NewContainer(ctx, ctrReq)
+	if err != nil {
+		return err
+	}
+	defer ctr.Release(context.WithoutCancel(ctx))
+
+	w := &execWriter{stream: stream}
+	startReq.Stdout = &iohelper.NopWriteCloser{Writer: w.writer(false)}
+	startReq.Stderr = &iohelper.NopWriteCloser{Writer: w.writer(true)}
+	proc, err := ctr.Start(ctx, startReq)
+	if err != nil {
+		return err
+	}
+
+	resp := &ExecResponse{Exited: true}
+	if err := proc.Wait(); err != nil {
+		var exitErr *gatewaypb.ExitError
+		if !errors.As(err, &exitErr) {
+			return err
+		}
+		resp.ExitCode = exitErr.ExitCode
+	}
+	return w.send(resp)
+}
+
+func (st *stopState) input(i int64) (gateway.Reference, error) {
+	if i < 0 || i >= int64(len(st.refs)) {
+		return nil, status.Errorf(codes.InvalidArgument, "vertex %s has no input %d", st.Digest, i)
+	}
+	return st.refs[i], nil
+}
+
+// container returns the requests to run the command of req with the inputs
+// of the vertex the build is stopped before.
+func (st *stopState) container(req *ExecRequest) (gateway.NewContainerRequest, gateway.StartRequest, error) {
+	op := st.v.op
+	ctrReq := gateway.NewContainerRequest{
+		Platform:    op.Platform,
+		Constraints: op.Constraints,
+	}
+	startReq := gateway.StartRequest{
+		Args: req.Args,
+		Env:  req.Env,
+		Cwd:  req.Cwd,
+		User: req.User,
+	}
+
+	exec, ok := op.Op.(*pb.Op_Exec)
+	if !ok {
+		ref, err := st.input(req.Input)
+		if err != nil {
+			return ctrReq, startReq, err
+		}
+		ctrReq.Mounts = []gateway.Mount{{
+			Dest:      "/",
+			MountType: pb.MountType_BIND,
+			Ref:       ref,
+		}}
+		return ctrReq, startReq, nil
+	}
+
+	for _, m := range exec.Exec.Mounts {
+		mnt := gateway.Mount{
+			Selector:  m.Selector,
+			Dest:      m.Dest,
+			Readonly:  m.Readonly,
+			MountType: m.MountType,
+			CacheOpt:  m.CacheOpt,
+			SecretOpt: m.SecretOpt,
+			SSHOpt:    m.SSHOpt,
+		}
+		if m.Input != int64(pb.Empty) {
+			ref, err := st.input(m.Input)
+			if err != nil {
+				return ctrReq, startReq, err
+			}
+			mnt.Ref = ref
+		}
+		ctrReq.Mounts = append(ctrReq.Mounts, mnt)
+	}
+	ctrReq.NetMode = exec.Exec.Network
+
+	meta := exec.Exec.Meta
+	startReq.Env = append(slices.Clone(meta.Env), req.Env...)
+	if startReq.Cwd == "" {
+		startReq.Cwd = meta.Cwd
+	}
+	if startReq.User == "" {
+		startReq.User = meta.User
+	}
+	startReq.SecretEnv = exec.Exec.Secretenv
+	startReq.SecurityMode = exec.Exec.Security
+	startReq.RemoveMountStubsRecursive = meta.RemoveMountStubsRecursive
+	return ctrReq, startReq, nil
+}
+
+type execWriter struct {
+	mu     sync.Mutex
+	stream Debugger_ExecServer
+}
+
+func (w *execWriter) send(resp *ExecResponse) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stream.Send(resp)
+}
+
+func (w *execWriter) writer(stderr bool) *execStreamWriter {
+	return &execStreamWriter{w: w, stderr: stderr}
+}
+
+type execStreamWriter struct {
+	w      *execWriter
+	stderr bool
+}
+
+func (w *execStreamWriter) Write(dt []byte) (int, error) {
+	resp := &ExecResponse{}
+	if w.stderr {
+		resp.Stderr = slices.Clone(dt)
+	} else {
+		resp.Stdout = slices.Clone(dt)
+	}
+	if err := w.w.send(resp); err != nil {
+		return 0, err
+	}
+	return len(dt), nil
+}
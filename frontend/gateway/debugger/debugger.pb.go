@@ -0,0 +1,1008 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.14.0
+// source: github.com/moby/buildkit/frontend/gateway/debugger/debugger.proto
+
+package debugger
+
+import (
+	pb "github.com/moby/buildkit/solver/pb"
+	types "github.com/tonistiigi/fsutil/types"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Breakpoint stops the build before a vertex, selected by digest or by a
+// line of a source file in the source map of the definition.
+type Breakpoint struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Digest string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	// Filename is optional when there is a single source file.
+	Filename      string `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	Line          int32  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Breakpoint) Reset() {
+	*x = Breakpoint{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Breakpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Breakpoint) ProtoMessage() {}
+
+func (x *Breakpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Breakpoint.ProtoReflect.Descriptor instead.
+func (*Breakpoint) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Breakpoint) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *Breakpoint) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Breakpoint) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+type WaitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitRequest) Reset() {
+	*x = WaitRequest{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitRequest) ProtoMessage() {}
+
+func (x *WaitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitRequest.ProtoReflect.Descriptor instead.
+func (*WaitRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{1}
+}
+
+type WaitResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Stop is set while the build is stopped.
+	Stop *Stop `protobuf:"bytes,1,opt,name=stop,proto3" json:"stop,omitempty"`
+	Done bool  `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+	// Error is the error the build finished with.
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitResponse) Reset() {
+	*x = WaitResponse{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitResponse) ProtoMessage() {}
+
+func (x *WaitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitResponse.ProtoReflect.Descriptor instead.
+func (*WaitResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WaitResponse) GetStop() *Stop {
+	if x != nil {
+		return x.Stop
+	}
+	return nil
+}
+
+func (x *WaitResponse) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *WaitResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Stop struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Digest    string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Locations []*Location            `protobuf:"bytes,3,rep,name=locations,proto3" json:"locations,omitempty"`
+	Inputs    []*Input               `protobuf:"bytes,4,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	// Breakpoint is the breakpoint the build stopped at, it is unset when
+	// stepping.
+	Breakpoint    *Breakpoint `protobuf:"bytes,5,opt,name=breakpoint,proto3" json:"breakpoint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stop) Reset() {
+	*x = Stop{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stop) ProtoMessage() {}
+
+func (x *Stop) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stop.ProtoReflect.Descriptor instead.
+func (*Stop) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Stop) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *Stop) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Stop) GetLocations() []*Location {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *Stop) GetInputs() []*Input {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *Stop) GetBreakpoint() *Breakpoint {
+	if x != nil {
+		return x.Breakpoint
+	}
+	return nil
+}
+
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Ranges        []*pb.Range            `protobuf:"bytes,2,rep,name=ranges,proto3" json:"ranges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Location) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Location) GetRanges() []*pb.Range {
+	if x != nil {
+		return x.Ranges
+	}
+	return nil
+}
+
+type Input struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Input) Reset() {
+	*x = Input{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Input) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Input) ProtoMessage() {}
+
+func (x *Input) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Input.ProtoReflect.Descriptor instead.
+func (*Input) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Input) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *Input) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ContinueRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Step stops the build before the next vertex.
+	Step          bool `protobuf:"varint,1,opt,name=step,proto3" json:"step,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContinueRequest) Reset() {
+	*x = ContinueRequest{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContinueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContinueRequest) ProtoMessage() {}
+
+func (x *ContinueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContinueRequest.ProtoReflect.Descriptor instead.
+func (*ContinueRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ContinueRequest) GetStep() bool {
+	if x != nil {
+		return x.Step
+	}
+	return false
+}
+
+type ContinueResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContinueResponse) Reset() {
+	*x = ContinueResponse{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContinueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContinueResponse) ProtoMessage() {}
+
+func (x *ContinueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContinueResponse.ProtoReflect.Descriptor instead.
+func (*ContinueResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{7}
+}
+
+type ListBreakpointsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBreakpointsRequest) Reset() {
+	*x = ListBreakpointsRequest{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBreakpointsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBreakpointsRequest) ProtoMessage() {}
+
+func (x *ListBreakpointsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBreakpointsRequest.ProtoReflect.Descriptor instead.
+func (*ListBreakpointsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{8}
+}
+
+type ListBreakpointsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Breakpoints   []*Breakpoint          `protobuf:"bytes,1,rep,name=breakpoints,proto3" json:"breakpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBreakpointsResponse) Reset() {
+	*x = ListBreakpointsResponse{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBreakpointsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBreakpointsResponse) ProtoMessage() {}
+
+func (x *ListBreakpointsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBreakpointsResponse.ProtoReflect.Descriptor instead.
+func (*ListBreakpointsResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListBreakpointsResponse) GetBreakpoints() []*Breakpoint {
+	if x != nil {
+		return x.Breakpoints
+	}
+	return nil
+}
+
+type SetBreakpointsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Breakpoints   []*Breakpoint          `protobuf:"bytes,1,rep,name=breakpoints,proto3" json:"breakpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBreakpointsRequest) Reset() {
+	*x = SetBreakpointsRequest{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBreakpointsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBreakpointsRequest) ProtoMessage() {}
+
+func (x *SetBreakpointsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBreakpointsRequest.ProtoReflect.Descriptor instead.
+func (*SetBreakpointsRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetBreakpointsRequest) GetBreakpoints() []*Breakpoint {
+	if x != nil {
+		return x.Breakpoints
+	}
+	return nil
+}
+
+type SetBreakpointsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBreakpointsResponse) Reset() {
+	*x = SetBreakpointsResponse{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBreakpointsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBreakpointsResponse) ProtoMessage() {}
+
+func (x *SetBreakpointsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBreakpointsResponse.ProtoReflect.Descriptor instead.
+func (*SetBreakpointsResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{11}
+}
+
+type ReadDirRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Input          int64                  `protobuf:"varint,1,opt,name=input,proto3" json:"input,omitempty"`
+	Path           string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	IncludePattern string                 `protobuf:"bytes,3,opt,name=include_pattern,json=includePattern,proto3" json:"include_pattern,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReadDirRequest) Reset() {
+	*x = ReadDirRequest{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDirRequest) ProtoMessage() {}
+
+func (x *ReadDirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDirRequest.ProtoReflect.Descriptor instead.
+func (*ReadDirRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ReadDirRequest) GetInput() int64 {
+	if x != nil {
+		return x.Input
+	}
+	return 0
+}
+
+func (x *ReadDirRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReadDirRequest) GetIncludePattern() string {
+	if x != nil {
+		return x.IncludePattern
+	}
+	return ""
+}
+
+type ReadDirResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*types.Stat          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDirResponse) Reset() {
+	*x = ReadDirResponse{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDirResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDirResponse) ProtoMessage() {}
+
+func (x *ReadDirResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDirResponse.ProtoReflect.Descriptor instead.
+func (*ReadDirResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReadDirResponse) GetEntries() []*types.Stat {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type ExecRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Input is the root filesystem of the container for vertices that are
+	// not exec vertices.
+	Input int64    `protobuf:"varint,1,opt,name=input,proto3" json:"input,omitempty"`
+	Args  []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// Env is appended to the environment of exec vertices.
+	Env           []string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty"`
+	Cwd           string   `protobuf:"bytes,4,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	User          string   `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecRequest) Reset() {
+	*x = ExecRequest{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecRequest) ProtoMessage() {}
+
+func (x *ExecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
+func (*ExecRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExecRequest) GetInput() int64 {
+	if x != nil {
+		return x.Input
+	}
+	return 0
+}
+
+func (x *ExecRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetEnv() []string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *ExecRequest) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *ExecRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+type ExecResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Stdout []byte                 `protobuf:"bytes,1,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr []byte                 `protobuf:"bytes,2,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	// Exited is set on the last message.
+	Exited        bool   `protobuf:"varint,3,opt,name=exited,proto3" json:"exited,omitempty"`
+	ExitCode      uint32 `protobuf:"varint,4,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecResponse) Reset() {
+	*x = ExecResponse{}
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecResponse) ProtoMessage() {}
+
+func (x *ExecResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
+func (*ExecResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExecResponse) GetStdout() []byte {
+	if x != nil {
+		return x.Stdout
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *ExecResponse) GetExited() bool {
+	if x != nil {
+		return x.Exited
+	}
+	return false
+}
+
+func (x *ExecResponse) GetExitCode() uint32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+var File_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto protoreflect.FileDescriptor
+
+const file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDesc = "" +
+	"\n" +
+	"Agithub.com/moby/buildkit/frontend/gateway/debugger/debugger.proto\x12\x19moby.buildkit.v1.debugger\x1a,github.com/moby/buildkit/solver/pb/ops.proto\x1a-github.com/tonistiigi/fsutil/types/stat.proto\"T\n" +
+	"\n" +
+	"Breakpoint\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04line\x18\x03 \x01(\x05R\x04line\"\r\n" +
+	"\vWaitRequest\"m\n" +
+	"\fWaitResponse\x123\n" +
+	"\x04stop\x18\x01 \x01(\v2\x1f.moby.buildkit.v1.debugger.StopR\x04stop\x12\x12\n" +
+	"\x04done\x18\x02 \x01(\bR\x04done\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xf6\x01\n" +
+	"\x04Stop\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12A\n" +
+	"\tlocations\x18\x03 \x03(\v2#.moby.buildkit.v1.debugger.LocationR\tlocations\x128\n" +
+	"\x06inputs\x18\x04 \x03(\v2 .moby.buildkit.v1.debugger.InputR\x06inputs\x12E\n" +
+	"\n" +
+	"breakpoint\x18\x05 \x01(\v2%.moby.buildkit.v1.debugger.BreakpointR\n" +
+	"breakpoint\"I\n" +
+	"\bLocation\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\x06ranges\x18\x02 \x03(\v2\t.pb.RangeR\x06ranges\"3\n" +
+	"\x05Input\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"%\n" +
+	"\x0fContinueRequest\x12\x12\n" +
+	"\x04step\x18\x01 \x01(\bR\x04step\"\x12\n" +
+	"\x10ContinueResponse\"\x18\n" +
+	"\x16ListBreakpointsRequest\"b\n" +
+	"\x17ListBreakpointsResponse\x12G\n" +
+	"\vbreakpoints\x18\x01 \x03(\v2%.moby.buildkit.v1.debugger.BreakpointR\vbreakpoints\"`\n" +
+	"\x15SetBreakpointsRequest\x12G\n" +
+	"\vbreakpoints\x18\x01 \x03(\v2%.moby.buildkit.v1.debugger.BreakpointR\vbreakpoints\"\x18\n" +
+	"\x16SetBreakpointsResponse\"c\n" +
+	"\x0eReadDirRequest\x12\x14\n" +
+	"\x05input\x18\x01 \x01(\x03R\x05input\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12'\n" +
+	"\x0finclude_pattern\x18\x03 \x01(\tR\x0eincludePattern\"?\n" +
+	"\x0fReadDirResponse\x12,\n" +
+	"\aentries\x18\x01 \x03(\v2\x12.fsutil.types.StatR\aentries\"o\n" +
+	"\vExecRequest\x12\x14\n" +
+	"\x05input\x18\x01 \x01(\x03R\x05input\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12\x10\n" +
+	"\x03env\x18\x03 \x03(\tR\x03env\x12\x10\n" +
+	"\x03cwd\x18\x04 \x01(\tR\x03cwd\x12\x12\n" +
+	"\x04user\x18\x05 \x01(\tR\x04user\"s\n" +
+	"\fExecResponse\x12\x16\n" +
+	"\x06stdout\x18\x01 \x01(\fR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x02 \x01(\fR\x06stderr\x12\x16\n" +
+	"\x06exited\x18\x03 \x01(\bR\x06exited\x12\x1b\n" +
+	"\texit_code\x18\x04 \x01(\rR\bexitCode2\xf6\x04\n" +
+	"\bDebugger\x12W\n" +
+	"\x04Wait\x12&.moby.buildkit.v1.debugger.WaitRequest\x1a'.moby.buildkit.v1.debugger.WaitResponse\x12c\n" +
+	"\bContinue\x12*.moby.buildkit.v1.debugger.ContinueRequest\x1a+.moby.buildkit.v1.debugger.ContinueResponse\x12x\n" +
+	"\x0fListBreakpoints\x121.moby.buildkit.v1.debugger.ListBreakpointsRequest\x1a2.moby.buildkit.v1.debugger.ListBreakpointsResponse\x12u\n" +
+	"\x0eSetBreakpoints\x120.moby.buildkit.v1.debugger.SetBreakpointsRequest\x1a1.moby.buildkit.v1.debugger.SetBreakpointsResponse\x12`\n" +
+	"\aReadDir\x12).moby.buildkit.v1.debugger.ReadDirRequest\x1a*.moby.buildkit.v1.debugger.ReadDirResponse\x12Y\n" +
+	"\x04Exec\x12&.moby.buildkit.v1.debugger.ExecRequest\x1a'.moby.buildkit.v1.debugger.ExecResponse0\x01B4Z2github.com/moby/buildkit/frontend/gateway/debuggerb\x06proto3"
+
+var (
+	file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescOnce sync.Once
+	file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescData []byte
+)
+
+func file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescGZIP() []byte {
+	file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescOnce.Do(func() {
+		file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDesc), len(file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDesc)))
+	})
+	return file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDescData
+}
+
+var file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_goTypes = []any{
+	(*Breakpoint)(nil),              // 0: moby.buildkit.v1.debugger.Breakpoint
+	(*WaitRequest)(nil),             // 1: moby.buildkit.v1.debugger.WaitRequest
+	(*WaitResponse)(nil),            // 2: moby.buildkit.v1.debugger.WaitResponse
+	(*Stop)(nil),                    // 3: moby.buildkit.v1.debugger.Stop
+	(*Location)(nil),                // 4: moby.buildkit.v1.debugger.Location
+	(*Input)(nil),                   // 5: moby.buildkit.v1.debugger.Input
+	(*ContinueRequest)(nil),         // 6: moby.buildkit.v1.debugger.ContinueRequest
+	(*ContinueResponse)(nil),        // 7: moby.buildkit.v1.debugger.ContinueResponse
+	(*ListBreakpointsRequest)(nil),  // 8: moby.buildkit.v1.debugger.ListBreakpointsRequest
+	(*ListBreakpointsResponse)(nil), // 9: moby.buildkit.v1.debugger.ListBreakpointsResponse
+	(*SetBreakpointsRequest)(nil),   // 10: moby.buildkit.v1.debugger.SetBreakpointsRequest
+	(*SetBreakpointsResponse)(nil),  // 11: moby.buildkit.v1.debugger.SetBreakpointsResponse
+	(*ReadDirRequest)(nil),          // 12: moby.buildkit.v1.debugger.ReadDirRequest
+	(*ReadDirResponse)(nil),         // 13: moby.buildkit.v1.debugger.ReadDirResponse
+	(*ExecRequest)(nil),             // 14: moby.buildkit.v1.debugger.ExecRequest
+	(*ExecResponse)(nil),            // 15: moby.buildkit.v1.debugger.ExecResponse
+	(*pb.Range)(nil),                // 16: pb.Range
+	(*types.Stat)(nil),              // 17: fsutil.types.Stat
+}
+var file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_depIdxs = []int32{
+	3,  // 0: moby.buildkit.v1.debugger.WaitResponse.stop:type_name -> moby.buildkit.v1.debugger.Stop
+	4,  // 1: moby.buildkit.v1.debugger.Stop.locations:type_name -> moby.buildkit.v1.debugger.Location
+	5,  // 2: moby.buildkit.v1.debugger.Stop.inputs:type_name -> moby.buildkit.v1.debugger.Input
+	0,  // 3: moby.buildkit.v1.debugger.Stop.breakpoint:type_name -> moby.buildkit.v1.debugger.Breakpoint
+	16, // 4: moby.buildkit.v1.debugger.Location.ranges:type_name -> pb.Range
+	0,  // 5: moby.buildkit.v1.debugger.ListBreakpointsResponse.breakpoints:type_name -> moby.buildkit.v1.debugger.Breakpoint
+	0,  // 6: moby.buildkit.v1.debugger.SetBreakpointsRequest.breakpoints:type_name -> moby.buildkit.v1.debugger.Breakpoint
+	17, // 7: moby.buildkit.v1.debugger.ReadDirResponse.entries:type_name -> fsutil.types.Stat
+	1,  // 8: moby.buildkit.v1.debugger.Debugger.Wait:input_type -> moby.buildkit.v1.debugger.WaitRequest
+	6,  // 9: moby.buildkit.v1.debugger.Debugger.Continue:input_type -> moby.buildkit.v1.debugger.ContinueRequest
+	8,  // 10: moby.buildkit.v1.debugger.Debugger.ListBreakpoints:input_type -> moby.buildkit.v1.debugger.ListBreakpointsRequest
+	10, // 11: moby.buildkit.v1.debugger.Debugger.SetBreakpoints:input_type -> moby.buildkit.v1.debugger.SetBreakpointsRequest
+	12, // 12: moby.buildkit.v1.debugger.Debugger.ReadDir:input_type -> moby.buildkit.v1.debugger.ReadDirRequest
+	14, // 13: moby.buildkit.v1.debugger.Debugger.Exec:input_type -> moby.buildkit.v1.debugger.ExecRequest
+	2,  // 14: moby.buildkit.v1.debugger.Debugger.Wait:output_type -> moby.buildkit.v1.debugger.WaitResponse
+	7,  // 15: moby.buildkit.v1.debugger.Debugger.Continue:output_type -> moby.buildkit.v1.debugger.ContinueResponse
+	9,  // 16: moby.buildkit.v1.debugger.Debugger.ListBreakpoints:output_type -> moby.buildkit.v1.debugger.ListBreakpointsResponse
+	11, // 17: moby.buildkit.v1.debugger.Debugger.SetBreakpoints:output_type -> moby.buildkit.v1.debugger.SetBreakpointsResponse
+	13, // 18: moby.buildkit.v1.debugger.Debugger.ReadDir:output_type -> moby.buildkit.v1.debugger.ReadDirResponse
+	15, // 19: moby.buildkit.v1.debugger.Debugger.Exec:output_type -> moby.buildkit.v1.debugger.ExecResponse
+	14, // [14:20] is the sub-list for method output_type
+	8,  // [8:14] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_init() }
+func file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_init() {
+	if File_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDesc), len(file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_goTypes,
+		DependencyIndexes: file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_depIdxs,
+		MessageInfos:      file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_msgTypes,
+	}.Build()
+	File_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto = out.File
+	file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_goTypes = nil
+	file_github_com_moby_buildkit_frontend_gateway_debugger_debugger_proto_depIdxs = nil
+}
@@ -0,0 +1,337 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             v3.14.0
+// source: github.com/moby/buildkit/frontend/gateway/debugger/debugger.proto
+
+package debugger
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Debugger_Wait_FullMethodName            = "/moby.buildkit.v1.debugger.Debugger/Wait"
+	Debugger_Continue_FullMethodName        = "/moby.buildkit.v1.debugger.Debugger/Continue"
+	Debugger_ListBreakpoints_FullMethodName = "/moby.buildkit.v1.debugger.Debugger/ListBreakpoints"
+	Debugger_SetBreakpoints_FullMethodName  = "/moby.buildkit.v1.debugger.Debugger/SetBreakpoints"
+	Debugger_ReadDir_FullMethodName         = "/moby.buildkit.v1.debugger.Debugger/ReadDir"
+	Debugger_Exec_FullMethodName            = "/moby.buildkit.v1.debugger.Debugger/Exec"
+)
+
+// DebuggerClient is the client API for Debugger service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Debugger controls a build that is evaluated vertex by vertex by a gateway
+// client. The build stops before its first vertex until it is continued.
+type DebuggerClient interface {
+	// Wait blocks until the build is stopped before a vertex or done.
+	Wait(ctx context.Context, in *WaitRequest, opts ...grpc.CallOption) (*WaitResponse, error)
+	// Continue resumes a stopped build.
+	Continue(ctx context.Context, in *ContinueRequest, opts ...grpc.CallOption) (*ContinueResponse, error)
+	ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error)
+	// SetBreakpoints replaces all breakpoints.
+	SetBreakpoints(ctx context.Context, in *SetBreakpointsRequest, opts ...grpc.CallOption) (*SetBreakpointsResponse, error)
+	// ReadDir lists a directory of an input of the vertex the build is
+	// stopped before.
+	ReadDir(ctx context.Context, in *ReadDirRequest, opts ...grpc.CallOption) (*ReadDirResponse, error)
+	// Exec runs a command in a container with the inputs of the vertex the
+	// build is stopped before. For exec vertices the container has the mounts,
+	// environment, user and working directory of the vertex, for other vertices
+	// the selected input is the root filesystem.
+	Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecResponse], error)
+}
+
+type debuggerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDebuggerClient(cc grpc.ClientConnInterface) DebuggerClient {
+	return &debuggerClient{cc}
+}
+
+func (c *debuggerClient) Wait(ctx context.Context, in *WaitRequest, opts ...grpc.CallOption) (*WaitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitResponse)
+	err := c.cc.Invoke(ctx, Debugger_Wait_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) Continue(ctx context.Context, in *ContinueRequest, opts ...grpc.CallOption) (*ContinueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ContinueResponse)
+	err := c.cc.Invoke(ctx, Debugger_Continue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBreakpointsResponse)
+	err := c.cc.Invoke(ctx, Debugger_ListBreakpoints_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) SetBreakpoints(ctx context.Context, in *SetBreakpointsRequest, opts ...grpc.CallOption) (*SetBreakpointsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetBreakpointsResponse)
+	err := c.cc.Invoke(ctx, Debugger_SetBreakpoints_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) ReadDir(ctx context.Context, in *ReadDirRequest, opts ...grpc.CallOption) (*ReadDirResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadDirResponse)
+	err := c.cc.Invoke(ctx, Debugger_ReadDir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debuggerClient) Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Debugger_ServiceDesc.Streams[0], Debugger_Exec_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecRequest, ExecResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Debugger_ExecClient = grpc.ServerStreamingClient[ExecResponse]
+
+// DebuggerServer is the server API for Debugger service.
+// All implementations should embed UnimplementedDebuggerServer
+// for forward compatibility.
+//
+// Debugger controls a build that is evaluated vertex by vertex by a gateway
+// client. The build stops before its first vertex until it is continued.
+type DebuggerServer interface {
+	// Wait blocks until the build is stopped before a vertex or done.
+	Wait(context.Context, *WaitRequest) (*WaitResponse, error)
+	// Continue resumes a stopped build.
+	Continue(context.Context, *ContinueRequest) (*ContinueResponse, error)
+	ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error)
+	// SetBreakpoints replaces all breakpoints.
+	SetBreakpoints(context.Context, *SetBreakpointsRequest) (*SetBreakpointsResponse, error)
+	// ReadDir lists a directory of an input of the vertex the build is
+	// stopped before.
+	ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error)
+	// Exec runs a command in a container with the inputs of the vertex the
+	// build is stopped before. For exec vertices the container has the mounts,
+	// environment, user and working directory of the vertex, for other vertices
+	// the selected input is the root filesystem.
+	Exec(*ExecRequest, grpc.ServerStreamingServer[ExecResponse]) error
+}
+
+// UnimplementedDebuggerServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDebuggerServer struct{}
+
+func (UnimplementedDebuggerServer) Wait(context.Context, *WaitRequest) (*WaitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Wait not implemented")
+}
+func (UnimplementedDebuggerServer) Continue(context.Context, *ContinueRequest) (*ContinueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Continue not implemented")
+}
+func (UnimplementedDebuggerServer) ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBreakpoints not implemented")
+}
+func (UnimplementedDebuggerServer) SetBreakpoints(context.Context, *SetBreakpointsRequest) (*SetBreakpointsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetBreakpoints not implemented")
+}
+func (UnimplementedDebuggerServer) ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadDir not implemented")
+}
+func (UnimplementedDebuggerServer) Exec(*ExecRequest, grpc.ServerStreamingServer[ExecResponse]) error {
+	return status.Error(codes.Unimplemented, "method Exec not implemented")
+}
+func (UnimplementedDebuggerServer) testEmbeddedByValue() {}
+
+// UnsafeDebuggerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DebuggerServer will
+// result in compilation errors.
+type UnsafeDebuggerServer interface {
+	mustEmbedUnimplementedDebuggerServer()
+}
+
+func RegisterDebuggerServer(s grpc.ServiceRegistrar, srv DebuggerServer) {
+	// If the following call panics, it indicates UnimplementedDebuggerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Debugger_ServiceDesc, srv)
+}
+
+func _Debugger_Wait_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).Wait(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_Wait_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).Wait(ctx, req.(*WaitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_Continue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContinueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).Continue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_Continue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).Continue(ctx, req.(*ContinueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_ListBreakpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBreakpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).ListBreakpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_ListBreakpoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).ListBreakpoints(ctx, req.(*ListBreakpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_SetBreakpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBreakpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).SetBreakpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_SetBreakpoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).SetBreakpoints(ctx, req.(*SetBreakpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_ReadDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebuggerServer).ReadDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debugger_ReadDir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebuggerServer).ReadDir(ctx, req.(*ReadDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debugger_Exec_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DebuggerServer).Exec(m, &grpc.GenericServerStream[ExecRequest, ExecResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Debugger_ExecServer = grpc.ServerStreamingServer[ExecResponse]
+
+// Debugger_ServiceDesc is the grpc.ServiceDesc for Debugger service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Debugger_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "moby.buildkit.v1.debugger.Debugger",
+	HandlerType: (*DebuggerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Wait",
+			Handler:    _Debugger_Wait_Handler,
+		},
+		{
+			MethodName: "Continue",
+			Handler:    _Debugger_Continue_Handler,
+		},
+		{
+			MethodName: "ListBreakpoints",
+			Handler:    _Debugger_ListBreakpoints_Handler,
+		},
+		{
+			MethodName: "SetBreakpoints",
+			Handler:    _Debugger_SetBreakpoints_Handler,
+		},
+		{
+			MethodName: "ReadDir",
+			Handler:    _Debugger_ReadDir_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Exec",
+			Handler:       _Debugger_Exec_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "github.com/moby/buildkit/frontend/gateway/debugger/debugger.proto",
+}
@@ -0,0 +1,200 @@
+package debugger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/moby/buildkit/client/llb"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	fstypes "github.com/tonistiigi/fsutil/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testClient struct {
+	gateway.Client
+	mu     sync.Mutex
+	solved []*pb.Definition
+}
+
+func (c *testClient) Solve(ctx context.Context, req gateway.SolveRequest) (*gateway.Result, error) {
+	c.mu.Lock()
+	c.solved = append(c.solved, req.Definition)
+	c.mu.Unlock()
+	def, err := llb.NewDefinitionOp(req.Definition)
+	if err != nil {
+		return nil, err
+	}
+	res := gateway.NewResult()
+	res.SetRef(&testRef{st: llb.NewState(def)})
+	return res, nil
+}
+
+type testRef struct {
+	gateway.Reference
+	st llb.State
+}
+
+func (r *testRef) ToState() (llb.State, error) {
+	return r.st, nil
+}
+
+func (r *testRef) ReadDir(ctx context.Context, req gateway.ReadDirRequest) ([]*fstypes.Stat, error) {
+	return []*fstypes.Stat{{Path: "foo"}}, nil
+}
+
+func lines(start, end int32) []*pb.Range {
+	return []*pb.Range{{Start: &pb.Position{Line: start}, End: &pb.Position{Line: end}}}
+}
+
+func TestDebugger(t *testing.T) {
+	ctx := context.TODO()
+	sm := llb.NewSourceMap(nil, "Dockerfile", "Dockerfile", []byte("FROM busybox\nRUN echo foo > /foo\nRUN cat /foo\n"))
+	base := llb.Image("docker.io/library/busybox:latest", sm.Location(lines(1, 1)))
+	st := base.Run(llb.Shlex("sh -c 'echo foo > /foo'"), sm.Location(lines(2, 2))).Root()
+	st = st.Run(llb.Shlex("cat /foo"), sm.Location(lines(3, 3))).Root()
+
+	res := gateway.NewResult()
+	res.SetRef(&testRef{st: st})
+
+	c := &testClient{}
+	d := New()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.Run(ctx, c, res)
+	}()
+
+	// stops before the first vertex
+	w, err := d.Wait(ctx, &WaitRequest{})
+	require.NoError(t, err)
+	require.False(t, w.Done)
+	require.NotNil(t, w.Stop)
+	require.Equal(t, "docker-image://docker.io/library/busybox:latest", w.Stop.Name)
+	require.Nil(t, w.Stop.Breakpoint)
+	require.Empty(t, w.Stop.Inputs)
+	require.Equal(t, []*Location{{Filename: "Dockerfile", Ranges: lines(1, 1)}}, w.Stop.Locations)
+
+	_, err = d.ReadDir(ctx, &ReadDirRequest{Input: 0})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = d.SetBreakpoints(ctx, &SetBreakpointsRequest{Breakpoints: []*Breakpoint{{Filename: "Dockerfile", Line: 3}}})
+	require.NoError(t, err)
+	bps, err := d.ListBreakpoints(ctx, &ListBreakpointsRequest{})
+	require.NoError(t, err)
+	require.Len(t, bps.Breakpoints, 1)
+
+	_, err = d.Continue(ctx, &ContinueRequest{})
+	require.NoError(t, err)
+
+	w, err = d.Wait(ctx, &WaitRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, w.Stop)
+	require.Equal(t, "cat /foo", w.Stop.Name)
+	require.Equal(t, int32(3), w.Stop.Breakpoint.GetLine())
+	require.Len(t, w.Stop.Inputs, 1)
+	require.Equal(t, "sh -c echo foo > /foo", w.Stop.Inputs[0].Name)
+
+	// the input of the vertex was solved
+	require.Len(t, c.solved, 1)
+	solved := c.solved[0]
+	require.Len(t, solved.Def, 3)
+	terminal, err := loadDefinition(solved)
+	require.NoError(t, err)
+	require.Equal(t, w.Stop.Inputs[0].Digest, terminal.ops[terminal.terminal].Inputs[0].Digest)
+	require.Len(t, solved.Source.Locations, 2)
+
+	rd, err := d.ReadDir(ctx, &ReadDirRequest{Input: 0, Path: "/"})
+	require.NoError(t, err)
+	require.Equal(t, "foo", rd.Entries[0].Path)
+
+	_, err = d.Continue(ctx, &ContinueRequest{Step: true})
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+	// the result itself is not evaluated
+	require.Len(t, c.solved, 1)
+
+	w, err = d.Wait(ctx, &WaitRequest{})
+	require.NoError(t, err)
+	require.True(t, w.Done)
+	require.Nil(t, w.Stop)
+	require.Empty(t, w.Error)
+
+	_, err = d.Continue(ctx, &ContinueRequest{})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestDebuggerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.TODO())
+	res := gateway.NewResult()
+	res.SetRef(&testRef{st: llb.Image("docker.io/library/busybox:latest")})
+
+	d := New()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.Run(ctx, &testClient{}, res)
+	}()
+
+	w, err := d.Wait(context.TODO(), &WaitRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, w.Stop)
+
+	cancel(errors.New("canceled"))
+	require.ErrorContains(t, <-errCh, "canceled")
+
+	w, err = d.Wait(context.TODO(), &WaitRequest{})
+	require.NoError(t, err)
+	require.True(t, w.Done)
+	require.Nil(t, w.Stop)
+
+	for range 2 {
+		_, err = d.Continue(context.TODO(), &ContinueRequest{})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	}
+	_, err = d.ReadDir(context.TODO(), &ReadDirRequest{})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestBreakpointDigest(t *testing.T) {
+	ctx := context.TODO()
+	st := llb.Image("docker.io/library/busybox:latest").File(llb.Mkdir("/foo", 0o755))
+	def, err := st.Marshal(ctx)
+	require.NoError(t, err)
+
+	g := &graph{}
+	require.NoError(t, g.add(def.ToPB(), map[digest.Digest]struct{}{}))
+	require.Len(t, g.vertices, 2)
+	require.Equal(t, "file", g.vertices[1].name())
+
+	bp := &Breakpoint{Digest: g.vertices[1].dgst.String()}
+	require.False(t, g.vertices[0].matches(bp))
+	require.True(t, g.vertices[1].matches(bp))
+	require.False(t, g.vertices[1].matches(&Breakpoint{Line: 1}))
+
+	_, err = New().SetBreakpoints(ctx, &SetBreakpointsRequest{Breakpoints: []*Breakpoint{{Filename: "Dockerfile"}}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListen(t *testing.T) {
+	_, err := Listen("tcp://127.0.0.1:0")
+	require.ErrorContains(t, err, "only listens on unix sockets")
+	_, err = Dial("tcp://127.0.0.1:1234")
+	require.ErrorContains(t, err, "only listens on unix sockets")
+
+	p := filepath.Join(t.TempDir(), "debug.sock")
+	l, err := Listen("unix://" + p)
+	require.NoError(t, err)
+	defer l.Close()
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(p)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	}
+}
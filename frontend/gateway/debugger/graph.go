@@ -0,0 +1,261 @@
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+type graph struct {
+	// vertices are in the order they are evaluated in, every vertex is
+	// preceded by its inputs
+	vertices []*vertex
+}
+
+type vertex struct {
+	dgst digest.Digest
+	op   *pb.Op
+	def  *definition
+}
+
+type definition struct {
+	*pb.Definition
+	ops      map[digest.Digest]*pb.Op
+	dts      map[digest.Digest][]byte
+	terminal digest.Digest
+}
+
+func loadResult(ctx context.Context, res *gateway.Result) (*graph, error) {
+	var refs []gateway.Reference
+	if res.Ref != nil {
+		refs = append(refs, res.Ref)
+	}
+	for _, k := range slices.Sorted(maps.Keys(res.Refs)) {
+		if res.Refs[k] != nil {
+			refs = append(refs, res.Refs[k])
+		}
+	}
+
+	g := &graph{}
+	seen := map[digest.Digest]struct{}{}
+	for _, ref := range refs {
+		st, err := ref.ToState()
+		if err != nil {
+			return nil, err
+		}
+		def, err := st.Marshal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.add(def.ToPB(), seen); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (g *graph) add(pbDef *pb.Definition, seen map[digest.Digest]struct{}) error {
+	def, err := loadDefinition(pbDef)
+	if err != nil || def == nil {
+		return err
+	}
+	var visit func(dgst digest.Digest) error
+	visit = func(dgst digest.Digest) error {
+		if _, ok := seen[dgst]; ok {
+			return nil
+		}
+		seen[dgst] = struct{}{}
+		op, ok := def.ops[dgst]
+		if !ok {
+			return errors.Errorf("invalid definition: missing vertex %s", dgst)
+		}
+		for _, inp := range op.Inputs {
+			if err := visit(digest.Digest(inp.Digest)); err != nil {
+				return err
+			}
+		}
+		g.vertices = append(g.vertices, &vertex{dgst: dgst, op: op, def: def})
+		return nil
+	}
+	for _, inp := range def.ops[def.terminal].Inputs {
+		if err := visit(digest.Digest(inp.Digest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadDefinition(def *pb.Definition) (*definition, error) {
+	if len(def.Def) == 0 {
+		return nil, nil
+	}
+	d := &definition{
+		Definition: def,
+		ops:        make(map[digest.Digest]*pb.Op, len(def.Def)),
+		dts:        make(map[digest.Digest][]byte, len(def.Def)),
+	}
+	for _, dt := range def.Def {
+		var op pb.Op
+		if err := op.UnmarshalVT(dt); err != nil {
+			return nil, errors.Wrap(err, "failed to parse llb proto op")
+		}
+		dgst := digest.FromBytes(dt)
+		d.ops[dgst] = &op
+		d.dts[dgst] = dt
+		d.terminal = dgst
+	}
+	return d, nil
+}
+
+// inputDefinition returns the definition of the subgraph of d producing inp.
+func (d *definition) inputDefinition(inp *pb.Input) (*pb.Definition, error) {
+	out := &pb.Definition{
+		Metadata: map[string]*pb.OpMetadata{},
+	}
+	var locations map[string]*pb.Locations
+	if d.Source != nil {
+		locations = map[string]*pb.Locations{}
+		out.Source = &pb.Source{
+			Infos:     d.Source.Infos,
+			Locations: locations,
+		}
+	}
+
+	seen := map[digest.Digest]struct{}{}
+	var visit func(dgst digest.Digest) error
+	visit = func(dgst digest.Digest) error {
+		if _, ok := seen[dgst]; ok {
+			return nil
+		}
+		seen[dgst] = struct{}{}
+		op, ok := d.ops[dgst]
+		if !ok {
+			return errors.Errorf("invalid definition: missing vertex %s", dgst)
+		}
+		for _, inp := range op.Inputs {
+			if err := visit(digest.Digest(inp.Digest)); err != nil {
+				return err
+			}
+		}
+		out.Def = append(out.Def, d.dts[dgst])
+		if md, ok := d.Metadata[string(dgst)]; ok {
+			out.Metadata[string(dgst)] = md
+		}
+		if locs, ok := d.sourceLocations(dgst); ok {
+			locations[string(dgst)] = locs
+		}
+		return nil
+	}
+	if err := visit(digest.Digest(inp.Digest)); err != nil {
+		return nil, err
+	}
+
+	terminal := &pb.Op{Inputs: []*pb.Input{{Digest: inp.Digest, Index: inp.Index}}}
+	dt, err := terminal.MarshalVT()
+	if err != nil {
+		return nil, err
+	}
+	out.Def = append(out.Def, dt)
+	if md, ok := d.Metadata[string(d.terminal)]; ok {
+		out.Metadata[string(digest.FromBytes(dt))] = md
+	}
+	return out, nil
+}
+
+func (d *definition) sourceLocations(dgst digest.Digest) (*pb.Locations, bool) {
+	if d.Source == nil {
+		return nil, false
+	}
+	locs, ok := d.Source.Locations[string(dgst)]
+	return locs, ok
+}
+
+func (v *vertex) name() string {
+	if md, ok := v.def.Metadata[string(v.dgst)]; ok {
+		if name, ok := md.Description["llb.customname"]; ok {
+			return name
+		}
+	}
+	switch op := v.op.Op.(type) {
+	case *pb.Op_Exec:
+		return strings.Join(op.Exec.Meta.Args, " ")
+	case *pb.Op_Source:
+		return op.Source.Identifier
+	case *pb.Op_File:
+		return "file"
+	case *pb.Op_Build:
+		return "build"
+	case *pb.Op_Merge:
+		return "merge"
+	case *pb.Op_Diff:
+		return "diff"
+	default:
+		return fmt.Sprintf("%T", op)
+	}
+}
+
+func (v *vertex) locations() []*Location {
+	locs, ok := v.def.sourceLocations(v.dgst)
+	if !ok {
+		return nil
+	}
+	var out []*Location
+	for _, loc := range locs.Locations {
+		if loc.SourceIndex < 0 || int(loc.SourceIndex) >= len(v.def.Source.Infos) {
+			continue
+		}
+		out = append(out, &Location{
+			Filename: v.def.Source.Infos[loc.SourceIndex].Filename,
+			Ranges:   loc.Ranges,
+		})
+	}
+	return out
+}
+
+func (v *vertex) matches(bp *Breakpoint) bool {
+	if bp.Digest != "" {
+		return bp.Digest == v.dgst.String()
+	}
+	for _, loc := range v.locations() {
+		if bp.Filename != "" && bp.Filename != loc.Filename {
+			continue
+		}
+		for _, r := range loc.Ranges {
+			if r.Start == nil {
+				continue
+			}
+			end := r.Start.Line
+			if r.End != nil {
+				end = max(end, r.End.Line)
+			}
+			if r.Start.Line <= bp.Line && bp.Line <= end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *vertex) stop(bp *Breakpoint) *Stop {
+	st := &Stop{
+		Digest:     v.dgst.String(),
+		Name:       v.name(),
+		Locations:  v.locations(),
+		Breakpoint: bp,
+	}
+	for _, inp := range v.op.Inputs {
+		in := &Input{Digest: inp.Digest}
+		if iv, ok := v.def.ops[digest.Digest(inp.Digest)]; ok {
+			in.Name = (&vertex{dgst: digest.Digest(inp.Digest), op: iv, def: v.def}).name()
+		}
+		st.Inputs = append(st.Inputs, in)
+	}
+	return st
+}
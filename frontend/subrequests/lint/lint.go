@@ -18,7 +18,10 @@ import (
 
 type SourceInfoMap func(*pb.SourceInfo) *pb.SourceInfo
 
-const RequestLint = "frontend.lint"
+const (
+	RequestLint    = "frontend.lint"
+	RequestLintFix = "frontend.lint.fix"
+)
 
 var SubrequestLintDefinition = subrequests.Request{
 	Name:        RequestLint,
@@ -33,12 +36,33 @@ var SubrequestLintDefinition = subrequests.Request{
 	},
 }
 
+var SubrequestLintFixDefinition = subrequests.Request{
+	Name:        RequestLintFix,
+	Version:     "1.0.0",
+	Type:        subrequests.TypeRPC,
+	Description: "Lint a Dockerfile and suggest fixes",
+	Opts:        []subrequests.Named{},
+	Metadata: []subrequests.Named{
+		{Name: "result.json"},
+		{Name: "result.txt"},
+		{Name: "result.statuscode"},
+	},
+}
+
 type Warning struct {
 	RuleName    string       `json:"ruleName"`
 	Description string       `json:"description,omitempty"`
 	URL         string       `json:"url,omitempty"`
 	Detail      string       `json:"detail,omitempty"`
 	Location    *pb.Location `json:"location,omitempty"`
+	Fixes       []TextEdit   `json:"fixes,omitempty"`
+}
+
+// TextEdit replaces the text in Range of the warning source with NewText.
+// Lines are 1-based and characters are 0-based byte offsets within the line.
+type TextEdit struct {
+	Range   *pb.Range `json:"range"`
+	NewText string    `json:"newText"`
 }
 
 func (w *Warning) PrintTo(wr io.Writer, sources []*pb.SourceInfo, scb SourceInfoMap) error {
@@ -116,6 +140,92 @@ func (results *LintResults) AddWarning(rulename, description, url, fmtmsg string
 	})
 }
 
+// ApplyFixes returns the data of the source at sourceIndex with the fixes of
+// all its warnings applied and the number of applied fixes. Fixes overlapping
+// an already applied fix are skipped.
+func (results *LintResults) ApplyFixes(sourceIndex int) ([]byte, int, error) {
+	if sourceIndex < 0 || sourceIndex >= len(results.Sources) || results.Sources[sourceIndex] == nil {
+		return nil, 0, errors.Errorf("invalid source index %d", sourceIndex)
+	}
+	var edits []TextEdit
+	for _, w := range results.Warnings {
+		if w.Location != nil && int(w.Location.SourceIndex) == sourceIndex {
+			edits = append(edits, w.Fixes...)
+		}
+	}
+	return ApplyEdits(results.Sources[sourceIndex].Data, edits)
+}
+
+// ApplyEdits applies text edits to dt and returns the result and the number
+// of applied edits. Duplicate edits and edits overlapping an earlier edit are
+// skipped.
+func ApplyEdits(dt []byte, edits []TextEdit) ([]byte, int, error) {
+	lineOffsets := []int{0}
+	for i, b := range dt {
+		if b == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+	offset := func(p *pb.Position) (int, error) {
+		if p == nil || p.Line < 1 || int(p.Line) > len(lineOffsets) {
+			return 0, errors.Errorf("invalid edit position %v", p)
+		}
+		lineStart := lineOffsets[p.Line-1]
+		lineEnd := len(dt)
+		if int(p.Line) < len(lineOffsets) {
+			lineEnd = lineOffsets[p.Line] - 1
+		}
+		if p.Character < 0 || lineStart+int(p.Character) > lineEnd {
+			return 0, errors.Errorf("invalid edit position %d:%d", p.Line, p.Character)
+		}
+		return lineStart + int(p.Character), nil
+	}
+
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(edits))
+	for _, e := range edits {
+		if e.Range == nil {
+			return nil, 0, errors.New("invalid edit without range")
+		}
+		start, err := offset(e.Range.Start)
+		if err != nil {
+			return nil, 0, err
+		}
+		end, err := offset(e.Range.End)
+		if err != nil {
+			return nil, 0, err
+		}
+		if end < start {
+			return nil, 0, errors.Errorf("invalid edit range %d:%d-%d:%d", e.Range.Start.Line, e.Range.Start.Character, e.Range.End.Line, e.Range.End.Character)
+		}
+		spans = append(spans, span{start: start, end: end, text: e.NewText})
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	var out bytes.Buffer
+	var applied int
+	last := 0
+	for i, s := range spans {
+		if i > 0 && s == spans[i-1] {
+			continue
+		}
+		if s.start < last {
+			continue
+		}
+		out.Write(dt[last:s.start])
+		out.WriteString(s.text)
+		last = s.end
+		applied++
+	}
+	out.Write(dt[last:])
+	return out.Bytes(), applied, nil
+}
+
 func (results *LintResults) ToResult(scb SourceInfoMap) (*client.Result, error) {
 	res := client.NewResult()
 	dt, err := json.MarshalIndent(results, "", "  ")
@@ -110,7 +110,8 @@ func recvDiffCopy(ds grpc.ClientStream, dest string, cu CacheUpdater, progress p
 	}))
 }
 
-func syncTargetDiffCopy(ds grpc.ServerStream, dest string, deleteMode bool) error {
+func syncTargetDiffCopy(ds grpc.ServerStream, target fsSyncDirTarget) error {
+	dest := target.outdir
 	if err := os.MkdirAll(dest, 0700); err != nil {
 		return errors.Wrapf(err, "failed to create synctarget dest dir %s", dest)
 	}
@@ -135,11 +136,18 @@ func syncTargetDiffCopy(ds grpc.ServerStream, dest string, deleteMode bool) erro
 	root := fsutil.NewRoot(osRoot)
 	defer root.Close()
 
-	if deleteMode {
+	switch {
+	case target.deleteMode:
 		opt.Merge = false
 		// Request every source file so delete mode mirrors file contents without
 		// relying on fsutil's path-based content comparison.
 		opt.Differ = fsutil.DiffNone
+	case target.syncMode:
+		opt.Merge = false
+		// Only request files whose size, modification time or metadata differ
+		// from the destination. Files with matching metadata were compared by
+		// content before the transfer and removed if they differ.
+		opt.Differ = fsutil.DiffMetadata
 	}
 
 	return errors.WithStack(fsutil.ReceiveRoot(ds.Context(), ds, root, opt))
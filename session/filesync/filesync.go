@@ -31,6 +31,7 @@ const (
 
 	keyExporterID                    = "buildkit-attachable-exporter-id"
 	keyExporterMultiPlatformTransfer = "buildkit-exporter-multi-platform"
+	keySyncContentCheck              = "buildkit-exporter-sync-content-check"
 )
 
 type fsSyncProvider struct {
@@ -258,6 +259,7 @@ type fsSyncTarget struct {
 	id         int
 	outdir     string
 	deleteMode bool
+	syncMode   bool
 	f          FileOutputFunc
 }
 
@@ -287,9 +289,21 @@ func WithFSSyncDirDelete(id int, outdir string) FSSyncTarget {
 	}
 }
 
+// WithFSSyncDirSync returns a target that only receives files that changed
+// compared to the destination directory and removes files that are not part
+// of the result.
+func WithFSSyncDirSync(id int, outdir string) FSSyncTarget {
+	return &fsSyncTarget{
+		id:       id,
+		outdir:   outdir,
+		syncMode: true,
+	}
+}
+
 type fsSyncDirTarget struct {
 	outdir     string
 	deleteMode bool
+	syncMode   bool
 }
 
 func NewFSSyncTarget(targets ...FSSyncTarget) *SyncTarget {
@@ -315,7 +329,7 @@ func (sp *SyncTarget) Add(targets ...FSSyncTarget) {
 			sp.fs[t.id] = t.f
 		}
 		if t.outdir != "" {
-			sp.outdirs[t.id] = fsSyncDirTarget{outdir: t.outdir, deleteMode: t.deleteMode}
+			sp.outdirs[t.id] = fsSyncDirTarget{outdir: t.outdir, deleteMode: t.deleteMode, syncMode: t.syncMode}
 		}
 	}
 }
@@ -346,7 +360,18 @@ func (sp *SyncTarget) DiffCopy(stream FileSend_DiffCopyServer) (err error) {
 		if target.deleteMode && !supportsExporterMultiPlatformTransfer(stream.Context()) {
 			return errors.New("local exporter mode=delete requires a BuildKit daemon with multi-platform local export support")
 		}
-		return syncTargetDiffCopy(stream, target.outdir, target.deleteMode)
+		if target.syncMode && !supportsExporterMultiPlatformTransfer(stream.Context()) {
+			return errors.New("local exporter mode=sync requires a BuildKit daemon with multi-platform local export support")
+		}
+		if target.syncMode {
+			switch v := metadata.ValueFromIncomingContext(stream.Context(), keySyncContentCheck); {
+			case slices.Contains(v, "check"):
+				return syncTargetContentCheck(stream, target.outdir)
+			case !slices.Contains(v, "done"):
+				return errors.New("local exporter mode=sync requires a BuildKit daemon with content comparison support")
+			}
+		}
+		return syncTargetDiffCopy(stream, target)
 	}
 	f, ok := sp.fs[id]
 	if !ok {
@@ -393,9 +418,17 @@ func supportsExporterMultiPlatformTransfer(ctx context.Context) bool {
 }
 
 func CopyToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, progress func(int, bool), copyOpts ...CopyToCallerOpt) error {
+	cc, err := diffCopyToCaller(ctx, id, c, copyOpts)
+	if err != nil {
+		return err
+	}
+	return sendDiffCopy(cc, fs, progress)
+}
+
+func diffCopyToCaller(ctx context.Context, id int, c session.Caller, copyOpts []CopyToCallerOpt) (FileSend_DiffCopyClient, error) {
 	method := session.MethodURL(FileSend_ServiceDesc.ServiceName, "diffcopy")
 	if !c.Supports(method) {
-		return errors.Errorf("method %s not supported by the client", method)
+		return nil, errors.Errorf("method %s not supported by the client", method)
 	}
 
 	ctx = c.Context(ctx)
@@ -417,10 +450,9 @@ func CopyToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, p
 
 	cc, err := client.DiffCopy(ctx)
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
-
-	return sendDiffCopy(cc, fs, progress)
+	return cc, nil
 }
 
 func CopyFileWriter(ctx context.Context, md map[string]string, id int, c session.Caller) (io.WriteCloser, error) {
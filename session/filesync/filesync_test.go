@@ -85,6 +85,107 @@ func TestFileSyncIncludePatterns(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestFileSyncTargetSyncMode(t *testing.T) {
+	ctx := t.Context()
+	t.Parallel()
+
+	srcDir := t.TempDir()
+	srcFS, err := fsutil.NewFS(srcDir)
+	require.NoError(t, err)
+	destDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "unchanged"), []byte("content1"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "changed"), []byte("content2"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "stale"), []byte("old"), 0600))
+
+	s, err := session.NewSession(ctx, "foo")
+	require.NoError(t, err)
+
+	m, err := session.NewManager()
+	require.NoError(t, err)
+
+	s.Allow(NewFSSyncTarget(WithFSSyncDirSync(1, destDir)))
+
+	dialer := session.Dialer(testutil.TestStream(testutil.Handler(m.HandleConn)))
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return s.Run(ctx, dialer)
+	})
+
+	g.Go(func() (reterr error) {
+		defer func() {
+			err := s.Close()
+			if reterr == nil {
+				reterr = err
+			}
+		}()
+
+		c, err := m.Get(ctx, s.ID(), false)
+		if err != nil {
+			return err
+		}
+		if err := SyncToCaller(ctx, srcFS, 1, c, nil, WithExporterMultiPlatformTransfer()); err != nil {
+			return err
+		}
+
+		if _, err := os.Stat(filepath.Join(destDir, "stale")); !errors.Is(err, os.ErrNotExist) {
+			return errors.Errorf("expected stale file to be removed: %v", err)
+		}
+		fi1, err := os.Stat(filepath.Join(destDir, "unchanged"))
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(srcDir, "changed"), []byte("content2-updated"), 0600); err != nil {
+			return err
+		}
+		if err := SyncToCaller(ctx, srcFS, 1, c, nil, WithExporterMultiPlatformTransfer()); err != nil {
+			return err
+		}
+
+		dt, err := os.ReadFile(filepath.Join(destDir, "changed"))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "content2-updated", string(dt))
+
+		fi2, err := os.Stat(filepath.Join(destDir, "unchanged"))
+		if err != nil {
+			return err
+		}
+		assert.True(t, os.SameFile(fi1, fi2), "unchanged file was rewritten")
+
+		// same size and modification time, different content
+		if err := os.WriteFile(filepath.Join(srcDir, "unchanged"), []byte("content9"), 0600); err != nil {
+			return err
+		}
+		if err := os.Chtimes(filepath.Join(srcDir, "unchanged"), fi2.ModTime(), fi2.ModTime()); err != nil {
+			return err
+		}
+		if err := SyncToCaller(ctx, srcFS, 1, c, nil, WithExporterMultiPlatformTransfer()); err != nil {
+			return err
+		}
+
+		dt, err = os.ReadFile(filepath.Join(destDir, "unchanged"))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "content9", string(dt))
+
+		fi3, err := os.Lstat(filepath.Join(destDir, "unchanged"))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, fi2.ModTime(), fi3.ModTime())
+		return nil
+	})
+
+	err = g.Wait()
+	require.NoError(t, err)
+}
+
 func TestLocalExporterModeDeleteRequiresDaemonSupport(t *testing.T) {
 	destDir := t.TempDir()
 	staleFile := filepath.Join(destDir, "stale")
@@ -101,6 +202,27 @@ func TestLocalExporterModeDeleteRequiresDaemonSupport(t *testing.T) {
 	assert.Equal(t, "old", string(dt))
 }
 
+func TestLocalExporterModeSyncRequiresDaemonSupport(t *testing.T) {
+	destDir := t.TempDir()
+	staleFile := filepath.Join(destDir, "stale")
+	require.NoError(t, os.WriteFile(staleFile, []byte("old"), 0600))
+
+	target := NewFSSyncTarget(WithFSSyncDirSync(1, destDir))
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(keyExporterID, "1"))
+
+	err := target.DiffCopy(&testFileSendStream{ctx: ctx})
+	require.ErrorContains(t, err, "local exporter mode=sync requires a BuildKit daemon")
+
+	// the transfer is rejected if the daemon did not compare the contents first
+	ctx = metadata.NewIncomingContext(t.Context(), metadata.Pairs(keyExporterID, "1", keyExporterMultiPlatformTransfer, "1"))
+	err = target.DiffCopy(&testFileSendStream{ctx: ctx})
+	require.ErrorContains(t, err, "local exporter mode=sync requires a BuildKit daemon with content comparison support")
+
+	dt, err := os.ReadFile(staleFile)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(dt))
+}
+
 type testFileSendStream struct {
 	ctx context.Context
 }
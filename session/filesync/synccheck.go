@@ -0,0 +1,217 @@
+package filesync
+
+import (
+	"context"
+	"io"
+	gofs "io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/buildkit/session"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/fsutil"
+	fstypes "github.com/tonistiigi/fsutil/types"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// SyncToCaller sends fs to a sync target of the caller. The fsutil transfer
+// only requests files whose metadata differs from the destination, so the
+// content of files with the same size and modification time is compared first
+// and the destination files that differ are removed, which makes the transfer
+// send them again. Only these files are read to compute their digests.
+func SyncToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, progress func(int, bool), copyOpts ...CopyToCallerOpt) error {
+	cc, err := diffCopyToCaller(ctx, id, c, append(copyOpts, withSyncContentCheck("check")))
+	if err != nil {
+		return err
+	}
+	if err := sendSyncContentCheck(cc, fs); err != nil {
+		return err
+	}
+	return CopyToCaller(ctx, fs, id, c, progress, append(copyOpts, withSyncContentCheck("done"))...)
+}
+
+func withSyncContentCheck(v string) CopyToCallerOpt {
+	return func(opts metadata.MD) {
+		opts.Set(keySyncContentCheck, v)
+	}
+}
+
+// sendSyncContentCheck sends the stats of the regular files in fs and replies
+// to the requests for their digests until the target finishes the check.
+func sendSyncContentCheck(cc grpc.ClientStream, fs fsutil.FS) error {
+	ctx := cc.Context()
+	var files []string
+	if err := fs.Walk(ctx, "", func(p string, entry gofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		st, ok := fi.Sys().(*fstypes.Stat)
+		if !ok || !os.FileMode(st.Mode).IsRegular() || st.Linkname != "" {
+			return nil
+		}
+		if err := cc.SendMsg(&fstypes.Packet{
+			Type: fstypes.Packet_PACKET_STAT,
+			ID:   uint32(len(files)),
+			Stat: &fstypes.Stat{Path: p, Mode: st.Mode, Size: st.Size, ModTime: st.ModTime},
+		}); err != nil {
+			return errors.WithStack(err)
+		}
+		files = append(files, p)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := cc.SendMsg(&fstypes.Packet{Type: fstypes.Packet_PACKET_FIN}); err != nil {
+		return errors.WithStack(err)
+	}
+	for {
+		var p fstypes.Packet
+		if err := cc.RecvMsg(&p); err != nil {
+			return errors.WithStack(err)
+		}
+		switch p.Type {
+		case fstypes.Packet_PACKET_REQ:
+			if int(p.ID) >= len(files) {
+				return errors.Errorf("invalid file request %d", p.ID)
+			}
+			dgst, err := digestFile(fs, files[p.ID])
+			if err != nil {
+				return err
+			}
+			if err := cc.SendMsg(&fstypes.Packet{Type: fstypes.Packet_PACKET_DATA, ID: p.ID, Data: []byte(dgst)}); err != nil {
+				return errors.WithStack(err)
+			}
+		case fstypes.Packet_PACKET_FIN:
+			if err := cc.SendMsg(&fstypes.Packet{Type: fstypes.Packet_PACKET_FIN}); err != nil {
+				return errors.WithStack(err)
+			}
+			if err := cc.CloseSend(); err != nil {
+				return errors.WithStack(err)
+			}
+			// block until the target is done
+			if err := cc.RecvMsg(&p); !errors.Is(err, io.EOF) {
+				return errors.WithStack(err)
+			}
+			return nil
+		default:
+			return errors.Errorf("unexpected packet %s", p.Type)
+		}
+	}
+}
+
+func digestFile(fs fsutil.FS, p string) (digest.Digest, error) {
+	rc, err := fs.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	dgst, err := digest.FromReader(rc)
+	return dgst, errors.Wrapf(err, "failed to digest %s", p)
+}
+
+// syncTargetContentCheck receives the stats sent by sendSyncContentCheck,
+// requests the digests of the files that have the same size and modification
+// time as the destination files and removes the destination files whose
+// content differs.
+func syncTargetContentCheck(ds grpc.ServerStream, dest string) error {
+	if err := os.MkdirAll(dest, 0700); err != nil {
+		return errors.Wrapf(err, "failed to create synctarget dest dir %s", dest)
+	}
+	root, err := os.OpenRoot(dest)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open synctarget dest root %s", dest)
+	}
+	defer root.Close()
+
+	candidates := map[uint32]*fstypes.Stat{}
+	for {
+		var p fstypes.Packet
+		if err := ds.RecvMsg(&p); err != nil {
+			return errors.WithStack(err)
+		}
+		if p.Type == fstypes.Packet_PACKET_FIN {
+			break
+		}
+		if p.Type != fstypes.Packet_PACKET_STAT || p.Stat == nil {
+			return errors.Errorf("unexpected packet %s", p.Type)
+		}
+		if sameMetadata(root, p.Stat) {
+			candidates[p.ID] = p.Stat
+		}
+	}
+
+	var eg errgroup.Group
+	eg.Go(func() error {
+		for id := range candidates {
+			if err := ds.SendMsg(&fstypes.Packet{Type: fstypes.Packet_PACKET_REQ, ID: id}); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return errors.WithStack(ds.SendMsg(&fstypes.Packet{Type: fstypes.Packet_PACKET_FIN}))
+	})
+	eg.Go(func() error {
+		for {
+			var p fstypes.Packet
+			if err := ds.RecvMsg(&p); err != nil {
+				return errors.WithStack(err)
+			}
+			switch p.Type {
+			case fstypes.Packet_PACKET_DATA:
+				st, ok := candidates[p.ID]
+				if !ok {
+					return errors.Errorf("unexpected digest for file %d", p.ID)
+				}
+				dgst, err := digest.Parse(string(p.Data))
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				p := filepath.FromSlash(st.Path)
+				if same, err := sameContent(root, p, dgst); err != nil {
+					return err
+				} else if !same {
+					if err := root.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+						return errors.WithStack(err)
+					}
+				}
+			case fstypes.Packet_PACKET_FIN:
+				return nil
+			default:
+				return errors.Errorf("unexpected packet %s", p.Type)
+			}
+		}
+	})
+	return eg.Wait()
+}
+
+// sameMetadata returns true if the destination file has the size and
+// modification time of st, so the fsutil transfer would not request it.
+func sameMetadata(root *os.Root, st *fstypes.Stat) bool {
+	fi, err := root.Lstat(filepath.FromSlash(st.Path))
+	if err != nil {
+		return false
+	}
+	return fi.Mode().IsRegular() && fi.Size() == st.Size && fi.ModTime().UnixNano() == st.ModTime
+}
+
+func sameContent(root *os.Root, p string, dgst digest.Digest) (bool, error) {
+	f, err := root.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	defer f.Close()
+	v := dgst.Verifier()
+	if _, err := io.Copy(v, f); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return v.Verified(), nil
+}
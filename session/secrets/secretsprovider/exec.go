@@ -0,0 +1,99 @@
+package secretsprovider
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/units"
+)
+
+// DefaultExecTimeout is the default timeout of a secret helper command.
+const DefaultExecTimeout = 30 * time.Second
+
+// maxHelperErrorSize limits the stderr output of a failed helper included in
+// the error.
+const maxHelperErrorSize = 4 * 1024
+
+// execSecret runs the helper command of a secret when it is first requested
+// and keeps the value in memory for the lifetime of the store.
+type execSecret struct {
+	src Source
+
+	mu   sync.Mutex
+	dt   []byte
+	done bool
+}
+
+func (es *execSecret) get(ctx context.Context) ([]byte, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if es.done {
+		return es.dt, nil
+	}
+	// failures are not cached so that a later request can retry
+	dt, err := runSecretHelper(ctx, es.src)
+	if err != nil {
+		return nil, err
+	}
+	es.dt = dt
+	es.done = true
+	return dt, nil
+}
+
+func runSecretHelper(ctx context.Context, src Source) ([]byte, error) {
+	timeout := src.Timeout
+	if timeout == 0 {
+		timeout = DefaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, errors.WithStack(context.DeadlineExceeded))
+	defer cancel()
+
+	var stdout, stderr limitedBuffer
+	stdout.max = MaxSecretSize
+	stderr.max = maxHelperErrorSize
+
+	cmd := exec.CommandContext(ctx, src.Cmd, src.Args...)
+	cmd.Env = append(os.Environ(), "BUILDKIT_SECRET_ID="+src.ID)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// don't wait for processes started by the helper that keep the output
+	// open after it has been killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.Errorf("secret %s helper %s timed out after %s", src.ID, src.Cmd, timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.Wrapf(err, "secret %s helper %s failed: %s", src.ID, src.Cmd, msg)
+		}
+		return nil, errors.Wrapf(err, "secret %s helper %s failed", src.ID, src.Cmd)
+	}
+	if stdout.overflow {
+		return nil, errors.Errorf("secret %s too big. max size %#.f", src.ID, MaxSecretSize*units.B)
+	}
+	return stdout.Bytes(), nil
+}
+
+// limitedBuffer is a buffer that discards writes after max bytes.
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(dt []byte) (int, error) {
+	n := len(dt)
+	if left := b.max - b.Len(); len(dt) > left {
+		dt = dt[:left]
+		b.overflow = true
+	}
+	b.Buffer.Write(dt)
+	return n, nil
+}
@@ -3,6 +3,8 @@ package secretsprovider
 import (
 	"context"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/moby/buildkit/session/secrets"
 	"github.com/pkg/errors"
@@ -13,6 +15,12 @@ type Source struct {
 	ID       string
 	FilePath string
 	Env      string
+	// Cmd is the helper command printing the secret value to stdout. The
+	// command is run the first time the secret is requested.
+	Cmd  string
+	Args []string
+	// Timeout of the helper command. Defaults to DefaultExecTimeout.
+	Timeout time.Duration
 }
 
 func NewStore(files []Source) (secrets.SecretStore, error) {
@@ -21,6 +29,13 @@ func NewStore(files []Source) (secrets.SecretStore, error) {
 		if f.ID == "" {
 			return nil, errors.Errorf("secret missing ID")
 		}
+		if f.Cmd != "" {
+			if f.Env != "" || f.FilePath != "" {
+				return nil, errors.Errorf("secret %s can't set both a command and a source", f.ID)
+			}
+			m[f.ID] = f
+			continue
+		}
 		if f.Env == "" && f.FilePath == "" {
 			if _, ok := os.LookupEnv(f.ID); ok {
 				f.Env = f.ID
@@ -40,12 +55,16 @@ func NewStore(files []Source) (secrets.SecretStore, error) {
 		m[f.ID] = f
 	}
 	return &fileStore{
-		m: m,
+		m:    m,
+		exec: map[string]*execSecret{},
 	}, nil
 }
 
 type fileStore struct {
 	m map[string]Source
+
+	mu   sync.Mutex
+	exec map[string]*execSecret
 }
 
 func (fs *fileStore) GetSecret(ctx context.Context, id string) ([]byte, error) {
@@ -53,6 +72,16 @@ func (fs *fileStore) GetSecret(ctx context.Context, id string) ([]byte, error) {
 	if !ok {
 		return nil, errors.WithStack(secrets.ErrNotFound)
 	}
+	if v.Cmd != "" {
+		fs.mu.Lock()
+		es, ok := fs.exec[id]
+		if !ok {
+			es = &execSecret{src: v}
+			fs.exec[id] = es
+		}
+		fs.mu.Unlock()
+		return es.get(ctx)
+	}
 	if v.Env != "" {
 		return []byte(os.Getenv(v.Env)), nil
 	}
@@ -0,0 +1,102 @@
+package secretsprovider
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHelper writes a stub secret helper that appends a line to a counter
+// file every time it is started and runs script.
+func writeHelper(t *testing.T, script string) (string, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("secret helper stub requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "count")
+	helper := filepath.Join(dir, "helper.sh")
+	err := os.WriteFile(helper, []byte("#!/bin/sh\necho run >> "+counter+"\n"+script+"\n"), 0700)
+	require.NoError(t, err)
+	return helper, counter
+}
+
+func runCount(t *testing.T, counter string) int {
+	dt, err := os.ReadFile(counter)
+	require.NoError(t, err)
+	return strings.Count(string(dt), "run")
+}
+
+func TestExecSecret(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	helper, counter := writeHelper(t, `printf '%s-%s-%s' "$BUILDKIT_SECRET_ID" "$1" "$2"`)
+
+	store, err := NewStore([]Source{{
+		ID:   "token",
+		Cmd:  helper,
+		Args: []string{"a", "b"},
+	}})
+	require.NoError(t, err)
+
+	// helper is only run when the secret is requested
+	_, err = os.Stat(counter)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	for range 3 {
+		dt, err := store.GetSecret(ctx, "token")
+		require.NoError(t, err)
+		require.Equal(t, "token-a-b", string(dt))
+	}
+	require.Equal(t, 1, runCount(t, counter))
+
+	_, err = store.GetSecret(ctx, "other")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+}
+
+func TestExecSecretError(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	helper, counter := writeHelper(t, "echo access denied >&2\nexit 3")
+
+	store, err := NewStore([]Source{{ID: "token", Cmd: helper}})
+	require.NoError(t, err)
+
+	_, err = store.GetSecret(ctx, "token")
+	require.ErrorContains(t, err, "secret token helper")
+	require.ErrorContains(t, err, "access denied")
+
+	// failures are retried
+	_, err = store.GetSecret(ctx, "token")
+	require.Error(t, err)
+	require.Equal(t, 2, runCount(t, counter))
+}
+
+func TestExecSecretTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	helper, _ := writeHelper(t, "exec sleep 10")
+
+	store, err := NewStore([]Source{{ID: "token", Cmd: helper, Timeout: 100 * time.Millisecond}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = store.GetSecret(ctx, "token")
+	require.ErrorContains(t, err, "timed out after 100ms")
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestExecSecretInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStore([]Source{{ID: "token", Cmd: "helper", FilePath: "/tmp/token"}})
+	require.ErrorContains(t, err, "can't set both a command and a source")
+}
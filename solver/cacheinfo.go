@@ -0,0 +1,118 @@
+package solver
+
+import (
+	"slices"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// VertexCacheInfo describes the cache keys computed for a vertex of a job.
+type VertexCacheInfo struct {
+	// Digest is the digest of the vertex in the LLB definition.
+	Digest      digest.Digest `json:"digest"`
+	Name        string        `json:"name,omitempty"`
+	IgnoreCache bool          `json:"ignoreCache,omitempty"`
+	// CacheMaps are the digests of the cache maps returned by the op.
+	CacheMaps []digest.Digest `json:"cacheMaps,omitempty"`
+	// Deps describe the inputs of the last cache map.
+	Deps []DepCacheInfo `json:"deps,omitempty"`
+	// Cached is true if the result of the vertex was loaded from cache.
+	Cached bool `json:"cached,omitempty"`
+	// CacheMiss is set if the vertex was executed.
+	CacheMiss *CacheMiss `json:"cacheMiss,omitempty"`
+}
+
+// CacheMissReason describes why a vertex could not be loaded from cache.
+type CacheMissReason string
+
+const (
+	// CacheMissNoMatch means no cache key matched the vertex.
+	CacheMissNoMatch CacheMissReason = "no-match"
+	// CacheMissNotImported means no cache key matched and none of the cache
+	// imports of the build contained the vertex.
+	CacheMissNotImported CacheMissReason = "not-imported"
+	// CacheMissPruned means a cache key matched but its result has been
+	// pruned.
+	CacheMissPruned CacheMissReason = "pruned"
+	// CacheMissExporterOnly means a cache key only matched in cache imports
+	// that don't contain the result of the vertex, e.g. a cache exported with
+	// mode=min that only includes the results of the exported build result.
+	CacheMissExporterOnly CacheMissReason = "exporter-only"
+	// CacheMissLoadFailed means a cached result was found but could not be
+	// loaded.
+	CacheMissLoadFailed CacheMissReason = "load-failed"
+	// CacheMissIgnoreCache means the vertex was set to ignore cache.
+	CacheMissIgnoreCache CacheMissReason = "ignore-cache"
+)
+
+// CacheMiss describes why a vertex was executed instead of being loaded from
+// cache.
+type CacheMiss struct {
+	Reason CacheMissReason `json:"reason"`
+	// Error is the error from loading the cached result, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// DepCacheInfo describes how an input contributes to the cache key of a
+// vertex.
+type DepCacheInfo struct {
+	Selector digest.Digest `json:"selector,omitempty"`
+	// ContentDigest is the content based cache key of the input, if the op
+	// computed one.
+	ContentDigest digest.Digest `json:"contentDigest,omitempty"`
+}
+
+// CacheInfo returns the cache keys computed for the vertices loaded by the
+// job, ordered by vertex digest.
+func (j *Job) CacheInfo() []VertexCacheInfo {
+	j.list.mu.RLock()
+	defer j.list.mu.RUnlock()
+
+	var out []VertexCacheInfo
+	for _, st := range j.list.actives {
+		if _, ok := st.jobs[j]; !ok {
+			continue
+		}
+		info := VertexCacheInfo{
+			Digest:      st.origDigest,
+			Name:        st.vtx.Name(),
+			IgnoreCache: st.vtx.Options().IgnoreCache,
+		}
+		st.mu.RLock()
+		op := st.op
+		st.mu.RUnlock()
+		if op != nil {
+			op.cacheInfo(&info)
+		}
+		out = append(out, info)
+	}
+	slices.SortFunc(out, func(a, b VertexCacheInfo) int {
+		return strings.Compare(string(a.Digest), string(b.Digest))
+	})
+	return out
+}
+
+func (s *sharedOp) cacheInfo(info *VertexCacheInfo) {
+	s.missMu.Lock()
+	info.Cached = s.cached
+	info.CacheMiss = s.cacheMiss
+	s.missMu.Unlock()
+
+	s.slowMu.Lock()
+	defer s.slowMu.Unlock()
+	for _, cm := range s.cacheRes {
+		info.CacheMaps = append(info.CacheMaps, cm.Digest)
+	}
+	if len(s.cacheRes) == 0 {
+		return
+	}
+	cm := s.cacheRes[len(s.cacheRes)-1]
+	info.Deps = make([]DepCacheInfo, len(cm.Deps))
+	for i, dep := range cm.Deps {
+		info.Deps[i] = DepCacheInfo{
+			Selector:      dep.Selector,
+			ContentDigest: s.slowCacheRes[Index(i)],
+		}
+	}
+}
@@ -43,6 +43,8 @@ type CacheKey struct {
 	vtx    digest.Digest
 	output Index
 	ids    map[*cacheManager]string
+	// queriedFrom is the cache manager that returned the key from a query
+	queriedFrom *cacheManager
 
 	indexIDs []string
 }
@@ -101,6 +103,8 @@ func (ck *CacheKey) clone() *CacheKey {
 		vtx:    ck.vtx,
 		output: ck.output,
 		ids:    make(map[*cacheManager]string, len(ck.ids)),
+
+		queriedFrom: ck.queriedFrom,
 	}
 	maps.Copy(nk.ids, ck.ids)
 	ck.mu.RUnlock()
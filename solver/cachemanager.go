@@ -358,6 +358,7 @@ func (c *cacheManager) newKeyWithID(id string, dgst digest.Digest, output Index)
 	k.output = output
 	k.ID = id
 	k.ids[c] = id
+	k.queriedFrom = c
 	return k
 }
 
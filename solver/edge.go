@@ -55,6 +55,7 @@ type edge struct {
 	err                error
 	cacheRecords       map[string]*CacheRecord
 	cacheRecordsLoaded map[string]struct{}
+	loadCacheErr       error
 	keyMap             map[string]struct{}
 
 	noCacheMatchPossible      bool
@@ -625,6 +626,9 @@ func (e *edge) processExecReq() {
 			for k := range e.cacheRecordsLoaded {
 				delete(e.cacheRecords, k)
 			}
+			if !upt.Status().Canceled {
+				e.loadCacheErr = err
+			}
 		} else if !upt.Status().Canceled && e.err == nil {
 			e.err = err
 		}
@@ -923,11 +927,34 @@ func (e *edge) execIfPossible(f *pipeFactory) bool {
 		}
 		e.execReq = f.NewFuncRequest(e.execOp)
 		e.execCacheLoad = false
+		e.op.SetCacheMiss(e.cacheMiss())
 		return true
 	}
 	return false
 }
 
+// cacheMiss returns why the result of the edge could not be loaded from cache
+func (e *edge) cacheMiss() CacheMiss {
+	switch {
+	case e.op.IgnoreCache():
+		return CacheMiss{Reason: CacheMissIgnoreCache}
+	case len(e.cacheRecordsLoaded) > 0:
+		var msg string
+		if e.loadCacheErr != nil {
+			msg = e.loadCacheErr.Error()
+		}
+		return CacheMiss{Reason: CacheMissLoadFailed, Error: msg}
+	case len(e.keys) > 0 && !e.op.MatchedMainCache(e.keys):
+		return CacheMiss{Reason: CacheMissExporterOnly}
+	case len(e.keys) > 0:
+		return CacheMiss{Reason: CacheMissPruned}
+	case e.op.HasCacheImports():
+		return CacheMiss{Reason: CacheMissNotImported}
+	default:
+		return CacheMiss{Reason: CacheMissNoMatch}
+	}
+}
+
 // postpone delays exec to next unpark invocation if we have unprocessed keys
 func (e *edge) postpone(f *pipeFactory) {
 	f.NewFuncRequest(func(context.Context) (any, error) {
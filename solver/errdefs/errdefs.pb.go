@@ -514,6 +514,61 @@ func (x *ContentCache) GetIndex() int64 {
 	return 0
 }
 
+type Timeout struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Timeout of the vertex in nanoseconds. Unset if the solve deadline was
+	// exceeded instead.
+	Timeout int64 `protobuf:"varint,1,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// Deadline is set if the solve deadline was exceeded.
+	Deadline      bool `protobuf:"varint,2,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Timeout) Reset() {
+	*x = Timeout{}
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Timeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timeout) ProtoMessage() {}
+
+func (x *Timeout) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timeout.ProtoReflect.Descriptor instead.
+func (*Timeout) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Timeout) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *Timeout) GetDeadline() bool {
+	if x != nil {
+		return x.Deadline
+	}
+	return false
+}
+
 type ProvenanceMaterialsIncomplete struct {
 	state         protoimpl.MessageState          `protogen:"open.v1"`
 	Incomplete    []*ProvenanceMaterialIncomplete `protobuf:"bytes,1,rep,name=incomplete,proto3" json:"incomplete,omitempty"`
@@ -523,7 +578,7 @@ type ProvenanceMaterialsIncomplete struct {
 
 func (x *ProvenanceMaterialsIncomplete) Reset() {
 	*x = ProvenanceMaterialsIncomplete{}
-	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -535,7 +590,7 @@ func (x *ProvenanceMaterialsIncomplete) String() string {
 func (*ProvenanceMaterialsIncomplete) ProtoMessage() {}
 
 func (x *ProvenanceMaterialsIncomplete) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -548,7 +603,7 @@ func (x *ProvenanceMaterialsIncomplete) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProvenanceMaterialsIncomplete.ProtoReflect.Descriptor instead.
 func (*ProvenanceMaterialsIncomplete) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{9}
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *ProvenanceMaterialsIncomplete) GetIncomplete() []*ProvenanceMaterialIncomplete {
@@ -572,7 +627,7 @@ type ProvenanceMaterialIncomplete struct {
 
 func (x *ProvenanceMaterialIncomplete) Reset() {
 	*x = ProvenanceMaterialIncomplete{}
-	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -584,7 +639,7 @@ func (x *ProvenanceMaterialIncomplete) String() string {
 func (*ProvenanceMaterialIncomplete) ProtoMessage() {}
 
 func (x *ProvenanceMaterialIncomplete) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -597,7 +652,7 @@ func (x *ProvenanceMaterialIncomplete) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProvenanceMaterialIncomplete.ProtoReflect.Descriptor instead.
 func (*ProvenanceMaterialIncomplete) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{10}
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ProvenanceMaterialIncomplete) GetOp() string {
@@ -678,7 +733,10 @@ const file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc = "" +
 	"FileAction\x12\x14\n" +
 	"\x05index\x18\x01 \x01(\x03R\x05index\"$\n" +
 	"\fContentCache\x12\x14\n" +
-	"\x05index\x18\x01 \x01(\x03R\x05index\"f\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\"?\n" +
+	"\aTimeout\x12\x18\n" +
+	"\atimeout\x18\x01 \x01(\x03R\atimeout\x12\x1a\n" +
+	"\bdeadline\x18\x02 \x01(\bR\bdeadline\"f\n" +
 	"\x1dProvenanceMaterialsIncomplete\x12E\n" +
 	"\n" +
 	"incomplete\x18\x01 \x03(\v2%.errdefs.ProvenanceMaterialIncompleteR\n" +
@@ -703,7 +761,7 @@ func file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP() []
 	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescData
 }
 
-var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_goTypes = []any{
 	(*Vertex)(nil),                        // 0: errdefs.Vertex
 	(*Source)(nil),                        // 1: errdefs.Source
@@ -714,21 +772,22 @@ var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_goTypes = []any{
 	(*Solve)(nil),                         // 6: errdefs.Solve
 	(*FileAction)(nil),                    // 7: errdefs.FileAction
 	(*ContentCache)(nil),                  // 8: errdefs.ContentCache
-	(*ProvenanceMaterialsIncomplete)(nil), // 9: errdefs.ProvenanceMaterialsIncomplete
-	(*ProvenanceMaterialIncomplete)(nil),  // 10: errdefs.ProvenanceMaterialIncomplete
-	nil,                                   // 11: errdefs.Solve.DescriptionEntry
-	(*pb.SourceInfo)(nil),                 // 12: pb.SourceInfo
-	(*pb.Range)(nil),                      // 13: pb.Range
-	(*pb.Op)(nil),                         // 14: pb.Op
+	(*Timeout)(nil),                       // 9: errdefs.Timeout
+	(*ProvenanceMaterialsIncomplete)(nil), // 10: errdefs.ProvenanceMaterialsIncomplete
+	(*ProvenanceMaterialIncomplete)(nil),  // 11: errdefs.ProvenanceMaterialIncomplete
+	nil,                                   // 12: errdefs.Solve.DescriptionEntry
+	(*pb.SourceInfo)(nil),                 // 13: pb.SourceInfo
+	(*pb.Range)(nil),                      // 14: pb.Range
+	(*pb.Op)(nil),                         // 15: pb.Op
 }
 var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_depIdxs = []int32{
-	12, // 0: errdefs.Source.info:type_name -> pb.SourceInfo
-	13, // 1: errdefs.Source.ranges:type_name -> pb.Range
-	14, // 2: errdefs.Solve.op:type_name -> pb.Op
+	13, // 0: errdefs.Source.info:type_name -> pb.SourceInfo
+	14, // 1: errdefs.Source.ranges:type_name -> pb.Range
+	15, // 2: errdefs.Solve.op:type_name -> pb.Op
 	7,  // 3: errdefs.Solve.file:type_name -> errdefs.FileAction
 	8,  // 4: errdefs.Solve.cache:type_name -> errdefs.ContentCache
-	11, // 5: errdefs.Solve.description:type_name -> errdefs.Solve.DescriptionEntry
-	10, // 6: errdefs.ProvenanceMaterialsIncomplete.incomplete:type_name -> errdefs.ProvenanceMaterialIncomplete
+	12, // 5: errdefs.Solve.description:type_name -> errdefs.Solve.DescriptionEntry
+	11, // 6: errdefs.ProvenanceMaterialsIncomplete.incomplete:type_name -> errdefs.ProvenanceMaterialIncomplete
 	7,  // [7:7] is the sub-list for method output_type
 	7,  // [7:7] is the sub-list for method input_type
 	7,  // [7:7] is the sub-list for extension type_name
@@ -751,7 +810,7 @@ func file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc), len(file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
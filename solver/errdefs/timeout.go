@@ -0,0 +1,64 @@
+package errdefs
+
+import (
+	"time"
+
+	"github.com/containerd/typeurl/v2"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+func init() {
+	typeurl.Register((*Timeout)(nil), "github.com/moby/buildkit", "errdefs.Timeout+json")
+}
+
+type TimeoutError struct {
+	*Timeout
+	error
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.error
+}
+
+func (e *TimeoutError) ToProto() grpcerrors.TypedErrorProto {
+	return e.Timeout
+}
+
+func (e *TimeoutError) Code() codes.Code {
+	return codes.DeadlineExceeded
+}
+
+func (t *Timeout) WrapError(err error) error {
+	return &TimeoutError{error: err, Timeout: t}
+}
+
+// WithTimeout marks err as caused by the vertex exceeding its timeout.
+func WithTimeout(err error, timeout time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &TimeoutError{
+		error:   errors.Wrapf(err, "timeout of %s exceeded", timeout),
+		Timeout: &Timeout{Timeout: int64(timeout)},
+	}
+}
+
+// WithDeadline marks err as caused by the solve exceeding its deadline.
+func WithDeadline(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TimeoutError{
+		error:   errors.Wrap(err, "solve deadline exceeded"),
+		Timeout: &Timeout{Deadline: true},
+	}
+}
+
+// IsTimeout returns true if err was caused by a vertex timeout or a solve
+// deadline.
+func IsTimeout(err error) bool {
+	var te *TimeoutError
+	return errors.As(err, &te)
+}
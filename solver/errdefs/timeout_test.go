@@ -0,0 +1,32 @@
+package errdefs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTimeoutRoundTrip(t *testing.T) {
+	err := WithTimeout(errors.WithStack(context.DeadlineExceeded), 10*time.Minute)
+	require.True(t, IsTimeout(err))
+	require.Equal(t, codes.DeadlineExceeded, grpcerrors.Code(err))
+
+	decoded := grpcerrors.FromGRPC(grpcerrors.ToGRPC(t.Context(), err))
+	var te *TimeoutError
+	require.ErrorAs(t, decoded, &te)
+	require.Equal(t, int64(10*time.Minute), te.Timeout.Timeout)
+	require.False(t, te.Deadline)
+	require.ErrorContains(t, decoded, "timeout of 10m0s exceeded")
+
+	decoded = grpcerrors.FromGRPC(grpcerrors.ToGRPC(t.Context(), WithDeadline(context.DeadlineExceeded)))
+	require.ErrorAs(t, decoded, &te)
+	require.True(t, te.Deadline)
+	require.ErrorContains(t, decoded, "solve deadline exceeded")
+
+	require.False(t, IsTimeout(context.DeadlineExceeded))
+}
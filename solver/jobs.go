@@ -14,9 +14,11 @@ import (
 	"github.com/moby/buildkit/solver/llbsolver/compat"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/bkmaps"
+	"github.com/moby/buildkit/util/fairqueue"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/controller"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/tracing"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
@@ -74,6 +76,8 @@ type state struct {
 	cache     map[string]CacheManager
 	mainCache CacheManager
 	metadata  VertexMetadata
+	timeout   time.Duration
+	tenant    TenantScope
 	solver    *Solver
 }
 
@@ -120,6 +124,33 @@ func (s *state) CompatibilityVersion() (int, error) {
 	return version, nil
 }
 
+// schedulingClass returns the class used to queue the vertex for worker slots.
+// When multiple jobs share the vertex, the one with the highest priority is
+// used.
+func (s *state) schedulingClass() fairqueue.Class {
+	s.mu.RLock()
+	jobs := make([]*Job, 0, len(s.jobs))
+	for j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.RUnlock()
+
+	var class fairqueue.Class
+	var found bool
+	for _, j := range jobs {
+		v, ok := j.values.Load(fairqueue.JobValueKey)
+		if !ok {
+			continue
+		}
+		c := v.(fairqueue.Class)
+		if !found || class.Priority.Less(c.Priority) || (class.Priority == c.Priority && c.ID < class.ID) {
+			class = c
+			found = true
+		}
+	}
+	return class
+}
+
 func (s *state) Lock(key any) (values []any, release func(any) error, err error) {
 	var rcs []ResolverCache
 	s.mu.RLock()
@@ -386,6 +417,29 @@ type Job struct {
 	progressCloser func(error)
 	SessionID      string
 	uniqueID       string // unique ID is used for provenance. We use a different field that client can't control
+	deadline       time.Time
+	tenantScope    TenantScope
+}
+
+// TenantScope isolates the vertices of a job from the jobs of other tenants.
+// Scoped vertices never share active states or cache keys with vertices of
+// other tenants.
+type TenantScope struct {
+	ID string
+	// Shared returns true for vertices that are shared between tenants even
+	// though the job is scoped.
+	Shared func(Vertex) bool
+}
+
+func (s TenantScope) tenantOf(v Vertex) string {
+	if s.ID == "" || s.Shared != nil && s.Shared(v) {
+		return ""
+	}
+	return s.ID
+}
+
+func tenantDigest(dgst digest.Digest, id string) digest.Digest {
+	return digest.FromBytes(fmt.Appendf(nil, "%s-tenant-%s", dgst, id))
 }
 
 type SolverOpt struct {
@@ -544,6 +598,13 @@ func (jl *Solver) loadUnlocked(ctx context.Context, v, parent Vertex, j *Job, ca
 
 	dgst := v.Digest()
 
+	scope := jl.tenantScope(parent, j)
+	if id := scope.tenantOf(v); id != "" {
+		dgst = tenantDigest(dgst, id)
+	} else {
+		scope = TenantScope{}
+	}
+
 	dgstWithoutCache := digest.FromBytes(fmt.Appendf(nil, "%s-ignorecache", dgst))
 
 	// if same vertex is already loaded without cache just use that
@@ -590,6 +651,8 @@ func (jl *Solver) loadUnlocked(ctx context.Context, v, parent Vertex, j *Job, ca
 			cache:        map[string]CacheManager{},
 			solver:       jl,
 			origDigest:   origVtx.Digest(),
+			timeout:      v.Options().Timeout,
+			tenant:       scope,
 		}
 		jl.actives[dgst] = st
 
@@ -636,6 +699,9 @@ func (jl *Solver) loadUnlocked(ctx context.Context, v, parent Vertex, j *Job, ca
 			st.metadata = st.metadata.Merge(md)
 		}
 	}
+	if ok {
+		st.timeout = mergeTimeout(st.timeout, v.Options().Timeout)
+	}
 
 	if j != nil {
 		if _, ok := st.jobs[j]; !ok {
@@ -662,6 +728,22 @@ func (jl *Solver) loadUnlocked(ctx context.Context, v, parent Vertex, j *Job, ca
 	return v, nil
 }
 
+// tenantScope returns the scope of vertices loaded for a job, or for a
+// sub-build of the parent vertex.
+func (jl *Solver) tenantScope(parent Vertex, j *Job) TenantScope {
+	if j != nil {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		return j.tenantScope
+	}
+	if parent != nil {
+		if st, ok := jl.actives[parent.Digest()]; ok {
+			return st.tenant
+		}
+	}
+	return TenantScope{}
+}
+
 func (jl *Solver) connectProgressFromState(target, src *state) {
 	for j := range src.jobs {
 		j.mu.Lock()
@@ -789,6 +871,15 @@ func (j *Job) Build(ctx context.Context, e Edge) (CachedResultWithProvenance, er
 		j.mu.Unlock()
 	}
 
+	j.mu.Lock()
+	deadline := j.deadline
+	j.mu.Unlock()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadlineCause(ctx, deadline, errdefs.WithDeadline(errors.WithStack(context.DeadlineExceeded)))
+		defer cancel()
+	}
+
 	v, err := j.list.load(ctx, e.Vertex, nil, j)
 	if err != nil {
 		return nil, err
@@ -797,6 +888,9 @@ func (j *Job) Build(ctx context.Context, e Edge) (CachedResultWithProvenance, er
 
 	res, err := j.list.s.build(ctx, e)
 	if err != nil {
+		if !deadline.IsZero() && ctx.Err() != nil && !errdefs.IsTimeout(err) {
+			err = errdefs.WithDeadline(err)
+		}
 		return nil, err
 	}
 
@@ -897,6 +991,28 @@ func (j *Job) Discard() error {
 	return nil
 }
 
+// SetTenantScope isolates the vertices built by the job from the jobs of other
+// tenants.
+func (j *Job) SetTenantScope(scope TenantScope) {
+	j.mu.Lock()
+	j.tenantScope = scope
+	j.mu.Unlock()
+}
+
+// TenantScope returns the scope set with SetTenantScope.
+func (j *Job) TenantScope() TenantScope {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.tenantScope
+}
+
+// SetDeadline sets the time all builds of the job must complete by.
+func (j *Job) SetDeadline(deadline time.Time) {
+	j.mu.Lock()
+	j.deadline = deadline
+	j.mu.Unlock()
+}
+
 func (j *Job) StartedTime() time.Time {
 	return j.startedTime
 }
@@ -971,6 +1087,9 @@ type activeOp interface {
 	Exec(ctx context.Context, inputs []Result) (outputs []Result, exporters []ExportableCacheKey, ctxOpts func(context.Context) context.Context, err error)
 	IgnoreCache() bool
 	Cache() CacheManager
+	HasCacheImports() bool
+	MatchedMainCache([]ExportableCacheKey) bool
+	SetCacheMiss(CacheMiss)
 	CalcSlowCache(context.Context, Index, PreprocessFunc, ResultBasedCacheFunc, Result) (digest.Digest, error)
 }
 
@@ -1012,6 +1131,11 @@ type sharedOp struct {
 	slowMu       sync.Mutex
 	slowCacheRes map[Index]digest.Digest
 	slowCacheErr map[Index]error
+
+	// missMu protects cached and cacheMiss
+	missMu    sync.Mutex
+	cached    bool
+	cacheMiss *CacheMiss
 }
 
 func (s *sharedOp) IgnoreCache() bool {
@@ -1022,6 +1146,41 @@ func (s *sharedOp) Cache() CacheManager {
 	return &cacheWithCacheOpts{s.st.combinedCacheManager(), s.st}
 }
 
+// HasCacheImports returns true if the vertex can load results from caches
+// other than the main cache, e.g. remote cache imports.
+func (s *sharedOp) HasCacheImports() bool {
+	s.st.mu.RLock()
+	defer s.st.mu.RUnlock()
+	return len(s.st.cache) > 0
+}
+
+// MatchedMainCache returns true if one of the keys was returned by the main
+// cache and not only by cache imports.
+func (s *sharedOp) MatchedMainCache(keys []ExportableCacheKey) bool {
+	s.st.mu.RLock()
+	defer s.st.mu.RUnlock()
+	for _, k := range keys {
+		k.mu.RLock()
+		cm := k.queriedFrom
+		k.mu.RUnlock()
+		if cm == nil {
+			return true
+		}
+		if _, ok := s.st.cache[cm.ID()]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sharedOp) SetCacheMiss(miss CacheMiss) {
+	s.missMu.Lock()
+	defer s.missMu.Unlock()
+	if s.cacheMiss == nil {
+		s.cacheMiss = &miss
+	}
+}
+
 type cacheWithCacheOpts struct {
 	CacheManager
 	st *state
@@ -1045,6 +1204,11 @@ func (s *sharedOp) LoadCache(ctx context.Context, rec *CacheRecord) (Result, fun
 	res, err := s.Cache().Load(withAncestorCacheOpts(ctx, s.st), rec)
 	tracing.FinishWithError(span, err)
 	notifyCompleted(err, true)
+	if err == nil {
+		s.missMu.Lock()
+		s.cached = true
+		s.missMu.Unlock()
+	}
 	return res, func(ctx context.Context) context.Context {
 		return withAncestorCacheOpts(ctx, s.st)
 	}, err
@@ -1156,6 +1320,7 @@ func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp,
 			ctx = trace.ContextWithSpan(ctx, s.st.mspan)
 		}
 		ctx = withAncestorCacheOpts(ctx, s.st)
+		ctx = tenant.WithID(ctx, s.st.tenant.ID)
 		if len(s.st.vtx.Inputs()) == 0 {
 			// no cache hit. start evaluating the node
 			span, ctx := tracing.StartSpan(ctx, "cache request: "+s.st.vtx.Name(), trace.WithAttributes(attribute.String("vertex", s.st.vtx.Digest().String())))
@@ -1183,6 +1348,9 @@ func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp,
 				if res.Opts == nil {
 					res.Opts = CacheOpts(make(map[any]any))
 				}
+				if id := s.st.tenant.ID; id != "" {
+					res.Digest = tenantDigest(res.Digest, id)
+				}
 				res.Opts[progressKey{}] = &controller.Controller{
 					WriterFactory: progress.FromContext(ctx),
 					Digest:        s.st.vtx.Digest(),
@@ -1224,17 +1392,21 @@ func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result,
 			}
 			return s.execRes, nil
 		}
+		// progress is set before acquiring so that time spent waiting for a
+		// slot is reported on the vertex
+		ctx = progress.WithProgress(ctx, s.st.mpw)
+		ctx = fairqueue.WithClass(ctx, s.st.schedulingClass())
 		release, err := op.Acquire(ctx)
 		if err != nil {
 			return nil, errors.Wrap(err, "acquire op resources")
 		}
 		defer release()
 
-		ctx = progress.WithProgress(ctx, s.st.mpw)
 		if s.st.mspan.Span != nil {
 			ctx = trace.ContextWithSpan(ctx, s.st.mspan)
 		}
 		ctx = withAncestorCacheOpts(ctx, s.st)
+		ctx = tenant.WithID(ctx, s.st.tenant.ID)
 
 		// no cache hit. start evaluating the node
 		span, ctx := tracing.StartSpan(ctx, s.st.vtx.Name(), trace.WithAttributes(attribute.String("vertex", s.st.vtx.Digest().String())))
@@ -1245,7 +1417,22 @@ func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result,
 			notifyCompleted(retErr, false)
 		}()
 
-		res, err := op.Exec(ctx, s.st, inputs)
+		s.st.mu.RLock()
+		timeout := s.st.timeout
+		s.st.mu.RUnlock()
+		execCtx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			execCtx, cancel = context.WithTimeoutCause(ctx, timeout, errors.WithStack(context.DeadlineExceeded))
+			defer cancel()
+		}
+
+		res, err := op.Exec(execCtx, s.st, inputs)
+		if err != nil && ctx.Err() == nil && execCtx.Err() != nil {
+			// the vertex timeout was reached, the result is an error that
+			// is shared with all jobs waiting for the vertex
+			err = errdefs.WithTimeout(err, timeout)
+		}
 		complete := true
 		if err != nil {
 			select {
@@ -1303,6 +1490,15 @@ func (s *sharedOp) getOp() (Op, error) {
 	return s.op, nil
 }
 
+// mergeTimeout returns the most permissive of two vertex timeouts when the
+// same vertex is loaded by multiple jobs. Zero means no timeout.
+func mergeTimeout(a, b time.Duration) time.Duration {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return max(a, b)
+}
+
 type vertexWithMetadata struct {
 	Vertex
 	metadata VertexMetadata
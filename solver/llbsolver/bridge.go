@@ -197,13 +197,9 @@ func (b *llbBridge) Run(ctx context.Context, id string, rootfs executor.Mount, m
 	if err := b.validateEntitlements(&process); err != nil {
 		return nil, err
 	}
-	policy, err := b.ProxyPolicy()
-	if err != nil {
+	if err := b.setProxyConfig(process.Meta.Proxy); err != nil {
 		return nil, err
 	}
-	if policy != nil && process.Meta.Proxy != nil {
-		process.Meta.Proxy.Policy = policy
-	}
 
 	if err := b.loadExecutor(); err != nil {
 		return nil, err
@@ -215,13 +211,9 @@ func (b *llbBridge) Exec(ctx context.Context, id string, process executor.Proces
 	if err := b.validateEntitlements(&process); err != nil {
 		return err
 	}
-	policy, err := b.ProxyPolicy()
-	if err != nil {
+	if err := b.setProxyConfig(process.Meta.Proxy); err != nil {
 		return err
 	}
-	if policy != nil && process.Meta.Proxy != nil {
-		process.Meta.Proxy.Policy = policy
-	}
 
 	if err := b.loadExecutor(); err != nil {
 		return err
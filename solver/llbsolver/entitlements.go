@@ -2,6 +2,7 @@ package llbsolver
 
 import (
 	"context"
+	"slices"
 
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/util/entitlements"
@@ -9,7 +10,8 @@ import (
 )
 
 const (
-	keyEntitlements = "llb.entitlements"
+	keyEntitlements     = "llb.entitlements"
+	keyAllowedFrontends = "llb.allowedfrontends"
 )
 
 func supportedEntitlements(ents []string) []entitlements.Entitlement {
@@ -49,3 +51,16 @@ func loadEntitlements(b solver.Builder) (entitlements.Set, error) {
 	}
 	return ent, nil
 }
+
+func validateFrontend(b solver.Builder, name string) error {
+	return b.EachValue(context.TODO(), keyAllowedFrontends, func(v any) error {
+		names, ok := v.([]string)
+		if !ok {
+			return errors.Errorf("invalid allowed frontends %T", v)
+		}
+		if !slices.Contains(names, name) {
+			return errors.Errorf("frontend %s is not allowed", name)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,46 @@
+package errdefs
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+)
+
+// EgressDeniedError is returned when a process made a network request that
+// was blocked by the proxy network policy.
+type EgressDeniedError struct {
+	Method string
+	URL    string
+	Reason string
+	error
+}
+
+func (e *EgressDeniedError) Error() string {
+	msg := fmt.Sprintf("network request %s %s denied", e.Method, e.URL)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.error != nil {
+		msg += ": " + e.error.Error()
+	}
+	return msg
+}
+
+func (e *EgressDeniedError) Unwrap() error {
+	return e.error
+}
+
+func (e *EgressDeniedError) Code() codes.Code {
+	return codes.PermissionDenied
+}
+
+// WithEgressDenied wraps err with the first request that was denied by the
+// proxy network policy. err may be nil if the process itself succeeded.
+func WithEgressDenied(err error, method, url, reason string) error {
+	return &EgressDeniedError{
+		Method: method,
+		URL:    url,
+		Reason: reason,
+		error:  err,
+	}
+}
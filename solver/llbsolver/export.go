@@ -2,8 +2,10 @@ package llbsolver
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	"github.com/moby/buildkit/util/tracing"
 	"github.com/moby/buildkit/worker"
 	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
@@ -157,7 +160,19 @@ func exporterVertexID(sessionID string, exporterIndex int) string {
 }
 
 func (s *Solver) runExporters(ctx context.Context, ref string, exporters []exporter.ExporterInstance, inlineCacheExporter inlineCacheExporter, job *solver.Job, cached *result.Result[solver.CachedResult], inp *exporter.Source) (exporterResponse map[string]string, finalizers []exporter.FinalizeFunc, descrefs []exporter.DescriptorReference, err error) {
+	cells, err := exptypes.SplitMatrix(inp)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var cachedCells []*result.Result[solver.CachedResult]
 	warnings, err := verifier.CheckInvalidPlatforms(ctx, inp)
+	if cells != nil {
+		if cachedCells, err = exptypes.SplitMatrix(cached); err != nil {
+			return nil, nil, nil, err
+		}
+		// every cell of the matrix is built for the same platforms
+		warnings, err = verifier.CheckInvalidPlatforms(ctx, cells[0])
+	}
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -183,23 +198,30 @@ func (s *Solver) runExporters(ctx context.Context, ref string, exporters []expor
 						return err
 					}
 				}
-				inlineCache := exptypes.InlineCache(func(ctx context.Context) (*result.Result[*exptypes.InlineCacheEntry], error) {
-					inlineCacheMu.Lock() // ensure only one inline cache exporter runs at a time
-					defer inlineCacheMu.Unlock()
-					return runInlineCacheExporter(ctx, exp, inlineCacheExporter, job, cached)
-				})
 				compatibilityVersion, err := job.CompatibilityVersion()
 				if err != nil {
 					return err
 				}
+				export := func(ctx context.Context, inp *exporter.Source, cached *result.Result[solver.CachedResult]) (map[string]string, exporter.FinalizeFunc, exporter.DescriptorReference, error) {
+					inlineCache := exptypes.InlineCache(func(ctx context.Context) (*result.Result[*exptypes.InlineCacheEntry], error) {
+						inlineCacheMu.Lock() // ensure only one inline cache exporter runs at a time
+						defer inlineCacheMu.Unlock()
+						return runInlineCacheExporter(ctx, exp, inlineCacheExporter, job, cached)
+					})
+					return exp.Export(ctx, inp, exporter.ExportBuildInfo{
+						Ref:                  ref,
+						SessionID:            job.SessionID,
+						InlineCache:          inlineCache,
+						CompatibilityVersion: compatibilityVersion,
+					})
+				}
 
-				resp, finalize, desc, expErr := exp.Export(ctx, inp, exporter.ExportBuildInfo{
-					Ref:                  ref,
-					SessionID:            job.SessionID,
-					InlineCache:          inlineCache,
-					CompatibilityVersion: compatibilityVersion,
-				})
-				resps[i], finalizeFuncs[i], descs[i] = resp, finalize, desc
+				var expErr error
+				if me, ok := exp.(exporter.MatrixExporter); ok && cells != nil && me.ExportsMatrixCells() {
+					resps[i], finalizeFuncs[i], descs[i], expErr = exportMatrixCells(ctx, cells, cachedCells, export)
+				} else {
+					resps[i], finalizeFuncs[i], descs[i], expErr = export(ctx, inp, cached)
+				}
 				if expErr != nil {
 					return expErr
 				}
@@ -238,6 +260,83 @@ func (s *Solver) runExporters(ctx context.Context, ref string, exporters []expor
 	return exporterResponse, finalizeFuncs, descs, nil
 }
 
+type exportFunc func(context.Context, *exporter.Source, *result.Result[solver.CachedResult]) (map[string]string, exporter.FinalizeFunc, exporter.DescriptorReference, error)
+
+// exportMatrixCells exports every cell of a build matrix. The keys of the
+// exporter responses are suffixed by the cell ID.
+func exportMatrixCells(ctx context.Context, cells []*exporter.Source, cached []*result.Result[solver.CachedResult], export exportFunc) (map[string]string, exporter.FinalizeFunc, exporter.DescriptorReference, error) {
+	resps := make([]map[string]string, len(cells))
+	finalizeFuncs := make([]exporter.FinalizeFunc, len(cells))
+	descs := make(matrixDescriptorReference, len(cells))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, src := range cells {
+		eg.Go(func() error {
+			var err error
+			resps[i], finalizeFuncs[i], descs[i], err = export(egCtx, src, cached[i])
+			return err
+		})
+	}
+	err := eg.Wait()
+
+	var desc exporter.DescriptorReference
+	if slices.ContainsFunc(descs, func(d exporter.DescriptorReference) bool { return d != nil }) {
+		desc = descs
+	}
+	if err != nil {
+		return nil, nil, desc, err
+	}
+
+	resp := make(map[string]string)
+	for i, r := range resps {
+		cell, err := exptypes.ParseMatrixCell(cells[i].Metadata)
+		if err != nil {
+			return nil, nil, desc, err
+		}
+		for k, v := range r {
+			resp[k+"/"+cell.ID] = v
+		}
+	}
+
+	var finalize exporter.FinalizeFunc
+	if slices.ContainsFunc(finalizeFuncs, func(f exporter.FinalizeFunc) bool { return f != nil }) {
+		finalize = func(ctx context.Context) error {
+			eg, ctx := errgroup.WithContext(ctx)
+			for _, f := range finalizeFuncs {
+				if f != nil {
+					eg.Go(func() error {
+						return f(ctx)
+					})
+				}
+			}
+			return eg.Wait()
+		}
+	}
+	return resp, finalize, desc, nil
+}
+
+// matrixDescriptorReference holds the descriptors exported for the cells of a
+// build matrix. The build history records the descriptor of the first cell.
+type matrixDescriptorReference []exporter.DescriptorReference
+
+func (m matrixDescriptorReference) Descriptor() ocispecs.Descriptor {
+	for _, d := range m {
+		if d != nil {
+			return d.Descriptor()
+		}
+	}
+	return ocispecs.Descriptor{}
+}
+
+func (m matrixDescriptorReference) Release() error {
+	var errs []error
+	for _, d := range m {
+		if d != nil {
+			errs = append(errs, d.Release())
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
 func splitCacheExporters(exporters []RemoteCacheExporter) (rest []RemoteCacheExporter, inline inlineCacheExporter) {
 	rest = make([]RemoteCacheExporter, 0, len(exporters))
 	for _, exp := range exporters {
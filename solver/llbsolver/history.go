@@ -16,9 +16,11 @@ import (
 	"github.com/moby/buildkit/frontend"
 	"github.com/moby/buildkit/frontend/attestations"
 	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/llbsolver/history"
 	"github.com/moby/buildkit/solver/llbsolver/provenance"
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/util/tracing"
 	"github.com/moby/buildkit/util/tracing/detect"
 	"github.com/pkg/errors"
@@ -37,6 +39,7 @@ func (s *Solver) recordBuildHistory(ctx context.Context, id string, req frontend
 		Frontend:      req.Frontend,
 		FrontendAttrs: req.FrontendOpt,
 		CreatedAt:     timestamppb.Now(),
+		Tenant:        tenant.FromContext(ctx),
 	}
 
 	for _, e := range exp.Exporters {
@@ -204,6 +207,17 @@ func (s *Solver) recordBuildHistory(ctx context.Context, id string, req frontend
 		eg.Go(func() error {
 			return j.Status(ctx2, ch)
 		})
+		eg.Go(func() error {
+			desc, release, err := s.recordCacheInfo(ctx2, j)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			releasers = append(releasers, release)
+			rec.CacheInfo = desc
+			mu.Unlock()
+			return nil
+		})
 
 		setDeprecated := true
 		for i, descref := range descrefs {
@@ -334,3 +348,34 @@ func (s *Solver) recordBuildHistory(ctx context.Context, id string, req frontend
 		return err
 	}, nil
 }
+
+// recordCacheInfo stores the cache keys computed for the vertices of the job
+// so that builds can later be compared with DiffBuildHistory.
+func (s *Solver) recordCacheInfo(ctx context.Context, j *solver.Job) (*controlapi.Descriptor, func(), error) {
+	dt, err := json.Marshal(j.CacheInfo())
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	w, err := s.history.OpenBlobWriter(ctx, history.CacheInfoMediaType)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if w != nil {
+			w.Discard()
+		}
+	}()
+	if _, err := w.Write(dt); err != nil {
+		return nil, nil, err
+	}
+	desc, release, err := w.Commit(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	w = nil
+	return &controlapi.Descriptor{
+		Digest:    string(desc.Digest),
+		Size:      desc.Size,
+		MediaType: desc.MediaType,
+	}, release, nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/moby/buildkit/util/grpcerrors"
 	"github.com/moby/buildkit/util/iohelper"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/tenant"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
@@ -324,6 +325,9 @@ func (h *Queue) UpdateRef(ctx context.Context, ref string, upt func(r *controlap
 		if err := br.UnmarshalVT(dt); err != nil {
 			return errors.Wrapf(err, "failed to unmarshal build record %s", ref)
 		}
+		if !ownedBy(&br, tenant.FromContext(ctx)) {
+			return errors.Wrapf(os.ErrNotExist, "failed to retrieve ref %s", ref)
+		}
 		return nil
 	}); err != nil {
 		return err
@@ -364,6 +368,9 @@ func (h *Queue) Status(ctx context.Context, ref string, st chan<- *client.SolveS
 		if err := br.UnmarshalVT(dt); err != nil {
 			return errors.Wrapf(err, "failed to unmarshal build record %s", ref)
 		}
+		if !ownedBy(&br, tenant.FromContext(ctx)) {
+			return errors.Wrapf(os.ErrNotExist, "failed to retrieve ref %s", ref)
+		}
 		return nil
 	}); err != nil {
 		return err
@@ -451,6 +458,9 @@ func (h *Queue) update(ctx context.Context, rec *controlapi.BuildHistoryRecord)
 		if err := h.addResource(ctx, l, rec.ExternalError, false); err != nil {
 			return err
 		}
+		if err := h.addResource(ctx, l, rec.CacheInfo, false); err != nil {
+			return err
+		}
 		if rec.Result != nil {
 			if err := h.addResource(ctx, l, rec.Result.ResultDeprecated, true); err != nil {
 				return err
@@ -516,7 +526,51 @@ func (h *Queue) AcquireFinalizer(ref string) (<-chan struct{}, func()) {
 	})
 }
 
+// checkTenant returns an error if the ref exists but is not visible to the
+// tenant of ctx.
+func (h *Queue) checkTenant(ctx context.Context, ref string) error {
+	id := tenant.FromContext(ctx)
+	if id == "" {
+		return nil
+	}
+	h.mu.Lock()
+	rec, ok := h.active[ref]
+	h.mu.Unlock()
+	if !ok {
+		if err := h.opt.DB.View(func(tx *bolt.Tx) error {
+			b := tx.Bucket([]byte(recordsBucket))
+			if b == nil {
+				return nil
+			}
+			dt := b.Get([]byte(ref))
+			if dt == nil {
+				return nil
+			}
+			rec = &controlapi.BuildHistoryRecord{}
+			if err := rec.UnmarshalVT(dt); err != nil {
+				return errors.Wrapf(err, "failed to unmarshal build record %s", ref)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if rec != nil && !ownedBy(rec, id) {
+		return errors.Wrapf(os.ErrNotExist, "failed to retrieve ref %s", ref)
+	}
+	return nil
+}
+
+// ownedBy returns true if the record is visible to the tenant. Requests
+// without a tenant see the records of all tenants.
+func ownedBy(rec *controlapi.BuildHistoryRecord, id string) bool {
+	return id == "" || rec.Tenant == id
+}
+
 func (h *Queue) Finalize(ctx context.Context, ref string) error {
+	if err := h.checkTenant(ctx, ref); err != nil {
+		return err
+	}
 	h.mu.Lock()
 	f, ok := h.finalizers[ref]
 	h.mu.Unlock()
@@ -551,6 +605,9 @@ func (h *Queue) Update(ctx context.Context, e *controlapi.BuildHistoryEvent) err
 }
 
 func (h *Queue) Delete(ctx context.Context, ref string) error {
+	if err := h.checkTenant(ctx, ref); err != nil {
+		return err
+	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -770,6 +827,8 @@ func (h *Queue) ImportStatus(ctx context.Context, ch chan *client.SolveStatus) (
 func (h *Queue) Listen(ctx context.Context, req *controlapi.BuildHistoryRequest, f func(*controlapi.BuildHistoryEvent) error) error {
 	h.init()
 
+	tid := tenant.FromContext(ctx)
+
 	h.mu.Lock()
 	sub := h.ps.Subscribe()
 	defer sub.close()
@@ -801,6 +860,9 @@ func (h *Queue) Listen(ctx context.Context, req *controlapi.BuildHistoryRequest,
 		if req.Ref != "" && e.Ref != req.Ref {
 			continue
 		}
+		if !ownedBy(e, tid) {
+			continue
+		}
 		if _, ok := h.deleted[e.Ref]; ok {
 			continue
 		}
@@ -833,6 +895,9 @@ func (h *Queue) Listen(ctx context.Context, req *controlapi.BuildHistoryRequest,
 				if err := br.UnmarshalVT(dt); err != nil {
 					return errors.Wrapf(err, "failed to unmarshal build record %s", key)
 				}
+				if !ownedBy(&br, tid) {
+					return nil
+				}
 				events = append(events, &controlapi.BuildHistoryEvent{
 					Record: &br,
 					Type:   controlapi.BuildHistoryEventType_COMPLETE,
@@ -878,6 +943,9 @@ func (h *Queue) Listen(ctx context.Context, req *controlapi.BuildHistoryRequest,
 			if req.Ref != "" && req.Ref != e.Record.Ref {
 				continue
 			}
+			if !ownedBy(e.Record, tid) {
+				continue
+			}
 			if err := f(e); err != nil {
 				return err
 			}
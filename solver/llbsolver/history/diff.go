@@ -0,0 +1,528 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/cachedigest"
+	"github.com/moby/buildkit/util/tenant"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// CacheInfoMediaType is the media type of the cache info attachment of a
+// build record.
+const CacheInfoMediaType = "application/vnd.buildkit.cacheinfo.v0+json"
+
+// maxFileListDepth limits how deep directory checksums are expanded when
+// looking for changed files.
+const maxFileListDepth = 8
+
+// Diff compares the LLB and the cache keys of two completed builds. It returns
+// the vertices of ref whose cache keys diverged from compareRef because of a
+// change in the vertex itself, ordered so that the vertices that diverged
+// first come first.
+func (h *Queue) Diff(ctx context.Context, ref, compareRef string) ([]*controlapi.BuildHistoryVertexDiff, error) {
+	h.init()
+
+	a, err := h.loadDiffBuilds(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	b, err := h.loadDiffBuilds(ctx, compareRef)
+	if err != nil {
+		return nil, err
+	}
+	if !hasCommonKey(a, b) {
+		return nil, errors.Errorf("builds %s and %s have no results in common", ref, compareRef)
+	}
+
+	db := cachedigest.GetDefaultDB()
+	lookup := func(dgst digest.Digest) (cachedigest.Type, []cachedigest.Frame, error) {
+		return db.Get(ctx, dgst.String())
+	}
+
+	var out []*controlapi.BuildHistoryVertexDiff
+	for _, k := range sortedKeys(a) {
+		if _, ok := b[k]; ok {
+			out = append(out, diffBuilds(a[k], b[k], lookup)...)
+		}
+	}
+	return out, nil
+}
+
+// loadDiffBuilds loads the build graphs of the results of a build record,
+// keyed by the result key.
+func (h *Queue) loadDiffBuilds(ctx context.Context, ref string) (map[string]*diffBuild, error) {
+	var br controlapi.BuildHistoryRecord
+	if err := h.opt.DB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(recordsBucket))
+		if b == nil {
+			return errors.Wrapf(os.ErrNotExist, "failed to retrieve bucket %s", recordsBucket)
+		}
+		dt := b.Get([]byte(ref))
+		if dt == nil {
+			return errors.Wrapf(os.ErrNotExist, "failed to retrieve ref %s", ref)
+		}
+
+		if err := br.UnmarshalVT(dt); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal build record %s", ref)
+		}
+		if !ownedBy(&br, tenant.FromContext(ctx)) {
+			return errors.Wrapf(os.ErrNotExist, "failed to retrieve ref %s", ref)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var cacheInfo []solver.VertexCacheInfo
+	if br.CacheInfo != nil {
+		dt, err := h.readBlob(ctx, br.CacheInfo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read cache info of %s", ref)
+		}
+		if err := json.Unmarshal(dt, &cacheInfo); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse cache info of %s", ref)
+		}
+	}
+
+	results := map[string]*controlapi.BuildResultInfo{}
+	if br.Result != nil {
+		results[""] = br.Result
+	}
+	for k, res := range br.Results {
+		results[k] = res
+	}
+
+	out := map[string]*diffBuild{}
+	for k, res := range results {
+		for _, att := range res.Attestations {
+			if att.Annotations["in-toto.io/predicate-type"] != slsa1.PredicateSLSAProvenance {
+				continue
+			}
+			dt, err := h.readBlob(ctx, att)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read provenance of %s", ref)
+			}
+			b, err := parseDiffBuild(dt, cacheInfo)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse provenance of %s", ref)
+			}
+			if b != nil {
+				out[k] = b
+			}
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.Errorf("build %s has no provenance to compare", ref)
+	}
+	return out, nil
+}
+
+func (h *Queue) readBlob(ctx context.Context, desc *controlapi.Descriptor) ([]byte, error) {
+	return content.ReadBlob(ctx, h.hContentStore, ocispecs.Descriptor{
+		Digest:    digest.Digest(desc.Digest),
+		Size:      desc.Size,
+		MediaType: desc.MediaType,
+	})
+}
+
+// diffBuild is the build graph of a result, read from its provenance.
+type diffBuild struct {
+	root  *diffStep
+	steps map[string]*diffStep
+}
+
+type diffStep struct {
+	id     string
+	digest digest.Digest
+	// op holds the fields of the LLB op without inputs, flattened to
+	// dotted keys.
+	op     map[string]string
+	inputs []diffInput
+	cache  *solver.VertexCacheInfo
+}
+
+type diffInput struct {
+	step   string
+	output string
+}
+
+func (s *diffStep) name() string {
+	if s.cache != nil && s.cache.Name != "" {
+		return s.cache.Name
+	}
+	return s.id
+}
+
+type diffProvenance struct {
+	BuildDefinition struct {
+		InternalParameters struct {
+			BuildConfig *struct {
+				Definition []struct {
+					ID     string         `json:"id"`
+					Op     map[string]any `json:"op"`
+					Inputs []string       `json:"inputs"`
+				} `json:"llbDefinition"`
+				DigestMapping map[digest.Digest]string `json:"digestMapping"`
+			} `json:"buildConfig"`
+		} `json:"internalParameters"`
+	} `json:"buildDefinition"`
+}
+
+// parseDiffBuild reads the build graph from a SLSA v1 provenance predicate
+// with the build config. Returns nil if the provenance has no build steps.
+func parseDiffBuild(dt []byte, cacheInfo []solver.VertexCacheInfo) (*diffBuild, error) {
+	var p diffProvenance
+	if err := decodeJSON(dt, &p); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	bc := p.BuildDefinition.InternalParameters.BuildConfig
+	if bc == nil || len(bc.Definition) == 0 {
+		return nil, nil
+	}
+
+	digests := make(map[string]digest.Digest, len(bc.DigestMapping))
+	for dgst, id := range bc.DigestMapping {
+		digests[id] = dgst
+	}
+	infos := make(map[digest.Digest]*solver.VertexCacheInfo, len(cacheInfo))
+	for i := range cacheInfo {
+		infos[cacheInfo[i].Digest] = &cacheInfo[i]
+	}
+
+	b := &diffBuild{steps: map[string]*diffStep{}}
+	for _, st := range bc.Definition {
+		s := &diffStep{
+			id:     st.ID,
+			digest: digests[st.ID],
+			op:     map[string]string{},
+		}
+		s.cache = infos[s.digest]
+		if op, ok := st.Op["Op"].(map[string]any); ok {
+			flattenJSON("", op, s.op)
+		}
+		for k, v := range st.Op {
+			if k != "Op" && k != "inputs" {
+				flattenJSON(k, v, s.op)
+			}
+		}
+		for _, inp := range st.Inputs {
+			id, output, _ := strings.Cut(inp, ":")
+			s.inputs = append(s.inputs, diffInput{step: id, output: output})
+		}
+		b.steps[s.id] = s
+	}
+
+	// the last step is the terminating vertex pointing to the result
+	last := b.steps[bc.Definition[len(bc.Definition)-1].ID]
+	if len(last.inputs) != 1 {
+		return nil, errors.Errorf("invalid last step inputs: %d", len(last.inputs))
+	}
+	root, ok := b.steps[last.inputs[0].step]
+	if !ok {
+		return nil, errors.Errorf("invalid last step input %s", last.inputs[0].step)
+	}
+	b.root = root
+	return b, nil
+}
+
+type differ struct {
+	a, b    *diffBuild
+	lookup  func(digest.Digest) (cachedigest.Type, []cachedigest.Frame, error)
+	visited map[[2]string]bool
+	out     []*controlapi.BuildHistoryVertexDiff
+}
+
+// diffBuilds walks both build graphs from their results and returns the
+// vertices with changed cache inputs. Inputs are reported before the vertices
+// depending on them.
+func diffBuilds(a, b *diffBuild, lookup func(digest.Digest) (cachedigest.Type, []cachedigest.Frame, error)) []*controlapi.BuildHistoryVertexDiff {
+	d := &differ{
+		a:       a,
+		b:       b,
+		lookup:  lookup,
+		visited: map[[2]string]bool{},
+	}
+	d.compare(a.root, b.root)
+	return d.out
+}
+
+// compare reports whether the cache keys of two steps differ.
+func (d *differ) compare(a, b *diffStep) bool {
+	key := [2]string{a.id, b.id}
+	if changed, ok := d.visited[key]; ok {
+		return changed
+	}
+	d.visited[key] = false
+
+	diffs := diffValues("", a.op, b.op)
+	if len(diffs) == 0 {
+		diffs = d.diffCacheMaps(a.cache, b.cache)
+	}
+
+	var inputChanged bool
+	for i := range max(len(a.inputs), len(b.inputs)) {
+		k := "input[" + strconv.Itoa(i) + "]"
+		if i >= len(a.inputs) || i >= len(b.inputs) {
+			var v, cv string
+			if i < len(a.inputs) {
+				v = a.inputs[i].step
+			} else {
+				cv = b.inputs[i].step
+			}
+			diffs = append(diffs, &controlapi.BuildHistoryInputDiff{Key: k, Value: v, CompareValue: cv})
+			continue
+		}
+		if a.inputs[i].output != b.inputs[i].output {
+			diffs = append(diffs, &controlapi.BuildHistoryInputDiff{Key: k + ".output", Value: a.inputs[i].output, CompareValue: b.inputs[i].output})
+		}
+		depA, depB := dep(a.cache, i), dep(b.cache, i)
+		if depA.Selector != depB.Selector {
+			diffs = append(diffs, &controlapi.BuildHistoryInputDiff{Key: k + ".selector", Value: depA.Selector.String(), CompareValue: depB.Selector.String()})
+		}
+		if depA.ContentDigest != "" && depB.ContentDigest != "" {
+			// the cache key only depends on the content of the input
+			if depA.ContentDigest != depB.ContentDigest {
+				diffs = append(diffs, d.diffContent(k, depA.ContentDigest, depB.ContentDigest)...)
+			}
+			continue
+		}
+		inA, okA := d.a.steps[a.inputs[i].step]
+		inB, okB := d.b.steps[b.inputs[i].step]
+		if okA && okB && d.compare(inA, inB) {
+			inputChanged = true
+		}
+	}
+
+	if len(diffs) > 0 {
+		d.out = append(d.out, &controlapi.BuildHistoryVertexDiff{
+			Name:          a.name(),
+			Digest:        string(a.digest),
+			CompareDigest: string(b.digest),
+			Inputs:        diffs,
+		})
+	}
+	changed := len(diffs) > 0 || inputChanged
+	d.visited[key] = changed
+	return changed
+}
+
+func dep(info *solver.VertexCacheInfo, i int) solver.DepCacheInfo {
+	if info == nil || i >= len(info.Deps) {
+		return solver.DepCacheInfo{}
+	}
+	return info.Deps[i]
+}
+
+// diffCacheMaps compares the cache maps of two vertices with the same op.
+// Cache maps can still differ for sources resolving to different content.
+func (d *differ) diffCacheMaps(a, b *solver.VertexCacheInfo) []*controlapi.BuildHistoryInputDiff {
+	if a == nil || b == nil {
+		return nil
+	}
+	var diffs []*controlapi.BuildHistoryInputDiff
+	for i := range max(len(a.CacheMaps), len(b.CacheMaps)) {
+		k := "cache.map"
+		if i > 0 {
+			k += "[" + strconv.Itoa(i) + "]"
+		}
+		var va, vb digest.Digest
+		if i < len(a.CacheMaps) {
+			va = a.CacheMaps[i]
+		}
+		if i < len(b.CacheMaps) {
+			vb = b.CacheMaps[i]
+		}
+		if va == vb {
+			continue
+		}
+		if va != "" && vb != "" {
+			if fd := d.diffCacheMap(k, va, vb); len(fd) > 0 {
+				diffs = append(diffs, fd...)
+				continue
+			}
+		}
+		diffs = append(diffs, &controlapi.BuildHistoryInputDiff{Key: k, Value: va.String(), CompareValue: vb.String()})
+	}
+	return diffs
+}
+
+// diffCacheMap compares the data of two cache map digests recorded in the
+// cache debug database, if available.
+func (d *differ) diffCacheMap(k string, a, b digest.Digest) []*controlapi.BuildHistoryInputDiff {
+	typA, dtA, okA := d.frameData(a)
+	typB, dtB, okB := d.frameData(b)
+	if !okA || !okB || typA != typB {
+		return nil
+	}
+	switch typA {
+	case cachedigest.TypeJSON:
+		var va, vb any
+		if decodeJSON(dtA, &va) != nil || decodeJSON(dtB, &vb) != nil {
+			return nil
+		}
+		ma, mb := map[string]string{}, map[string]string{}
+		flattenJSON(k, va, ma)
+		flattenJSON(k, vb, mb)
+		return diffValues("", ma, mb)
+	case cachedigest.TypeString:
+		return []*controlapi.BuildHistoryInputDiff{{Key: k, Value: string(dtA), CompareValue: string(dtB)}}
+	}
+	return nil
+}
+
+// diffContent compares the content based cache keys of an input. If the
+// cache debug database has the checksums, the changed files are reported.
+func (d *differ) diffContent(k string, a, b digest.Digest) []*controlapi.BuildHistoryInputDiff {
+	fa, okA := d.contentFiles(a)
+	fb, okB := d.contentFiles(b)
+	if okA && okB {
+		if diffs := diffValues(k+":", fa, fb); len(diffs) > 0 {
+			return diffs
+		}
+	}
+	return []*controlapi.BuildHistoryInputDiff{{Key: k + ".content", Value: a.String(), CompareValue: b.String()}}
+}
+
+// contentFiles expands a content based cache key to the checksums of the
+// files it was computed from.
+func (d *differ) contentFiles(dgst digest.Digest) (map[string]string, bool) {
+	typ, dt, ok := d.frameData(dgst)
+	if !ok || typ != cachedigest.TypeDigestList {
+		return nil, false
+	}
+	files := map[string]string{}
+	for sel := range strings.SplitSeq(string(dt), "\x00") {
+		d.addFiles(files, "/", digest.Digest(sel), 0)
+	}
+	return files, true
+}
+
+func (d *differ) addFiles(files map[string]string, p string, dgst digest.Digest, depth int) {
+	if depth < maxFileListDepth {
+		if typ, frames, err := d.lookup(dgst); err == nil && typ == cachedigest.TypeFileList {
+			// file lists alternate between a path and its checksum
+			var name []byte
+			for _, f := range frames {
+				if f.ID != cachedigest.FrameIDData {
+					continue
+				}
+				if name == nil {
+					name = f.Data
+					continue
+				}
+				sub := strings.ReplaceAll(strings.Trim(string(name), "\x00"), "\x00", "/")
+				d.addFiles(files, path.Join(p, sub), digest.Digest(f.Data), depth+1)
+				name = nil
+			}
+			return
+		}
+	}
+	files[p] = dgst.String()
+}
+
+func (d *differ) frameData(dgst digest.Digest) (cachedigest.Type, []byte, bool) {
+	typ, frames, err := d.lookup(dgst)
+	if err != nil {
+		return "", nil, false
+	}
+	var dt []byte
+	for _, f := range frames {
+		if f.ID != cachedigest.FrameIDData {
+			// skipped data can't be compared
+			return "", nil, false
+		}
+		dt = append(dt, f.Data...)
+	}
+	return typ, dt, true
+}
+
+// decodeJSON decodes JSON keeping numbers in their original form.
+func decodeJSON(dt []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(dt))
+	dec.UseNumber()
+	return errors.WithStack(dec.Decode(v))
+}
+
+// diffValues returns the differing keys of two flattened values.
+func diffValues(prefix string, a, b map[string]string) []*controlapi.BuildHistoryInputDiff {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var diffs []*controlapi.BuildHistoryInputDiff
+	for _, k := range keys {
+		if a[k] != b[k] {
+			diffs = append(diffs, &controlapi.BuildHistoryInputDiff{
+				Key:          prefix + k,
+				Value:        a[k],
+				CompareValue: b[k],
+			})
+		}
+	}
+	return diffs
+}
+
+// flattenJSON flattens a decoded JSON value to dotted keys, e.g.
+// "exec.meta.args[0]".
+func flattenJSON(prefix string, v any, out map[string]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, vv := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flattenJSON(k, vv, out)
+		}
+	case []any:
+		for i, vv := range v {
+			flattenJSON(prefix+"["+strconv.Itoa(i)+"]", vv, out)
+		}
+	case string:
+		out[prefix] = v
+	case nil:
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+func sortedKeys(m map[string]*diffBuild) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func hasCommonKey(a, b map[string]*diffBuild) bool {
+	for k := range a {
+		if _, ok := b[k]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/solver"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/cachedigest"
+	"github.com/moby/buildkit/util/tenant"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+type testDiffBuild struct {
+	image     string
+	env       []string
+	imageMap  digest.Digest
+	contextCS digest.Digest
+}
+
+// makeDiffBuild returns the provenance and the cache info of a build running
+// a command on an image with a local context mounted.
+func makeDiffBuild(t *testing.T, b testDiffBuild) ([]byte, []solver.VertexCacheInfo) {
+	ops := []*pb.Op{
+		{Op: &pb.Op_Source{Source: &pb.SourceOp{Identifier: "docker-image://" + b.image}}},
+		{Op: &pb.Op_Source{Source: &pb.SourceOp{Identifier: "local://context"}}},
+		{Op: &pb.Op_Exec{Exec: &pb.ExecOp{
+			Meta: &pb.Meta{Args: []string{"make"}, Env: b.env, Cwd: "/"},
+			Mounts: []*pb.Mount{
+				{Input: 0, Dest: "/", Output: 0},
+				{Input: 1, Dest: "/src", Output: -1, Readonly: true},
+			},
+		}}},
+		{},
+	}
+	inputs := [][]string{nil, nil, {"step0:0", "step1:0"}, {"step2:0"}}
+
+	bc := &provenancetypes.BuildConfig{DigestMapping: map[digest.Digest]string{}}
+	digests := make([]digest.Digest, len(ops))
+	for i, op := range ops {
+		dt, err := json.Marshal(op)
+		require.NoError(t, err)
+		digests[i] = digest.FromBytes(append(dt, fmt.Sprint(inputs[i])...))
+		id := fmt.Sprintf("step%d", i)
+		bc.DigestMapping[digests[i]] = id
+		bc.Definition = append(bc.Definition, provenancetypes.BuildStep{
+			ID:     id,
+			Op:     op,
+			Inputs: inputs[i],
+		})
+	}
+	var p provenancetypes.ProvenancePredicateSLSA1
+	p.BuildDefinition.InternalParameters.BuildConfig = bc
+	dt, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	info := []solver.VertexCacheInfo{
+		{Digest: digests[0], Name: "image", CacheMaps: []digest.Digest{b.imageMap}},
+		{Digest: digests[1], Name: "context", CacheMaps: []digest.Digest{digest.FromString("local")}},
+		{
+			Digest:    digests[2],
+			Name:      "make",
+			CacheMaps: []digest.Digest{digest.FromString(fmt.Sprint(b.env))},
+			Deps:      []solver.DepCacheInfo{{}, {ContentDigest: b.contextCS}},
+		},
+	}
+	return dt, info
+}
+
+type testCacheDigestDB map[digest.Digest][]cachedigest.Frame
+
+func (db testCacheDigestDB) add(typ cachedigest.Type, data ...string) digest.Digest {
+	var all string
+	frames := []cachedigest.Frame{}
+	for _, d := range data {
+		all += d
+		frames = append(frames, cachedigest.Frame{ID: cachedigest.FrameIDData, Data: []byte(d)})
+	}
+	dgst := digest.FromString(string(typ) + all)
+	db[dgst] = append([]cachedigest.Frame{{ID: cachedigest.FrameIDType, Data: []byte(typ)}}, frames...)
+	return dgst
+}
+
+func (db testCacheDigestDB) lookup(dgst digest.Digest) (cachedigest.Type, []cachedigest.Frame, error) {
+	frames, ok := db[dgst]
+	if !ok {
+		return "", nil, cachedigest.ErrNotFound
+	}
+	return cachedigest.Type(frames[0].Data), frames[1:], nil
+}
+
+func TestDiffBuilds(t *testing.T) {
+	db := testCacheDigestDB{}
+	fileA := digest.FromString("a")
+	fileB := digest.FromString("b")
+	fileC := digest.FromString("c")
+
+	dirA := db.add(cachedigest.TypeFileList, "\x00main.go", fileA.String())
+	dirB := db.add(cachedigest.TypeFileList, "\x00main.go", fileB.String())
+	csA := db.add(cachedigest.TypeDigestList, db.add(cachedigest.TypeFileList, "Makefile", fileC.String(), "pkg", dirA.String()).String())
+	csB := db.add(cachedigest.TypeDigestList, db.add(cachedigest.TypeFileList, "Makefile", fileC.String(), "pkg", dirB.String()).String())
+	imageMap := db.add(cachedigest.TypeString, "source:docker-image://alpine@sha256:1")
+
+	parse := func(b testDiffBuild) *diffBuild {
+		dt, info := makeDiffBuild(t, b)
+		build, err := parseDiffBuild(dt, info)
+		require.NoError(t, err)
+		require.NotNil(t, build)
+		return build
+	}
+
+	base := testDiffBuild{image: "alpine", env: []string{"A=1"}, imageMap: imageMap, contextCS: csA}
+
+	t.Run("same", func(t *testing.T) {
+		out := diffBuilds(parse(base), parse(base), db.lookup)
+		require.Empty(t, out)
+	})
+
+	t.Run("env and files", func(t *testing.T) {
+		b := base
+		b.env = []string{"A=2"}
+		b.contextCS = csB
+		out := diffBuilds(parse(base), parse(b), db.lookup)
+		require.Len(t, out, 1)
+		require.Equal(t, "make", out[0].Name)
+		require.NotEqual(t, out[0].Digest, out[0].CompareDigest)
+		require.Equal(t, []*controlapi.BuildHistoryInputDiff{
+			{Key: "exec.meta.env[0]", Value: "A=1", CompareValue: "A=2"},
+			{Key: "input[1]:/pkg/main.go", Value: fileA.String(), CompareValue: fileB.String()},
+		}, out[0].Inputs)
+	})
+
+	t.Run("source resolved", func(t *testing.T) {
+		b := base
+		b.imageMap = db.add(cachedigest.TypeString, "source:docker-image://alpine@sha256:2")
+		out := diffBuilds(parse(base), parse(b), db.lookup)
+		require.Len(t, out, 1)
+		require.Equal(t, "image", out[0].Name)
+		require.Equal(t, []*controlapi.BuildHistoryInputDiff{
+			{Key: "cache.map", Value: "source:docker-image://alpine@sha256:1", CompareValue: "source:docker-image://alpine@sha256:2"},
+		}, out[0].Inputs)
+	})
+
+	t.Run("source changed", func(t *testing.T) {
+		b := base
+		b.image = "busybox"
+		out := diffBuilds(parse(base), parse(b), db.lookup)
+		require.Len(t, out, 1)
+		require.Equal(t, "image", out[0].Name)
+		require.Equal(t, []*controlapi.BuildHistoryInputDiff{
+			{Key: "source.identifier", Value: "docker-image://alpine", CompareValue: "docker-image://busybox"},
+		}, out[0].Inputs)
+	})
+
+	t.Run("no debug data", func(t *testing.T) {
+		b := base
+		b.contextCS = csB
+		out := diffBuilds(parse(base), parse(b), testCacheDigestDB{}.lookup)
+		require.Len(t, out, 1)
+		require.Equal(t, []*controlapi.BuildHistoryInputDiff{
+			{Key: "input[1].content", Value: csA.String(), CompareValue: csB.String()},
+		}, out[0].Inputs)
+	})
+}
+
+func TestLoadDiffBuildsTenant(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "history.db"), 0600, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	br := &controlapi.BuildHistoryRecord{Ref: "ref1", Tenant: "team-a"}
+	dt, err := br.MarshalVT()
+	require.NoError(t, err)
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(recordsBucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(br.Ref), dt)
+	})
+	require.NoError(t, err)
+
+	h := &Queue{opt: QueueOpt{DB: db}}
+
+	// the record is found, but has no provenance
+	_, err = h.loadDiffBuilds(tenant.WithID(context.Background(), "team-a"), "ref1")
+	require.ErrorContains(t, err, "no provenance")
+
+	_, err = h.loadDiffBuilds(context.Background(), "ref1")
+	require.ErrorContains(t, err, "no provenance")
+
+	// records of other tenants are not found
+	_, err = h.loadDiffBuilds(tenant.WithID(context.Background(), "team-b"), "ref1")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
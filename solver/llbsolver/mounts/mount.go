@@ -382,6 +382,15 @@ func (mm *MountManager) MountableSecret(ctx context.Context, m *pb.Mount, g sess
 	return mm.getSecretMountable(ctx, m, g)
 }
 
+// SecretValue returns the value of a mountable returned by MountableSecret.
+func SecretValue(m cache.Mountable) ([]byte, bool) {
+	sm, ok := m.(*secretMount)
+	if !ok {
+		return nil, false
+	}
+	return sm.data, true
+}
+
 func (mm *MountManager) MountableSSH(ctx context.Context, m *pb.Mount, g session.Group) (cache.Mountable, error) {
 	return mm.getSSHMountable(ctx, m, g)
 }
@@ -2,16 +2,38 @@ package llbsolver
 
 import (
 	"context"
+	"io"
 
+	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source/containerblob/blobfetch"
+	srctypes "github.com/moby/buildkit/source/types"
 	"github.com/moby/buildkit/sourcepolicy"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/network"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 )
 
-const keyProxyNetwork = "llb.proxy-network"
+const (
+	keyProxyNetwork      = "llb.proxy-network"
+	keyProxyEgressPolicy = "llb.proxy-egress-policy"
+	keyProxyReplay       = "llb.proxy-replay"
+
+	// frontendOptProxyEgressPolicy is the solve request frontend attribute
+	// carrying a JSON egress policy for the proxy network.
+	frontendOptProxyEgressPolicy = "proxy-egress-policy"
+	// frontendOptProxyReplay is the solve request frontend attribute
+	// carrying a JSON replay config for the proxy network.
+	frontendOptProxyReplay = "proxy-replay"
+)
+
+type proxyReplay struct {
+	sessionID string
+	store     string
+	materials map[string]digest.Digest
+}
 
 func proxyNetworkForOp(op *pb.Op, proxyNetwork bool) (bool, error) {
 	exec := op.GetExec()
@@ -35,6 +57,14 @@ func (b *provenanceBridge) ProxyPolicy() (network.ProxyPolicy, error) {
 	return b.llbBridge.ProxyPolicy()
 }
 
+func (b *provenanceBridge) ProxyEgress() (network.EgressPolicies, error) {
+	return b.llbBridge.ProxyEgress()
+}
+
+func (b *provenanceBridge) ProxyReplay() (*network.ProxyReplay, error) {
+	return b.llbBridge.ProxyReplay()
+}
+
 func (b *provenanceBridge) ProxyNetwork() bool {
 	return b.llbBridge.ProxyNetwork()
 }
@@ -75,3 +105,96 @@ func (b *llbBridge) ProxyPolicy() (network.ProxyPolicy, error) {
 	}
 	return b.policy(sourcepolicy.NewEngine(policies)), nil
 }
+
+func (b *llbBridge) ProxyEgress() (network.EgressPolicies, error) {
+	return loadProxyEgressPolicies(b.builder)
+}
+
+func loadProxyEgressPolicies(b solver.Builder) (network.EgressPolicies, error) {
+	if b == nil {
+		return nil, nil
+	}
+	var policies network.EgressPolicies
+	err := b.EachValue(context.TODO(), keyProxyEgressPolicy, func(v any) error {
+		p, ok := v.(*network.EgressPolicy)
+		if !ok {
+			return errors.Errorf("invalid egress policy %T", v)
+		}
+		policies = append(policies, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (b *llbBridge) ProxyReplay() (*network.ProxyReplay, error) {
+	r, err := loadProxyReplay(b.builder)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return &network.ProxyReplay{
+		Materials: r.materials,
+		Open: func(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+			rc, _, err := blobfetch.FetchBlob(ctx, session.NewGroup(r.sessionID), blobfetch.FetchOpt{
+				Scheme:         srctypes.OCIBlobScheme,
+				Digest:         dgst,
+				SessionManager: b.sm,
+				SessionID:      r.sessionID,
+				StoreID:        r.store,
+			})
+			return rc, err
+		},
+	}, nil
+}
+
+func loadProxyReplay(b solver.Builder) (*proxyReplay, error) {
+	if b == nil {
+		return nil, nil
+	}
+	var replay *proxyReplay
+	err := b.EachValue(context.TODO(), keyProxyReplay, func(v any) error {
+		r, ok := v.(*proxyReplay)
+		if !ok {
+			return errors.Errorf("invalid proxy replay config %T", v)
+		}
+		if replay == nil {
+			replay = r
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// setProxyConfig applies the build-level proxy settings to an exec.
+func (b *llbBridge) setProxyConfig(cfg *network.ProxyConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	policy, err := b.ProxyPolicy()
+	if err != nil {
+		return err
+	}
+	egress, err := b.ProxyEgress()
+	if err != nil {
+		return err
+	}
+	replay, err := b.ProxyReplay()
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		cfg.Policy = policy
+	}
+	if egress != nil {
+		cfg.Egress = egress
+	}
+	if replay != nil {
+		cfg.Replay = replay
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package ops
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,6 +28,7 @@ import (
 	"github.com/moby/buildkit/solver/llbsolver/ops/opsutils"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/cachedigest"
+	"github.com/moby/buildkit/util/fairqueue"
 	"github.com/moby/buildkit/util/network"
 	"github.com/moby/buildkit/util/progress/logs"
 	utilsystem "github.com/moby/buildkit/util/system"
@@ -33,7 +37,6 @@ import (
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/sync/semaphore"
 )
 
 const execCacheType = "buildkit.exec.v0"
@@ -47,17 +50,19 @@ type ExecOp struct {
 	w              worker.Worker
 	platform       *pb.Platform
 	numInputs      int
-	parallelism    *semaphore.Weighted
+	parallelism    *fairqueue.Semaphore
 	rec            resourcestypes.Recorder
 	digest         digest.Digest
 	linuxResources *pb.LinuxResources
 	proxyNetwork   bool
 	proxyCap       *network.ProxyCapture
+	secretCacheKey []byte
+	netProvider    network.Provider
 }
 
 var _ solver.Op = &ExecOp{}
 
-func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.Manager, parallelism *semaphore.Weighted, sm *session.Manager, exec executor.Executor, w worker.Worker, linuxResources *pb.LinuxResources, proxyNetwork bool) (*ExecOp, error) {
+func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.Manager, parallelism *fairqueue.Semaphore, sm *session.Manager, exec executor.Executor, w worker.Worker, linuxResources *pb.LinuxResources, proxyNetwork bool, secretCacheKey []byte, netProvider network.Provider) (*ExecOp, error) {
 	if err := opsutils.Validate(&pb.Op{Op: op}); err != nil {
 		return nil, err
 	}
@@ -75,6 +80,8 @@ func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.
 		digest:         v.Digest(),
 		linuxResources: linuxResources,
 		proxyNetwork:   proxyNetwork,
+		secretCacheKey: secretCacheKey,
+		netProvider:    netProvider,
 	}, nil
 }
 
@@ -160,22 +167,31 @@ func (e *ExecOp) CacheMap(ctx context.Context, jobCtx solver.JobContext, index i
 		op.Mounts = nil
 	}
 
+	secretDigests, err := secretCacheDigests(ctx, e.op, e.secretCacheKey, func(ctx context.Context, id string) ([]byte, error) {
+		return e.loadSecret(ctx, jobCtx.Session(), id)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
 	dt, err := json.Marshal(struct {
-		Type       string
-		Exec       *pb.ExecOp
-		OS         string
-		Arch       string
-		Variant    string   `json:",omitempty"`
-		OSVersion  string   `json:",omitempty"`
-		OSFeatures []string `json:",omitempty"`
+		Type          string
+		Exec          *pb.ExecOp
+		OS            string
+		Arch          string
+		Variant       string            `json:",omitempty"`
+		OSVersion     string            `json:",omitempty"`
+		OSFeatures    []string          `json:",omitempty"`
+		SecretDigests map[string]string `json:",omitempty"`
 	}{
-		Type:       execCacheType,
-		Exec:       op,
-		OS:         p.OS,
-		Arch:       p.Architecture,
-		Variant:    p.Variant,
-		OSVersion:  p.OSVersion,
-		OSFeatures: p.OSFeatures,
+		Type:          execCacheType,
+		Exec:          op,
+		OS:            p.OS,
+		Arch:          p.Architecture,
+		Variant:       p.Variant,
+		OSVersion:     p.OSVersion,
+		OSFeatures:    p.OSFeatures,
+		SecretDigests: secretDigests,
 	})
 	if err != nil {
 		return nil, false, err
@@ -494,7 +510,7 @@ func (e *ExecOp) Exec(ctx context.Context, jobCtx solver.JobContext, inputs []so
 		meta.Env = addDefaultEnvvar(meta.Env, "PATH", utilsystem.DefaultPathEnv(currentOS))
 	}
 
-	secretEnv, err := e.loadSecretEnv(ctx, g)
+	secretEnv, redactEnv, err := e.loadSecretEnv(ctx, g)
 	if err != nil {
 		return nil, err
 	}
@@ -508,6 +524,10 @@ func (e *ExecOp) Exec(ctx context.Context, jobCtx solver.JobContext, inputs []so
 	}
 
 	stdout, stderr, flush := logs.NewLogStreams(ctx, os.Getenv("BUILDKIT_DEBUG_EXEC_OUTPUT") == "1")
+	if redact := append(p.Secrets, redactEnv...); len(redact) > 0 {
+		stdout = logs.NewRedactWriter(stdout, redact)
+		stderr = logs.NewRedactWriter(stderr, redact)
+	}
 	defer stdout.Close()
 	defer stderr.Close()
 	defer func() {
@@ -521,14 +541,30 @@ func (e *ExecOp) Exec(ctx context.Context, jobCtx solver.JobContext, inputs []so
 		meta.Proxy.Capture = e.proxyCap
 	}
 
+	stopServices := func() {}
+	if len(e.op.Services) > 0 {
+		var ns network.Namespace
+		ns, stopServices, err = e.startServices(ctx, g, refs, extraHosts, stderr)
+		if err != nil {
+			return nil, err
+		}
+		defer stopServices()
+		meta.NetworkNamespace = ns
+		meta.ExtraHosts = append(meta.ExtraHosts, serviceHosts(e.op.Services)...)
+	}
+
 	rec, execErr := e.exec.Run(ctx, "", p.Root, p.Mounts, executor.ProcessInfo{
 		Meta:   meta,
 		Stdin:  nil,
 		Stdout: stdout,
 		Stderr: stderr,
 	}, nil)
+	stopServices()
 	if e.proxyCap != nil {
 		logProxyRequests(stderr, e.proxyCap.Requests())
+		if denied := e.proxyCap.Denied(); len(denied) > 0 {
+			execErr = errdefs.WithEgressDenied(execErr, denied[0].Method, denied[0].URL, denied[0].Reason)
+		}
 	}
 
 	for i, out := range p.OutputRefs {
@@ -602,30 +638,92 @@ func (e *ExecOp) Acquire(ctx context.Context) (solver.ReleaseFunc, error) {
 	}, nil
 }
 
-func (e *ExecOp) loadSecretEnv(ctx context.Context, g session.Group) ([]string, error) {
+// loadSecretEnv returns the environment variables of the secrets and the
+// values that should be redacted from the logs.
+func (e *ExecOp) loadSecretEnv(ctx context.Context, g session.Group) ([]string, [][]byte, error) {
 	secretenv := e.op.Secretenv
 	if len(secretenv) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 	out := make([]string, 0, len(secretenv))
+	var redact [][]byte
 	for _, sopt := range secretenv {
 		id := sopt.ID
 		if id == "" {
-			return nil, errors.Errorf("secret ID missing for %q environment variable", sopt.Name)
+			return nil, nil, errors.Errorf("secret ID missing for %q environment variable", sopt.Name)
+		}
+		dt, err := e.loadSecret(ctx, g, id)
+		if err != nil && (!errors.Is(err, secrets.ErrNotFound) || !sopt.Optional) {
+			return nil, nil, err
+		}
+		out = append(out, fmt.Sprintf("%s=%s", sopt.Name, string(dt)))
+		if !sopt.NoRedact {
+			redact = append(redact, dt)
 		}
-		var dt []byte
+	}
+	return out, redact, nil
+}
+
+func (e *ExecOp) loadSecret(ctx context.Context, g session.Group, id string) ([]byte, error) {
+	var dt []byte
+	err := e.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
 		var err error
-		err = e.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
-			dt, err = secrets.GetSecret(ctx, caller, id)
-			if err != nil {
+		dt, err = secrets.GetSecret(ctx, caller, id)
+		return err
+	})
+	return dt, err
+}
+
+// secretCacheDigests returns the HMAC of the values of the secrets that have
+// CacheDigest set, keyed by secret ID. The plaintext value never becomes part
+// of the cache key. A missing optional secret is recorded so that providing
+// it later invalidates the cache.
+func secretCacheDigests(ctx context.Context, op *pb.ExecOp, key []byte, load func(context.Context, string) ([]byte, error)) (map[string]string, error) {
+	var out map[string]string
+	add := func(id string, optional bool) error {
+		if id == "" {
+			return errors.Errorf("secret ID missing for cache digest")
+		}
+		if _, ok := out[id]; ok {
+			return nil
+		}
+		if len(key) == 0 {
+			return errors.Errorf("secret cache digests are not supported by this worker")
+		}
+		dt, err := load(ctx, id)
+		if err != nil {
+			if !errors.Is(err, secrets.ErrNotFound) || !optional {
 				return err
 			}
+			if out == nil {
+				out = map[string]string{}
+			}
+			out[id] = "missing"
 			return nil
-		})
-		if err != nil && (!errors.Is(err, secrets.ErrNotFound) || !sopt.Optional) {
+		}
+		h := hmac.New(sha256.New, key)
+		h.Write(dt)
+		if out == nil {
+			out = map[string]string{}
+		}
+		out[id] = "hmac-sha256:" + hex.EncodeToString(h.Sum(nil))
+		return nil
+	}
+	for _, m := range op.Mounts {
+		if m.MountType != pb.MountType_SECRET || m.SecretOpt == nil || !m.SecretOpt.CacheDigest {
+			continue
+		}
+		if err := add(m.SecretOpt.ID, m.SecretOpt.Optional); err != nil {
+			return nil, err
+		}
+	}
+	for _, se := range op.Secretenv {
+		if !se.CacheDigest {
+			continue
+		}
+		if err := add(se.ID, se.Optional); err != nil {
 			return nil, err
 		}
-		out = append(out, fmt.Sprintf("%s=%s", sopt.Name, string(dt)))
 	}
 	return out, nil
 }
@@ -0,0 +1,193 @@
+package ops
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/frontend/gateway/container"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/network"
+	"github.com/moby/buildkit/util/progress/logs"
+	utilsystem "github.com/moby/buildkit/util/system"
+	"github.com/moby/buildkit/worker"
+	"github.com/pkg/errors"
+)
+
+// serviceHostIP is the address services are reachable at from the exec and
+// from each other since they all share the same network namespace.
+var serviceHostIP = net.IPv4(127, 0, 0, 1)
+
+// serviceHosts returns the host entries resolving the names of the services.
+func serviceHosts(services []*pb.Service) []executor.HostIP {
+	hosts := make([]executor.HostIP, 0, len(services))
+	for _, svc := range services {
+		hosts = append(hosts, executor.HostIP{Host: svc.Name, IP: serviceHostIP})
+	}
+	return hosts
+}
+
+func validateServices(services []*pb.Service, numInputs int) error {
+	names := map[string]struct{}{}
+	for _, svc := range services {
+		if svc.Name == "" {
+			return errors.Errorf("service name is required")
+		}
+		if _, ok := names[svc.Name]; ok {
+			return errors.Errorf("duplicate service name %q", svc.Name)
+		}
+		names[svc.Name] = struct{}{}
+		if svc.Input < 0 || int(svc.Input) >= numInputs {
+			return errors.Errorf("invalid input index %d for service %s", svc.Input, svc.Name)
+		}
+		if svc.Meta == nil || len(svc.Meta.Args) == 0 {
+			return errors.Errorf("invalid service %s with no args", svc.Name)
+		}
+	}
+	return nil
+}
+
+// sharedNamespace is a network namespace joined by the exec and its services.
+// Containers don't close it, it is closed once all of them have exited.
+type sharedNamespace struct {
+	network.Namespace
+}
+
+func (ns *sharedNamespace) Close() error {
+	return nil
+}
+
+type runningService struct {
+	name   string
+	ref    cache.MutableRef
+	done   chan error
+	stdout io.WriteCloser
+	stderr io.WriteCloser
+}
+
+// startServices creates the network namespace shared by the exec and its
+// services and starts the service containers in it. The returned function
+// stops the services, waits for them to exit and releases the namespace. It
+// is safe to call it multiple times.
+func (e *ExecOp) startServices(ctx context.Context, g session.Group, refs []*worker.WorkerRef, extraHosts []executor.HostIP, stderr io.Writer) (network.Namespace, func(), error) {
+	if e.netProvider == nil {
+		return nil, nil, errors.Errorf("network mode %s is not supported for services", e.op.Network)
+	}
+	if e.op.Network == pb.NetMode_NONE {
+		return nil, nil, errors.Errorf("services are not supported with network mode %s", e.op.Network)
+	}
+	if e.proxyNetwork {
+		return nil, nil, errors.Errorf("services are not supported with the proxy network")
+	}
+	if err := validateServices(e.op.Services, len(refs)); err != nil {
+		return nil, nil, err
+	}
+
+	ns, err := e.netProvider.New(ctx, e.op.Meta.Hostname, network.NamespaceOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	var running []*runningService
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel(errors.WithStack(context.Canceled))
+			for _, rs := range running {
+				if err := <-rs.done; err != nil {
+					fmt.Fprintf(stderr, "service %s exited: %v\n", rs.name, err)
+				}
+				rs.stdout.Close()
+				rs.stderr.Close()
+				rs.ref.Release(context.TODO())
+			}
+			if err := ns.Close(); err != nil {
+				bklog.G(ctx).Errorf("failed to release service network: %+v", err)
+			}
+		})
+	}
+
+	hosts := append(extraHosts, serviceHosts(e.op.Services)...)
+	var currentOS string
+	if e.platform != nil {
+		currentOS = e.platform.OS
+	}
+
+	for _, svc := range e.op.Services {
+		desc := fmt.Sprintf("service %s for exec %s", svc.Name, strings.Join(e.op.Meta.Args, " "))
+		ref, err := e.cm.New(ctx, refs[svc.Input].ImmutableRef, g, cache.WithDescription(desc))
+		if err != nil {
+			stop()
+			return nil, nil, err
+		}
+
+		meta := executor.Meta{
+			Args:             svc.Meta.Args,
+			Env:              svc.Meta.Env,
+			Cwd:              svc.Meta.Cwd,
+			User:             svc.Meta.User,
+			Hostname:         svc.Name,
+			ExtraHosts:       hosts,
+			CgroupParent:     e.op.Meta.CgroupParent,
+			NetMode:          e.op.Network,
+			NetworkNamespace: &sharedNamespace{ns},
+		}
+		if meta.Cwd == "" {
+			meta.Cwd = "/"
+		}
+		if currentOS != "windows" {
+			meta.Env = addDefaultEnvvar(meta.Env, "PATH", utilsystem.DefaultPathEnv(currentOS))
+		}
+
+		stdout, stderr, _ := logs.NewLogStreams(ctx, false)
+		rs := &runningService{
+			name:   svc.Name,
+			ref:    ref,
+			done:   make(chan error, 1),
+			stdout: stdout,
+			stderr: stderr,
+		}
+		running = append(running, rs)
+
+		started := make(chan struct{})
+		go func() {
+			_, err := e.exec.Run(ctx, identity.NewID(), container.MountWithSession(ref, g), nil, executor.ProcessInfo{
+				Meta:   meta,
+				Stdout: stdout,
+				Stderr: stderr,
+			}, started)
+			if ctx.Err() != nil {
+				// stopped after the exec completed
+				err = nil
+			}
+			rs.done <- err
+		}()
+
+		select {
+		case <-started:
+		case <-ctx.Done():
+		}
+		select {
+		case err := <-rs.done:
+			// exited before the exec could start
+			rs.done <- nil
+			stop()
+			if err == nil {
+				err = errors.New("exited")
+			}
+			return nil, nil, errors.Wrapf(err, "failed to start service %s", svc.Name)
+		default:
+		}
+	}
+
+	return &sharedNamespace{ns}, stop, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/network"
@@ -156,6 +157,85 @@ func TestExecOpCacheMap(t *testing.T) {
 	}
 }
 
+func TestSecretCacheDigests(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	values := map[string]string{"token": "v1"}
+	load := func(_ context.Context, id string) ([]byte, error) {
+		v, ok := values[id]
+		if !ok {
+			return nil, errors.WithStack(secrets.ErrNotFound)
+		}
+		return []byte(v), nil
+	}
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	op := &pb.ExecOp{
+		Meta: &pb.Meta{},
+		Mounts: []*pb.Mount{
+			{Dest: "/run/secrets/token", MountType: pb.MountType_SECRET, SecretOpt: &pb.SecretOpt{ID: "token", CacheDigest: true}},
+			{Dest: "/run/secrets/other", MountType: pb.MountType_SECRET, SecretOpt: &pb.SecretOpt{ID: "other"}},
+		},
+		Secretenv: []*pb.SecretEnv{
+			{ID: "opt", Name: "OPT", Optional: true, CacheDigest: true},
+		},
+	}
+
+	d1, err := secretCacheDigests(ctx, op, key, load)
+	require.NoError(t, err)
+	require.Len(t, d1, 2)
+	require.Equal(t, "missing", d1["opt"])
+	require.NotContains(t, d1["token"], "v1")
+
+	// same value, same digest
+	d2, err := secretCacheDigests(ctx, op, key, load)
+	require.NoError(t, err)
+	require.Equal(t, d1, d2)
+
+	// different daemon key
+	d2, err = secretCacheDigests(ctx, op, []byte("fedcba9876543210fedcba9876543210"), load)
+	require.NoError(t, err)
+	require.NotEqual(t, d1["token"], d2["token"])
+
+	// changed value
+	values["token"] = "v2"
+	values["opt"] = "v3"
+	d2, err = secretCacheDigests(ctx, op, key, load)
+	require.NoError(t, err)
+	require.NotEqual(t, d1["token"], d2["token"])
+	require.NotEqual(t, "missing", d2["opt"])
+
+	// required secret is missing
+	op.Mounts[0].SecretOpt.ID = "nope"
+	_, err = secretCacheDigests(ctx, op, key, load)
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	// no secrets with cache digest
+	d2, err = secretCacheDigests(ctx, &pb.ExecOp{Meta: &pb.Meta{}}, nil, load)
+	require.NoError(t, err)
+	require.Nil(t, d2)
+}
+
+func TestValidateServices(t *testing.T) {
+	t.Parallel()
+
+	svc := func(name string, input int64) *pb.Service {
+		return &pb.Service{Name: name, Input: input, Meta: &pb.Meta{Args: []string{"serve"}}}
+	}
+
+	require.NoError(t, validateServices([]*pb.Service{svc("db", 1), svc("cache", 1)}, 2))
+	require.ErrorContains(t, validateServices([]*pb.Service{svc("", 1)}, 2), "name is required")
+	require.ErrorContains(t, validateServices([]*pb.Service{svc("db", 0), svc("db", 1)}, 2), "duplicate service name")
+	require.ErrorContains(t, validateServices([]*pb.Service{svc("db", 2)}, 2), "invalid input index")
+	require.ErrorContains(t, validateServices([]*pb.Service{{Name: "db", Input: 0, Meta: &pb.Meta{}}}, 1), "no args")
+
+	hosts := serviceHosts([]*pb.Service{svc("db", 1)})
+	require.Len(t, hosts, 1)
+	require.Equal(t, "db", hosts[0].Host)
+	require.Equal(t, "127.0.0.1", hosts[0].IP.String())
+}
+
 func TestExecOpContentCache(t *testing.T) {
 	type testCase struct {
 		name string
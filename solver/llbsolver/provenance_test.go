@@ -1,11 +1,17 @@
 package llbsolver
 
 import (
+	"context"
 	"testing"
 
+	"github.com/moby/buildkit/executor"
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/solver/llbsolver/provenance"
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -26,3 +32,40 @@ func TestIncompleteMaterialsErrorIncludesLocalSources(t *testing.T) {
 	require.ErrorContains(t, err, "Uncaptured local sources")
 	require.ErrorContains(t, err, "context")
 }
+
+// nestedFrontend requests the frontend in its "frontend" option, like the
+// dockerfile frontend does for a syntax directive.
+type nestedFrontend struct{}
+
+func (nestedFrontend) Solve(ctx context.Context, llb frontend.FrontendLLBBridge, _ executor.Executor, opt map[string]string, _ map[string]*pb.Definition, sid string, _ *session.Manager) (*frontend.Result, error) {
+	return llb.Solve(ctx, frontend.SolveRequest{Frontend: opt["frontend"]}, sid)
+}
+
+func TestSolveAllowedFrontends(t *testing.T) {
+	s := solver.NewSolver(solver.SolverOpt{})
+	defer s.Close()
+
+	j, err := s.NewJob("job0")
+	require.NoError(t, err)
+	defer j.Discard()
+	j.SetValue(keyAllowedFrontends, []string{"dockerfile.v0", "frontend.v0"})
+
+	b := &provenanceBridge{llbBridge: &llbBridge{
+		builder: j,
+		frontends: map[string]frontend.Frontend{
+			"dockerfile.v0": nestedFrontend{},
+			"frontend.v0":   nestedFrontend{},
+			"gateway.v0":    nestedFrontend{},
+		},
+	}}
+	ctx := t.Context()
+
+	_, err = b.Solve(ctx, frontend.SolveRequest{Frontend: "gateway.v0"}, "")
+	require.ErrorContains(t, err, "frontend gateway.v0 is not allowed")
+
+	_, err = b.Solve(ctx, frontend.SolveRequest{Frontend: "dockerfile.v0", FrontendOpt: map[string]string{"frontend": "gateway.v0"}}, "")
+	require.ErrorContains(t, err, "frontend gateway.v0 is not allowed")
+
+	_, err = b.Solve(ctx, frontend.SolveRequest{Frontend: "dockerfile.v0", FrontendOpt: map[string]string{"frontend": "frontend.v0"}}, "")
+	require.NoError(t, err)
+}
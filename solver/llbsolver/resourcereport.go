@@ -0,0 +1,77 @@
+package llbsolver
+
+import (
+	"encoding/json"
+
+	resourcestypes "github.com/moby/buildkit/executor/resources/types"
+	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/exptypes"
+	"github.com/moby/buildkit/exporter/util/resourcereport"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+func resourceReportRequested(exporters []exporter.ExporterInstance) bool {
+	for _, e := range exporters {
+		if f, err := resourcereport.ParseFormat(e.Attrs()[string(exptypes.OptKeyResourceReport)]); err == nil && f != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// addResourceReport adds the resource usage report of the job to the
+// metadata of the result.
+func addResourceReport(res *Result, j *solver.Job) error {
+	samples := map[digest.Digest]*resourcestypes.Samples{}
+	addSamples := func(c *provenance.Capture) {
+		if c == nil {
+			return
+		}
+		for dgst, s := range c.Samples {
+			samples[dgst] = s
+		}
+	}
+	if res.Provenance != nil {
+		addSamples(res.Provenance.Ref)
+		for _, c := range res.Provenance.Refs {
+			addSamples(c)
+		}
+	}
+
+	report := newResourceReport(j.VertexStatus(), samples)
+	dt, err := json.Marshal(report)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	res.AddMeta(exptypes.ExporterResourceReportKey, dt)
+	return nil
+}
+
+func newResourceReport(vertexes []solver.VertexStatus, samples map[digest.Digest]*resourcestypes.Samples) *resourcereport.Report {
+	report := &resourcereport.Report{
+		Steps: make([]resourcereport.Step, 0, len(vertexes)),
+	}
+	for _, v := range vertexes {
+		step := resourcereport.Step{
+			Digest:    v.Digest,
+			Name:      v.Name,
+			Started:   v.Started,
+			Completed: v.Completed,
+			// vertices that were not executed only computed their cache
+			// key because a later vertex was loaded from cache
+			Cached: v.Cached || v.CacheMiss == nil,
+		}
+		if v.Started != nil && v.Completed != nil {
+			step.WallTimeNanos = v.Completed.Sub(*v.Started).Nanoseconds()
+		}
+		if !step.Cached {
+			step.CacheMiss = string(v.CacheMiss.Reason)
+		}
+		step.AddSamples(samples[v.Digest])
+		report.Steps = append(report.Steps, step)
+	}
+	return report
+}
@@ -20,13 +20,19 @@ import (
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/solver/llbsolver/compat"
 	"github.com/moby/buildkit/solver/llbsolver/history"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/solver/result"
+	srctypes "github.com/moby/buildkit/source/types"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/fairqueue"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/network"
 	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/tenant"
 	"github.com/moby/buildkit/worker"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
@@ -61,8 +67,10 @@ type Opt struct {
 	HistoryQueue     *history.Queue
 	ResourceMonitor  *resources.Monitor
 	ProxyNetwork     bool
-	ProvenanceEnv    map[string]any
-	MeterProvider    metric.MeterProvider
+	// ShareBaseImages shares pulled images between tenants.
+	ShareBaseImages bool
+	ProvenanceEnv   map[string]any
+	MeterProvider   metric.MeterProvider
 }
 
 type Solver struct {
@@ -78,6 +86,7 @@ type Solver struct {
 	history                   *history.Queue
 	sysSampler                *resources.Sampler[*resourcestypes.SysSample]
 	proxyNetwork              bool
+	shareBaseImages           bool
 	provenanceEnv             map[string]any
 	provenanceStore           *provenanceStore
 	metrics                   *buildMetrics
@@ -116,6 +125,7 @@ func New(opt Opt) (*Solver, error) {
 		entitlements:              opt.Entitlements,
 		history:                   opt.HistoryQueue,
 		proxyNetwork:              opt.ProxyNetwork,
+		shareBaseImages:           opt.ShareBaseImages,
 		provenanceEnv:             opt.ProvenanceEnv,
 		provenanceStore:           newProvenanceStore(),
 		metrics:                   bm,
@@ -152,6 +162,8 @@ func (s *Solver) resolver() solver.ResolveOpFunc {
 		return w.ResolveOp(v, br, s.sm, worker.ProxyOpt{
 			Network: br.ProxyNetwork(),
 			Policy:  br.ProxyPolicy,
+			Egress:  br.ProxyEgress,
+			Replay:  br.ProxyReplay,
 		})
 	}
 }
@@ -192,7 +204,7 @@ func (s *Solver) Bridge(b solver.Builder) frontend.FrontendLLBBridge {
 	return s.bridge(b)
 }
 
-func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req frontend.SolveRequest, compatibilityVersion int, exp ExporterRequest, ent []entitlements.Entitlement, post []Processor, internal bool, srcPol *spb.Policy, policySession string, proxyNetwork bool) (_ *client.SolveResponse, err error) {
+func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req frontend.SolveRequest, compatibilityVersion int, exp ExporterRequest, ent []entitlements.Entitlement, post []Processor, internal bool, srcPol *spb.Policy, policySession string, proxyNetwork bool, deadline time.Time, schedClass fairqueue.Class) (_ *client.SolveResponse, err error) {
 	hasNamedDockerfileContext := false
 	for k := range req.FrontendOpt {
 		if k == "context:dockerfile.v0" || strings.HasPrefix(k, "context:dockerfile.v0::") {
@@ -245,9 +257,40 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		return nil, err
 	}
 	j.SetValue(keyEntitlements, set)
+	if names, ok := frontend.AllowedFrontends(ctx); ok {
+		j.SetValue(keyAllowedFrontends, names)
+	}
 	if proxyNetwork {
 		j.SetValue(keyProxyNetwork, true)
 	}
+	if v, ok := req.FrontendOpt[frontendOptProxyEgressPolicy]; ok {
+		if !proxyNetwork && !s.proxyNetwork {
+			return nil, errors.New("proxy egress policy requires proxy network to be enabled")
+		}
+		egress, err := network.ParseEgressPolicy([]byte(v))
+		if err != nil {
+			return nil, err
+		}
+		j.SetValue(keyProxyEgressPolicy, egress)
+	}
+	if v, ok := req.FrontendOpt[frontendOptProxyReplay]; ok {
+		if !proxyNetwork && !s.proxyNetwork {
+			return nil, errors.New("proxy replay requires proxy network to be enabled")
+		}
+		cfg, err := network.ParseProxyReplayConfig([]byte(v))
+		if err != nil {
+			return nil, err
+		}
+		materials, err := cfg.MaterialMap()
+		if err != nil {
+			return nil, err
+		}
+		j.SetValue(keyProxyReplay, &proxyReplay{
+			sessionID: sessionID,
+			store:     cfg.Store,
+			materials: materials,
+		})
+	}
 
 	if srcPol != nil {
 		if err := validateSourcePolicy(srcPol); err != nil {
@@ -262,9 +305,24 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		compatibilityVersion = compat.CompatibilityVersionCurrent
 	}
 	j.SetValue(compat.JobValueKey, compatibilityVersion)
+	j.SetValue(fairqueue.JobValueKey, schedClass)
+	if id := tenant.FromContext(ctx); id != "" {
+		scope := solver.TenantScope{ID: id}
+		if s.shareBaseImages {
+			scope.Shared = isImageSource
+		}
+		j.SetTenantScope(scope)
+	}
 
 	j.SessionID = sessionID
 
+	if !deadline.IsZero() {
+		j.SetDeadline(deadline)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadlineCause(ctx, deadline, errdefs.WithDeadline(errors.WithStack(context.DeadlineExceeded)))
+		defer cancel()
+	}
+
 	br := s.bridge(j, withBridgeProxyNetwork(proxyNetwork || s.proxyNetwork))
 	defer br.releaseProvenanceRefs()
 	rootReq := req.Clone()
@@ -349,6 +407,11 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		}
 		resProv = res2
 	}
+	if resourceReportRequested(exp.Exporters) {
+		if err := addResourceReport(resProv, j); err != nil {
+			return nil, err
+		}
+	}
 	res = resProv.Result
 
 	cached, err := result.ConvertResult(res, func(res solver.ResultProxy) (solver.CachedResult, error) {
@@ -472,6 +535,11 @@ func (s *Solver) Status(ctx context.Context, id string, statusChan chan *client.
 		close(statusChan)
 		return err
 	}
+	// hide active builds of other tenants like their history records
+	if id := tenant.FromContext(ctx); id != "" && j.TenantScope().ID != id {
+		close(statusChan)
+		return errdefs.NewUnknownJobError(id)
+	}
 	return j.Status(ctx, statusChan)
 }
 
@@ -532,3 +600,13 @@ func notifyStarted(ctx context.Context, v *client.Vertex) func(err error) {
 		pw.Write(id, *v)
 	}
 }
+
+// isImageSource returns true for vertices pulling an image from a registry.
+func isImageSource(v solver.Vertex) bool {
+	op, ok := v.Sys().(*pb.Op)
+	if !ok {
+		return false
+	}
+	src := op.GetSource()
+	return src != nil && strings.HasPrefix(src.Identifier, srctypes.DockerImageScheme+"://")
+}
@@ -0,0 +1,71 @@
+package llbsolver
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	ctdmetadata "github.com/containerd/containerd/v2/core/metadata"
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/moby/buildkit/client"
+	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/llbsolver/history"
+	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/tenant"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestHistoryQueue(t *testing.T) *history.Queue {
+	tmpdir := t.TempDir()
+	store, err := local.NewStore(tmpdir)
+	require.NoError(t, err)
+	db, err := bolt.Open(filepath.Join(tmpdir, "containerdmeta.db"), 0644, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	mdb := ctdmetadata.NewDB(db, store, map[string]snapshots.Snapshotter{})
+	require.NoError(t, mdb.Init(t.Context()))
+
+	hdb, err := bolt.Open(filepath.Join(tmpdir, "history.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, hdb.Close())
+	})
+	hq, err := history.NewQueue(history.QueueOpt{
+		DB:           hdb,
+		LeaseManager: leaseutil.WithNamespace(ctdmetadata.NewLeaseManager(mdb), "buildkit"),
+		ContentStore: containerdsnapshot.NewContentStore(mdb.ContentStore(), "buildkit"),
+	})
+	require.NoError(t, err)
+	return hq
+}
+
+func TestStatusTenant(t *testing.T) {
+	s := solver.NewSolver(solver.SolverOpt{})
+	defer s.Close()
+
+	j, err := s.NewJob("job0")
+	require.NoError(t, err)
+	defer j.Discard()
+	j.SetTenantScope(solver.TenantScope{ID: "a"})
+
+	ls := &Solver{solver: s, history: newTestHistoryQueue(t)}
+
+	// canceled requests return right away instead of streaming the status
+	ctxA, cancel := context.WithCancelCause(tenant.WithID(t.Context(), "a"))
+	cancel(context.Canceled)
+	ctxB, cancel := context.WithCancelCause(tenant.WithID(t.Context(), "b"))
+	cancel(context.Canceled)
+
+	err = ls.Status(ctxB, "job0", make(chan *client.SolveStatus, 16))
+	var unknownJob *errdefs.UnknownJobError
+	require.ErrorAs(t, err, &unknownJob)
+
+	err = ls.Status(ctxA, "job0", make(chan *client.SolveStatus, 16))
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/containerd/platforms"
 	"github.com/moby/buildkit/solver"
@@ -274,6 +275,7 @@ func vertexOptions(opMeta *pb.OpMetadata) solver.VertexOptions {
 			opt.ExportCache = &opMeta.ExportCache.Value
 		}
 		opt.ProgressGroup = opMeta.ProgressGroup
+		opt.Timeout = time.Duration(opMeta.Timeout)
 	}
 	return opt
 }
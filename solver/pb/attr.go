@@ -12,6 +12,8 @@ const AttrGitMTime = "git.mtime"
 const AttrGitFetchByCommit = "git.fetchbycommit"
 const AttrGitBundle = "git.bundle"
 const AttrGitCheckoutBundle = "git.checkoutbundle"
+const AttrGitSparsePaths = "git.sparsepaths"
+const AttrGitLFS = "git.lfs"
 
 const AttrGitSignatureVerifyPubKey = "git.sig.pubkey"
 const AttrGitSignatureVerifyRejectExpired = "git.sig.rejectexpired"
@@ -38,6 +40,7 @@ const AttrHTTPAuthHeaderSecret = "http.authheadersecret"
 const AttrHTTPHeaderPrefix = "http.header."
 const AttrHTTPSignatureVerifyPubKey = "http.sig.pubkey"
 const AttrHTTPSignatureVerify = "http.sig.signature"
+const AttrHTTPMirrors = "http.mirrors"
 
 const AttrImageResolveMode = "image.resolvemode"
 const AttrImageResolveModeDefault = "default"
@@ -38,6 +38,8 @@ const (
 	CapSourceGitFetchByCommit   apicaps.CapID = "source.git.fetchbycommit"
 	CapSourceGitBundle          apicaps.CapID = "source.git.bundle"
 	CapSourceGitCheckoutBundle  apicaps.CapID = "source.git.checkoutbundle"
+	CapSourceGitSparsePaths     apicaps.CapID = "source.git.sparsepaths"
+	CapSourceGitLFS             apicaps.CapID = "source.git.lfs"
 
 	CapSourceHTTP         apicaps.CapID = "source.http"
 	CapSourceHTTPAuth     apicaps.CapID = "source.http.auth"
@@ -47,6 +49,7 @@ const (
 	CapSourceHTTPUIDGID          apicaps.CapID = "soruce.http.uidgid"
 	CapSourceHTTPHeader          apicaps.CapID = "source.http.header"
 	CapSourceHTTPSignatureVerify apicaps.CapID = "source.http.signatureverify"
+	CapSourceHTTPMirrors         apicaps.CapID = "source.http.mirrors"
 
 	CapSourceImageBlob apicaps.CapID = "source.imageblob"
 
@@ -79,6 +82,8 @@ const (
 	CapExecCgroupsMounted                apicaps.CapID = "exec.cgroup"
 	CapExecSecretEnv                     apicaps.CapID = "exec.secretenv"
 	CapExecValidExitCode                 apicaps.CapID = "exec.validexitcode"
+	CapExecSecretCacheDigest             apicaps.CapID = "exec.secret.cachedigest"
+	CapExecServices                      apicaps.CapID = "exec.services"
 
 	CapFileBase                               apicaps.CapID = "file.base"
 	CapFileRmWildcard                         apicaps.CapID = "file.rm.wildcard"
@@ -95,6 +100,7 @@ const (
 	CapMetaIgnoreCache apicaps.CapID = "meta.ignorecache"
 	CapMetaDescription apicaps.CapID = "meta.description"
 	CapMetaExportCache apicaps.CapID = "meta.exportcache"
+	CapMetaTimeout     apicaps.CapID = "meta.timeout"
 
 	CapRemoteCacheGHA    apicaps.CapID = "cache.gha"
 	CapRemoteCacheS3     apicaps.CapID = "cache.s3"
@@ -286,6 +292,18 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceGitSparsePaths,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceGitLFS,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapSourceHTTP,
 		Enabled: true,
@@ -328,6 +346,12 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceHTTPMirrors,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapSourceImageBlob,
 		Enabled: true,
@@ -490,6 +514,18 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapExecSecretCacheDigest,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapExecServices,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapFileBase,
 		Enabled: true,
@@ -566,6 +602,12 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapMetaTimeout,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapRemoteCacheGHA,
 		Enabled: true,
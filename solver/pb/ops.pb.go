@@ -608,6 +608,7 @@ type ExecOp struct {
 	Security      SecurityMode           `protobuf:"varint,4,opt,name=security,proto3,enum=pb.SecurityMode" json:"security,omitempty"`
 	Secretenv     []*SecretEnv           `protobuf:"bytes,5,rep,name=secretenv,proto3" json:"secretenv,omitempty"`
 	CdiDevices    []*CDIDevice           `protobuf:"bytes,6,rep,name=cdiDevices,proto3" json:"cdiDevices,omitempty"`
+	Services      []*Service             `protobuf:"bytes,7,rep,name=services,proto3" json:"services,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -684,6 +685,78 @@ func (x *ExecOp) GetCdiDevices() []*CDIDevice {
 	return nil
 }
 
+func (x *ExecOp) GetServices() []*Service {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+// Service is a long-running container started before the process of an
+// ExecOp and stopped when the process exits. Services share the network
+// namespace of the process and are reachable by their name.
+type Service struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name is the hostname the service is reachable by.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Input is the index of the input used as the root filesystem.
+	Input         int64 `protobuf:"varint,2,opt,name=input,proto3" json:"input,omitempty"`
+	Meta          *Meta `protobuf:"bytes,3,opt,name=meta,proto3" json:"meta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Service) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Service) GetInput() int64 {
+	if x != nil {
+		return x.Input
+	}
+	return 0
+}
+
+func (x *Service) GetMeta() *Meta {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
 // Meta is a set of arguments for ExecOp.
 // Meta is unrelated to LLB metadata.
 // FIXME: rename (ExecContext? ExecArgs?)
@@ -706,7 +779,7 @@ type Meta struct {
 
 func (x *Meta) Reset() {
 	*x = Meta{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[4]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -718,7 +791,7 @@ func (x *Meta) String() string {
 func (*Meta) ProtoMessage() {}
 
 func (x *Meta) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[4]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -731,7 +804,7 @@ func (x *Meta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Meta.ProtoReflect.Descriptor instead.
 func (*Meta) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{4}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Meta) GetArgs() []string {
@@ -821,7 +894,7 @@ type HostIP struct {
 
 func (x *HostIP) Reset() {
 	*x = HostIP{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -833,7 +906,7 @@ func (x *HostIP) String() string {
 func (*HostIP) ProtoMessage() {}
 
 func (x *HostIP) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -846,7 +919,7 @@ func (x *HostIP) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HostIP.ProtoReflect.Descriptor instead.
 func (*HostIP) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{5}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *HostIP) GetHost() string {
@@ -874,7 +947,7 @@ type Ulimit struct {
 
 func (x *Ulimit) Reset() {
 	*x = Ulimit{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -886,7 +959,7 @@ func (x *Ulimit) String() string {
 func (*Ulimit) ProtoMessage() {}
 
 func (x *Ulimit) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -899,7 +972,7 @@ func (x *Ulimit) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Ulimit.ProtoReflect.Descriptor instead.
 func (*Ulimit) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{6}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Ulimit) GetName() string {
@@ -925,17 +998,22 @@ func (x *Ulimit) GetHard() int64 {
 
 // SecretEnv is an environment variable that is backed by a secret.
 type SecretEnv struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ID            string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Optional      bool                   `protobuf:"varint,3,opt,name=optional,proto3" json:"optional,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ID       string                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Optional bool                   `protobuf:"varint,3,opt,name=optional,proto3" json:"optional,omitempty"`
+	// NoRedact disables replacing the secret value in the logs of the exec.
+	NoRedact bool `protobuf:"varint,4,opt,name=noRedact,proto3" json:"noRedact,omitempty"`
+	// CacheDigest includes a keyed digest of the secret value in the cache
+	// key of the exec, so changing the value invalidates the cache.
+	CacheDigest   bool `protobuf:"varint,5,opt,name=cacheDigest,proto3" json:"cacheDigest,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SecretEnv) Reset() {
 	*x = SecretEnv{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -947,7 +1025,7 @@ func (x *SecretEnv) String() string {
 func (*SecretEnv) ProtoMessage() {}
 
 func (x *SecretEnv) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -960,7 +1038,7 @@ func (x *SecretEnv) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SecretEnv.ProtoReflect.Descriptor instead.
 func (*SecretEnv) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{7}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *SecretEnv) GetID() string {
@@ -984,6 +1062,20 @@ func (x *SecretEnv) GetOptional() bool {
 	return false
 }
 
+func (x *SecretEnv) GetNoRedact() bool {
+	if x != nil {
+		return x.NoRedact
+	}
+	return false
+}
+
+func (x *SecretEnv) GetCacheDigest() bool {
+	if x != nil {
+		return x.CacheDigest
+	}
+	return false
+}
+
 // CDIDevice specifies a CDI device information.
 type CDIDevice struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -998,7 +1090,7 @@ type CDIDevice struct {
 
 func (x *CDIDevice) Reset() {
 	*x = CDIDevice{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1010,7 +1102,7 @@ func (x *CDIDevice) String() string {
 func (*CDIDevice) ProtoMessage() {}
 
 func (x *CDIDevice) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1023,7 +1115,7 @@ func (x *CDIDevice) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CDIDevice.ProtoReflect.Descriptor instead.
 func (*CDIDevice) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{8}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *CDIDevice) GetName() string {
@@ -1061,7 +1153,7 @@ type Mount struct {
 
 func (x *Mount) Reset() {
 	*x = Mount{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1073,7 +1165,7 @@ func (x *Mount) String() string {
 func (*Mount) ProtoMessage() {}
 
 func (x *Mount) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1086,7 +1178,7 @@ func (x *Mount) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Mount.ProtoReflect.Descriptor instead.
 func (*Mount) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{9}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Mount) GetInput() int64 {
@@ -1184,7 +1276,7 @@ type TmpfsOpt struct {
 
 func (x *TmpfsOpt) Reset() {
 	*x = TmpfsOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1196,7 +1288,7 @@ func (x *TmpfsOpt) String() string {
 func (*TmpfsOpt) ProtoMessage() {}
 
 func (x *TmpfsOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1209,7 +1301,7 @@ func (x *TmpfsOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TmpfsOpt.ProtoReflect.Descriptor instead.
 func (*TmpfsOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{10}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *TmpfsOpt) GetSize() int64 {
@@ -1232,7 +1324,7 @@ type CacheOpt struct {
 
 func (x *CacheOpt) Reset() {
 	*x = CacheOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1244,7 +1336,7 @@ func (x *CacheOpt) String() string {
 func (*CacheOpt) ProtoMessage() {}
 
 func (x *CacheOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1257,7 +1349,7 @@ func (x *CacheOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CacheOpt.ProtoReflect.Descriptor instead.
 func (*CacheOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{11}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *CacheOpt) GetID() string {
@@ -1287,14 +1379,19 @@ type SecretOpt struct {
 	Mode uint32 `protobuf:"varint,4,opt,name=mode,proto3" json:"mode,omitempty"`
 	// Optional defines if secret value is required. Error is produced
 	// if value is not found and optional is false.
-	Optional      bool `protobuf:"varint,5,opt,name=optional,proto3" json:"optional,omitempty"`
+	Optional bool `protobuf:"varint,5,opt,name=optional,proto3" json:"optional,omitempty"`
+	// NoRedact disables replacing the secret value in the logs of the exec.
+	NoRedact bool `protobuf:"varint,6,opt,name=noRedact,proto3" json:"noRedact,omitempty"`
+	// CacheDigest includes a keyed digest of the secret value in the cache
+	// key of the exec, so changing the value invalidates the cache.
+	CacheDigest   bool `protobuf:"varint,7,opt,name=cacheDigest,proto3" json:"cacheDigest,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SecretOpt) Reset() {
 	*x = SecretOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1306,7 +1403,7 @@ func (x *SecretOpt) String() string {
 func (*SecretOpt) ProtoMessage() {}
 
 func (x *SecretOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1319,7 +1416,7 @@ func (x *SecretOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SecretOpt.ProtoReflect.Descriptor instead.
 func (*SecretOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{12}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *SecretOpt) GetID() string {
@@ -1357,6 +1454,20 @@ func (x *SecretOpt) GetOptional() bool {
 	return false
 }
 
+func (x *SecretOpt) GetNoRedact() bool {
+	if x != nil {
+		return x.NoRedact
+	}
+	return false
+}
+
+func (x *SecretOpt) GetCacheDigest() bool {
+	if x != nil {
+		return x.CacheDigest
+	}
+	return false
+}
+
 // SSHOpt defines options describing ssh mounts
 type SSHOpt struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1377,7 +1488,7 @@ type SSHOpt struct {
 
 func (x *SSHOpt) Reset() {
 	*x = SSHOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1389,7 +1500,7 @@ func (x *SSHOpt) String() string {
 func (*SSHOpt) ProtoMessage() {}
 
 func (x *SSHOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1402,7 +1513,7 @@ func (x *SSHOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SSHOpt.ProtoReflect.Descriptor instead.
 func (*SSHOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{13}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *SSHOpt) GetID() string {
@@ -1454,7 +1565,7 @@ type SourceOp struct {
 
 func (x *SourceOp) Reset() {
 	*x = SourceOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1466,7 +1577,7 @@ func (x *SourceOp) String() string {
 func (*SourceOp) ProtoMessage() {}
 
 func (x *SourceOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1479,7 +1590,7 @@ func (x *SourceOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SourceOp.ProtoReflect.Descriptor instead.
 func (*SourceOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{14}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *SourceOp) GetIdentifier() string {
@@ -1510,7 +1621,7 @@ type BuildOp struct {
 
 func (x *BuildOp) Reset() {
 	*x = BuildOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1522,7 +1633,7 @@ func (x *BuildOp) String() string {
 func (*BuildOp) ProtoMessage() {}
 
 func (x *BuildOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1535,7 +1646,7 @@ func (x *BuildOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildOp.ProtoReflect.Descriptor instead.
 func (*BuildOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{15}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *BuildOp) GetBuilder() int64 {
@@ -1576,7 +1687,7 @@ type BuildInput struct {
 
 func (x *BuildInput) Reset() {
 	*x = BuildInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1588,7 +1699,7 @@ func (x *BuildInput) String() string {
 func (*BuildInput) ProtoMessage() {}
 
 func (x *BuildInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1601,7 +1712,7 @@ func (x *BuildInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildInput.ProtoReflect.Descriptor instead.
 func (*BuildInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{16}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *BuildInput) GetInput() int64 {
@@ -1624,13 +1735,16 @@ type OpMetadata struct {
 	Caps           map[string]bool `protobuf:"bytes,5,rep,name=caps,proto3" json:"caps,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 	ProgressGroup  *ProgressGroup  `protobuf:"bytes,6,opt,name=progress_group,json=progressGroup,proto3" json:"progress_group,omitempty"`
 	LinuxResources *LinuxResources `protobuf:"bytes,7,opt,name=linux_resources,json=linuxResources,proto3" json:"linux_resources,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// timeout is the maximum duration in nanoseconds the Op is allowed to run
+	// for. It does not affect the cache key. Zero means no timeout.
+	Timeout       int64 `protobuf:"varint,8,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *OpMetadata) Reset() {
 	*x = OpMetadata{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1642,7 +1756,7 @@ func (x *OpMetadata) String() string {
 func (*OpMetadata) ProtoMessage() {}
 
 func (x *OpMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1655,7 +1769,7 @@ func (x *OpMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OpMetadata.ProtoReflect.Descriptor instead.
 func (*OpMetadata) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{17}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *OpMetadata) GetIgnoreCache() bool {
@@ -1700,6 +1814,13 @@ func (x *OpMetadata) GetLinuxResources() *LinuxResources {
 	return nil
 }
 
+func (x *OpMetadata) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
 // Source is a source mapping description for a file
 type Source struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1711,7 +1832,7 @@ type Source struct {
 
 func (x *Source) Reset() {
 	*x = Source{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1723,7 +1844,7 @@ func (x *Source) String() string {
 func (*Source) ProtoMessage() {}
 
 func (x *Source) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1736,7 +1857,7 @@ func (x *Source) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Source.ProtoReflect.Descriptor instead.
 func (*Source) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{18}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *Source) GetLocations() map[string]*Locations {
@@ -1763,7 +1884,7 @@ type Locations struct {
 
 func (x *Locations) Reset() {
 	*x = Locations{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1775,7 +1896,7 @@ func (x *Locations) String() string {
 func (*Locations) ProtoMessage() {}
 
 func (x *Locations) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1788,7 +1909,7 @@ func (x *Locations) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Locations.ProtoReflect.Descriptor instead.
 func (*Locations) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{19}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *Locations) GetLocations() []*Location {
@@ -1811,7 +1932,7 @@ type SourceInfo struct {
 
 func (x *SourceInfo) Reset() {
 	*x = SourceInfo{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1823,7 +1944,7 @@ func (x *SourceInfo) String() string {
 func (*SourceInfo) ProtoMessage() {}
 
 func (x *SourceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1836,7 +1957,7 @@ func (x *SourceInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SourceInfo.ProtoReflect.Descriptor instead.
 func (*SourceInfo) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{20}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *SourceInfo) GetFilename() string {
@@ -1878,7 +1999,7 @@ type Location struct {
 
 func (x *Location) Reset() {
 	*x = Location{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1890,7 +2011,7 @@ func (x *Location) String() string {
 func (*Location) ProtoMessage() {}
 
 func (x *Location) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1903,7 +2024,7 @@ func (x *Location) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Location.ProtoReflect.Descriptor instead.
 func (*Location) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{21}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *Location) GetSourceIndex() int32 {
@@ -1931,7 +2052,7 @@ type Range struct {
 
 func (x *Range) Reset() {
 	*x = Range{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1943,7 +2064,7 @@ func (x *Range) String() string {
 func (*Range) ProtoMessage() {}
 
 func (x *Range) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1956,7 +2077,7 @@ func (x *Range) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Range.ProtoReflect.Descriptor instead.
 func (*Range) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{22}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *Range) GetStart() *Position {
@@ -1984,7 +2105,7 @@ type Position struct {
 
 func (x *Position) Reset() {
 	*x = Position{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1996,7 +2117,7 @@ func (x *Position) String() string {
 func (*Position) ProtoMessage() {}
 
 func (x *Position) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2009,7 +2130,7 @@ func (x *Position) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Position.ProtoReflect.Descriptor instead.
 func (*Position) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{23}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *Position) GetLine() int32 {
@@ -2035,7 +2156,7 @@ type ExportCache struct {
 
 func (x *ExportCache) Reset() {
 	*x = ExportCache{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2047,7 +2168,7 @@ func (x *ExportCache) String() string {
 func (*ExportCache) ProtoMessage() {}
 
 func (x *ExportCache) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2060,7 +2181,7 @@ func (x *ExportCache) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExportCache.ProtoReflect.Descriptor instead.
 func (*ExportCache) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{24}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *ExportCache) GetValue() bool {
@@ -2081,7 +2202,7 @@ type ProgressGroup struct {
 
 func (x *ProgressGroup) Reset() {
 	*x = ProgressGroup{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2093,7 +2214,7 @@ func (x *ProgressGroup) String() string {
 func (*ProgressGroup) ProtoMessage() {}
 
 func (x *ProgressGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2106,7 +2227,7 @@ func (x *ProgressGroup) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProgressGroup.ProtoReflect.Descriptor instead.
 func (*ProgressGroup) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{25}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ProgressGroup) GetId() string {
@@ -2148,7 +2269,7 @@ type LinuxResources struct {
 
 func (x *LinuxResources) Reset() {
 	*x = LinuxResources{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2160,7 +2281,7 @@ func (x *LinuxResources) String() string {
 func (*LinuxResources) ProtoMessage() {}
 
 func (x *LinuxResources) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2173,7 +2294,7 @@ func (x *LinuxResources) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LinuxResources.ProtoReflect.Descriptor instead.
 func (*LinuxResources) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{26}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *LinuxResources) GetMemory() int64 {
@@ -2238,7 +2359,7 @@ type ProxyEnv struct {
 
 func (x *ProxyEnv) Reset() {
 	*x = ProxyEnv{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2250,7 +2371,7 @@ func (x *ProxyEnv) String() string {
 func (*ProxyEnv) ProtoMessage() {}
 
 func (x *ProxyEnv) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2263,7 +2384,7 @@ func (x *ProxyEnv) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProxyEnv.ProtoReflect.Descriptor instead.
 func (*ProxyEnv) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{27}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *ProxyEnv) GetHttpProxy() string {
@@ -2311,7 +2432,7 @@ type WorkerConstraints struct {
 
 func (x *WorkerConstraints) Reset() {
 	*x = WorkerConstraints{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2323,7 +2444,7 @@ func (x *WorkerConstraints) String() string {
 func (*WorkerConstraints) ProtoMessage() {}
 
 func (x *WorkerConstraints) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2336,7 +2457,7 @@ func (x *WorkerConstraints) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WorkerConstraints.ProtoReflect.Descriptor instead.
 func (*WorkerConstraints) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{28}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *WorkerConstraints) GetFilter() []string {
@@ -2362,7 +2483,7 @@ type Definition struct {
 
 func (x *Definition) Reset() {
 	*x = Definition{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2374,7 +2495,7 @@ func (x *Definition) String() string {
 func (*Definition) ProtoMessage() {}
 
 func (x *Definition) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2387,7 +2508,7 @@ func (x *Definition) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Definition.ProtoReflect.Descriptor instead.
 func (*Definition) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{29}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *Definition) GetDef() [][]byte {
@@ -2420,7 +2541,7 @@ type FileOp struct {
 
 func (x *FileOp) Reset() {
 	*x = FileOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2432,7 +2553,7 @@ func (x *FileOp) String() string {
 func (*FileOp) ProtoMessage() {}
 
 func (x *FileOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2445,7 +2566,7 @@ func (x *FileOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileOp.ProtoReflect.Descriptor instead.
 func (*FileOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{30}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *FileOp) GetActions() []*FileAction {
@@ -2475,7 +2596,7 @@ type FileAction struct {
 
 func (x *FileAction) Reset() {
 	*x = FileAction{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2487,7 +2608,7 @@ func (x *FileAction) String() string {
 func (*FileAction) ProtoMessage() {}
 
 func (x *FileAction) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2500,7 +2621,7 @@ func (x *FileAction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileAction.ProtoReflect.Descriptor instead.
 func (*FileAction) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{31}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *FileAction) GetInput() int64 {
@@ -2656,7 +2777,7 @@ type FileActionCopy struct {
 
 func (x *FileActionCopy) Reset() {
 	*x = FileActionCopy{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2668,7 +2789,7 @@ func (x *FileActionCopy) String() string {
 func (*FileActionCopy) ProtoMessage() {}
 
 func (x *FileActionCopy) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2681,7 +2802,7 @@ func (x *FileActionCopy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionCopy.ProtoReflect.Descriptor instead.
 func (*FileActionCopy) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{32}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *FileActionCopy) GetSrc() string {
@@ -2814,7 +2935,7 @@ type FileActionMkFile struct {
 
 func (x *FileActionMkFile) Reset() {
 	*x = FileActionMkFile{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2826,7 +2947,7 @@ func (x *FileActionMkFile) String() string {
 func (*FileActionMkFile) ProtoMessage() {}
 
 func (x *FileActionMkFile) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2839,7 +2960,7 @@ func (x *FileActionMkFile) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionMkFile.ProtoReflect.Descriptor instead.
 func (*FileActionMkFile) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{33}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *FileActionMkFile) GetPath() string {
@@ -2893,7 +3014,7 @@ type FileActionSymlink struct {
 
 func (x *FileActionSymlink) Reset() {
 	*x = FileActionSymlink{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2905,7 +3026,7 @@ func (x *FileActionSymlink) String() string {
 func (*FileActionSymlink) ProtoMessage() {}
 
 func (x *FileActionSymlink) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2918,7 +3039,7 @@ func (x *FileActionSymlink) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionSymlink.ProtoReflect.Descriptor instead.
 func (*FileActionSymlink) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{34}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *FileActionSymlink) GetOldpath() string {
@@ -2967,7 +3088,7 @@ type FileActionMkDir struct {
 
 func (x *FileActionMkDir) Reset() {
 	*x = FileActionMkDir{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2979,7 +3100,7 @@ func (x *FileActionMkDir) String() string {
 func (*FileActionMkDir) ProtoMessage() {}
 
 func (x *FileActionMkDir) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2992,7 +3113,7 @@ func (x *FileActionMkDir) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionMkDir.ProtoReflect.Descriptor instead.
 func (*FileActionMkDir) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{35}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *FileActionMkDir) GetPath() string {
@@ -3044,7 +3165,7 @@ type FileActionRm struct {
 
 func (x *FileActionRm) Reset() {
 	*x = FileActionRm{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3056,7 +3177,7 @@ func (x *FileActionRm) String() string {
 func (*FileActionRm) ProtoMessage() {}
 
 func (x *FileActionRm) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3069,7 +3190,7 @@ func (x *FileActionRm) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionRm.ProtoReflect.Descriptor instead.
 func (*FileActionRm) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{36}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *FileActionRm) GetPath() string {
@@ -3103,7 +3224,7 @@ type ChownOpt struct {
 
 func (x *ChownOpt) Reset() {
 	*x = ChownOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3115,7 +3236,7 @@ func (x *ChownOpt) String() string {
 func (*ChownOpt) ProtoMessage() {}
 
 func (x *ChownOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3128,7 +3249,7 @@ func (x *ChownOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChownOpt.ProtoReflect.Descriptor instead.
 func (*ChownOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{37}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *ChownOpt) GetUser() *UserOpt {
@@ -3160,7 +3281,7 @@ type UserOpt struct {
 
 func (x *UserOpt) Reset() {
 	*x = UserOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3172,7 +3293,7 @@ func (x *UserOpt) String() string {
 func (*UserOpt) ProtoMessage() {}
 
 func (x *UserOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3185,7 +3306,7 @@ func (x *UserOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserOpt.ProtoReflect.Descriptor instead.
 func (*UserOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{38}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *UserOpt) GetUser() isUserOpt_User {
@@ -3239,7 +3360,7 @@ type NamedUserOpt struct {
 
 func (x *NamedUserOpt) Reset() {
 	*x = NamedUserOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3251,7 +3372,7 @@ func (x *NamedUserOpt) String() string {
 func (*NamedUserOpt) ProtoMessage() {}
 
 func (x *NamedUserOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3264,7 +3385,7 @@ func (x *NamedUserOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NamedUserOpt.ProtoReflect.Descriptor instead.
 func (*NamedUserOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{39}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *NamedUserOpt) GetName() string {
@@ -3290,7 +3411,7 @@ type MergeInput struct {
 
 func (x *MergeInput) Reset() {
 	*x = MergeInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3302,7 +3423,7 @@ func (x *MergeInput) String() string {
 func (*MergeInput) ProtoMessage() {}
 
 func (x *MergeInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3315,7 +3436,7 @@ func (x *MergeInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MergeInput.ProtoReflect.Descriptor instead.
 func (*MergeInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{40}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *MergeInput) GetInput() int64 {
@@ -3334,7 +3455,7 @@ type MergeOp struct {
 
 func (x *MergeOp) Reset() {
 	*x = MergeOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3346,7 +3467,7 @@ func (x *MergeOp) String() string {
 func (*MergeOp) ProtoMessage() {}
 
 func (x *MergeOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3359,7 +3480,7 @@ func (x *MergeOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MergeOp.ProtoReflect.Descriptor instead.
 func (*MergeOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{41}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *MergeOp) GetInputs() []*MergeInput {
@@ -3378,7 +3499,7 @@ type LowerDiffInput struct {
 
 func (x *LowerDiffInput) Reset() {
 	*x = LowerDiffInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3390,7 +3511,7 @@ func (x *LowerDiffInput) String() string {
 func (*LowerDiffInput) ProtoMessage() {}
 
 func (x *LowerDiffInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3403,7 +3524,7 @@ func (x *LowerDiffInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LowerDiffInput.ProtoReflect.Descriptor instead.
 func (*LowerDiffInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{42}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *LowerDiffInput) GetInput() int64 {
@@ -3422,7 +3543,7 @@ type UpperDiffInput struct {
 
 func (x *UpperDiffInput) Reset() {
 	*x = UpperDiffInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3434,7 +3555,7 @@ func (x *UpperDiffInput) String() string {
 func (*UpperDiffInput) ProtoMessage() {}
 
 func (x *UpperDiffInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3447,7 +3568,7 @@ func (x *UpperDiffInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpperDiffInput.ProtoReflect.Descriptor instead.
 func (*UpperDiffInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{43}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *UpperDiffInput) GetInput() int64 {
@@ -3467,7 +3588,7 @@ type DiffOp struct {
 
 func (x *DiffOp) Reset() {
 	*x = DiffOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[44]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3479,7 +3600,7 @@ func (x *DiffOp) String() string {
 func (*DiffOp) ProtoMessage() {}
 
 func (x *DiffOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[44]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3492,7 +3613,7 @@ func (x *DiffOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DiffOp.ProtoReflect.Descriptor instead.
 func (*DiffOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{44}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *DiffOp) GetLower() *LowerDiffInput {
@@ -3519,7 +3640,7 @@ type PassthroughOp struct {
 
 func (x *PassthroughOp) Reset() {
 	*x = PassthroughOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[45]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3531,7 +3652,7 @@ func (x *PassthroughOp) String() string {
 func (*PassthroughOp) ProtoMessage() {}
 
 func (x *PassthroughOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[45]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3544,7 +3665,7 @@ func (x *PassthroughOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PassthroughOp.ProtoReflect.Descriptor instead.
 func (*PassthroughOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{45}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *PassthroughOp) GetId() string {
@@ -3592,7 +3713,7 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"OSFeatures\"5\n" +
 	"\x05Input\x12\x16\n" +
 	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x14\n" +
-	"\x05index\x18\x02 \x01(\x03R\x05index\"\xfa\x01\n" +
+	"\x05index\x18\x02 \x01(\x03R\x05index\"\xa3\x02\n" +
 	"\x06ExecOp\x12\x1c\n" +
 	"\x04meta\x18\x01 \x01(\v2\b.pb.MetaR\x04meta\x12!\n" +
 	"\x06mounts\x18\x02 \x03(\v2\t.pb.MountR\x06mounts\x12%\n" +
@@ -3601,7 +3722,12 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\tsecretenv\x18\x05 \x03(\v2\r.pb.SecretEnvR\tsecretenv\x12-\n" +
 	"\n" +
 	"cdiDevices\x18\x06 \x03(\v2\r.pb.CDIDeviceR\n" +
-	"cdiDevices\"\xf3\x02\n" +
+	"cdiDevices\x12'\n" +
+	"\bservices\x18\a \x03(\v2\v.pb.ServiceR\bservices\"Q\n" +
+	"\aService\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05input\x18\x02 \x01(\x03R\x05input\x12\x1c\n" +
+	"\x04meta\x18\x03 \x01(\v2\b.pb.MetaR\x04meta\"\xf3\x02\n" +
 	"\x04Meta\x12\x12\n" +
 	"\x04args\x18\x01 \x03(\tR\x04args\x12\x10\n" +
 	"\x03env\x18\x02 \x03(\tR\x03env\x12\x10\n" +
@@ -3625,11 +3751,13 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\x06Ulimit\x12\x12\n" +
 	"\x04Name\x18\x01 \x01(\tR\x04Name\x12\x12\n" +
 	"\x04Soft\x18\x02 \x01(\x03R\x04Soft\x12\x12\n" +
-	"\x04Hard\x18\x03 \x01(\x03R\x04Hard\"K\n" +
+	"\x04Hard\x18\x03 \x01(\x03R\x04Hard\"\x89\x01\n" +
 	"\tSecretEnv\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
-	"\boptional\x18\x03 \x01(\bR\boptional\";\n" +
+	"\boptional\x18\x03 \x01(\bR\boptional\x12\x1a\n" +
+	"\bnoRedact\x18\x04 \x01(\bR\bnoRedact\x12 \n" +
+	"\vcacheDigest\x18\x05 \x01(\bR\vcacheDigest\";\n" +
 	"\tCDIDevice\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
 	"\boptional\x18\x02 \x01(\bR\boptional\"\xaa\x03\n" +
@@ -3651,13 +3779,15 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\x04size\x18\x01 \x01(\x03R\x04size\"I\n" +
 	"\bCacheOpt\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12-\n" +
-	"\asharing\x18\x02 \x01(\x0e2\x13.pb.CacheSharingOptR\asharing\"o\n" +
+	"\asharing\x18\x02 \x01(\x0e2\x13.pb.CacheSharingOptR\asharing\"\xad\x01\n" +
 	"\tSecretOpt\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x10\n" +
 	"\x03uid\x18\x02 \x01(\rR\x03uid\x12\x10\n" +
 	"\x03gid\x18\x03 \x01(\rR\x03gid\x12\x12\n" +
 	"\x04mode\x18\x04 \x01(\rR\x04mode\x12\x1a\n" +
-	"\boptional\x18\x05 \x01(\bR\boptional\"l\n" +
+	"\boptional\x18\x05 \x01(\bR\boptional\x12\x1a\n" +
+	"\bnoRedact\x18\x06 \x01(\bR\bnoRedact\x12 \n" +
+	"\vcacheDigest\x18\a \x01(\bR\vcacheDigest\"l\n" +
 	"\x06SSHOpt\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x10\n" +
 	"\x03uid\x18\x02 \x01(\rR\x03uid\x12\x10\n" +
@@ -3687,7 +3817,7 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\"\n" +
 	"\n" +
 	"BuildInput\x12\x14\n" +
-	"\x05input\x18\x01 \x01(\x03R\x05input\"\xc4\x03\n" +
+	"\x05input\x18\x01 \x01(\x03R\x05input\"\xde\x03\n" +
 	"\n" +
 	"OpMetadata\x12!\n" +
 	"\fignore_cache\x18\x01 \x01(\bR\vignoreCache\x12A\n" +
@@ -3695,7 +3825,8 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\fexport_cache\x18\x04 \x01(\v2\x0f.pb.ExportCacheR\vexportCache\x12,\n" +
 	"\x04caps\x18\x05 \x03(\v2\x18.pb.OpMetadata.CapsEntryR\x04caps\x128\n" +
 	"\x0eprogress_group\x18\x06 \x01(\v2\x11.pb.ProgressGroupR\rprogressGroup\x12;\n" +
-	"\x0flinux_resources\x18\a \x01(\v2\x12.pb.LinuxResourcesR\x0elinuxResources\x1a>\n" +
+	"\x0flinux_resources\x18\a \x01(\v2\x12.pb.LinuxResourcesR\x0elinuxResources\x12\x18\n" +
+	"\atimeout\x18\b \x01(\x03R\atimeout\x1a>\n" +
 	"\x10DescriptionEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a7\n" +
@@ -3881,7 +4012,7 @@ func file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP() []byte {
 }
 
 var file_github_com_moby_buildkit_solver_pb_ops_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 53)
+var file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
 var file_github_com_moby_buildkit_solver_pb_ops_proto_goTypes = []any{
 	(NetMode)(0),              // 0: pb.NetMode
 	(SecurityMode)(0),         // 1: pb.SecurityMode
@@ -3892,125 +4023,128 @@ var file_github_com_moby_buildkit_solver_pb_ops_proto_goTypes = []any{
 	(*Platform)(nil),          // 6: pb.Platform
 	(*Input)(nil),             // 7: pb.Input
 	(*ExecOp)(nil),            // 8: pb.ExecOp
-	(*Meta)(nil),              // 9: pb.Meta
-	(*HostIP)(nil),            // 10: pb.HostIP
-	(*Ulimit)(nil),            // 11: pb.Ulimit
-	(*SecretEnv)(nil),         // 12: pb.SecretEnv
-	(*CDIDevice)(nil),         // 13: pb.CDIDevice
-	(*Mount)(nil),             // 14: pb.Mount
-	(*TmpfsOpt)(nil),          // 15: pb.TmpfsOpt
-	(*CacheOpt)(nil),          // 16: pb.CacheOpt
-	(*SecretOpt)(nil),         // 17: pb.SecretOpt
-	(*SSHOpt)(nil),            // 18: pb.SSHOpt
-	(*SourceOp)(nil),          // 19: pb.SourceOp
-	(*BuildOp)(nil),           // 20: pb.BuildOp
-	(*BuildInput)(nil),        // 21: pb.BuildInput
-	(*OpMetadata)(nil),        // 22: pb.OpMetadata
-	(*Source)(nil),            // 23: pb.Source
-	(*Locations)(nil),         // 24: pb.Locations
-	(*SourceInfo)(nil),        // 25: pb.SourceInfo
-	(*Location)(nil),          // 26: pb.Location
-	(*Range)(nil),             // 27: pb.Range
-	(*Position)(nil),          // 28: pb.Position
-	(*ExportCache)(nil),       // 29: pb.ExportCache
-	(*ProgressGroup)(nil),     // 30: pb.ProgressGroup
-	(*LinuxResources)(nil),    // 31: pb.LinuxResources
-	(*ProxyEnv)(nil),          // 32: pb.ProxyEnv
-	(*WorkerConstraints)(nil), // 33: pb.WorkerConstraints
-	(*Definition)(nil),        // 34: pb.Definition
-	(*FileOp)(nil),            // 35: pb.FileOp
-	(*FileAction)(nil),        // 36: pb.FileAction
-	(*FileActionCopy)(nil),    // 37: pb.FileActionCopy
-	(*FileActionMkFile)(nil),  // 38: pb.FileActionMkFile
-	(*FileActionSymlink)(nil), // 39: pb.FileActionSymlink
-	(*FileActionMkDir)(nil),   // 40: pb.FileActionMkDir
-	(*FileActionRm)(nil),      // 41: pb.FileActionRm
-	(*ChownOpt)(nil),          // 42: pb.ChownOpt
-	(*UserOpt)(nil),           // 43: pb.UserOpt
-	(*NamedUserOpt)(nil),      // 44: pb.NamedUserOpt
-	(*MergeInput)(nil),        // 45: pb.MergeInput
-	(*MergeOp)(nil),           // 46: pb.MergeOp
-	(*LowerDiffInput)(nil),    // 47: pb.LowerDiffInput
-	(*UpperDiffInput)(nil),    // 48: pb.UpperDiffInput
-	(*DiffOp)(nil),            // 49: pb.DiffOp
-	(*PassthroughOp)(nil),     // 50: pb.PassthroughOp
-	nil,                       // 51: pb.SourceOp.AttrsEntry
-	nil,                       // 52: pb.BuildOp.InputsEntry
-	nil,                       // 53: pb.BuildOp.AttrsEntry
-	nil,                       // 54: pb.OpMetadata.DescriptionEntry
-	nil,                       // 55: pb.OpMetadata.CapsEntry
-	nil,                       // 56: pb.Source.LocationsEntry
-	nil,                       // 57: pb.Definition.MetadataEntry
+	(*Service)(nil),           // 9: pb.Service
+	(*Meta)(nil),              // 10: pb.Meta
+	(*HostIP)(nil),            // 11: pb.HostIP
+	(*Ulimit)(nil),            // 12: pb.Ulimit
+	(*SecretEnv)(nil),         // 13: pb.SecretEnv
+	(*CDIDevice)(nil),         // 14: pb.CDIDevice
+	(*Mount)(nil),             // 15: pb.Mount
+	(*TmpfsOpt)(nil),          // 16: pb.TmpfsOpt
+	(*CacheOpt)(nil),          // 17: pb.CacheOpt
+	(*SecretOpt)(nil),         // 18: pb.SecretOpt
+	(*SSHOpt)(nil),            // 19: pb.SSHOpt
+	(*SourceOp)(nil),          // 20: pb.SourceOp
+	(*BuildOp)(nil),           // 21: pb.BuildOp
+	(*BuildInput)(nil),        // 22: pb.BuildInput
+	(*OpMetadata)(nil),        // 23: pb.OpMetadata
+	(*Source)(nil),            // 24: pb.Source
+	(*Locations)(nil),         // 25: pb.Locations
+	(*SourceInfo)(nil),        // 26: pb.SourceInfo
+	(*Location)(nil),          // 27: pb.Location
+	(*Range)(nil),             // 28: pb.Range
+	(*Position)(nil),          // 29: pb.Position
+	(*ExportCache)(nil),       // 30: pb.ExportCache
+	(*ProgressGroup)(nil),     // 31: pb.ProgressGroup
+	(*LinuxResources)(nil),    // 32: pb.LinuxResources
+	(*ProxyEnv)(nil),          // 33: pb.ProxyEnv
+	(*WorkerConstraints)(nil), // 34: pb.WorkerConstraints
+	(*Definition)(nil),        // 35: pb.Definition
+	(*FileOp)(nil),            // 36: pb.FileOp
+	(*FileAction)(nil),        // 37: pb.FileAction
+	(*FileActionCopy)(nil),    // 38: pb.FileActionCopy
+	(*FileActionMkFile)(nil),  // 39: pb.FileActionMkFile
+	(*FileActionSymlink)(nil), // 40: pb.FileActionSymlink
+	(*FileActionMkDir)(nil),   // 41: pb.FileActionMkDir
+	(*FileActionRm)(nil),      // 42: pb.FileActionRm
+	(*ChownOpt)(nil),          // 43: pb.ChownOpt
+	(*UserOpt)(nil),           // 44: pb.UserOpt
+	(*NamedUserOpt)(nil),      // 45: pb.NamedUserOpt
+	(*MergeInput)(nil),        // 46: pb.MergeInput
+	(*MergeOp)(nil),           // 47: pb.MergeOp
+	(*LowerDiffInput)(nil),    // 48: pb.LowerDiffInput
+	(*UpperDiffInput)(nil),    // 49: pb.UpperDiffInput
+	(*DiffOp)(nil),            // 50: pb.DiffOp
+	(*PassthroughOp)(nil),     // 51: pb.PassthroughOp
+	nil,                       // 52: pb.SourceOp.AttrsEntry
+	nil,                       // 53: pb.BuildOp.InputsEntry
+	nil,                       // 54: pb.BuildOp.AttrsEntry
+	nil,                       // 55: pb.OpMetadata.DescriptionEntry
+	nil,                       // 56: pb.OpMetadata.CapsEntry
+	nil,                       // 57: pb.Source.LocationsEntry
+	nil,                       // 58: pb.Definition.MetadataEntry
 }
 var file_github_com_moby_buildkit_solver_pb_ops_proto_depIdxs = []int32{
 	7,  // 0: pb.Op.inputs:type_name -> pb.Input
 	8,  // 1: pb.Op.exec:type_name -> pb.ExecOp
-	19, // 2: pb.Op.source:type_name -> pb.SourceOp
-	35, // 3: pb.Op.file:type_name -> pb.FileOp
-	20, // 4: pb.Op.build:type_name -> pb.BuildOp
-	46, // 5: pb.Op.merge:type_name -> pb.MergeOp
-	49, // 6: pb.Op.diff:type_name -> pb.DiffOp
-	50, // 7: pb.Op.passthrough:type_name -> pb.PassthroughOp
+	20, // 2: pb.Op.source:type_name -> pb.SourceOp
+	36, // 3: pb.Op.file:type_name -> pb.FileOp
+	21, // 4: pb.Op.build:type_name -> pb.BuildOp
+	47, // 5: pb.Op.merge:type_name -> pb.MergeOp
+	50, // 6: pb.Op.diff:type_name -> pb.DiffOp
+	51, // 7: pb.Op.passthrough:type_name -> pb.PassthroughOp
 	6,  // 8: pb.Op.platform:type_name -> pb.Platform
-	33, // 9: pb.Op.constraints:type_name -> pb.WorkerConstraints
-	9,  // 10: pb.ExecOp.meta:type_name -> pb.Meta
-	14, // 11: pb.ExecOp.mounts:type_name -> pb.Mount
+	34, // 9: pb.Op.constraints:type_name -> pb.WorkerConstraints
+	10, // 10: pb.ExecOp.meta:type_name -> pb.Meta
+	15, // 11: pb.ExecOp.mounts:type_name -> pb.Mount
 	0,  // 12: pb.ExecOp.network:type_name -> pb.NetMode
 	1,  // 13: pb.ExecOp.security:type_name -> pb.SecurityMode
-	12, // 14: pb.ExecOp.secretenv:type_name -> pb.SecretEnv
-	13, // 15: pb.ExecOp.cdiDevices:type_name -> pb.CDIDevice
-	32, // 16: pb.Meta.proxy_env:type_name -> pb.ProxyEnv
-	10, // 17: pb.Meta.extraHosts:type_name -> pb.HostIP
-	11, // 18: pb.Meta.ulimit:type_name -> pb.Ulimit
-	2,  // 19: pb.Mount.mountType:type_name -> pb.MountType
-	15, // 20: pb.Mount.TmpfsOpt:type_name -> pb.TmpfsOpt
-	16, // 21: pb.Mount.cacheOpt:type_name -> pb.CacheOpt
-	17, // 22: pb.Mount.secretOpt:type_name -> pb.SecretOpt
-	18, // 23: pb.Mount.SSHOpt:type_name -> pb.SSHOpt
-	3,  // 24: pb.Mount.contentCache:type_name -> pb.MountContentCache
-	4,  // 25: pb.CacheOpt.sharing:type_name -> pb.CacheSharingOpt
-	51, // 26: pb.SourceOp.attrs:type_name -> pb.SourceOp.AttrsEntry
-	52, // 27: pb.BuildOp.inputs:type_name -> pb.BuildOp.InputsEntry
-	34, // 28: pb.BuildOp.def:type_name -> pb.Definition
-	53, // 29: pb.BuildOp.attrs:type_name -> pb.BuildOp.AttrsEntry
-	54, // 30: pb.OpMetadata.description:type_name -> pb.OpMetadata.DescriptionEntry
-	29, // 31: pb.OpMetadata.export_cache:type_name -> pb.ExportCache
-	55, // 32: pb.OpMetadata.caps:type_name -> pb.OpMetadata.CapsEntry
-	30, // 33: pb.OpMetadata.progress_group:type_name -> pb.ProgressGroup
-	31, // 34: pb.OpMetadata.linux_resources:type_name -> pb.LinuxResources
-	56, // 35: pb.Source.locations:type_name -> pb.Source.LocationsEntry
-	25, // 36: pb.Source.infos:type_name -> pb.SourceInfo
-	26, // 37: pb.Locations.locations:type_name -> pb.Location
-	34, // 38: pb.SourceInfo.definition:type_name -> pb.Definition
-	27, // 39: pb.Location.ranges:type_name -> pb.Range
-	28, // 40: pb.Range.start:type_name -> pb.Position
-	28, // 41: pb.Range.end:type_name -> pb.Position
-	57, // 42: pb.Definition.metadata:type_name -> pb.Definition.MetadataEntry
-	23, // 43: pb.Definition.Source:type_name -> pb.Source
-	36, // 44: pb.FileOp.actions:type_name -> pb.FileAction
-	37, // 45: pb.FileAction.copy:type_name -> pb.FileActionCopy
-	38, // 46: pb.FileAction.mkfile:type_name -> pb.FileActionMkFile
-	40, // 47: pb.FileAction.mkdir:type_name -> pb.FileActionMkDir
-	41, // 48: pb.FileAction.rm:type_name -> pb.FileActionRm
-	39, // 49: pb.FileAction.symlink:type_name -> pb.FileActionSymlink
-	42, // 50: pb.FileActionCopy.owner:type_name -> pb.ChownOpt
-	42, // 51: pb.FileActionMkFile.owner:type_name -> pb.ChownOpt
-	42, // 52: pb.FileActionSymlink.owner:type_name -> pb.ChownOpt
-	42, // 53: pb.FileActionMkDir.owner:type_name -> pb.ChownOpt
-	43, // 54: pb.ChownOpt.user:type_name -> pb.UserOpt
-	43, // 55: pb.ChownOpt.group:type_name -> pb.UserOpt
-	44, // 56: pb.UserOpt.byName:type_name -> pb.NamedUserOpt
-	45, // 57: pb.MergeOp.inputs:type_name -> pb.MergeInput
-	47, // 58: pb.DiffOp.lower:type_name -> pb.LowerDiffInput
-	48, // 59: pb.DiffOp.upper:type_name -> pb.UpperDiffInput
-	21, // 60: pb.BuildOp.InputsEntry.value:type_name -> pb.BuildInput
-	24, // 61: pb.Source.LocationsEntry.value:type_name -> pb.Locations
-	22, // 62: pb.Definition.MetadataEntry.value:type_name -> pb.OpMetadata
-	63, // [63:63] is the sub-list for method output_type
-	63, // [63:63] is the sub-list for method input_type
-	63, // [63:63] is the sub-list for extension type_name
-	63, // [63:63] is the sub-list for extension extendee
-	0,  // [0:63] is the sub-list for field type_name
+	13, // 14: pb.ExecOp.secretenv:type_name -> pb.SecretEnv
+	14, // 15: pb.ExecOp.cdiDevices:type_name -> pb.CDIDevice
+	9,  // 16: pb.ExecOp.services:type_name -> pb.Service
+	10, // 17: pb.Service.meta:type_name -> pb.Meta
+	33, // 18: pb.Meta.proxy_env:type_name -> pb.ProxyEnv
+	11, // 19: pb.Meta.extraHosts:type_name -> pb.HostIP
+	12, // 20: pb.Meta.ulimit:type_name -> pb.Ulimit
+	2,  // 21: pb.Mount.mountType:type_name -> pb.MountType
+	16, // 22: pb.Mount.TmpfsOpt:type_name -> pb.TmpfsOpt
+	17, // 23: pb.Mount.cacheOpt:type_name -> pb.CacheOpt
+	18, // 24: pb.Mount.secretOpt:type_name -> pb.SecretOpt
+	19, // 25: pb.Mount.SSHOpt:type_name -> pb.SSHOpt
+	3,  // 26: pb.Mount.contentCache:type_name -> pb.MountContentCache
+	4,  // 27: pb.CacheOpt.sharing:type_name -> pb.CacheSharingOpt
+	52, // 28: pb.SourceOp.attrs:type_name -> pb.SourceOp.AttrsEntry
+	53, // 29: pb.BuildOp.inputs:type_name -> pb.BuildOp.InputsEntry
+	35, // 30: pb.BuildOp.def:type_name -> pb.Definition
+	54, // 31: pb.BuildOp.attrs:type_name -> pb.BuildOp.AttrsEntry
+	55, // 32: pb.OpMetadata.description:type_name -> pb.OpMetadata.DescriptionEntry
+	30, // 33: pb.OpMetadata.export_cache:type_name -> pb.ExportCache
+	56, // 34: pb.OpMetadata.caps:type_name -> pb.OpMetadata.CapsEntry
+	31, // 35: pb.OpMetadata.progress_group:type_name -> pb.ProgressGroup
+	32, // 36: pb.OpMetadata.linux_resources:type_name -> pb.LinuxResources
+	57, // 37: pb.Source.locations:type_name -> pb.Source.LocationsEntry
+	26, // 38: pb.Source.infos:type_name -> pb.SourceInfo
+	27, // 39: pb.Locations.locations:type_name -> pb.Location
+	35, // 40: pb.SourceInfo.definition:type_name -> pb.Definition
+	28, // 41: pb.Location.ranges:type_name -> pb.Range
+	29, // 42: pb.Range.start:type_name -> pb.Position
+	29, // 43: pb.Range.end:type_name -> pb.Position
+	58, // 44: pb.Definition.metadata:type_name -> pb.Definition.MetadataEntry
+	24, // 45: pb.Definition.Source:type_name -> pb.Source
+	37, // 46: pb.FileOp.actions:type_name -> pb.FileAction
+	38, // 47: pb.FileAction.copy:type_name -> pb.FileActionCopy
+	39, // 48: pb.FileAction.mkfile:type_name -> pb.FileActionMkFile
+	41, // 49: pb.FileAction.mkdir:type_name -> pb.FileActionMkDir
+	42, // 50: pb.FileAction.rm:type_name -> pb.FileActionRm
+	40, // 51: pb.FileAction.symlink:type_name -> pb.FileActionSymlink
+	43, // 52: pb.FileActionCopy.owner:type_name -> pb.ChownOpt
+	43, // 53: pb.FileActionMkFile.owner:type_name -> pb.ChownOpt
+	43, // 54: pb.FileActionSymlink.owner:type_name -> pb.ChownOpt
+	43, // 55: pb.FileActionMkDir.owner:type_name -> pb.ChownOpt
+	44, // 56: pb.ChownOpt.user:type_name -> pb.UserOpt
+	44, // 57: pb.ChownOpt.group:type_name -> pb.UserOpt
+	45, // 58: pb.UserOpt.byName:type_name -> pb.NamedUserOpt
+	46, // 59: pb.MergeOp.inputs:type_name -> pb.MergeInput
+	48, // 60: pb.DiffOp.lower:type_name -> pb.LowerDiffInput
+	49, // 61: pb.DiffOp.upper:type_name -> pb.UpperDiffInput
+	22, // 62: pb.BuildOp.InputsEntry.value:type_name -> pb.BuildInput
+	25, // 63: pb.Source.LocationsEntry.value:type_name -> pb.Locations
+	23, // 64: pb.Definition.MetadataEntry.value:type_name -> pb.OpMetadata
+	65, // [65:65] is the sub-list for method output_type
+	65, // [65:65] is the sub-list for method input_type
+	65, // [65:65] is the sub-list for extension type_name
+	65, // [65:65] is the sub-list for extension extendee
+	0,  // [0:65] is the sub-list for field type_name
 }
 
 func init() { file_github_com_moby_buildkit_solver_pb_ops_proto_init() }
@@ -4027,14 +4161,14 @@ func file_github_com_moby_buildkit_solver_pb_ops_proto_init() {
 		(*Op_Diff)(nil),
 		(*Op_Passthrough)(nil),
 	}
-	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31].OneofWrappers = []any{
+	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32].OneofWrappers = []any{
 		(*FileAction_Copy)(nil),
 		(*FileAction_Mkfile)(nil),
 		(*FileAction_Mkdir)(nil),
 		(*FileAction_Rm)(nil),
 		(*FileAction_Symlink)(nil),
 	}
-	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38].OneofWrappers = []any{
+	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39].OneofWrappers = []any{
 		(*UserOpt_ByName)(nil),
 		(*UserOpt_ByID)(nil),
 	}
@@ -4044,7 +4178,7 @@ func file_github_com_moby_buildkit_solver_pb_ops_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc), len(file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc)),
 			NumEnums:      5,
-			NumMessages:   53,
+			NumMessages:   54,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
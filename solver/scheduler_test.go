@@ -7,12 +7,16 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/util/fairqueue"
+	"github.com/moby/buildkit/util/tenant"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
@@ -554,6 +558,204 @@ func TestSingleCancelExec(t *testing.T) {
 	j1 = nil
 }
 
+func TestSingleExecTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	s := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer s.Close()
+
+	j0, err := s.NewJob("job0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	g0 := Edge{
+		Vertex: vtx(vtxOpt{
+			name:      "v0",
+			execDelay: time.Minute,
+			timeout:   50 * time.Millisecond,
+		}),
+	}
+	g0.Vertex.(*vertex).setupCallCounters()
+
+	_, err = j0.Build(ctx, g0)
+	require.Error(t, err)
+	require.True(t, errdefs.IsTimeout(err))
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	var te *errdefs.TimeoutError
+	require.ErrorAs(t, err, &te)
+	require.Equal(t, int64(50*time.Millisecond), te.Timeout.Timeout)
+
+	require.Equal(t, int64(1), *g0.Vertex.(*vertex).execCallCount)
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	// the most permissive timeout wins when the vertex is shared
+	require.Equal(t, time.Duration(0), mergeTimeout(time.Second, 0))
+	require.Equal(t, 2*time.Second, mergeTimeout(time.Second, 2*time.Second))
+}
+
+func TestJobDeadline(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	s := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer s.Close()
+
+	j0, err := s.NewJob("job0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+	j0.SetDeadline(time.Now().Add(50 * time.Millisecond))
+
+	g0 := Edge{
+		Vertex: vtx(vtxOpt{
+			name:      "v0",
+			execDelay: time.Minute,
+		}),
+	}
+
+	_, err = j0.Build(ctx, g0)
+	require.Error(t, err)
+	require.True(t, errdefs.IsTimeout(err))
+	var te *errdefs.TimeoutError
+	require.ErrorAs(t, err, &te)
+	require.True(t, te.Deadline)
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+}
+
+func TestSchedulingClass(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	s := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer s.Close()
+
+	j0, err := s.NewJob("job0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+	j0.SetValue(fairqueue.JobValueKey, fairqueue.Class{ID: "job0", Tenant: "ci", Priority: fairqueue.PriorityHigh})
+
+	var class fairqueue.Class
+	g0 := Edge{
+		Vertex: vtx(vtxOpt{
+			name: "v0",
+			execPreFunc: func(ctx context.Context) error {
+				class = fairqueue.ClassFromContext(ctx)
+				return nil
+			},
+		}),
+	}
+
+	_, err = j0.Build(ctx, g0)
+	require.NoError(t, err)
+	require.Equal(t, fairqueue.Class{ID: "job0", Tenant: "ci", Priority: fairqueue.PriorityHigh}, class)
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	// a vertex shared by multiple jobs is queued with the highest priority
+	low := &Job{}
+	low.SetValue(fairqueue.JobValueKey, fairqueue.Class{ID: "low", Priority: fairqueue.PriorityLow})
+	high := &Job{}
+	high.SetValue(fairqueue.JobValueKey, fairqueue.Class{ID: "high", Priority: fairqueue.PriorityHigh})
+	st := &state{jobs: map[*Job]struct{}{low: {}, high: {}, {}: {}}}
+	require.Equal(t, "high", st.schedulingClass().ID)
+}
+
+func TestTenantScope(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	s := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer s.Close()
+
+	shared := func(v Vertex) bool {
+		return v.Name() == "base"
+	}
+
+	var mu sync.Mutex
+	tenants := map[string]string{}
+	build := func(id string) (*vertex, *vertex) {
+		record := func(name string) func(context.Context) error {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				tenants[name] = tenant.FromContext(ctx)
+				mu.Unlock()
+				return nil
+			}
+		}
+		base := vtx(vtxOpt{
+			name:         "base",
+			cacheKeySeed: "seed-base",
+			value:        "base",
+			execPreFunc:  record("base"),
+		})
+		top := vtx(vtxOpt{
+			name:         "top",
+			cacheKeySeed: "seed-top",
+			value:        "top",
+			inputs:       []Edge{{Vertex: base}},
+			execPreFunc:  record("top"),
+		})
+		// counters are shared with inputs, give base its own
+		top.setupCallCounters()
+		base.setupCallCounters()
+
+		j, err := s.NewJob(identity.NewID())
+		require.NoError(t, err)
+		defer j.Discard()
+		j.SetTenantScope(TenantScope{ID: id, Shared: shared})
+
+		res, err := j.Build(ctx, Edge{Vertex: top})
+		require.NoError(t, err)
+		require.Equal(t, "top", unwrap(res))
+		return base, top
+	}
+
+	base, top := build("a")
+	require.Equal(t, int64(1), *base.execCallCount)
+	require.Equal(t, int64(1), *top.execCallCount)
+	require.Equal(t, map[string]string{"base": "", "top": "a"}, tenants)
+
+	// another tenant reuses shared vertices but not the cache of the first
+	clear(tenants)
+	base, top = build("b")
+	require.Equal(t, int64(0), *base.execCallCount)
+	require.Equal(t, int64(1), *top.execCallCount)
+	require.Equal(t, map[string]string{"top": "b"}, tenants)
+
+	// the same tenant matches its own cache
+	base, top = build("a")
+	require.Equal(t, int64(0), *base.execCallCount)
+	require.Equal(t, int64(0), *top.execCallCount)
+}
+
 func TestSingleCancelParallel(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
@@ -3710,6 +3912,287 @@ func generateSubGraph(nodes int) (Edge, int) {
 	return Edge{Vertex: vtxSum(extra, vtxOpt{inputs: inputs})}, value
 }
 
+func TestJobCacheInfo(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer l.Close()
+
+	j0, err := l.NewJob("j0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	v1 := vtx(vtxOpt{
+		name:         "v1",
+		cacheKeySeed: "seed1",
+		value:        "result1",
+	})
+	v2 := vtx(vtxOpt{
+		name:         "v2",
+		cacheKeySeed: "seed2",
+		value:        "result2",
+	})
+	v0 := vtx(vtxOpt{
+		name:         "v0",
+		cacheKeySeed: "seed0",
+		value:        "result0",
+		inputs:       []Edge{{Vertex: v1}, {Vertex: v2}},
+		selectors: map[int]digest.Digest{
+			0: digest.FromBytes([]byte("sel0")),
+		},
+		slowCacheCompute: map[int]ResultBasedCacheFunc{
+			1: digestFromResult,
+		},
+	})
+
+	res, err := j0.Build(ctx, Edge{Vertex: v0})
+	require.NoError(t, err)
+	require.Equal(t, "result0", unwrap(res))
+
+	infos := j0.CacheInfo()
+	require.Len(t, infos, 3)
+	byDigest := map[digest.Digest]VertexCacheInfo{}
+	for _, info := range infos {
+		byDigest[info.Digest] = info
+	}
+
+	info, ok := byDigest[v0.Digest()]
+	require.True(t, ok)
+	require.Equal(t, "v0", info.Name)
+	require.Equal(t, []digest.Digest{digest.FromBytes([]byte("seed:seed0"))}, info.CacheMaps)
+	require.Equal(t, []DepCacheInfo{
+		{Selector: digest.FromBytes([]byte("sel0"))},
+		{ContentDigest: digest.FromBytes([]byte("result2"))},
+	}, info.Deps)
+
+	info, ok = byDigest[v1.Digest()]
+	require.True(t, ok)
+	require.Equal(t, []digest.Digest{digest.FromBytes([]byte("seed:seed1"))}, info.CacheMaps)
+	require.Empty(t, info.Deps)
+}
+
+func TestJobCacheMiss(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	cacheManager := newTrackingCacheManager(NewInMemoryCacheManager())
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  cacheManager,
+	})
+	defer l.Close()
+
+	cacheInfo := func(j *Job) map[string]VertexCacheInfo {
+		m := map[string]VertexCacheInfo{}
+		for _, info := range j.CacheInfo() {
+			m[info.Name] = info
+		}
+		return m
+	}
+
+	j0, err := l.NewJob("j0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	v1 := vtx(vtxOpt{
+		name:         "v1",
+		cacheKeySeed: "seed1",
+		value:        "result1",
+	})
+	v0 := vtx(vtxOpt{
+		name:         "v0",
+		cacheKeySeed: "seed0",
+		value:        "result0",
+		inputs:       []Edge{{Vertex: v1}},
+	})
+
+	res, err := j0.Build(ctx, Edge{Vertex: v0})
+	require.NoError(t, err)
+	require.Equal(t, "result0", unwrap(res))
+
+	infos := cacheInfo(j0)
+	require.False(t, infos["v0"].Cached)
+	require.Equal(t, &CacheMiss{Reason: CacheMissNoMatch}, infos["v0"].CacheMiss)
+	require.Equal(t, &CacheMiss{Reason: CacheMissNoMatch}, infos["v1"].CacheMiss)
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	j1, err := l.NewJob("j1")
+	require.NoError(t, err)
+
+	defer func() {
+		if j1 != nil {
+			j1.Discard()
+		}
+	}()
+
+	emptyCache := newTrackingCacheManager(NewInMemoryCacheManager())
+
+	// a cache import that has the keys of v4 and v5 but not their results,
+	// like a cache exported with mode=min for intermediate vertices
+	importKeys := NewInMemoryCacheStorage()
+	l2 := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  NewCacheManager(ctx, "export", importKeys, NewInMemoryResultStorage()),
+	})
+	defer l2.Close()
+	importCache := NewCacheManager(ctx, "import", importKeys, NewInMemoryResultStorage())
+	v4 := func() Vertex {
+		return vtx(vtxOpt{
+			name:         "v4",
+			cacheKeySeed: "seed4",
+			value:        "result4",
+			cacheSource:  importCache,
+			inputs: []Edge{{Vertex: vtx(vtxOpt{
+				name:         "v5",
+				cacheKeySeed: "seed5",
+				value:        "result5",
+				cacheSource:  importCache,
+			})}},
+		})
+	}
+	j2, err := l2.NewJob("j2")
+	require.NoError(t, err)
+	_, err = j2.Build(ctx, Edge{Vertex: v4()})
+	require.NoError(t, err)
+	require.NoError(t, j2.Discard())
+
+	v2 := vtx(vtxOpt{
+		name:         "v2",
+		cacheKeySeed: "seed2",
+		value:        "result2",
+		ignoreCache:  true,
+		inputs: []Edge{{Vertex: vtx(vtxOpt{
+			name:         "v0",
+			cacheKeySeed: "seed0",
+			value:        "result0-no-cache",
+			inputs: []Edge{{Vertex: vtx(vtxOpt{
+				name:         "v1",
+				cacheKeySeed: "seed1",
+				value:        "result1-no-cache",
+			})}},
+		})}, {Vertex: vtx(vtxOpt{
+			name:         "v3",
+			cacheKeySeed: "seed3",
+			value:        "result3",
+			cacheSource:  emptyCache,
+		})}, {Vertex: v4()}},
+	})
+
+	res, err = j1.Build(ctx, Edge{Vertex: v2})
+	require.NoError(t, err)
+	require.Equal(t, "result2", unwrap(res))
+
+	infos = cacheInfo(j1)
+	require.True(t, infos["v0"].Cached)
+	require.Nil(t, infos["v0"].CacheMiss)
+	require.Equal(t, &CacheMiss{Reason: CacheMissIgnoreCache}, infos["v2"].CacheMiss)
+	require.Equal(t, &CacheMiss{Reason: CacheMissNotImported}, infos["v3"].CacheMiss)
+	require.Equal(t, &CacheMiss{Reason: CacheMissExporterOnly}, infos["v4"].CacheMiss)
+	require.Equal(t, &CacheMiss{Reason: CacheMissExporterOnly}, infos["v5"].CacheMiss)
+
+	require.NoError(t, j1.Discard())
+	j1 = nil
+}
+
+func TestJobVertexStatus(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+		DefaultCache:  NewInMemoryCacheManager(),
+	})
+	defer l.Close()
+
+	vertexStatus := func(j *Job) map[string]VertexStatus {
+		m := map[string]VertexStatus{}
+		for _, vs := range j.VertexStatus() {
+			m[vs.Name] = vs
+		}
+		return m
+	}
+
+	newVertex := func() *vertex {
+		return vtx(vtxOpt{
+			name:         "v0",
+			cacheKeySeed: "seed0",
+			value:        "result0",
+			inputs: []Edge{{Vertex: vtx(vtxOpt{
+				name:         "v1",
+				cacheKeySeed: "seed1",
+				value:        "result1",
+			})}},
+		})
+	}
+
+	j0, err := l.NewJob("j0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	res, err := j0.Build(ctx, Edge{Vertex: newVertex()})
+	require.NoError(t, err)
+	require.Equal(t, "result0", unwrap(res))
+
+	status := vertexStatus(j0)
+	require.Len(t, status, 2)
+	for _, name := range []string{"v0", "v1"} {
+		vs := status[name]
+		require.NotNil(t, vs.Started)
+		require.NotNil(t, vs.Completed)
+		require.False(t, vs.Completed.Before(*vs.Started))
+		require.False(t, vs.Cached)
+		require.Equal(t, &CacheMiss{Reason: CacheMissNoMatch}, vs.CacheMiss)
+	}
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	j1, err := l.NewJob("j1")
+	require.NoError(t, err)
+
+	defer func() {
+		if j1 != nil {
+			j1.Discard()
+		}
+	}()
+
+	res, err = j1.Build(ctx, Edge{Vertex: newVertex()})
+	require.NoError(t, err)
+	require.Equal(t, "result0", unwrap(res))
+
+	status = vertexStatus(j1)
+	require.Len(t, status, 2)
+	require.True(t, status["v0"].Cached)
+	require.Nil(t, status["v0"].CacheMiss)
+	// v1 only computed its cache key as v0 was loaded from cache
+	require.False(t, status["v1"].Cached)
+	require.Nil(t, status["v1"].CacheMiss)
+
+	require.NoError(t, j1.Discard())
+	j1 = nil
+}
+
 type vtxOpt struct {
 	name             string
 	cacheKeySeed     string
@@ -3725,6 +4208,7 @@ type vtxOpt struct {
 	selectors        map[int]digest.Digest
 	cacheSource      CacheManager
 	ignoreCache      bool
+	timeout          time.Duration
 }
 
 func vtx(opt vtxOpt) *vertex {
@@ -3766,6 +4250,7 @@ func (v *vertex) Options() VertexOptions {
 	return VertexOptions{
 		CacheSources: cache,
 		IgnoreCache:  v.opt.ignoreCache,
+		Timeout:      v.opt.timeout,
 	}
 }
 
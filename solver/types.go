@@ -57,6 +57,9 @@ type VertexOptions struct {
 	// WorkerConstraint
 	ProgressGroup *pb.ProgressGroup
 	Metadata      VertexMetadata
+	// Timeout is the maximum duration the vertex is allowed to execute for.
+	// Zero means no timeout.
+	Timeout time.Duration
 }
 
 // VertexMetadata is opaque per-vertex metadata that gets merged when the same
@@ -0,0 +1,61 @@
+package solver
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// VertexStatus describes the last run of a vertex of a job.
+type VertexStatus struct {
+	// Digest is the digest of the vertex as reported in the progress stream.
+	Digest    digest.Digest
+	Name      string
+	Started   *time.Time
+	Completed *time.Time
+	Cached    bool
+	// CacheMiss is set if the vertex was executed.
+	CacheMiss *CacheMiss
+}
+
+// VertexStatus returns the status of the vertices of the job that have been
+// started, ordered by start time.
+func (j *Job) VertexStatus() []VertexStatus {
+	j.list.mu.RLock()
+	defer j.list.mu.RUnlock()
+
+	var out []VertexStatus
+	for _, st := range j.list.actives {
+		if _, ok := st.jobs[j]; !ok {
+			continue
+		}
+		if st.clientVertex.Started == nil {
+			continue
+		}
+		vs := VertexStatus{
+			Digest:    st.clientVertex.Digest,
+			Name:      st.clientVertex.Name,
+			Started:   st.clientVertex.Started,
+			Completed: st.clientVertex.Completed,
+			Cached:    st.clientVertex.Cached,
+		}
+		st.mu.RLock()
+		op := st.op
+		st.mu.RUnlock()
+		if op != nil {
+			op.missMu.Lock()
+			vs.CacheMiss = op.cacheMiss
+			op.missMu.Unlock()
+		}
+		out = append(out, vs)
+	}
+	slices.SortFunc(out, func(a, b VertexStatus) int {
+		if c := a.Started.Compare(*b.Started); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Digest), string(b.Digest))
+	})
+	return out
+}
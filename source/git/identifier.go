@@ -43,6 +43,12 @@ type GitIdentifier struct {
 	// CheckoutBundle, when true, produces a single-file git bundle at the
 	// checkout mount root (filename "bundle") instead of a worktree.
 	CheckoutBundle bool
+	// SparsePaths, when set, limits the checkout to the given directories
+	// relative to Subdir.
+	SparsePaths []string
+	// LFS, when true, replaces the Git LFS pointer files in the checkout with
+	// the objects they point to.
+	LFS bool
 
 	VerifySignature *GitSignatureVerifyOptions
 }
@@ -136,6 +142,9 @@ func validateBundleAttrs(id *GitIdentifier) error {
 		if id.Subdir != "" {
 			return errors.Errorf("git.checkoutbundle is incompatible with git.subdir")
 		}
+		if id.LFS {
+			return errors.Errorf("git.checkoutbundle is incompatible with git.lfs")
+		}
 	}
 	return nil
 }
@@ -0,0 +1,534 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/gitutil"
+	"github.com/moby/buildkit/util/tracing"
+	"github.com/moby/buildkit/util/urlutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+const (
+	lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+	// lfsMaxPointerSize is the maximum size of a pointer file, larger files
+	// are never parsed as pointers
+	lfsMaxPointerSize = 1024
+	lfsMediaType      = "application/vnd.git-lfs+json"
+	// lfsBatchSize is the maximum number of objects in a batch request
+	lfsBatchSize = 100
+	// gitArgsBatchSize is the maximum number of paths or objects passed to a
+	// single git command
+	gitArgsBatchSize = 1000
+)
+
+// lfsPointer is a file in the repository pointing to an LFS object.
+type lfsPointer struct {
+	Path string
+	Oid  string
+	Size int64
+}
+
+// parseLFSPointer parses the contents of an LFS pointer file as defined in
+// https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md.
+func parseLFSPointer(dt []byte) (oid string, size int64, ok bool) {
+	if len(dt) > lfsMaxPointerSize {
+		return "", 0, false
+	}
+	lines := strings.Split(strings.TrimSuffix(string(dt), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != lfsPointerVersion {
+		return "", 0, false
+	}
+	size = -1
+	for _, l := range lines[1:] {
+		k, v, found := strings.Cut(l, " ")
+		if !found {
+			return "", 0, false
+		}
+		switch k {
+		case "oid":
+			hash, found := strings.CutPrefix(v, "sha256:")
+			if !found || len(hash) != 64 || strings.ToLower(hash) != hash {
+				return "", 0, false
+			}
+			if _, err := hex.DecodeString(hash); err != nil {
+				return "", 0, false
+			}
+			oid = hash
+		case "size":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return "", 0, false
+			}
+			size = n
+		}
+	}
+	if oid == "" || size < 0 {
+		return "", 0, false
+	}
+	return oid, size, true
+}
+
+// lfsPointers returns the LFS pointer files in the directories in paths, or
+// the whole tree if paths is empty, of commit. The files are not matched with
+// the filter attributes of the repository, so this may include files that
+// only look like pointers. If prefetch is set, the blobs of the candidate
+// files are fetched from the remote in batches first as the repository is a
+// partial clone that would otherwise fetch them one by one.
+func lfsPointers(ctx context.Context, git *gitutil.GitCLI, commit string, paths []string, prefetch bool) ([]lfsPointer, error) {
+	dt, err := git.Run(ctx, append([]string{"ls-tree", "-r", "-l", "-z", "--full-tree", commit, "--"}, paths...)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list files of %s", commit)
+	}
+	var candidates, oids []string
+	for entry := range bytes.SplitSeq(bytes.TrimSuffix(dt, []byte{0}), []byte{0}) {
+		meta, p, ok := bytes.Cut(entry, []byte{'\t'})
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(string(meta))
+		if len(fields) != 4 || fields[1] != "blob" || (fields[0] != "100644" && fields[0] != "100755") {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil || size == 0 || size > lfsMaxPointerSize {
+			continue
+		}
+		candidates = append(candidates, string(p))
+		oids = append(oids, fields[2])
+	}
+
+	if prefetch {
+		for chunk := range slices.Chunk(oids, gitArgsBatchSize) {
+			args := append([]string{"-c", "fetch.negotiationAlgorithm=noop", "fetch", "--no-tags", "--no-write-fetch-head", "--recurse-submodules=no", "--filter=" + partialCloneFilter, "origin"}, chunk...)
+			if _, err := git.Run(ctx, args...); err != nil {
+				return nil, errors.Wrap(err, "failed to fetch LFS pointer files")
+			}
+		}
+	}
+
+	contents := map[string]*bytes.Buffer{}
+	for chunk := range slices.Chunk(candidates, gitArgsBatchSize) {
+		args := []string{"grep", "-z", "--no-color", "-e", "^version ", "-e", "^oid ", "-e", "^size ", commit, "--"}
+		for _, p := range chunk {
+			// pathspecs must match literally
+			args = append(args, ":(literal)"+p)
+		}
+		dt, err := git.Run(ctx, args...)
+		if err != nil {
+			// git grep exits with 1 if nothing matched
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+				continue
+			}
+			return nil, errors.Wrap(err, "failed to read LFS pointer files")
+		}
+		for line := range strings.SplitSeq(strings.TrimSuffix(string(dt), "\n"), "\n") {
+			name, text, ok := strings.Cut(line, "\x00")
+			if !ok {
+				continue
+			}
+			p := strings.TrimPrefix(name, commit+":")
+			if _, ok := contents[p]; !ok {
+				contents[p] = &bytes.Buffer{}
+			}
+			contents[p].WriteString(text + "\n")
+		}
+	}
+
+	var pointers []lfsPointer
+	for _, p := range slices.Sorted(maps.Keys(contents)) {
+		if oid, size, ok := parseLFSPointer(contents[p].Bytes()); ok {
+			pointers = append(pointers, lfsPointer{Path: p, Oid: oid, Size: size})
+		}
+	}
+	return pointers, nil
+}
+
+// lfsCacheKey returns a checksum of the oids of the LFS objects in pointers.
+func lfsCacheKey(pointers []lfsPointer) string {
+	oids := make([]string, 0, len(pointers))
+	for _, p := range pointers {
+		oids = append(oids, p.Oid)
+	}
+	slices.Sort(oids)
+	return digest.FromString(strings.Join(slices.Compact(oids), "\n")).Encoded()
+}
+
+// filterLFSPointers returns the pointers of the files with the lfs filter
+// attribute in the work tree of git.
+func filterLFSPointers(ctx context.Context, git *gitutil.GitCLI, pointers []lfsPointer) ([]lfsPointer, error) {
+	lfs := map[string]struct{}{}
+	for chunk := range slices.Chunk(pointers, gitArgsBatchSize) {
+		args := []string{"check-attr", "-z", "filter", "--"}
+		for _, p := range chunk {
+			args = append(args, p.Path)
+		}
+		dt, err := git.Run(ctx, args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check attributes of LFS pointer files")
+		}
+		// the output consists of path, attribute and value triplets
+		fields := strings.Split(strings.TrimSuffix(string(dt), "\x00"), "\x00")
+		for i := 0; i+2 < len(fields); i += 3 {
+			if fields[i+2] == "lfs" {
+				lfs[fields[i]] = struct{}{}
+			}
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(pointers), func(p lfsPointer) bool {
+		_, ok := lfs[p.Path]
+		return !ok
+	}), nil
+}
+
+// fetchLFSObjects replaces the LFS pointer files of commit in the checkout in
+// dir, the work tree of git, with the LFS objects they point to.
+func (gs *gitSourceHandler) fetchLFSObjects(ctx context.Context, git *gitutil.GitCLI, dir string, commit string, g session.Group) error {
+	pointers, err := lfsPointers(ctx, git, commit, gs.src.checkoutPaths(), false)
+	if err != nil {
+		return err
+	}
+	pointers, err = filterLFSPointers(ctx, git, pointers)
+	if err != nil || len(pointers) == 0 {
+		return err
+	}
+
+	ep, err := gs.lfsEndpoint(ctx, git, commit, g)
+	if err != nil {
+		return err
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to open checkout root")
+	}
+	defer root.Close()
+
+	byOid := map[string][]lfsPointer{}
+	var objects []lfsObject
+	for _, p := range pointers {
+		if _, ok := byOid[p.Oid]; !ok {
+			objects = append(objects, lfsObject{Oid: p.Oid, Size: p.Size})
+		}
+		byOid[p.Oid] = append(byOid[p.Oid], p)
+	}
+
+	bklog.G(ctx).Debugf("fetching %d LFS objects from %s", len(objects), urlutil.RedactCredentials(ep.URL))
+	for chunk := range slices.Chunk(objects, lfsBatchSize) {
+		resp, err := ep.batch(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		for _, obj := range resp.Objects {
+			files, ok := byOid[obj.Oid]
+			if !ok {
+				continue
+			}
+			if obj.Error != nil {
+				return errors.Errorf("failed to fetch LFS object %s for %s: %s (%d)", obj.Oid, files[0].Path, obj.Error.Message, obj.Error.Code)
+			}
+			action, ok := obj.Actions["download"]
+			if !ok {
+				return errors.Errorf("no download action for LFS object %s for %s", obj.Oid, files[0].Path)
+			}
+			if err := ep.download(ctx, root, action, files); err != nil {
+				return err
+			}
+			delete(byOid, obj.Oid)
+		}
+	}
+	if len(byOid) > 0 {
+		oid := slices.Min(slices.Collect(maps.Keys(byOid)))
+		return errors.Errorf("LFS object %s for %s missing from batch response", oid, byOid[oid][0].Path)
+	}
+	return nil
+}
+
+type lfsBatchRequest struct {
+	Operation string      `json:"operation"`
+	Transfers []string    `json:"transfers,omitempty"`
+	Objects   []lfsObject `json:"objects"`
+	HashAlgo  string      `json:"hash_algo,omitempty"`
+}
+
+type lfsBatchResponse struct {
+	Transfer string      `json:"transfer,omitempty"`
+	Objects  []lfsObject `json:"objects"`
+	HashAlgo string      `json:"hash_algo,omitempty"`
+}
+
+type lfsObject struct {
+	Oid     string                `json:"oid"`
+	Size    int64                 `json:"size"`
+	Actions map[string]*lfsAction `json:"actions,omitempty"`
+	Error   *lfsError             `json:"error,omitempty"`
+}
+
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+type lfsError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// lfsEndpoint is the LFS server of a repository.
+type lfsEndpoint struct {
+	URL    string
+	Header map[string]string
+	// authScope is the URL prefix that the Authorization header of the
+	// repository is sent to
+	authScope  string
+	authHeader string
+	client     *http.Client
+}
+
+// lfsEndpoint returns the LFS server for the remote: the lfs.url in the
+// .lfsconfig file of commit or the one derived from the remote URL. For SSH
+// remotes, the URL and headers are requested with git-lfs-authenticate.
+func (gs *gitSourceHandler) lfsEndpoint(ctx context.Context, git *gitutil.GitCLI, commit string, g session.Group) (*lfsEndpoint, error) {
+	ep := &lfsEndpoint{
+		authScope:  tokenScope(gs.src.Remote),
+		authHeader: gs.authHeader,
+		client:     tracing.DefaultClient,
+	}
+	// .lfsconfig is read from the repository rather than the checkout to
+	// not follow symlinks
+	if dt, err := git.Run(ctx, "config", "--blob", commit+":.lfsconfig", "--get", "lfs.url"); err == nil {
+		if u := strings.TrimSpace(string(dt)); u != "" {
+			ep.URL = u
+			return ep, nil
+		}
+	}
+
+	u, err := gitutil.ParseURL(gs.src.Remote)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case gitutil.HTTPProtocol, gitutil.HTTPSProtocol:
+		ep.URL = lfsURL(u.Remote)
+	case gitutil.GitProtocol:
+		ep.URL = lfsURL("https://" + u.Host + u.Path)
+	case gitutil.SSHProtocol:
+		if err := gs.lfsSSHAuthenticate(ctx, ep, u, g); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported LFS remote %s", urlutil.RedactCredentials(gs.src.Remote))
+	}
+	return ep, nil
+}
+
+// lfsURL returns the LFS server URL for the repository URL.
+func lfsURL(remote string) string {
+	remote = strings.TrimSuffix(remote, "/")
+	if !strings.HasSuffix(remote, ".git") {
+		remote += ".git"
+	}
+	return remote + "/info/lfs"
+}
+
+// lfsSSHAuthenticate sets the LFS server URL and headers of ep from the
+// response of the git-lfs-authenticate command on the SSH server of u.
+func (gs *gitSourceHandler) lfsSSHAuthenticate(ctx context.Context, ep *lfsEndpoint, u *gitutil.GitURL, g session.Group) error {
+	args := []string{"-F", os.DevNull}
+	var env []string
+	if gs.src.MountSSHSock != "" {
+		sock, unmount, err := gs.mountSSHAuthSock(ctx, gs.src.MountSSHSock, g)
+		if err != nil {
+			return err
+		}
+		defer unmount()
+		env = append(env, "SSH_AUTH_SOCK="+sock)
+	}
+	if gs.src.KnownSSHHosts != "" {
+		knownHosts, unmount, err := gs.mountKnownHosts()
+		if err != nil {
+			return err
+		}
+		defer unmount()
+		args = append(args, "-o", "UserKnownHostsFile="+knownHosts)
+	} else {
+		args = append(args, "-o", "StrictHostKeyChecking=no")
+	}
+	host := u.Host
+	if h, port, err := net.SplitHostPort(u.Host); err == nil {
+		host = h
+		args = append(args, "-p", port)
+	}
+	if u.User != nil {
+		host = u.User.Username() + "@" + host
+	}
+	args = append(args, "--", host, "git-lfs-authenticate", strings.TrimPrefix(u.Path, "/"), "download")
+
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Env = append([]string{"PATH=" + os.Getenv("PATH")}, env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	dt, err := cmd.Output()
+	if err != nil {
+		return errors.Wrapf(err, "failed to run git-lfs-authenticate on %s: %s", host, strings.TrimSpace(stderr.String()))
+	}
+	var resp struct {
+		Href   string            `json:"href"`
+		Header map[string]string `json:"header"`
+	}
+	if err := json.Unmarshal(dt, &resp); err != nil {
+		return errors.Wrap(err, "failed to parse git-lfs-authenticate response")
+	}
+	if resp.Href == "" {
+		return errors.Errorf("git-lfs-authenticate on %s returned no LFS server", host)
+	}
+	ep.URL = resp.Href
+	ep.Header = resp.Header
+	// the credentials of the repository are for HTTP remotes only
+	ep.authHeader = ""
+	return nil
+}
+
+func (ep *lfsEndpoint) setHeaders(req *http.Request, header map[string]string) {
+	if ep.authHeader != "" && strings.HasPrefix(req.URL.String(), ep.authScope) {
+		req.Header.Set("Authorization", ep.authHeader)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+}
+
+func (ep *lfsEndpoint) batch(ctx context.Context, objects []lfsObject) (*lfsBatchResponse, error) {
+	dt, err := json.Marshal(lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   objects,
+		HashAlgo:  "sha256",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(ep.URL, "/")+"/objects/batch", bytes.NewReader(dt))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	ep.setHeaders(req, ep.Header)
+
+	resp, err := ep.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to request LFS objects from %s", urlutil.RedactCredentials(ep.URL))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to request LFS objects from %s: %s", urlutil.RedactCredentials(ep.URL), resp.Status)
+	}
+	var br lfsBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&br); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse LFS batch response from %s", urlutil.RedactCredentials(ep.URL))
+	}
+	if br.Transfer != "" && br.Transfer != "basic" {
+		return nil, errors.Errorf("unsupported LFS transfer %q", br.Transfer)
+	}
+	return &br, nil
+}
+
+// download writes the LFS object of action to the files, verifying its size
+// and checksum.
+func (ep *lfsEndpoint) download(ctx context.Context, root *os.Root, action *lfsAction, files []lfsPointer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, action.Href, nil)
+	if err != nil {
+		return err
+	}
+	ep.setHeaders(req, action.Header)
+	resp, err := ep.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download LFS object for %s", files[0].Path)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to download LFS object for %s: %s", files[0].Path, resp.Status)
+	}
+
+	first := filepath.FromSlash(files[0].Path)
+	if err := writeLFSObject(root, first, func(w io.Writer) error {
+		h := sha256.New()
+		n, err := io.Copy(io.MultiWriter(w, h), resp.Body)
+		if err != nil {
+			return errors.Wrapf(err, "failed to download LFS object for %s", files[0].Path)
+		}
+		if n != files[0].Size {
+			return errors.Errorf("invalid size of LFS object for %s: expected %d, got %d", files[0].Path, files[0].Size, n)
+		}
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != files[0].Oid {
+			return errors.Errorf("invalid checksum of LFS object for %s: expected %s, got %s", files[0].Path, files[0].Oid, sum)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// files with the same contents only need to be downloaded once
+	for _, f := range files[1:] {
+		if err := writeLFSObject(root, filepath.FromSlash(f.Path), func(w io.Writer) error {
+			src, err := root.Open(first)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			_, err = io.Copy(w, src)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLFSObject replaces the contents of the pointer file p in root,
+// keeping its mode.
+func writeLFSObject(root *os.Root, p string, write func(io.Writer) error) error {
+	fi, err := root.Lstat(p)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return errors.Errorf("LFS pointer %s is not a regular file", p)
+	}
+	f, err := root.OpenFile(p, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	err = write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to write LFS object to %s", p)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLFSPointer(t *testing.T) {
+	oid := strings.Repeat("ab", 32)
+	dt := "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 12345\n"
+	parsedOid, size, ok := parseLFSPointer([]byte(dt))
+	require.True(t, ok)
+	require.Equal(t, oid, parsedOid)
+	require.Equal(t, int64(12345), size)
+
+	// extension lines are allowed
+	_, _, ok = parseLFSPointer([]byte("version https://git-lfs.github.com/spec/v1\next-0-foo sha256:" + oid + "\noid sha256:" + oid + "\nsize 1\n"))
+	require.True(t, ok)
+
+	for _, dt := range []string{
+		"",
+		"hello world\n",
+		"version https://git-lfs.github.com/spec/v2\noid sha256:" + oid + "\nsize 1\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha1:" + oid[:40] + "\nsize 1\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + strings.ToUpper(oid) + "\nsize 1\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize -1\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 1\n" + strings.Repeat("x", lfsMaxPointerSize),
+	} {
+		_, _, ok := parseLFSPointer([]byte(dt))
+		require.False(t, ok, dt)
+	}
+}
+
+func TestLFSURL(t *testing.T) {
+	require.Equal(t, "https://github.com/moby/buildkit.git/info/lfs", lfsURL("https://github.com/moby/buildkit.git"))
+	require.Equal(t, "https://github.com/moby/buildkit.git/info/lfs", lfsURL("https://github.com/moby/buildkit"))
+	require.Equal(t, "https://github.com/moby/buildkit.git/info/lfs", lfsURL("https://github.com/moby/buildkit/"))
+}
+
+func TestLFSCacheKey(t *testing.T) {
+	a := strings.Repeat("a", 64)
+	b := strings.Repeat("b", 64)
+	require.Equal(t,
+		lfsCacheKey([]lfsPointer{{Path: "x", Oid: a}, {Path: "y", Oid: b}}),
+		lfsCacheKey([]lfsPointer{{Path: "z", Oid: b}, {Path: "w", Oid: a}, {Path: "v", Oid: a}}),
+	)
+	require.NotEqual(t, lfsCacheKey([]lfsPointer{{Oid: a}}), lfsCacheKey([]lfsPointer{{Oid: b}}))
+}
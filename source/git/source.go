@@ -157,6 +157,13 @@ func (gs *Source) Identifier(scheme, ref string, attrs map[string]string, platfo
 			id.BundleOCISessionID = v
 		case pb.AttrOCILayoutStoreID:
 			id.BundleOCIStoreID = v
+		case pb.AttrGitLFS:
+			id.LFS = v == "true"
+		case pb.AttrGitSparsePaths:
+			id.SparsePaths, err = parseSparsePaths(v)
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 	if err := validateGitRef(id.Ref); err != nil {
@@ -165,13 +172,24 @@ func (gs *Source) Identifier(scheme, ref string, attrs map[string]string, platfo
 	if err := validateBundleAttrs(id); err != nil {
 		return nil, err
 	}
+	if err := validateSparseAttrs(id); err != nil {
+		return nil, err
+	}
 
 	return id, nil
 }
 
 // needs to be called with repo lock
-func (gs *Source) mountRemote(ctx context.Context, remote string, authArgs []string, sha256 bool, reset bool, g session.Group) (target string, release func() error, retErr error) {
-	sis, err := searchGitRemote(ctx, gs.cache, remote)
+func (gs *Source) mountRemote(ctx context.Context, remote string, authArgs []string, sha256 bool, partial bool, reset bool, g session.Group) (target string, release func() error, retErr error) {
+	// partial clones are kept separately so that fetches for full checkouts
+	// do not need to lazily fetch the missing blobs one by one
+	remoteKey := remote
+	desc := fmt.Sprintf("shared git repo for %s", urlutil.RedactCredentials(remote))
+	if partial {
+		remoteKey += "#filter=" + partialCloneFilter
+		desc += " (filter=" + partialCloneFilter + ")"
+	}
+	sis, err := searchGitRemote(ctx, gs.cache, remoteKey)
 	if err != nil {
 		return "", nil, errors.Wrapf(err, "failed to search metadata for %s", urlutil.RedactCredentials(remote))
 	}
@@ -198,7 +216,7 @@ func (gs *Source) mountRemote(ctx context.Context, remote string, authArgs []str
 
 	initializeRepo := false
 	if remoteRef == nil {
-		remoteRef, err = gs.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("shared git repo for %s", urlutil.RedactCredentials(remote))))
+		remoteRef, err = gs.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(desc))
 		if err != nil {
 			return "", nil, errors.Wrapf(err, "failed to create new mutable for %s", urlutil.RedactCredentials(remote))
 		}
@@ -256,7 +274,7 @@ func (gs *Source) mountRemote(ctx context.Context, remote string, authArgs []str
 
 		// save new remote metadata
 		md := cacheRefMetadata{remoteRef}
-		if err := md.setGitRemote(remote); err != nil {
+		if err := md.setGitRemote(remoteKey); err != nil {
 			return "", nil, err
 		}
 	}
@@ -277,6 +295,8 @@ type gitSourceHandler struct {
 	sha256      bool
 	sm          *session.Manager
 	authArgs    []string
+	// authHeader is the Authorization header set by authArgs
+	authHeader string
 
 	// stagedBundleURL / stagedBundleCleanup cache the temp bundle staging
 	// across resolveBundleMetadata (CacheKey) and tryRemoteFetch (Snapshot)
@@ -305,6 +325,12 @@ func (gs *gitSourceHandler) shaToCacheKey(sha, ref string) string {
 	if gs.src.CheckoutBundle {
 		key += "(bundle)"
 	}
+	if len(gs.src.SparsePaths) > 0 {
+		key += "(sparse=" + strings.Join(gs.src.SparsePaths, ",") + ")"
+	}
+	if gs.src.LFS {
+		key += "(lfs)"
+	}
 	return key
 }
 
@@ -450,7 +476,8 @@ func (gs *gitSourceHandler) getAuthToken(ctx context.Context, g session.Group) e
 			if s.token {
 				dt = []byte("basic " + base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "x-access-token:%s", dt)))
 			}
-			gs.authArgs = []string{"-c", "http." + tokenScope(gs.src.Remote) + ".extraheader=Authorization: " + string(dt)}
+			gs.authHeader = string(dt)
+			gs.authArgs = []string{"-c", "http." + tokenScope(gs.src.Remote) + ".extraheader=Authorization: " + gs.authHeader}
 			break
 		}
 		return err
@@ -773,23 +800,67 @@ func (gs *gitSourceHandler) CacheKey(ctx context.Context, jobCtx solver.JobConte
 		}
 	}
 
+	var cacheKey, pin string
 	if gitutil.IsCommitSHA(md.Ref) {
-		cacheKey := gs.shaToCacheKey(md.Ref, md.Ref)
-		gs.cacheKey = cacheKey
-		gs.cacheCommit = md.Ref
+		cacheKey = gs.shaToCacheKey(md.Ref, md.Ref)
 		// gs.src.Checksum is verified when checking out the commit
-		return cacheKey, md.Ref, nil, true, nil
+		pin = md.Ref
+	} else {
+		shaForCacheKey := md.Checksum
+		if md.CommitChecksum != "" && !gs.src.KeepGitDir {
+			// prefer commit sha pointed by annotated tag if no git dir is kept for more matches
+			shaForCacheKey = md.CommitChecksum
+		}
+		cacheKey = gs.shaToCacheKey(shaForCacheKey, md.Ref)
+		pin = md.Checksum
 	}
+	gs.cacheCommit = pin
 
-	shaForCacheKey := md.Checksum
-	if md.CommitChecksum != "" && !gs.src.KeepGitDir {
-		// prefer commit sha pointed by annotated tag if no git dir is kept for more matches
-		shaForCacheKey = md.CommitChecksum
+	if gs.treeCacheKey() || gs.src.LFS {
+		// The commit key is returned first so that it can match without
+		// fetching from the remote. The second key depends on the fetched
+		// contents instead.
+		if index == 0 {
+			return cacheKey, pin, nil, false, nil
+		}
+		cacheKey, err = gs.contentCacheKey(ctx, jobCtx, cacheKey, pin, md.Ref)
+		if err != nil {
+			return "", "", nil, false, err
+		}
 	}
-	cacheKey := gs.shaToCacheKey(shaForCacheKey, md.Ref)
 	gs.cacheKey = cacheKey
-	gs.cacheCommit = md.Checksum
-	return cacheKey, md.Checksum, nil, true, nil
+	return cacheKey, pin, nil, true, nil
+}
+
+// contentCacheKey returns the cache key for the contents of commit. With a
+// sparse checkout, the key only depends on the trees of the checked out
+// directories so commits changing other parts of the repository still match.
+// With LFS, the key includes the oids of the LFS objects.
+func (gs *gitSourceHandler) contentCacheKey(ctx context.Context, jobCtx solver.JobContext, commitKey, commit, ref string) (string, error) {
+	repo, err := gs.remoteFetch(ctx, jobCtx)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Release()
+
+	cacheKey := commitKey
+	if gs.treeCacheKey() {
+		treeKey, err := resolveTreeKey(ctx, repo.GitCLI, commit, gs.src.checkoutPaths())
+		if err != nil {
+			return "", err
+		}
+		if treeKey != "" {
+			cacheKey = gs.shaToCacheKey(treeKey, ref)
+		}
+	}
+	if gs.src.LFS {
+		pointers, err := lfsPointers(ctx, repo.GitCLI, commit, gs.src.checkoutPaths(), gs.partialClone())
+		if err != nil {
+			return "", err
+		}
+		cacheKey += "(lfs=" + lfsCacheKey(pointers) + ")"
+	}
+	return cacheKey, nil
 }
 
 func (gs *gitSourceHandler) remoteFetch(ctx context.Context, jobCtx solver.JobContext) (_ *gitRepo, retErr error) {
@@ -862,14 +933,13 @@ func (gs *gitSourceHandler) remoteFetch(ctx context.Context, jobCtx solver.JobCo
 }
 
 func (gs *gitSourceHandler) Snapshot(ctx context.Context, jobCtx solver.JobContext) (cache.ImmutableRef, error) {
-	cacheKey := gs.cacheKey
-	if cacheKey == "" {
-		var err error
-		cacheKey, _, _, _, err = gs.CacheKey(ctx, jobCtx, 0)
-		if err != nil {
+	// gs.cacheKey is set by the last call to CacheKey
+	for i := 0; gs.cacheKey == ""; i++ {
+		if _, _, _, _, err := gs.CacheKey(ctx, jobCtx, i); err != nil {
 			return nil, err
 		}
 	}
+	cacheKey := gs.cacheKey
 
 	var g session.Group
 	if jobCtx != nil {
@@ -971,7 +1041,7 @@ func (gs *gitSourceHandler) tryRemoteFetch(ctx context.Context, jobCtx solver.Jo
 		}
 	}
 
-	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, authArgs, gs.sha256, reset, g)
+	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, authArgs, gs.sha256, gs.partialClone(), reset, g)
 	if err != nil {
 		return nil, err
 	}
@@ -1072,6 +1142,9 @@ func (gs *gitSourceHandler) tryRemoteFetch(ctx context.Context, jobCtx solver.Jo
 				args = append(args, "--unshallow")
 			}
 		}
+		if gs.partialClone() {
+			args = append(args, "--filter="+partialCloneFilter)
+		}
 		args = append(args, origin)
 		if gitutil.IsCommitSHA(ref) {
 			args = append(args, ref)
@@ -1118,6 +1191,9 @@ func (gs *gitSourceHandler) tryRemoteFetch(ctx context.Context, jobCtx solver.Jo
 				if _, err := gitDirRoot.Lstat("shallow"); err == nil {
 					args = append(args, "--unshallow")
 				}
+				if gs.partialClone() {
+					args = append(args, "--filter="+partialCloneFilter)
+				}
 				args = append(args, origin, gs.cacheCommit)
 				if _, err := git.Run(ctx, args...); err != nil {
 					return nil, errors.Wrapf(err, "failed to fetch remote %s", urlutil.RedactCredentials(gs.src.Remote))
@@ -1197,6 +1273,8 @@ func (gs *gitSourceHandler) checkout(ctx context.Context, repo *gitRepo, g sessi
 	}
 
 	cd := checkoutDir
+	// checkoutCommit is the checked out commit in gitDir
+	checkoutCommit := refOrCommit
 	if gs.src.KeepGitDir && subdir == "." {
 		checkoutDirGit := filepath.Join(checkoutDir, ".git")
 		if err := os.MkdirAll(checkoutDir, 0711); err != nil {
@@ -1266,6 +1344,7 @@ func (gs *gitSourceHandler) checkout(ctx context.Context, repo *gitRepo, g sessi
 			return nil, errors.Wrapf(err, "failed to remove FETCH_HEAD for remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
 		gitDir = checkoutDirGit
+		checkoutCommit = "HEAD"
 	} else {
 		if subdir != "." {
 			cd, err = os.MkdirTemp(cd, "checkout")
@@ -1274,7 +1353,11 @@ func (gs *gitSourceHandler) checkout(ctx context.Context, repo *gitRepo, g sessi
 			}
 		}
 		checkoutGit := git.New(gitutil.WithWorkTree(cd), gitutil.WithGitDir(gitDir))
-		_, err = checkoutGit.Run(ctx, "checkout", "--no-overlay", refOrCommit, "--", ".")
+		if gs.sparse() {
+			err = sparseCheckout(ctx, checkoutGit, gitDir, refOrCommit, gs.src.checkoutPaths())
+		} else {
+			_, err = checkoutGit.Run(ctx, "checkout", "--no-overlay", refOrCommit, "--", ".")
+		}
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
@@ -1288,6 +1371,12 @@ func (gs *gitSourceHandler) checkout(ctx context.Context, repo *gitRepo, g sessi
 		}
 	}
 
+	if gs.src.LFS {
+		if err := gs.fetchLFSObjects(ctx, git, cd, checkoutCommit, g); err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch LFS objects for %s", urlutil.RedactCredentials(gs.src.Remote))
+		}
+	}
+
 	if subdir != "." {
 		subdir = filepath.FromSlash(subdir)
 		subdir = rootRelativePath(subdir)
@@ -1334,6 +1423,12 @@ func (gs *gitSourceHandler) checkout(ctx context.Context, repo *gitRepo, g sessi
 	}
 	defer checkoutRoot.Close()
 
+	if len(gs.src.SparsePaths) > 0 {
+		if err := pruneSparseCheckout(checkoutRoot, gs.src.SparsePaths); err != nil {
+			return nil, err
+		}
+	}
+
 	if compatibilityVersion == compat.CompatibilityVersion013 {
 		if err := resetCompatibility014FileModes(checkoutRoot); err != nil {
 			return nil, errors.Wrapf(err, "failed to normalize compatibility file modes for %s", urlutil.RedactCredentials(gs.src.Remote))
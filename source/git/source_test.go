@@ -3,6 +3,8 @@ package git
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +16,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -1945,7 +1948,7 @@ func testSubmoduleSubdir(t *testing.T, keepGitDir bool, format string) {
 
 	key1, pin1, _, done, err := g.CacheKey(ctx, nil, 0)
 	require.NoError(t, err)
-	require.True(t, done)
+	require.Equal(t, keepGitDir, done)
 
 	expLen := 44
 	expPinLen := 40
@@ -2336,7 +2339,8 @@ func testSubdir(t *testing.T, keepGitDir bool) {
 
 	key1, pin1, _, done, err := g.CacheKey(ctx, nil, 0)
 	require.NoError(t, err)
-	require.True(t, done)
+	// without the git dir the second cache key is based on the subdir tree
+	require.Equal(t, keepGitDir, done)
 
 	expLen := 44
 	if keepGitDir {
@@ -2370,6 +2374,240 @@ func testSubdir(t *testing.T, keepGitDir bool) {
 	require.Equal(t, "abc\n", string(dt))
 }
 
+func TestSparsePaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Depends on unimplemented containerd bind-mount support on Windows")
+	}
+
+	t.Parallel()
+
+	ctx := logProgressStreams(context.Background(), t)
+
+	gs := setupGitSource(t, t.TempDir())
+
+	repodir := t.TempDir()
+
+	runShell(t, repodir,
+		"git -c init.defaultBranch=master init",
+		"git config --local user.email test",
+		"git config --local user.name test",
+		"git config --local uploadpack.allowfilter true",
+		"echo foo > abc",
+		"mkdir -p a/b a/c d",
+		"echo b > a/b/foo",
+		"echo c > a/c/foo",
+		"echo a > a/foo",
+		"echo d > d/foo",
+		"git add abc a d",
+		"git commit -m initial",
+	)
+
+	repoURL := serveGitRepo(t, repodir)
+
+	cacheKey := func(id *GitIdentifier) string {
+		g, err := gs.Resolve(ctx, id, nil, nil)
+		require.NoError(t, err)
+		_, _, _, done, err := g.CacheKey(ctx, nil, 0)
+		require.NoError(t, err)
+		require.False(t, done)
+		key, _, _, done, err := g.CacheKey(ctx, nil, 1)
+		require.NoError(t, err)
+		require.True(t, done)
+		return key
+	}
+
+	readFiles := func(id *GitIdentifier) map[string]string {
+		return readSnapshotFiles(ctx, t, gs, id)
+	}
+
+	subdirID := &GitIdentifier{Remote: repoURL, Subdir: "a", SparsePaths: []string{"b"}}
+	require.Equal(t, map[string]string{"b/foo": "b\n"}, readFiles(subdirID))
+
+	rootID := &GitIdentifier{Remote: repoURL, SparsePaths: []string{"a/b", "d"}}
+	require.Equal(t, map[string]string{"a/b/foo": "b\n", "d/foo": "d\n"}, readFiles(rootID))
+
+	key1 := cacheKey(subdirID)
+
+	// commits outside of the sparse paths do not change the cache key
+	runShell(t, repodir,
+		"echo c2 > a/c/foo",
+		"echo a2 > a/foo",
+		"git add a",
+		"git commit -m unrelated",
+	)
+	key2 := cacheKey(subdirID)
+	require.Equal(t, key1, key2)
+
+	runShell(t, repodir,
+		"echo b2 > a/b/foo",
+		"git add a",
+		"git commit -m related",
+	)
+	key3 := cacheKey(subdirID)
+	require.NotEqual(t, key1, key3)
+	require.Equal(t, map[string]string{"b/foo": "b2\n"}, readFiles(subdirID))
+
+	g, err := gs.Resolve(ctx, &GitIdentifier{Remote: repoURL, SparsePaths: []string{"missing"}}, nil, nil)
+	require.NoError(t, err)
+	_, err = g.Snapshot(ctx, nil)
+	require.ErrorContains(t, err, "invalid sparse path")
+}
+
+func TestLFS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Depends on unimplemented containerd bind-mount support on Windows")
+	}
+
+	t.Parallel()
+
+	ctx := logProgressStreams(context.Background(), t)
+
+	gs := setupGitSource(t, t.TempDir())
+
+	objects := map[string]string{}
+	pointer := func(contents string) string {
+		oid := fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+		objects[oid] = contents
+		return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(contents))
+	}
+	fooPointer := pointer("foo lfs contents\n")
+	barPointer := pointer("bar lfs contents\n")
+	brokenPointer := "version https://git-lfs.github.com/spec/v1\noid sha256:" + strings.Repeat("0", 64) + "\nsize 3\n"
+
+	root := t.TempDir()
+	repodir := filepath.Join(root, "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repodir, "sub"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, ".gitattributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, "foo.bin"), []byte(fooPointer), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, "foo-copy.bin"), []byte(fooPointer), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, "pointer.txt"), []byte(fooPointer), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, "sub", "bar.bin"), []byte(barPointer), 0600))
+	runShell(t, repodir,
+		"git -c init.defaultBranch=master init",
+		"git config --local user.email test",
+		"git config --local user.name test",
+		"git config --local uploadpack.allowfilter true",
+		"git add -A",
+		"git commit -m initial",
+		"git checkout -b broken",
+	)
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, "broken.bin"), []byte(brokenPointer), 0600))
+	runShell(t, repodir,
+		"git add -A",
+		"git commit -m broken",
+		"git checkout master",
+	)
+
+	var mu sync.Mutex
+	var downloads []string
+	mux := http.NewServeMux()
+	var srvURL string
+	mux.HandleFunc("POST /repo.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		var req lfsBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := lfsBatchResponse{Transfer: "basic"}
+		for _, obj := range req.Objects {
+			if _, ok := objects[obj.Oid]; !ok {
+				obj.Error = &lfsError{Code: 404, Message: "object not found"}
+			} else {
+				obj.Actions = map[string]*lfsAction{
+					"download": {Href: srvURL + "/objects/" + obj.Oid, Header: map[string]string{"X-Object": obj.Oid}},
+				}
+			}
+			resp.Objects = append(resp.Objects, obj)
+		}
+		w.Header().Set("Content-Type", lfsMediaType)
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("GET /objects/{oid}", func(w http.ResponseWriter, r *http.Request) {
+		oid := r.PathValue("oid")
+		if r.Header.Get("X-Object") != oid {
+			http.Error(w, "missing header", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		downloads = append(downloads, objects[oid])
+		mu.Unlock()
+		w.Write([]byte(objects[oid]))
+	})
+	mux.Handle("/", gitHTTPHandler(t, root))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	srvURL = server.URL
+
+	id := &GitIdentifier{Remote: server.URL + "/repo", LFS: true}
+	g, err := gs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, _, _, done, err := g.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.False(t, done)
+	key, _, _, done, err := g.CacheKey(ctx, nil, 1)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Contains(t, key, "(lfs=")
+
+	require.Equal(t, map[string]string{
+		".gitattributes": "*.bin filter=lfs diff=lfs merge=lfs -text\n",
+		"foo.bin":        "foo lfs contents\n",
+		"foo-copy.bin":   "foo lfs contents\n",
+		"pointer.txt":    fooPointer,
+		"sub/bar.bin":    "bar lfs contents\n",
+	}, readSnapshotFiles(ctx, t, gs, id))
+	// objects referenced by multiple files are downloaded once
+	require.ElementsMatch(t, []string{"foo lfs contents\n", "bar lfs contents\n"}, downloads)
+
+	downloads = nil
+	require.Equal(t, map[string]string{
+		"bar.bin": "bar lfs contents\n",
+	}, readSnapshotFiles(ctx, t, gs, &GitIdentifier{Remote: server.URL + "/repo", Subdir: "sub", LFS: true}))
+	require.Equal(t, []string{"bar lfs contents\n"}, downloads)
+
+	g, err = gs.Resolve(ctx, &GitIdentifier{Remote: server.URL + "/repo", Ref: "broken", LFS: true}, nil, nil)
+	require.NoError(t, err)
+	_, err = g.Snapshot(ctx, nil)
+	require.ErrorContains(t, err, "object not found")
+}
+
+func readSnapshotFiles(ctx context.Context, t *testing.T, gs *Source, id *GitIdentifier) map[string]string {
+	t.Helper()
+	g, err := gs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	ref, err := g.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+
+	mount, err := ref.Mount(ctx, true, nil)
+	require.NoError(t, err)
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	require.NoError(t, err)
+	defer lm.Unmount()
+
+	files := map[string]string{}
+	err = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		dt, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(dt)
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
 func setupGitSource(t *testing.T, tmpdir string) *Source {
 	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
 	require.NoError(t, err)
@@ -2607,6 +2845,13 @@ func setupGitRepo(t *testing.T, format string) gitRepoFixture {
 }
 
 func serveGitRepo(t *testing.T, root string) string {
+	t.Helper()
+	server := httptest.NewServer(gitHTTPHandler(t, root))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func gitHTTPHandler(t *testing.T, root string) http.Handler {
 	t.Helper()
 	gitpath, err := exec.LookPath("git")
 	require.NoError(t, err)
@@ -2643,9 +2888,7 @@ func serveGitRepo(t *testing.T, root string) string {
 			}
 		}
 	})
-	server := httptest.NewServer(&githttp)
-	t.Cleanup(server.Close)
-	return server.URL
+	return githttp
 }
 
 func runShell(t *testing.T, dir string, cmds ...string) {
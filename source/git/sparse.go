@@ -0,0 +1,224 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/moby/buildkit/util/gitutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// partialCloneFilter is the object filter used when fetching into the shared
+// repository of a sparse checkout. Blobs are only fetched from the remote when
+// they are checked out.
+const partialCloneFilter = "blob:none"
+
+// parseSparsePaths parses the value of the git.sparsepaths attribute, a JSON
+// array of directories relative to the subdir.
+func parseSparsePaths(v string) ([]string, error) {
+	var paths []string
+	if err := json.Unmarshal([]byte(v), &paths); err != nil {
+		return nil, errors.Wrap(err, "failed to parse git.sparsepaths")
+	}
+	return normalizeSparsePaths(paths)
+}
+
+// normalizeSparsePaths cleans and sorts paths and removes the paths contained
+// in another path of the list.
+func normalizeSparsePaths(paths []string) ([]string, error) {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if strings.ContainsAny(p, "\n\r") {
+			return nil, errors.Errorf("invalid sparse path %q", p)
+		}
+		p = filepath.ToSlash(p)
+		cp := path.Clean("/" + p)
+		if cp == "/" || slices.Contains(strings.Split(p, "/"), "..") {
+			return nil, errors.Errorf("invalid sparse path %q", p)
+		}
+		out = append(out, cp[1:])
+	}
+	slices.Sort(out)
+	out = slices.Compact(out)
+	return slices.DeleteFunc(out, func(p string) bool {
+		return slices.ContainsFunc(out, func(parent string) bool {
+			return strings.HasPrefix(p, parent+"/")
+		})
+	}), nil
+}
+
+func validateSparseAttrs(id *GitIdentifier) error {
+	if len(id.SparsePaths) == 0 {
+		return nil
+	}
+	if id.KeepGitDir {
+		return errors.Errorf("git.sparsepaths is incompatible with git.keepgitdir")
+	}
+	if id.CheckoutBundle {
+		return errors.Errorf("git.sparsepaths is incompatible with git.checkoutbundle")
+	}
+	return nil
+}
+
+// checkoutPaths returns the directories of the repository that are checked
+// out, or nil if the checkout contains the whole tree.
+func (id *GitIdentifier) checkoutPaths() []string {
+	subdir := path.Join("/", id.Subdir)[1:]
+	if len(id.SparsePaths) == 0 {
+		if subdir == "" {
+			return nil
+		}
+		return []string{subdir}
+	}
+	paths := make([]string, 0, len(id.SparsePaths))
+	for _, p := range id.SparsePaths {
+		paths = append(paths, path.Join(subdir, p))
+	}
+	return paths
+}
+
+// sparse returns true if only the directories returned by checkoutPaths are
+// checked out of the repository.
+func (gs *gitSourceHandler) sparse() bool {
+	// the git directory is kept only when checking out the whole tree
+	if gs.src.CheckoutBundle || gs.src.KeepGitDir && path.Join("/", gs.src.Subdir) == "/" {
+		return false
+	}
+	return len(gs.src.checkoutPaths()) > 0
+}
+
+// partialClone returns true if the shared repository is a partial clone
+// without the blobs outside of the checked out directories.
+func (gs *gitSourceHandler) partialClone() bool {
+	return gs.sparse() && gs.src.Bundle == ""
+}
+
+// treeCacheKey returns true if the contents of the checkout only depend on
+// the trees of the checked out directories and not on the commit.
+func (gs *gitSourceHandler) treeCacheKey() bool {
+	return gs.partialClone() && !gs.src.KeepGitDir && gs.src.MTime != "commit"
+}
+
+// resolveTreeKey returns a checksum of the trees of the directories in paths
+// at commit. It returns an empty string if a directory is not
+// found in the repository, e.g. because it is inside a submodule.
+func resolveTreeKey(ctx context.Context, git *gitutil.GitCLI, commit string, paths []string) (string, error) {
+	args := append([]string{"ls-tree", "-d", "-z", "--full-tree", commit, "--"}, paths...)
+	dt, err := git.Run(ctx, args...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list trees of %s", commit)
+	}
+	var n int
+	for entry := range bytes.SplitSeq(bytes.TrimSuffix(dt, []byte{0}), []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+		// submodules are listed as commit entries
+		if !bytes.HasPrefix(entry, []byte("040000 tree ")) && !bytes.HasPrefix(entry, []byte("160000 commit ")) {
+			return "", nil
+		}
+		n++
+	}
+	if n != len(paths) {
+		return "", nil
+	}
+	return "tree:" + digest.FromBytes(dt).Encoded(), nil
+}
+
+// sparseCheckoutPatterns returns the cone mode sparse-checkout patterns for
+// the directories in paths.
+func sparseCheckoutPatterns(paths []string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+	lines := map[string][]string{}
+	for _, p := range paths {
+		lines[p] = []string{"/" + escape.Replace(p) + "/"}
+		for parent := path.Dir(p); parent != "."; parent = path.Dir(parent) {
+			lines[parent] = []string{"/" + escape.Replace(parent) + "/", "!/" + escape.Replace(parent) + "/*/"}
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("/*\n!/*/\n")
+	for _, p := range slices.Sorted(maps.Keys(lines)) {
+		for _, l := range lines[p] {
+			sb.WriteString(l + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// sparseCheckout checks out the directories in paths of ref with cone mode
+// sparse-checkout patterns. In a partial clone, only the blobs of the checked
+// out files are fetched from the remote.
+func sparseCheckout(ctx context.Context, git *gitutil.GitCLI, gitDir string, ref string, paths []string) error {
+	gitDirRoot, err := os.OpenRoot(gitDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to open git dir root")
+	}
+	defer gitDirRoot.Close()
+
+	if err := gitDirRoot.MkdirAll("info", 0755); err != nil {
+		return err
+	}
+	if err := gitDirRoot.WriteFile(filepath.Join("info", "sparse-checkout"), []byte(sparseCheckoutPatterns(paths)), 0644); err != nil {
+		return err
+	}
+	defer gitDirRoot.Remove(filepath.Join("info", "sparse-checkout"))
+
+	_, err = git.Run(ctx, "-c", "core.sparseCheckout=true", "-c", "core.sparseCheckoutCone=true", "read-tree", "--reset", "-u", ref)
+	return err
+}
+
+// pruneSparseCheckout removes everything outside of the directories in paths
+// from the checkout in root. Cone mode also checks out the files in the
+// parent directories of the selected directories and the submodules are
+// updated regardless of the sparse-checkout patterns.
+func pruneSparseCheckout(root *os.Root, paths []string) error {
+	if err := pruneDir(root, ".", paths); err != nil {
+		return err
+	}
+	for _, p := range paths {
+		p = filepath.FromSlash(p)
+		if err := validateDirsOnly(root, p); err != nil {
+			return errors.Wrapf(err, "invalid sparse path %v", p)
+		}
+	}
+	return nil
+}
+
+func pruneDir(root *os.Root, dir string, paths []string) error {
+	f, err := root.Open(dir)
+	if err != nil {
+		return err
+	}
+	names, err := f.Readdirnames(0)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		p := path.Join(filepath.ToSlash(dir), n)
+		if slices.Contains(paths, p) {
+			continue
+		}
+		if slices.ContainsFunc(paths, func(sp string) bool { return strings.HasPrefix(sp, p+"/") }) {
+			if fi, err := root.Lstat(filepath.FromSlash(p)); err == nil && fi.IsDir() {
+				if err := pruneDir(root, filepath.FromSlash(p), paths); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := root.RemoveAll(filepath.FromSlash(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSparsePaths(t *testing.T) {
+	paths, err := normalizeSparsePaths([]string{"/foo/bar/", "foo/bar/baz", "abc", "foo/bar", "./y"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"abc", "foo/bar", "y"}, paths)
+
+	for _, p := range []string{"", "/", ".", "../foo", "foo/../../bar", "foo\nbar"} {
+		_, err := normalizeSparsePaths([]string{p})
+		require.ErrorContains(t, err, "invalid sparse path", p)
+	}
+
+	paths, err = parseSparsePaths(`["b","a"]`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, paths)
+
+	_, err = parseSparsePaths(`"a"`)
+	require.ErrorContains(t, err, "failed to parse git.sparsepaths")
+}
+
+func TestCheckoutPaths(t *testing.T) {
+	require.Nil(t, (&GitIdentifier{}).checkoutPaths())
+	require.Nil(t, (&GitIdentifier{Subdir: "/"}).checkoutPaths())
+	require.Equal(t, []string{"sub"}, (&GitIdentifier{Subdir: "/sub/"}).checkoutPaths())
+	require.Equal(t, []string{"a", "b/c"}, (&GitIdentifier{SparsePaths: []string{"a", "b/c"}}).checkoutPaths())
+	require.Equal(t, []string{"sub/a", "sub/b/c"}, (&GitIdentifier{Subdir: "sub", SparsePaths: []string{"a", "b/c"}}).checkoutPaths())
+}
+
+func TestSparseCheckoutPatterns(t *testing.T) {
+	require.Equal(t, "/*\n!/*/\n/a/\n", sparseCheckoutPatterns([]string{"a"}))
+	require.Equal(t, "/*\n!/*/\n"+
+		"/a/\n!/a/*/\n"+
+		"/a/b/\n!/a/b/*/\n"+
+		"/a/b/c/\n"+
+		"/a/d/\n"+
+		"/e\\*/\n",
+		sparseCheckoutPatterns([]string{"a/b/c", "a/d", "e*"}))
+}
@@ -0,0 +1,533 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/urlutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxDownloadAttempts is the number of attempts to download from a single
+	// URL before falling back to the next mirror.
+	maxDownloadAttempts = 5
+	// defaultRetryBackoff is the delay before the first retry of a download.
+	// The delay is doubled for every following attempt.
+	defaultRetryBackoff = time.Second
+	// partialFileName is the name of the file the content is downloaded to
+	// before it is renamed to the final filename.
+	partialFileName = ".download"
+)
+
+// parseMirrors parses the value of the http.mirrors attribute, a JSON array
+// of URLs.
+func parseMirrors(v string) ([]string, error) {
+	var mirrors []string
+	if err := json.Unmarshal([]byte(v), &mirrors); err != nil {
+		return nil, errors.Wrap(err, "failed to parse http.mirrors")
+	}
+	for _, m := range mirrors {
+		u, err := url.Parse(m)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.Errorf("invalid mirror URL %q", urlutil.RedactCredentials(m))
+		}
+	}
+	return mirrors, nil
+}
+
+// transientError is an error of a download attempt that is retried.
+type transientError struct {
+	error
+}
+
+func (e transientError) Unwrap() error {
+	return e.error
+}
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("invalid response status %d", e.code)
+}
+
+func checkResponseStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+		return nil
+	}
+	err := &statusError{code: resp.StatusCode}
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return transientError{err}
+	default:
+		return err
+	}
+}
+
+// bodyReader marks the errors of reading a response body as transient.
+type bodyReader struct {
+	io.Reader
+}
+
+func (r bodyReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = transientError{err}
+	}
+	return n, err
+}
+
+// download fetches the source into a new cache ref. If the download from a URL
+// fails, the mirrors are tried in order. resp is an optional response of an
+// earlier request to the source URL. The returned response is the response the
+// content was read from.
+func (hs *httpSourceHandler) download(ctx context.Context, g session.Group, resp *http.Response) (_ cache.ImmutableRef, _ digest.Digest, _ *http.Response, retErr error) {
+	d, err := hs.newPartialDownload(ctx, g)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer func() {
+		if err := d.release(ctx); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+
+	var errs []error
+	for i, u := range append([]string{hs.src.URL}, hs.src.Mirrors...) {
+		var r *http.Response
+		if i == 0 {
+			r, err = d.fetch(ctx, g, u, resp)
+		} else {
+			bklog.G(ctx).Warnf("falling back to mirror %s for %s", urlutil.RedactCredentials(u), urlutil.RedactCredentials(hs.src.URL))
+			done := progress.OneOff(ctx, "falling back to mirror "+urlutil.RedactCredentials(u))
+			r, err = d.fetch(ctx, g, u, nil)
+			done(err)
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to download %s", urlutil.RedactCredentials(u)))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		ref, err := d.commit(ctx, r)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return ref, d.digest(), r, nil
+	}
+	return nil, "", nil, stderrors.Join(errs...)
+}
+
+// partialDownload is a download into a mutable cache ref. The content of an
+// interrupted download is kept in the cache so that a later download of the
+// same source can resume from it with a range request.
+type partialDownload struct {
+	hs    *httpSourceHandler
+	key   string
+	ref   cache.MutableRef
+	mount snapshot.Mountable
+	lm    snapshot.Mounter
+	root  *os.Root
+	f     *os.File
+	h     hash.Hash
+	size  int64
+	// resumed is true if the content was not downloaded from the start by the
+	// last request.
+	resumed bool
+	// url and validator are the URL the content was downloaded from and its
+	// strong ETag or Last-Modified value for If-Range requests.
+	url       string
+	validator string
+}
+
+// partialKey returns the key the content of an interrupted download is
+// stored by.
+func (hs *httpSourceHandler) partialKey() (string, error) {
+	uh, err := hs.urlHash()
+	if err != nil {
+		return "", err
+	}
+	return digest.FromString(uh.String() + "#" + hs.src.Checksum.String()).String(), nil
+}
+
+func (hs *httpSourceHandler) newPartialDownload(ctx context.Context, g session.Group) (_ *partialDownload, retErr error) {
+	key, err := hs.partialKey()
+	if err != nil {
+		return nil, err
+	}
+	sis, err := searchHTTPPartial(ctx, hs.cache, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to search metadata for %s", key)
+	}
+
+	d := &partialDownload{hs: hs, key: key, h: sha256.New()}
+	defer func() {
+		if retErr != nil {
+			d.release(context.WithoutCancel(ctx))
+		}
+	}()
+	for _, si := range sis {
+		ref, err := hs.cache.GetMutable(ctx, si.ID())
+		if err != nil {
+			if errors.Is(err, cache.ErrLocked) {
+				// used by a concurrent download of the same source
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get mutable ref for %s", urlutil.RedactCredentials(hs.src.URL))
+		}
+		d.ref = ref
+		d.url = si.getHTTPPartialURL()
+		d.validator = si.getHTTPPartialValidator()
+		break
+	}
+	if d.ref == nil {
+		d.ref, err = hs.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("http url %s", hs.src.URL)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.mount, err = d.ref.Mount(ctx, false, g)
+	if err != nil {
+		return nil, err
+	}
+	lm := snapshot.LocalMounter(d.mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	d.lm = lm
+	d.root, err = os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := 0600
+	if hs.src.Perm != 0 {
+		perm = hs.src.Perm
+	}
+	d.f, err = d.root.OpenFile(partialFileName, os.O_RDWR|os.O_CREATE, os.FileMode(perm))
+	if err != nil {
+		return nil, err
+	}
+	// the digest covers the content downloaded by an earlier build
+	d.size, err = io.Copy(d.h, d.f)
+	if err != nil {
+		return nil, err
+	}
+	if d.size > 0 {
+		bklog.G(ctx).Debugf("resuming download of %s from %d bytes", urlutil.RedactCredentials(hs.src.URL), d.size)
+	}
+	return d, nil
+}
+
+func (d *partialDownload) Write(p []byte) (int, error) {
+	n, err := d.f.Write(p)
+	d.h.Write(p[:n])
+	d.size += int64(n)
+	return n, err
+}
+
+func (d *partialDownload) digest() digest.Digest {
+	return digest.NewDigest(digest.SHA256, d.h)
+}
+
+func (d *partialDownload) reset() error {
+	if _, err := d.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := d.f.Truncate(0); err != nil {
+		return err
+	}
+	d.h.Reset()
+	d.size = 0
+	d.resumed = false
+	return nil
+}
+
+// resumable returns true if the download can be resumed from u with a range
+// request. Without a pinned checksum, the content must be validated with
+// If-Range.
+func (d *partialDownload) resumable(u string) bool {
+	return d.size > 0 && (d.hs.src.Checksum != "" || d.url == u && d.validator != "")
+}
+
+// fetch downloads u into the partial file. Interrupted transfers are resumed
+// with range requests and failed attempts are retried with an exponential
+// backoff. resp is an optional response of an earlier request to u.
+func (d *partialDownload) fetch(ctx context.Context, g session.Group, u string, resp *http.Response) (*http.Response, error) {
+	client := d.hs.client(g)
+	if resp != nil && d.resumable(u) {
+		// request the rest of the content instead
+		resp.Body.Close()
+		resp = nil
+	}
+	for attempt := 1; ; attempt++ {
+		var err error
+		if resp == nil {
+			resp, err = d.request(ctx, g, client, u)
+		}
+		if err == nil {
+			err = d.copy(u, resp)
+			resp.Body.Close()
+		}
+		if err == nil {
+			return resp, nil
+		}
+		var te transientError
+		if !errors.As(err, &te) || attempt == maxDownloadAttempts || ctx.Err() != nil {
+			return nil, err
+		}
+		if err := d.hs.retryWait(ctx, u, attempt, err); err != nil {
+			return nil, err
+		}
+		resp = nil
+	}
+}
+
+func (d *partialDownload) request(ctx context.Context, g session.Group, client *http.Client, u string) (*http.Response, error) {
+	req, err := d.hs.newHTTPRequest(ctx, g, u)
+	if err != nil {
+		return nil, err
+	}
+	if d.resumable(u) {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.size))
+		if d.url == u && d.validator != "" {
+			req.Header.Set("If-Range", d.validator)
+		}
+	} else if d.size > 0 {
+		if err := d.reset(); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, transientError{err}
+	}
+	return resp, nil
+}
+
+// copy appends the body of resp to the partial file.
+func (d *partialDownload) copy(u string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, err := contentRangeStart(resp.Header.Get("Content-Range"))
+		if err != nil || start != d.size {
+			if err := d.reset(); err != nil {
+				return err
+			}
+			return transientError{errors.Errorf("invalid content range %q for resumed download from %d bytes", resp.Header.Get("Content-Range"), d.size)}
+		}
+		d.resumed = true
+	case http.StatusRequestedRangeNotSatisfiable:
+		if err := d.reset(); err != nil {
+			return err
+		}
+		return transientError{errors.Errorf("range not satisfiable for resumed download from %d bytes", d.size)}
+	default:
+		if err := checkResponseStatus(resp); err != nil {
+			return err
+		}
+		if d.size > 0 {
+			if err := d.reset(); err != nil {
+				return err
+			}
+		}
+	}
+	if d.url != u {
+		d.validator = ""
+	}
+	d.url = u
+	if v := rangeValidator(resp); v != "" {
+		d.validator = v
+	}
+
+	if _, err := io.Copy(d, bodyReader{resp.Body}); err != nil {
+		return err
+	}
+
+	if d.hs.src.Checksum != "" && d.digest() != d.hs.src.Checksum {
+		err := errors.Errorf("digest mismatch for %s: %s (expected: %s)", urlutil.RedactCredentials(u), d.digest(), d.hs.src.Checksum)
+		if !d.resumed {
+			return err
+		}
+		// the content downloaded before may not match, retry from the start
+		if err := d.reset(); err != nil {
+			return err
+		}
+		return transientError{err}
+	}
+	return nil
+}
+
+// commit finalizes the downloaded file and commits the cache ref.
+func (d *partialDownload) commit(ctx context.Context, resp *http.Response) (cache.ImmutableRef, error) {
+	hs := d.hs
+	if err := d.f.Close(); err != nil {
+		return nil, err
+	}
+	d.f = nil
+
+	name := getFileName(hs.src.URL, hs.src.Filename, resp)
+	if err := d.root.Rename(partialFileName, name); err != nil {
+		return nil, err
+	}
+
+	uid := hs.src.UID
+	gid := hs.src.GID
+	if idmap := d.mount.IdentityMapping(); idmap != nil {
+		var err error
+		uid, gid, err = idmap.ToHost(uid, gid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if gid != 0 || uid != 0 {
+		if err := d.root.Chown(name, uid, gid); err != nil {
+			return nil, err
+		}
+	}
+
+	mTime := time.Unix(0, 0)
+	lastMod := resp.Header.Get("Last-Modified")
+	if lastMod != "" {
+		if parsedMTime, err := http.ParseTime(lastMod); err == nil {
+			mTime = parsedMTime
+		}
+	}
+
+	if err := d.root.Chtimes(name, mTime, mTime); err != nil {
+		return nil, err
+	}
+
+	d.root.Close()
+	d.root = nil
+	if err := d.lm.Unmount(); err != nil {
+		return nil, err
+	}
+	d.lm = nil
+
+	if err := (cacheRefMetadata{d.ref}).clearHTTPPartial(); err != nil {
+		return nil, err
+	}
+	ref, err := d.ref.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.ref = nil
+	md := cacheRefMetadata{ref}
+
+	// the ETag of a mirror is not valid for the source URL
+	if respETag := resp.Header.Get("ETag"); respETag != "" && d.url == hs.src.URL {
+		respETag = etagValue(respETag)
+		if err := md.setETag(respETag); err != nil {
+			return nil, err
+		}
+		uh, err := hs.urlHash()
+		if err != nil {
+			return nil, err
+		}
+		if err := md.setHTTPChecksum(uh, d.digest()); err != nil {
+			return nil, err
+		}
+	}
+
+	if modTime := resp.Header.Get("Last-Modified"); modTime != "" {
+		if err := md.setHTTPModTime(modTime); err != nil {
+			return nil, err
+		}
+	}
+
+	return ref, nil
+}
+
+// release releases the cache ref of an uncommitted download. The content is
+// kept in the cache if the download can be resumed.
+func (d *partialDownload) release(ctx context.Context) error {
+	if d.f != nil {
+		d.f.Close()
+		d.f = nil
+	}
+	if d.root != nil {
+		d.root.Close()
+		d.root = nil
+	}
+	if d.lm != nil {
+		d.lm.Unmount()
+		d.lm = nil
+	}
+	if d.ref == nil {
+		return nil
+	}
+	md := cacheRefMetadata{d.ref}
+	if d.size > 0 && (d.hs.src.Checksum != "" || d.validator != "") {
+		if err := md.setHTTPPartial(d.key, d.url, d.validator); err != nil {
+			bklog.G(ctx).WithError(err).Warnf("failed to save partial download of %s", urlutil.RedactCredentials(d.hs.src.URL))
+		}
+	} else if err := md.clearHTTPPartial(); err != nil {
+		bklog.G(ctx).WithError(err).Warnf("failed to clear partial download metadata of %s", urlutil.RedactCredentials(d.hs.src.URL))
+	}
+	err := d.ref.Release(context.WithoutCancel(ctx))
+	d.ref = nil
+	return err
+}
+
+// retryWait waits before the next attempt to download u and reports the wait
+// as a progress status.
+func (hs *httpSourceHandler) retryWait(ctx context.Context, u string, attempt int, err error) error {
+	backoff := hs.retryBackoff << (attempt - 1)
+	bklog.G(ctx).WithError(err).Warnf("failed to download %s, retrying in %s", urlutil.RedactCredentials(u), backoff)
+	done := progress.OneOff(ctx, fmt.Sprintf("retrying %s in %s (attempt %d/%d): %v", urlutil.RedactCredentials(u), backoff, attempt+1, maxDownloadAttempts, err))
+	t := time.NewTimer(backoff)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return done(context.Cause(ctx))
+	case <-t.C:
+		return done(nil)
+	}
+}
+
+// contentRangeStart returns the first byte position of a Content-Range header
+// value.
+func contentRangeStart(v string) (int64, error) {
+	v, ok := strings.CutPrefix(v, "bytes ")
+	if !ok {
+		return 0, errors.Errorf("invalid content range %q", v)
+	}
+	start, _, ok := strings.Cut(v, "-")
+	if !ok {
+		return 0, errors.Errorf("invalid content range %q", v)
+	}
+	return strconv.ParseInt(start, 10, 64)
+}
+
+// rangeValidator returns the value of the If-Range header for requesting the
+// rest of the content of resp.
+func rangeValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
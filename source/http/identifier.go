@@ -27,7 +27,10 @@ type HTTPIdentifier struct {
 	GID              int
 	AuthHeaderSecret string
 	Header           []HeaderField
-	VerifySignature  *HTTPSignatureVerifyOptions
+	// Mirrors are the URLs the content is downloaded from if the download
+	// from URL fails.
+	Mirrors         []string
+	VerifySignature *HTTPSignatureVerifyOptions
 }
 
 type HTTPSignatureVerifyOptions struct {
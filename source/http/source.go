@@ -4,9 +4,7 @@ import (
 	"bytes"
 	"cmp"
 	"context"
-	"crypto/sha256"
 	"encoding/json"
-	"fmt"
 	"io"
 	"mime"
 	"net/http"
@@ -32,6 +30,7 @@ import (
 	"github.com/moby/buildkit/util/cachedigest"
 	"github.com/moby/buildkit/util/pgpsign"
 	"github.com/moby/buildkit/util/tracing"
+	"github.com/moby/buildkit/util/urlutil"
 	"github.com/moby/buildkit/version"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
@@ -55,8 +54,9 @@ type Opt struct {
 }
 
 type Source struct {
-	cache     cache.Accessor
-	transport http.RoundTripper
+	cache        cache.Accessor
+	transport    http.RoundTripper
+	retryBackoff time.Duration
 }
 
 var _ source.Source = &Source{}
@@ -67,8 +67,9 @@ func NewSource(opt Opt) (*Source, error) {
 		transport = tracing.DefaultTransport
 	}
 	hs := &Source{
-		cache:     opt.CacheAccessor,
-		transport: transport,
+		cache:        opt.CacheAccessor,
+		transport:    transport,
+		retryBackoff: defaultRetryBackoff,
 	}
 	return hs, nil
 }
@@ -113,6 +114,12 @@ func (hs *Source) Identifier(scheme, ref string, attrs map[string]string, platfo
 			id.GID = int(i)
 		case pb.AttrHTTPAuthHeaderSecret:
 			id.AuthHeaderSecret = v
+		case pb.AttrHTTPMirrors:
+			mirrors, err := parseMirrors(v)
+			if err != nil {
+				return nil, err
+			}
+			id.Mirrors = mirrors
 		case pb.AttrHTTPSignatureVerifyPubKey:
 			if id.VerifySignature == nil {
 				id.VerifySignature = &HTTPSignatureVerifyOptions{}
@@ -143,6 +150,11 @@ func (hs *Source) Identifier(scheme, ref string, attrs map[string]string, platfo
 		return nil, err
 	}
 
+	// the content from the mirrors is only trusted if it is pinned
+	if len(id.Mirrors) > 0 && id.Checksum == "" {
+		return nil, errors.Errorf("http.mirrors requires http.checksum")
+	}
+
 	return id, nil
 }
 
@@ -358,7 +370,7 @@ func (hs *httpSourceHandler) resolveMetadataRef(ctx context.Context, jobCtx solv
 		return nil, errors.Wrapf(err, "failed to search metadata for %s", uh)
 	}
 
-	req, err := hs.newHTTPRequest(ctx, g)
+	req, err := hs.newHTTPRequest(ctx, g, hs.src.URL)
 	if err != nil {
 		return nil, err
 	}
@@ -455,13 +467,13 @@ func (hs *httpSourceHandler) resolveMetadataRef(ctx context.Context, jobCtx solv
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		return nil, errors.Errorf("invalid response status %d", resp.StatusCode)
+		// the request is retried by download
+		bklog.G(ctx).WithError(err).Debugf("failed to request %s", urlutil.RedactCredentials(hs.src.URL))
+		resp = nil
+	} else {
+		defer resp.Body.Close()
 	}
-	if resp.StatusCode == http.StatusNotModified {
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
 		respETag := etagValue(resp.Header.Get("ETag"))
 		if respETag == "" && onlyETag != "" {
 			respETag = onlyETag
@@ -500,7 +512,7 @@ func (hs *httpSourceHandler) resolveMetadataRef(ctx context.Context, jobCtx solv
 		return m, nil
 	}
 
-	ref, dgst, err := hs.save(ctx, resp, g)
+	ref, dgst, resp, err := hs.download(ctx, g, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -722,134 +734,6 @@ func checksumAlgo(algo MetadataChecksumAlgo) (digest.Algorithm, error) {
 	}
 }
 
-func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s session.Group) (ref cache.ImmutableRef, dgst digest.Digest, retErr error) {
-	newRef, err := hs.cache.New(ctx, nil, s, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("http url %s", hs.src.URL)))
-	if err != nil {
-		return nil, "", err
-	}
-
-	releaseRef := func() {
-		newRef.Release(context.TODO())
-	}
-
-	defer func() {
-		if retErr != nil && newRef != nil {
-			releaseRef()
-		}
-	}()
-
-	mount, err := newRef.Mount(ctx, false, s)
-	if err != nil {
-		return nil, "", err
-	}
-
-	lm := snapshot.LocalMounter(mount)
-	dir, err := lm.Mount()
-	if err != nil {
-		return nil, "", err
-	}
-
-	defer func() {
-		if retErr != nil && lm != nil {
-			lm.Unmount()
-		}
-	}()
-	perm := 0600
-	if hs.src.Perm != 0 {
-		perm = hs.src.Perm
-	}
-
-	name := getFileName(hs.src.URL, hs.src.Filename, resp)
-
-	root, err := os.OpenRoot(dir)
-	if err != nil {
-		return nil, "", err
-	}
-	defer root.Close()
-
-	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(perm))
-	if err != nil {
-		return nil, "", err
-	}
-	defer func() {
-		if f != nil {
-			f.Close()
-		}
-	}()
-
-	h := sha256.New()
-
-	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
-		return nil, "", err
-	}
-
-	if err := f.Close(); err != nil {
-		return nil, "", err
-	}
-	f = nil
-
-	uid := hs.src.UID
-	gid := hs.src.GID
-	if idmap := mount.IdentityMapping(); idmap != nil {
-		uid, gid, err = idmap.ToHost(uid, gid)
-		if err != nil {
-			return nil, "", err
-		}
-	}
-
-	if gid != 0 || uid != 0 {
-		if err := root.Chown(name, uid, gid); err != nil {
-			return nil, "", err
-		}
-	}
-
-	mTime := time.Unix(0, 0)
-	lastMod := resp.Header.Get("Last-Modified")
-	if lastMod != "" {
-		if parsedMTime, err := http.ParseTime(lastMod); err == nil {
-			mTime = parsedMTime
-		}
-	}
-
-	if err := root.Chtimes(name, mTime, mTime); err != nil {
-		return nil, "", err
-	}
-
-	lm.Unmount()
-	lm = nil
-
-	ref, err = newRef.Commit(ctx)
-	if err != nil {
-		return nil, "", err
-	}
-	newRef = nil
-	md := cacheRefMetadata{ref}
-
-	dgst = digest.NewDigest(digest.SHA256, h)
-
-	if respETag := resp.Header.Get("ETag"); respETag != "" {
-		respETag = etagValue(respETag)
-		if err := md.setETag(respETag); err != nil {
-			return nil, "", err
-		}
-		uh, err := hs.urlHash()
-		if err != nil {
-			return nil, "", err
-		}
-		if err := md.setHTTPChecksum(uh, dgst); err != nil {
-			return nil, "", err
-		}
-	}
-
-	if modTime := resp.Header.Get("Last-Modified"); modTime != "" {
-		if err := md.setHTTPModTime(modTime); err != nil {
-			return nil, "", err
-		}
-	}
-
-	return ref, dgst, nil
-}
-
 func (hs *httpSourceHandler) Snapshot(ctx context.Context, jobCtx solver.JobContext) (cache.ImmutableRef, error) {
 	refID := ""
 	if hs.resolved != nil && hs.resolved.refID != "" {
@@ -881,22 +765,7 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, jobCtx solver.JobCont
 		g = jobCtx.Session()
 	}
 
-	req, err := hs.newHTTPRequest(ctx, g)
-	if err != nil {
-		return nil, err
-	}
-
-	client := hs.client(g)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	ref, dgst, err := hs.save(ctx, resp, g)
+	ref, dgst, _, err := hs.download(ctx, g, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -935,8 +804,8 @@ func (hs *httpSourceHandler) snapshotRefIDFromResolverCache(rc solver.ResolverCa
 	return refID, nil
 }
 
-func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hs.src.URL, nil)
+func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group, u string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -951,11 +820,17 @@ func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group
 		token bool
 	}
 
+	// the explicit auth secret is not sent to the mirrors
+	authHeaderSecret := hs.src.AuthHeaderSecret
+	if u != hs.src.URL {
+		authHeaderSecret = ""
+	}
+
 	var secretNames []authSecret
-	if hs.src.AuthHeaderSecret != "" {
-		secretNames = append(secretNames, authSecret{name: hs.src.AuthHeaderSecret})
+	if authHeaderSecret != "" {
+		secretNames = append(secretNames, authSecret{name: authHeaderSecret})
 	} else {
-		u, err := url.Parse(hs.src.URL)
+		u, err := url.Parse(u)
 		if err == nil {
 			secretNames = append(secretNames, authSecret{name: HTTPAuthHeaderSecretPrefix + u.Hostname()})
 			secretNames = append(secretNames, authSecret{name: HTTPAuthTokenSecretPrefix + u.Hostname(), token: true})
@@ -975,8 +850,8 @@ func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group
 			req.Header.Set("Authorization", v)
 			return nil
 		})
-		if err != nil && hs.src.AuthHeaderSecret != "" {
-			return nil, errors.Wrapf(err, "failed to retrieve HTTP auth secret %s", hs.src.AuthHeaderSecret)
+		if err != nil && authHeaderSecret != "" {
+			return nil, errors.Wrapf(err, "failed to retrieve HTTP auth secret %s", authHeaderSecret)
 		}
 	}
 
@@ -1019,14 +894,30 @@ func searchHTTPURLDigest(ctx context.Context, store cache.MetadataStore, dgst di
 	return results, nil
 }
 
+func searchHTTPPartial(ctx context.Context, store cache.MetadataStore, key string) ([]cacheRefMetadata, error) {
+	var results []cacheRefMetadata
+	mds, err := store.Search(ctx, httpPartialIndex+key, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, md := range mds {
+		results = append(results, cacheRefMetadata{md})
+	}
+	return results, nil
+}
+
 type cacheRefMetadata struct {
 	cache.RefMetadata
 }
 
 const (
-	keyHTTPChecksum = "http.checksum"
-	keyETag         = "etag"
-	keyModTime      = "http.modtime"
+	keyHTTPChecksum         = "http.checksum"
+	keyETag                 = "etag"
+	keyModTime              = "http.modtime"
+	keyHTTPPartial          = "http.partial"
+	httpPartialIndex        = keyHTTPPartial + "::"
+	keyHTTPPartialURL       = "http.partial.url"
+	keyHTTPPartialValidator = "http.partial.validator"
 )
 
 func (md cacheRefMetadata) getHTTPChecksum() digest.Digest {
@@ -1053,6 +944,28 @@ func (md cacheRefMetadata) setHTTPModTime(s string) error {
 	return md.SetString(keyModTime, s, "")
 }
 
+func (md cacheRefMetadata) getHTTPPartialURL() string {
+	return md.GetString(keyHTTPPartialURL)
+}
+
+func (md cacheRefMetadata) getHTTPPartialValidator() string {
+	return md.GetString(keyHTTPPartialValidator)
+}
+
+func (md cacheRefMetadata) setHTTPPartial(key, url, validator string) error {
+	if err := md.SetString(keyHTTPPartialURL, url, ""); err != nil {
+		return err
+	}
+	if err := md.SetString(keyHTTPPartialValidator, validator, ""); err != nil {
+		return err
+	}
+	return md.SetString(keyHTTPPartial, key, httpPartialIndex+key)
+}
+
+func (md cacheRefMetadata) clearHTTPPartial() error {
+	return md.ClearValueAndIndex(keyHTTPPartial, httpPartialIndex)
+}
+
 func etagValue(v string) string {
 	// remove weak for direct comparison
 	return strings.TrimPrefix(v, "W/")
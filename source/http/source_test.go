@@ -1,10 +1,18 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/diff/apply"
 	ctdmetadata "github.com/containerd/containerd/v2/core/metadata"
@@ -20,6 +28,7 @@ import (
 	"github.com/moby/buildkit/snapshot"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
 	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/source"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/testutil/httpserver"
@@ -478,6 +487,219 @@ func TestPruneAfterCacheKey(t *testing.T) {
 	require.Len(t, du, 0)
 }
 
+func TestHTTPResume(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+	hs.(*Source).retryBackoff = time.Millisecond
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	server := newFlakyServer(content)
+	defer server.Close()
+
+	// drops the connection twice after sending 100k of the content
+	server.drop(2, 100*1024)
+
+	id := &HTTPIdentifier{URL: server.URL + "/foo"}
+
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromBytes(content).String(), p)
+	require.Equal(t, []string{"", "bytes=102400-", "bytes=204800-"}, server.ranges())
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, content, dt)
+}
+
+func TestHTTPResumePartial(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+	hs.(*Source).retryBackoff = time.Millisecond
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	server := newFlakyServer(content)
+	defer server.Close()
+
+	// every attempt fails, the partial content is kept in the cache
+	server.drop(maxDownloadAttempts, 100*1024)
+
+	id := &HTTPIdentifier{URL: server.URL + "/foo", Checksum: digest.FromBytes(content)}
+
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+
+	_, err = h.Snapshot(ctx, nil)
+	require.Error(t, err)
+	require.Len(t, server.ranges(), maxDownloadAttempts)
+
+	h, err = hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+
+	ranges := server.ranges()
+	require.Len(t, ranges, maxDownloadAttempts+1)
+	require.Equal(t, fmt.Sprintf("bytes=%d-", maxDownloadAttempts*100*1024), ranges[maxDownloadAttempts])
+
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, content, dt)
+}
+
+func TestHTTPMirrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+	hs.(*Source).retryBackoff = time.Millisecond
+
+	content := []byte("content-correct")
+	server := httpserver.NewTestServer(map[string]*httpserver.Response{
+		"/wrong":   {Content: []byte("content-wrong")},
+		"/correct": {Content: content},
+	})
+	defer server.Close()
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	_, err = hs.Identifier("http", "example.com/foo", map[string]string{
+		pb.AttrHTTPMirrors: `["http://mirror.example.com/foo"]`,
+	}, nil)
+	require.ErrorContains(t, err, "http.mirrors requires http.checksum")
+
+	_, err = hs.Identifier("http", "example.com/foo", map[string]string{
+		pb.AttrHTTPChecksum: digest.FromBytes(content).String(),
+		pb.AttrHTTPMirrors:  `["file:///foo"]`,
+	}, nil)
+	require.ErrorContains(t, err, "invalid mirror URL")
+
+	id, err := hs.Identifier("http", strings.TrimPrefix(unavailable.URL, "http://")+"/foo", map[string]string{
+		pb.AttrHTTPChecksum: digest.FromBytes(content).String(),
+		pb.AttrHTTPMirrors:  `["` + server.URL + `/missing", "` + server.URL + `/wrong", "` + server.URL + `/correct"]`,
+	}, nil)
+	require.NoError(t, err)
+
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromBytes(content).String(), p)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+
+	// the filename is based on the source URL
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, content, dt)
+
+	require.Equal(t, 1, server.Stats("/wrong").AllRequests)
+	require.Equal(t, 1, server.Stats("/correct").AllRequests)
+
+	// without a working mirror, the errors of all URLs are returned
+	id.(*HTTPIdentifier).Mirrors = []string{server.URL + "/wrong"}
+	h, err = hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+
+	_, err = h.Snapshot(ctx, nil)
+	require.ErrorContains(t, err, "invalid response status 503")
+	require.ErrorContains(t, err, "digest mismatch")
+}
+
+// flakyServer serves content with range requests and drops the connection of
+// a number of requests.
+type flakyServer struct {
+	*httptest.Server
+	content []byte
+	etag    string
+
+	mu        sync.Mutex
+	drops     int
+	dropAfter int
+	reqRanges []string
+}
+
+func newFlakyServer(content []byte) *flakyServer {
+	s := &flakyServer{
+		content: content,
+		etag:    `"` + digest.FromBytes(content).Encoded() + `"`,
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *flakyServer) drop(n, after int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drops = n
+	s.dropAfter = after
+}
+
+func (s *flakyServer) ranges() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.reqRanges)
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.reqRanges = append(s.reqRanges, r.Header.Get("Range"))
+	if s.drops > 0 {
+		s.drops--
+		w = &dropWriter{ResponseWriter: w, n: s.dropAfter}
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", s.etag)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.content))
+}
+
+// dropWriter closes the connection after writing n bytes of the body.
+type dropWriter struct {
+	http.ResponseWriter
+	n int
+}
+
+func (w *dropWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.n {
+		w.n -= len(p)
+		return w.ResponseWriter.Write(p)
+	}
+	w.ResponseWriter.Write(p[:w.n])
+	w.ResponseWriter.(http.Flusher).Flush()
+	panic(http.ErrAbortHandler)
+}
+
 func readFile(ctx context.Context, ref cache.ImmutableRef, fp string) ([]byte, error) {
 	mount, err := ref.Mount(ctx, true, nil)
 	if err != nil {
@@ -34,6 +34,34 @@ func New(ctx context.Context, cs content.Store, desc ocispecs.Descriptor, comp c
 // NewWithRewriteTimestamp returns converter function according to the specified compression type and the epoch.
 // If no conversion is needed, this returns nil without error.
 func NewWithRewriteTimestamp(ctx context.Context, cs content.Store, desc ocispecs.Descriptor, comp compression.Config, rewriteTimestamp *time.Time, immDiffIDs map[digest.Digest]struct{}) (converter.ConvertFunc, error) {
+	return NewWithOpt(ctx, cs, desc, comp, Opt{
+		RewriteTimestamp: rewriteTimestamp,
+		ImmutableDiffIDs: immDiffIDs,
+	})
+}
+
+// Opt configures the rewriting of the layer tar during conversion.
+type Opt struct {
+	// RewriteTimestamp clamps the timestamps of the files to the epoch.
+	RewriteTimestamp *time.Time
+	// ImmutableDiffIDs are the diffIDs of layers that are not rewritten.
+	ImmutableDiffIDs map[digest.Digest]struct{}
+	// Strict normalizes the layer tar with tarconverter.Normalize and
+	// verifies that normalizing the layer tar returned by Regenerate results
+	// in the same diffID.
+	Strict bool
+	// Regenerate returns the uncompressed layer tar generated again from the
+	// snapshot of the layer. It is required with Strict.
+	Regenerate func(ctx context.Context) (io.ReadCloser, error)
+	// TempDir is the directory the file data is buffered in while
+	// normalizing. If empty, the default directory for temporary files is used.
+	TempDir string
+}
+
+// NewWithOpt returns converter function according to the specified compression type and opt.
+// If no conversion is needed, this returns nil without error.
+func NewWithOpt(ctx context.Context, cs content.Store, desc ocispecs.Descriptor, comp compression.Config, opt Opt) (converter.ConvertFunc, error) {
+	rewriteTimestamp := opt.RewriteTimestamp
 	needs, err := comp.Type.NeedsConversion(ctx, cs, desc)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to determine conversion needs")
@@ -41,10 +69,16 @@ func NewWithRewriteTimestamp(ctx context.Context, cs content.Store, desc ocispec
 	if !needs && rewriteTimestamp != nil {
 		needs = desc.Annotations[labelRewrittenTimestamp] != fmt.Sprintf("%d", rewriteTimestamp.UTC().Unix())
 	}
+	if !needs && opt.Strict {
+		needs = desc.Annotations[labelReproducible] != reproducibleStrict
+	}
 	if !needs {
 		// No conversion. No need to return an error here.
 		return nil, nil
 	}
+	if opt.Strict && opt.Regenerate == nil {
+		return nil, errors.New("strict conversion requires regenerating the layer from its snapshot")
+	}
 
 	from, err := compression.FromMediaType(desc.MediaType)
 	if err != nil {
@@ -55,7 +89,10 @@ func NewWithRewriteTimestamp(ctx context.Context, cs content.Store, desc ocispec
 	c.compress, c.finalize = comp.Type.Compress(ctx, comp)
 	c.decompress = from.Decompress
 	c.rewriteTimestamp = rewriteTimestamp
-	c.immDiffIDs = immDiffIDs
+	c.immDiffIDs = opt.ImmutableDiffIDs
+	c.strict = opt.Strict
+	c.regenerate = opt.Regenerate
+	c.tempDir = opt.TempDir
 
 	return (&c).convert, nil
 }
@@ -67,6 +104,9 @@ type conversion struct {
 	finalize         compression.Finalizer
 	rewriteTimestamp *time.Time
 	immDiffIDs       map[digest.Digest]struct{} // diffIDs of immutable layers
+	strict           bool
+	regenerate       func(context.Context) (io.ReadCloser, error)
+	tempDir          string
 }
 
 var bufioPool = pools.New(func() *bufio.Writer {
@@ -125,7 +165,17 @@ func (c *conversion) convert(ctx context.Context, cs content.Store, desc ocispec
 	}
 	defer decR.Close()
 	rdr := decR
-	if c.rewriteTimestamp != nil {
+	var spool *tarconverter.Spool
+	if c.strict {
+		spool, err = tarconverter.NewSpool(c.tempDir)
+		if err != nil {
+			return nil, err
+		}
+		defer spool.Close()
+		nR := c.normalize(spool, io.TeeReader(decR, origDiffID.Hash()))
+		defer nR.Close()
+		rdr = nR
+	} else if c.rewriteTimestamp != nil {
 		tcR := tarconverter.NewReader(io.TeeReader(decR, origDiffID.Hash()), rewriteTimestampInTarHeader(*c.rewriteTimestamp))
 		defer tcR.Close()
 		rdr = tcR
@@ -148,6 +198,9 @@ func (c *conversion) convert(ctx context.Context, cs content.Store, desc ocispec
 	if c.rewriteTimestamp != nil {
 		labelz[labelRewrittenTimestamp] = fmt.Sprintf("%d", c.rewriteTimestamp.UTC().Unix())
 	}
+	if c.strict {
+		labelz[labelReproducible] = reproducibleStrict
+	}
 	if err = w.Commit(ctx, 0, "", content.WithLabels(labelz)); err != nil && !cerrdefs.IsAlreadyExists(err) {
 		return nil, err
 	}
@@ -158,6 +211,11 @@ func (c *conversion) convert(ctx context.Context, cs content.Store, desc ocispec
 	if err != nil {
 		return nil, err
 	}
+	if c.strict {
+		if err := c.verify(ctx, desc, diffID.Digest(), spool); err != nil {
+			return nil, err
+		}
+	}
 
 	newDesc := desc
 	newDesc.MediaType = c.target.Type.MediaType()
@@ -167,6 +225,9 @@ func (c *conversion) convert(ctx context.Context, cs content.Store, desc ocispec
 	if c.rewriteTimestamp != nil {
 		newDesc.Annotations[labelRewrittenTimestamp] = fmt.Sprintf("%d", c.rewriteTimestamp.UTC().Unix())
 	}
+	if c.strict {
+		newDesc.Annotations[labelReproducible] = reproducibleStrict
+	}
 	if c.finalize != nil {
 		a, err := c.finalize(ctx, cs)
 		if err != nil {
@@ -177,6 +238,54 @@ func (c *conversion) convert(ctx context.Context, cs content.Store, desc ocispec
 	return &newDesc, nil
 }
 
+// normalize returns a reader of the normalized tar stream of r. Closing the
+// reader waits until the spool is no longer used.
+func (c *conversion) normalize(spool *tarconverter.Spool, r io.Reader) io.ReadCloser {
+	var hc tarconverter.HeaderConverter
+	if c.rewriteTimestamp != nil {
+		hc = rewriteTimestampInTarHeader(*c.rewriteTimestamp)
+	}
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(spool.Normalize(pw, r, hc))
+	}()
+	return &normalizeReader{PipeReader: pr, done: done}
+}
+
+type normalizeReader struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+func (r *normalizeReader) Close() error {
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
+}
+
+// verify normalizes the layer tar regenerated from the snapshot, reusing the
+// spool of the conversion, and fails if the result does not match diffID.
+func (c *conversion) verify(ctx context.Context, desc ocispecs.Descriptor, diffID digest.Digest, spool *tarconverter.Spool) error {
+	rc, err := c.regenerate(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to regenerate layer %s", desc.Digest)
+	}
+	defer rc.Close()
+	nR := c.normalize(spool, rc)
+	defer nR.Close()
+
+	digester := diffID.Algorithm().Digester()
+	if _, err := io.Copy(digester.Hash(), nR); err != nil {
+		return err
+	}
+	if digester.Digest() != diffID {
+		return errors.Errorf("layer %s is not reproducible: regenerating the layer from the snapshot resulted in diffID %s instead of %s", desc.Digest, digester.Digest(), diffID)
+	}
+	return nil
+}
+
 type onceWriteCloser struct {
 	io.WriteCloser
 	closeOnce sync.Once
@@ -189,4 +298,8 @@ func (w *onceWriteCloser) Close() (err error) {
 	return
 }
 
-const labelRewrittenTimestamp = "buildkit/rewritten-timestamp"
+const (
+	labelRewrittenTimestamp = "buildkit/rewritten-timestamp"
+	labelReproducible       = "buildkit/reproducible"
+	reproducibleStrict      = "strict"
+)
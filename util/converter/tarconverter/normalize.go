@@ -0,0 +1,223 @@
+package tarconverter
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const paxXattrPrefix = "SCHILY.xattr."
+
+// hostXattrs are extended attributes that depend on the host or the
+// snapshotter that created the layer rather than on the build.
+var hostXattrs = []string{
+	"security.selinux",
+	"trusted.",
+	"user.overlay.",
+}
+
+// NormalizeHeader removes the fields of hdr that depend on the snapshotter and
+// differ that created the layer instead of the contents of the file: access
+// and change times, sub-second modification times, user and group names, PAX
+// records other than extended attributes and the header format.
+//
+// Numeric user and group IDs are kept, also for files not owned by root. They
+// define the ownership of the file in the image, so rewriting them would
+// change the image instead of its encoding. Differences in the IDs between
+// builders, e.g. from the user namespace of a rootless daemon, have to be
+// avoided by setting the ownership explicitly in the build.
+func NormalizeHeader(hdr *tar.Header) {
+	hdr.ModTime = hdr.ModTime.Truncate(time.Second)
+	hdr.AccessTime = time.Time{}
+	hdr.ChangeTime = time.Time{}
+	hdr.Uname = ""
+	hdr.Gname = ""
+	hdr.Format = tar.FormatUnknown
+	switch hdr.Typeflag {
+	case tar.TypeRegA, tar.TypeGNUSparse: //nolint:staticcheck // TypeRegA is deprecated but still read
+		hdr.Typeflag = tar.TypeReg
+	case tar.TypeChar, tar.TypeBlock:
+	default:
+		hdr.Devmajor, hdr.Devminor = 0, 0
+	}
+	for k := range hdr.PAXRecords {
+		xattr, ok := strings.CutPrefix(k, paxXattrPrefix)
+		if !ok || slices.ContainsFunc(hostXattrs, func(p string) bool {
+			return xattr == p || strings.HasSuffix(p, ".") && strings.HasPrefix(xattr, p)
+		}) {
+			delete(hdr.PAXRecords, k)
+		}
+	}
+	for k := range hdr.Xattrs { //nolint:staticcheck // Xattrs is deprecated but still set by tar.Reader
+		if _, ok := hdr.PAXRecords[paxXattrPrefix+k]; !ok {
+			delete(hdr.Xattrs, k) //nolint:staticcheck
+		}
+	}
+}
+
+type entry struct {
+	hdr    *tar.Header
+	name   string
+	offset int64
+}
+
+// Spool buffers the file data of the tar streams normalized with it in a
+// temporary file. A spool can be reused for several streams, one at a time.
+type Spool struct {
+	f *os.File
+}
+
+// NewSpool creates a spool in dir. If dir is empty, the default directory for
+// temporary files is used.
+func NewSpool(dir string) (*Spool, error) {
+	f, err := os.CreateTemp(dir, "buildkit-tar-normalize-")
+	if err != nil {
+		return nil, err
+	}
+	return &Spool{f: f}, nil
+}
+
+// Close removes the temporary file of the spool.
+func (s *Spool) Close() error {
+	err := s.f.Close()
+	if err1 := os.Remove(s.f.Name()); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// Normalize writes the tar stream read from r to w with the entries sorted by
+// path and the headers normalized by NormalizeHeader, followed by
+// headerConverter. The output only depends on the files in the archive and not
+// on the order they were written in. Of a set of hardlinked files, the first
+// in path order contains the data and the others link to it. The file data is
+// buffered in the spool, replacing the data of the previous stream. r is
+// drained until hitting EOF.
+func (s *Spool) Normalize(w io.Writer, r io.Reader, headerConverter HeaderConverter) error {
+	f := s.f
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	entries := map[string]*entry{}
+	tr := tar.NewReader(r)
+	var offset int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(f, tr)
+		if err != nil {
+			return err
+		}
+		// a later entry for the same path replaces the earlier one
+		name := cleanName(hdr.Name)
+		entries[name] = &entry{hdr: hdr, name: name, offset: offset}
+		offset += n
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+
+	relinkHardlinks(entries)
+
+	tw := tar.NewWriter(w)
+	for _, name := range slices.Sorted(maps.Keys(entries)) {
+		e := entries[name]
+		hdr := e.hdr
+		hdr.Name = e.name
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Name += "/"
+		}
+		NormalizeHeader(hdr)
+		if headerConverter != nil {
+			headerConverter(hdr)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeReg && hdr.Size > 0 {
+			if _, err := io.Copy(tw, io.NewSectionReader(f, e.offset, hdr.Size)); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Close()
+}
+
+// relinkHardlinks makes the first path in order of every set of hardlinked
+// files contain the data, as tar readers expect the target of a hardlink to
+// precede the link.
+func relinkHardlinks(entries map[string]*entry) {
+	groups := map[string][]string{}
+	for name, e := range entries {
+		if e.hdr.Typeflag != tar.TypeLink {
+			continue
+		}
+		target := cleanName(e.hdr.Linkname)
+		// follow links to links up to the file containing the data
+		for seen := map[string]struct{}{name: {}}; ; {
+			t, ok := entries[target]
+			if !ok || t.hdr.Typeflag != tar.TypeLink {
+				break
+			}
+			if _, ok := seen[target]; ok {
+				break
+			}
+			seen[target] = struct{}{}
+			target = cleanName(t.hdr.Linkname)
+		}
+		if _, ok := entries[target]; !ok {
+			// link to a file in a lower layer
+			e.hdr.Linkname = target
+			continue
+		}
+		groups[target] = append(groups[target], name)
+	}
+	for target, links := range groups {
+		data := entries[target]
+		first := slices.Min(links)
+		if first < target {
+			// move the data to the first path and link the target to it
+			hdr := *data.hdr
+			hdr.Name = first
+			entries[first] = &entry{hdr: &hdr, name: first, offset: data.offset}
+			links = slices.DeleteFunc(links, func(l string) bool { return l == first })
+			links = append(links, target)
+		} else {
+			first = target
+		}
+		for _, l := range links {
+			e := entries[l]
+			if l == target {
+				h := *data.hdr
+				e = &entry{hdr: &h, name: target}
+				entries[target] = e
+			}
+			e.hdr.Typeflag = tar.TypeLink
+			e.hdr.Linkname = first
+			e.hdr.Size = 0
+		}
+	}
+}
+
+func cleanName(name string) string {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return "."
+	}
+	return name[1:]
+}
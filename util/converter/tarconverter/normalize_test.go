@@ -0,0 +1,131 @@
+package tarconverter
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	hdr  tar.Header
+	data string
+}
+
+func writeTar(t *testing.T, entries []testEntry) []byte {
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := e.hdr
+		hdr.Size = int64(len(e.data))
+		require.NoError(t, tw.WriteHeader(&hdr))
+		_, err := tw.Write([]byte(e.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func readTar(t *testing.T, dt []byte) []testEntry {
+	var out []testEntry
+	tr := tar.NewReader(bytes.NewReader(dt))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		out = append(out, testEntry{hdr: *hdr, data: string(data)})
+	}
+}
+
+func newTestSpool(t *testing.T) *Spool {
+	dir := t.TempDir()
+	s, err := NewSpool(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+	return s
+}
+
+func TestNormalize(t *testing.T) {
+	// the spool is reused for all the streams
+	s := newTestSpool(t)
+	now := time.Unix(1700000000, 123456789)
+	dir := tar.Header{Typeflag: tar.TypeDir, Name: "./usr/", Mode: 0o755, ModTime: now, AccessTime: now, ChangeTime: now, Format: tar.FormatPAX}
+	file := tar.Header{Typeflag: tar.TypeReg, Name: "./usr/b", Mode: 0o644, ModTime: now, Uid: 1000, Gid: 1000, Uname: "builder", Gname: "builder",
+		PAXRecords: map[string]string{
+			"SCHILY.xattr.security.capability": "cap",
+			"SCHILY.xattr.security.selinux":    "system_u:object_r:container_file_t:s0",
+			"SCHILY.xattr.trusted.overlay.foo": "y",
+		},
+	}
+	link := tar.Header{Typeflag: tar.TypeLink, Name: "./usr/a", Linkname: "./usr/b", Mode: 0o644, ModTime: now}
+
+	dt1 := writeTar(t, []testEntry{{hdr: dir}, {hdr: file, data: "hello"}, {hdr: link}})
+	// the same files as written by a different differ
+	dir.Name = "usr"
+	file.Name, file.Uname, file.Gname = "usr/b", "", ""
+	link.Name, link.Linkname = "usr/a", "usr/b"
+	dt2 := writeTar(t, []testEntry{{hdr: dir}, {hdr: file, data: "hello"}, {hdr: link}})
+
+	var out1, out2 bytes.Buffer
+	require.NoError(t, s.Normalize(&out1, bytes.NewReader(dt1), nil))
+	require.NoError(t, s.Normalize(&out2, bytes.NewReader(dt2), nil))
+	require.Equal(t, out1.Bytes(), out2.Bytes())
+
+	entries := readTar(t, out1.Bytes())
+	require.Len(t, entries, 3)
+	require.Equal(t, "usr/", entries[0].hdr.Name)
+	require.Equal(t, byte(tar.TypeDir), entries[0].hdr.Typeflag)
+	require.True(t, entries[0].hdr.AccessTime.IsZero())
+	require.Equal(t, now.Truncate(time.Second), entries[0].hdr.ModTime)
+
+	// the first hardlinked path contains the data
+	require.Equal(t, "usr/a", entries[1].hdr.Name)
+	require.Equal(t, byte(tar.TypeReg), entries[1].hdr.Typeflag)
+	require.Equal(t, "hello", entries[1].data)
+	// numeric IDs are part of the file ownership and are kept
+	require.Equal(t, 1000, entries[1].hdr.Uid)
+	require.Equal(t, 1000, entries[1].hdr.Gid)
+	require.Empty(t, entries[1].hdr.Uname)
+	require.Equal(t, map[string]string{"SCHILY.xattr.security.capability": "cap"}, entries[1].hdr.PAXRecords)
+	require.Equal(t, "usr/b", entries[2].hdr.Name)
+	require.Equal(t, byte(tar.TypeLink), entries[2].hdr.Typeflag)
+	require.Equal(t, "usr/a", entries[2].hdr.Linkname)
+
+	// normalizing is idempotent
+	var out3 bytes.Buffer
+	require.NoError(t, s.Normalize(&out3, bytes.NewReader(out1.Bytes()), nil))
+	require.Equal(t, out1.Bytes(), out3.Bytes())
+}
+
+func TestNormalizeHeaderConverter(t *testing.T) {
+	s := newTestSpool(t)
+	epoch := time.Unix(1000, 0)
+	dt := writeTar(t, []testEntry{
+		{hdr: tar.Header{Typeflag: tar.TypeReg, Name: "b", Mode: 0o644, ModTime: time.Now()}, data: "b"},
+		{hdr: tar.Header{Typeflag: tar.TypeReg, Name: "a", Mode: 0o644, ModTime: time.Now()}, data: "a1"},
+		{hdr: tar.Header{Typeflag: tar.TypeReg, Name: "a", Mode: 0o644, ModTime: time.Now()}, data: "a2"},
+	})
+	var out bytes.Buffer
+	require.NoError(t, s.Normalize(&out, bytes.NewReader(dt), func(hdr *tar.Header) {
+		hdr.ModTime = epoch
+	}))
+	entries := readTar(t, out.Bytes())
+	require.Len(t, entries, 2)
+	require.Equal(t, "a", entries[0].hdr.Name)
+	require.Equal(t, "a2", entries[0].data)
+	require.Equal(t, epoch, entries[0].hdr.ModTime)
+	require.Equal(t, "b", entries[1].hdr.Name)
+}
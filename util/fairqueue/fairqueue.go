@@ -0,0 +1,270 @@
+// Package fairqueue implements a weighted semaphore that grants slots to
+// concurrent solves in weighted fair order instead of first come, first
+// served. Every solve belongs to a flow, either its tenant or the solve itself,
+// and flows get a share of the slots proportional to the weight of their
+// priority class.
+package fairqueue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/moby/buildkit/util/progress"
+	"github.com/pkg/errors"
+)
+
+// JobValueKey is the key of the Class value set on solver jobs.
+const JobValueKey = "fairqueue.class"
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
+var priorityWeights = map[Priority]float64{
+	PriorityLow:    1,
+	PriorityNormal: 2,
+	PriorityHigh:   4,
+}
+
+// ParsePriority parses a priority class name. An empty name is the normal
+// priority.
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return PriorityNormal, nil
+	}
+	p := Priority(s)
+	if _, ok := priorityWeights[p]; !ok {
+		return "", errors.Errorf("invalid priority %q, expected one of low, normal, high", s)
+	}
+	return p, nil
+}
+
+func (p Priority) weight() float64 {
+	if w, ok := priorityWeights[p]; ok {
+		return w
+	}
+	return priorityWeights[PriorityNormal]
+}
+
+// Less returns true if p has a lower weight than other.
+func (p Priority) Less(other Priority) bool {
+	return p.weight() < other.weight()
+}
+
+// Class is the scheduling class of a solve.
+type Class struct {
+	// ID is the ID of the solve.
+	ID string
+	// Tenant groups solves sharing a single flow. Solves without a tenant
+	// are each a flow of their own.
+	Tenant   string
+	Priority Priority
+}
+
+func (c Class) flow() string {
+	if c.Tenant != "" {
+		return "tenant:" + c.Tenant
+	}
+	return "solve:" + c.ID
+}
+
+type classKey struct{}
+
+// WithClass returns a context whose slot requests use the class c.
+func WithClass(ctx context.Context, c Class) context.Context {
+	return context.WithValue(ctx, classKey{}, c)
+}
+
+// ClassFromContext returns the class set with WithClass.
+func ClassFromContext(ctx context.Context) Class {
+	c, _ := ctx.Value(classKey{}).(Class)
+	return c
+}
+
+// Semaphore is a weighted semaphore using start-time fair queuing to pick the
+// next waiter when a slot is released. Waiters of a single flow are served in
+// order.
+type Semaphore struct {
+	// Name identifies the slots in the progress stream. The time spent
+	// waiting for a slot is only reported if it is set.
+	Name string
+
+	mu      sync.Mutex
+	size    int64
+	cur     int64
+	vtime   float64
+	seq     uint64
+	flows   map[string]*flow
+	waiters waiterHeap
+}
+
+type flow struct {
+	finish  float64
+	pending int
+}
+
+type waiter struct {
+	flow   *flow
+	start  float64
+	finish float64
+	seq    uint64
+	n      int64
+	ready  chan struct{}
+	index  int
+}
+
+func New(size int64) *Semaphore {
+	return &Semaphore{
+		size:  size,
+		flows: map[string]*flow{},
+	}
+}
+
+// Acquire acquires n slots for the class of ctx, blocking until they are
+// available or ctx is done.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	c := ClassFromContext(ctx)
+
+	s.mu.Lock()
+	if n > s.size {
+		// can never succeed, same as semaphore.Weighted
+		s.mu.Unlock()
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}
+	f, ok := s.flows[c.flow()]
+	if !ok {
+		f = &flow{}
+		s.flows[c.flow()] = f
+	}
+	start := max(f.finish, s.vtime)
+	f.finish = start + float64(n)/c.Priority.weight()
+
+	if len(s.waiters) == 0 && s.cur+n <= s.size {
+		s.cur += n
+		s.vtime = start
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{
+		flow:   f,
+		start:  start,
+		finish: f.finish,
+		seq:    s.seq,
+		n:      n,
+		ready:  make(chan struct{}),
+	}
+	s.seq++
+	f.pending++
+	heap.Push(&s.waiters, w)
+	s.mu.Unlock()
+
+	done := s.reportWait(ctx)
+	select {
+	case <-w.ready:
+		done(nil)
+		return nil
+	case <-ctx.Done():
+		err := context.Cause(ctx)
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// acquired while the context was canceled
+			s.cur -= n
+		default:
+			heap.Remove(&s.waiters, w.index)
+			w.flow.pending--
+			// give the slots back to the flow unless a later waiter of the
+			// flow was already queued after this one
+			if w.flow.finish == w.finish {
+				w.flow.finish = w.start
+			}
+		}
+		s.dispatch()
+		s.mu.Unlock()
+		done(err)
+		return err
+	}
+}
+
+// Release releases n slots.
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("fairqueue: released more than held")
+	}
+	s.dispatch()
+}
+
+func (s *Semaphore) dispatch() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if s.cur+w.n > s.size {
+			break
+		}
+		heap.Pop(&s.waiters)
+		s.cur += w.n
+		s.vtime = max(s.vtime, w.start)
+		w.flow.pending--
+		close(w.ready)
+	}
+	if len(s.waiters) == 0 && s.cur == 0 {
+		// idle, no flow is behind another
+		clear(s.flows)
+		s.vtime = 0
+		return
+	}
+	for k, f := range s.flows {
+		if f.pending == 0 && f.finish <= s.vtime {
+			delete(s.flows, k)
+		}
+	}
+}
+
+func (s *Semaphore) reportWait(ctx context.Context) func(error) error {
+	if s.Name == "" {
+		return func(err error) error { return err }
+	}
+	return progress.OneOff(ctx, fmt.Sprintf("waiting for %s slot", s.Name))
+}
+
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].start != h[j].start {
+		return h[i].start < h[j].start
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	w.index = -1
+	return w
+}
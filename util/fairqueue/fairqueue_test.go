@@ -0,0 +1,153 @@
+package fairqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// enqueue starts a goroutine acquiring a slot for class c and waits until it
+// is queued. Acquired slots are recorded in order and released immediately.
+func enqueue(t *testing.T, s *Semaphore, c Class, wg *sync.WaitGroup, mu *sync.Mutex, order *[]string) {
+	s.mu.Lock()
+	queued := len(s.waiters)
+	s.mu.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := s.Acquire(WithClass(context.TODO(), c), 1)
+		if !assertNoError(t, err) {
+			return
+		}
+		mu.Lock()
+		*order = append(*order, c.flow())
+		mu.Unlock()
+		s.Release(1)
+	}()
+
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.waiters) == queued+1
+	}, time.Second, time.Millisecond)
+}
+
+func assertNoError(t *testing.T, err error) bool {
+	t.Helper()
+	if err != nil {
+		t.Error(err)
+		return false
+	}
+	return true
+}
+
+func TestFairBetweenSolves(t *testing.T) {
+	t.Parallel()
+
+	s := New(1)
+	big := Class{ID: "big"}
+	small := Class{ID: "small"}
+
+	require.NoError(t, s.Acquire(WithClass(context.TODO(), big), 1))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var order []string
+	for range 5 {
+		enqueue(t, s, big, &wg, &mu, &order)
+	}
+	enqueue(t, s, small, &wg, &mu, &order)
+
+	s.Release(1)
+	wg.Wait()
+
+	// the small solve doesn't wait for the backlog of the big one
+	require.Equal(t, []string{"solve:small", "solve:big", "solve:big", "solve:big", "solve:big", "solve:big"}, order)
+	require.Empty(t, s.flows)
+}
+
+func TestPriorityWeights(t *testing.T) {
+	t.Parallel()
+
+	s := New(1)
+	high := Class{Tenant: "high", Priority: PriorityHigh}
+	low := Class{Tenant: "low", Priority: PriorityLow}
+
+	require.NoError(t, s.Acquire(context.TODO(), 1))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var order []string
+	for range 5 {
+		enqueue(t, s, low, &wg, &mu, &order)
+	}
+	for range 5 {
+		enqueue(t, s, high, &wg, &mu, &order)
+	}
+
+	s.Release(1)
+	wg.Wait()
+
+	require.Len(t, order, 10)
+	var highFirst int
+	for _, f := range order[:5] {
+		if f == "tenant:high" {
+			highFirst++
+		}
+	}
+	require.Equal(t, 4, highFirst)
+}
+
+func TestAcquireCanceled(t *testing.T) {
+	t.Parallel()
+
+	s := New(2)
+	require.NoError(t, s.Acquire(context.TODO(), 2))
+	s.mu.Lock()
+	finish := s.flows[Class{}.flow()].finish
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancelCause(context.TODO())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Acquire(ctx, 1)
+	}()
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.waiters) == 1
+	}, time.Second, time.Millisecond)
+
+	cancel(errors.WithStack(context.Canceled))
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	s.mu.Lock()
+	require.Empty(t, s.waiters)
+	// the canceled request doesn't count against the flow
+	require.Equal(t, finish, s.flows[Class{}.flow()].finish)
+	s.mu.Unlock()
+
+	s.Release(2)
+	require.NoError(t, s.Acquire(context.TODO(), 2))
+	s.Release(2)
+}
+
+func TestParsePriority(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParsePriority("")
+	require.NoError(t, err)
+	require.Equal(t, PriorityNormal, p)
+
+	p, err = ParsePriority("high")
+	require.NoError(t, err)
+	require.Equal(t, PriorityHigh, p)
+
+	_, err = ParsePriority("urgent")
+	require.ErrorContains(t, err, "invalid priority")
+}
@@ -0,0 +1,287 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EgressPolicy is an allowlist for requests made through the exec proxy. A
+// request is allowed if it matches any of the rules. A policy without rules
+// denies all requests.
+type EgressPolicy struct {
+	Rules []EgressRule `json:"rules"`
+	// MaxResponseSize limits the size of response bodies in bytes. Zero
+	// means no limit.
+	MaxResponseSize int64 `json:"maxResponseSize,omitempty"`
+
+	cidrs [][]netip.Prefix
+}
+
+// EgressRule matches requests. Empty fields match any request. Hosts and
+// CIDRs are alternatives: a request matches if its host matches one of the
+// host globs or one of its addresses is in one of the CIDRs.
+type EgressRule struct {
+	// Hosts are host name globs, e.g. "*.example.com".
+	Hosts []string `json:"hosts,omitempty"`
+	// CIDRs match literal IP hosts and addresses the host resolves to.
+	CIDRs []string `json:"cidrs,omitempty"`
+	// Methods are HTTP methods, e.g. "GET".
+	Methods []string `json:"methods,omitempty"`
+	// PathPrefixes match the beginning of the decoded and cleaned URL path.
+	// Requests with dot-segments or encoded slashes in the path never match.
+	PathPrefixes []string `json:"pathPrefixes,omitempty"`
+}
+
+// ParseEgressPolicy parses and validates a JSON egress policy.
+func ParseEgressPolicy(dt []byte) (*EgressPolicy, error) {
+	var p EgressPolicy
+	dec := json.NewDecoder(bytes.NewReader(dt))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, errors.Wrap(err, "failed to parse egress policy")
+	}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (p *EgressPolicy) init() error {
+	if p.MaxResponseSize < 0 {
+		return errors.Errorf("invalid egress policy max response size %d", p.MaxResponseSize)
+	}
+	p.cidrs = make([][]netip.Prefix, len(p.Rules))
+	for i, r := range p.Rules {
+		for _, h := range r.Hosts {
+			if _, err := path.Match(h, ""); err != nil {
+				return errors.Wrapf(err, "invalid egress policy host %q", h)
+			}
+		}
+		for _, c := range r.CIDRs {
+			prefix, err := netip.ParsePrefix(c)
+			if err != nil {
+				return errors.Wrapf(err, "invalid egress policy CIDR %q", c)
+			}
+			p.cidrs[i] = append(p.cidrs[i], prefix.Masked())
+		}
+	}
+	return nil
+}
+
+// Allowed checks a request against the policy. It returns a non-empty
+// reason if the request is denied. If the request is only allowed by CIDR
+// rules, it also returns the allowed addresses of the host. The connection for
+// the request then has to be made to one of them, see WithEgressAddrs.
+func (p *EgressPolicy) Allowed(ctx context.Context, method string, u *url.URL) (string, []netip.Addr, error) {
+	return p.allowed(ctx, method, u, &hostResolver{host: strings.ToLower(u.Hostname())})
+}
+
+func (p *EgressPolicy) allowed(ctx context.Context, method string, u *url.URL, res *hostResolver) (string, []netip.Addr, error) {
+	if p == nil {
+		return "", nil, nil
+	}
+	cidrs := p.cidrs
+	if len(cidrs) != len(p.Rules) {
+		cp := *p
+		if err := cp.init(); err != nil {
+			return "", nil, err
+		}
+		cidrs = cp.cidrs
+	}
+	var allowedAddrs []netip.Addr
+	var reqPath string
+	for i, r := range p.Rules {
+		if len(r.Methods) > 0 && !slices.ContainsFunc(r.Methods, func(m string) bool { return strings.EqualFold(m, method) }) {
+			continue
+		}
+		if len(r.PathPrefixes) > 0 {
+			if reqPath == "" {
+				var err error
+				if reqPath, err = egressPath(u); err != nil {
+					return err.Error(), nil, nil
+				}
+			}
+			if !slices.ContainsFunc(r.PathPrefixes, func(pfx string) bool { return strings.HasPrefix(reqPath, pfx) }) {
+				continue
+			}
+		}
+		if len(r.Hosts) == 0 && len(r.CIDRs) == 0 {
+			return "", nil, nil
+		}
+		if slices.ContainsFunc(r.Hosts, func(h string) bool {
+			ok, _ := path.Match(strings.ToLower(h), res.host)
+			return ok
+		}) {
+			return "", nil, nil
+		}
+		if len(cidrs[i]) == 0 {
+			continue
+		}
+		addrs, err := res.resolve(ctx)
+		if err != nil {
+			return fmt.Sprintf("failed to resolve %s: %v", res.host, err), nil, nil
+		}
+		for _, a := range addrs {
+			if !slices.Contains(allowedAddrs, a) && slices.ContainsFunc(cidrs[i], func(c netip.Prefix) bool { return c.Contains(a) }) {
+				allowedAddrs = append(allowedAddrs, a)
+			}
+		}
+	}
+	if len(allowedAddrs) > 0 {
+		return "", allowedAddrs, nil
+	}
+	return "not allowed by egress policy", nil, nil
+}
+
+// egressPath returns the decoded and cleaned path of u for matching path
+// prefixes. Paths that could be interpreted differently by the server, with
+// dot-segments or encoded slashes, are rejected.
+func egressPath(u *url.URL) (string, error) {
+	escaped := u.EscapedPath()
+	lower := strings.ToLower(escaped)
+	if strings.Contains(lower, "%2f") || strings.Contains(lower, "%5c") {
+		return "", errors.Errorf("path %q with encoded slashes not allowed by egress policy", escaped)
+	}
+	p, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", errors.Errorf("invalid path %q not allowed by egress policy", escaped)
+	}
+	if slices.ContainsFunc(strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' }), func(seg string) bool {
+		return seg == "." || seg == ".."
+	}) {
+		return "", errors.Errorf("path %q with dot-segments not allowed by egress policy", escaped)
+	}
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned, nil
+}
+
+// hostResolver resolves a host once for all the rules and policies a request
+// is checked against.
+type hostResolver struct {
+	host     string
+	addrs    []netip.Addr
+	err      error
+	resolved bool
+}
+
+func (r *hostResolver) resolve(ctx context.Context) ([]netip.Addr, error) {
+	if !r.resolved {
+		r.resolved = true
+		r.addrs, r.err = resolveHost(ctx, r.host)
+	}
+	return r.addrs, r.err
+}
+
+func resolveHost(ctx context.Context, host string) ([]netip.Addr, error) {
+	if a, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{a.Unmap()}, nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for i, ip := range ips {
+		ips[i] = ip.Unmap()
+	}
+	return ips, nil
+}
+
+// EgressPolicies is a set of policies that all have to allow a request.
+type EgressPolicies []*EgressPolicy
+
+// Allowed checks a request against all policies. It returns a non-empty
+// reason if any policy denies the request. If some policies only allow the
+// request by CIDR rules, it also returns the addresses of the host that are
+// allowed by all of them.
+func (ps EgressPolicies) Allowed(ctx context.Context, method string, u *url.URL) (string, []netip.Addr, error) {
+	res := &hostResolver{host: strings.ToLower(u.Hostname())}
+	var addrs []netip.Addr
+	for _, p := range ps {
+		reason, pAddrs, err := p.allowed(ctx, method, u, res)
+		if err != nil || reason != "" {
+			return reason, nil, err
+		}
+		switch {
+		case pAddrs == nil:
+		case addrs == nil:
+			addrs = pAddrs
+		default:
+			addrs = slices.DeleteFunc(addrs, func(a netip.Addr) bool { return !slices.Contains(pAddrs, a) })
+			if len(addrs) == 0 {
+				return "not allowed by egress policy", nil, nil
+			}
+		}
+	}
+	return "", addrs, nil
+}
+
+type egressAddrsKey struct{}
+
+type egressAddrs struct {
+	host  string
+	addrs []netip.Addr
+}
+
+// WithEgressAddrs returns a context that makes the dial function returned by
+// EgressDialContext connect to one of addrs instead of resolving host again.
+func WithEgressAddrs(ctx context.Context, host string, addrs []netip.Addr) context.Context {
+	return context.WithValue(ctx, egressAddrsKey{}, &egressAddrs{host: host, addrs: addrs})
+}
+
+// EgressDialContext wraps dial to connect to the addresses checked by the
+// egress policy, as set by WithEgressAddrs, so that the host can't resolve to
+// a different address between the check and the connection. Connections to
+// other hosts fail for such contexts.
+func EgressDialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		v, ok := ctx.Value(egressAddrsKey{}).(*egressAddrs)
+		if !ok {
+			return dial(ctx, network, address)
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(host, v.host) {
+			return nil, errors.Errorf("connection to %s not allowed by egress policy", host)
+		}
+		var firstErr error
+		for _, a := range v.addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(a.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr == nil {
+			firstErr = errors.Errorf("no address of %s allowed by egress policy", host)
+		}
+		return nil, firstErr
+	}
+}
+
+// MaxResponseSize returns the smallest response size limit of all policies.
+func (ps EgressPolicies) MaxResponseSize() int64 {
+	var limit int64
+	for _, p := range ps {
+		if p != nil && p.MaxResponseSize > 0 && (limit == 0 || p.MaxResponseSize < limit) {
+			limit = p.MaxResponseSize
+		}
+	}
+	return limit
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressPolicyAllowed(t *testing.T) {
+	p, err := ParseEgressPolicy([]byte(`{
+		"rules": [
+			{"hosts": ["*.example.com"], "methods": ["GET"]},
+			{"hosts": ["registry.example.org"], "pathPrefixes": ["/v2/"]},
+			{"cidrs": ["10.0.0.0/8"]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		method  string
+		url     string
+		allowed bool
+	}{
+		{"GET", "https://deb.example.com/file", true},
+		{"get", "https://DEB.example.com/file", true},
+		{"POST", "https://deb.example.com/file", false},
+		{"GET", "https://example.com/file", false},
+		{"PUT", "https://registry.example.org/v2/foo", true},
+		{"GET", "https://registry.example.org/v1/foo", false},
+		{"GET", "https://registry.example.org//v2/foo", true},
+		{"GET", "https://registry.example.org/v%32/foo", true},
+		{"GET", "https://registry.example.org/v2/../v1/foo", false},
+		{"GET", "https://registry.example.org/v2/./foo", false},
+		{"GET", "https://registry.example.org/v2/%2e%2e/v1/foo", false},
+		{"GET", "https://registry.example.org/v2/%2E%2E/v1/foo", false},
+		{"GET", "https://registry.example.org/v2/..%5cv1/foo", false},
+		{"GET", "https://registry.example.org/v2/foo%2f..%2f..%2fv1", false},
+		{"GET", "https://registry.example.org/v2%2F..%2Fv1/foo", false},
+		{"GET", "http://10.1.2.3:8080/", true},
+		{"GET", "http://192.168.0.1/", false},
+	} {
+		u, err := url.Parse(tc.url)
+		require.NoError(t, err)
+		reason, _, err := p.Allowed(t.Context(), tc.method, u)
+		require.NoError(t, err)
+		if tc.allowed {
+			require.Empty(t, reason, "%s %s", tc.method, tc.url)
+		} else {
+			require.NotEmpty(t, reason, "%s %s", tc.method, tc.url)
+		}
+	}
+}
+
+func TestEgressPolicyAllowedAddrs(t *testing.T) {
+	p1, err := ParseEgressPolicy([]byte(`{"rules":[{"hosts":["example.com"]},{"cidrs":["10.0.0.0/8"]}]}`))
+	require.NoError(t, err)
+	p2, err := ParseEgressPolicy([]byte(`{"rules":[{"cidrs":["10.1.0.0/16"]}]}`))
+	require.NoError(t, err)
+
+	u, err := url.Parse("http://example.com/")
+	require.NoError(t, err)
+	reason, addrs, err := p1.Allowed(t.Context(), "GET", u)
+	require.NoError(t, err)
+	require.Empty(t, reason)
+	require.Nil(t, addrs)
+
+	u, err = url.Parse("http://10.1.2.3/")
+	require.NoError(t, err)
+	reason, addrs, err = EgressPolicies{p1, p2}.Allowed(t.Context(), "GET", u)
+	require.NoError(t, err)
+	require.Empty(t, reason)
+	require.Equal(t, []netip.Addr{netip.MustParseAddr("10.1.2.3")}, addrs)
+
+	u, err = url.Parse("http://10.2.0.1/")
+	require.NoError(t, err)
+	reason, _, err = EgressPolicies{p1, p2}.Allowed(t.Context(), "GET", u)
+	require.NoError(t, err)
+	require.NotEmpty(t, reason)
+}
+
+func TestEgressDialContext(t *testing.T) {
+	var dialed []string
+	dial := EgressDialContext(func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		if address == "10.0.0.1:443" {
+			return nil, &net.OpError{Op: "dial", Net: network}
+		}
+		return nil, nil
+	})
+
+	_, err := dial(t.Context(), "tcp", "example.com:443")
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com:443"}, dialed)
+
+	dialed = nil
+	ctx := WithEgressAddrs(t.Context(), "example.com", []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("::1")})
+	_, err = dial(ctx, "tcp", "Example.com:443")
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1:443", "[::1]:443"}, dialed)
+
+	dialed = nil
+	_, err = dial(ctx, "tcp", "example.org:443")
+	require.Error(t, err)
+	require.Empty(t, dialed)
+}
+
+func TestEgressPolicyNoRulesDenies(t *testing.T) {
+	p, err := ParseEgressPolicy([]byte(`{"rules":[]}`))
+	require.NoError(t, err)
+	u, err := url.Parse("https://example.com/")
+	require.NoError(t, err)
+	reason, _, err := p.Allowed(t.Context(), "GET", u)
+	require.NoError(t, err)
+	require.NotEmpty(t, reason)
+}
+
+func TestEgressPoliciesMaxResponseSize(t *testing.T) {
+	require.Equal(t, int64(0), EgressPolicies{}.MaxResponseSize())
+	ps := EgressPolicies{
+		{MaxResponseSize: 100},
+		{},
+		{MaxResponseSize: 10},
+	}
+	require.Equal(t, int64(10), ps.MaxResponseSize())
+}
+
+func TestParseEgressPolicyInvalid(t *testing.T) {
+	for _, dt := range []string{
+		`{"rules":[{"cidrs":["10.0.0.0"]}]}`,
+		`{"rules":[{"hosts":["["]}]}`,
+		`{"rules":[],"maxResponseSize":-1}`,
+		`{"rule":[]}`,
+	} {
+		_, err := ParseEgressPolicy([]byte(dt))
+		require.Error(t, err, dt)
+	}
+}
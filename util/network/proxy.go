@@ -17,6 +17,8 @@ type ProxyPolicy interface {
 
 type ProxyConfig struct {
 	Policy     ProxyPolicy
+	Egress     EgressPolicies
+	Replay     *ProxyReplay
 	Capture    *ProxyCapture
 	EgressMode pb.NetMode
 }
@@ -52,11 +54,19 @@ type ProxyIncomplete struct {
 	Reason string
 }
 
+// ProxyDenied is a request that was blocked by the proxy policy.
+type ProxyDenied struct {
+	Method string
+	URL    string
+	Reason string
+}
+
 type ProxyCapture struct {
 	mu         sync.Mutex
 	requests   []ProxyRequest
 	materials  []ProxyMaterial
 	incomplete []ProxyIncomplete
+	denied     []ProxyDenied
 }
 
 func NewProxyCapture() *ProxyCapture {
@@ -90,6 +100,15 @@ func (c *ProxyCapture) AddIncomplete(in ProxyIncomplete) {
 	c.incomplete = append(c.incomplete, in)
 }
 
+func (c *ProxyCapture) AddDenied(d ProxyDenied) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.denied = append(c.denied, d)
+}
+
 func (c *ProxyCapture) Materials() []ProxyMaterial {
 	if c == nil {
 		return nil
@@ -152,3 +171,12 @@ func (c *ProxyCapture) Incomplete() []ProxyIncomplete {
 	copy(out, c.incomplete)
 	return out
 }
+
+func (c *ProxyCapture) Denied() []ProxyDenied {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.denied)
+}
@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"net/netip"
 	neturl "net/url"
 	"os"
 	"path/filepath"
@@ -404,10 +405,13 @@ func (n *proxyNS) startProxy(ctx context.Context, proxy *network.ProxyConfig) er
 	transport.DialContext = dialer.DialContext
 	n.transport = transport
 	handler := &proxyHandler{
-		provider:  n.provider,
-		policy:    proxy.Policy,
-		capture:   proxy.Capture,
-		transport: transport,
+		provider:        n.provider,
+		policy:          proxy.Policy,
+		egress:          proxy.Egress,
+		replay:          proxy.Replay,
+		capture:         proxy.Capture,
+		transport:       transport,
+		egressTransport: newEgressTransport(transport),
 	}
 	n.server = &http.Server{
 		Handler:           handler,
@@ -502,8 +506,27 @@ func (n *proxyNS) deleteVeth() error {
 type proxyHandler struct {
 	provider  *provider
 	policy    network.ProxyPolicy
+	egress    network.EgressPolicies
+	replay    *network.ProxyReplay
 	capture   *network.ProxyCapture
 	transport *http.Transport
+	// egressTransport is used for requests that the egress policy only allows
+	// to connect to some addresses of the host.
+	egressTransport *http.Transport
+}
+
+// newEgressTransport returns a transport that connects to the addresses
+// checked by the egress policy for the request. It doesn't reuse connections,
+// as a connection is only valid for the addresses checked for one request.
+func newEgressTransport(tr *http.Transport) *http.Transport {
+	tr = tr.Clone()
+	dial := tr.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	tr.DialContext = network.EgressDialContext(dial)
+	tr.DisableKeepAlives = true
+	return tr
 }
 
 func (h *proxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -515,14 +538,15 @@ func (h *proxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r.URL.Scheme = "http"
 		r.URL.Host = r.Host
 	}
-	if target, err := h.check(r.Context(), r.Method, r.URL.String()); err != nil {
+	target, addrs, err := h.check(r.Context(), r.Method, r.URL.String())
+	if err != nil {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	} else if target != nil {
 		r.URL = target
 		r.Host = target.Host
 	}
-	resp, err := h.roundTrip(r)
+	resp, err := h.roundTrip(r, addrs)
 	if err != nil {
 		h.recordRequest(r, http.StatusBadGateway, "")
 		h.recordIncomplete(r, "upstream_error")
@@ -531,6 +555,10 @@ func (h *proxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	defer resp.Body.Close()
 	h.recordRequest(r, resp.StatusCode, finalURL(r, resp))
+	if err := h.limitResponse(r, resp); err != nil {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
 	copyHeader(w.Header(), resp.Header)
 	w.WriteHeader(resp.StatusCode)
 	tracker := newProxyBodyTracker(resp.Body)
@@ -574,7 +602,8 @@ func (h *proxyHandler) handleConnect(w http.ResponseWriter, r *http.Request) {
 		req.URL.Scheme = "https"
 		req.URL.Host = r.Host
 		req.RequestURI = ""
-		if target, err := h.check(req.Context(), req.Method, req.URL.String()); err != nil {
+		target, addrs, err := h.check(req.Context(), req.Method, req.URL.String())
+		if err != nil {
 			_ = req.Body.Close()
 			_, _ = io.WriteString(tlsConn, "HTTP/1.1 403 Forbidden\r\nContent-Length: 10\r\nConnection: close\r\n\r\nForbidden\n")
 			return
@@ -582,7 +611,7 @@ func (h *proxyHandler) handleConnect(w http.ResponseWriter, r *http.Request) {
 			req.URL = target
 			req.Host = target.Host
 		}
-		resp, err := h.roundTrip(req)
+		resp, err := h.roundTrip(req, addrs)
 		if err != nil {
 			_ = req.Body.Close()
 			h.recordRequest(req, http.StatusBadGateway, "")
@@ -591,6 +620,11 @@ func (h *proxyHandler) handleConnect(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		h.recordRequest(req, resp.StatusCode, finalURL(req, resp))
+		if err := h.limitResponse(req, resp); err != nil {
+			resp.Body.Close()
+			_, _ = io.WriteString(tlsConn, "HTTP/1.1 403 Forbidden\r\nContent-Length: 10\r\nConnection: close\r\n\r\nForbidden\n")
+			return
+		}
 		prepareMITMResponse(req, resp)
 		tracker := newProxyBodyTracker(resp.Body)
 		resp.Body = tracker
@@ -686,6 +720,64 @@ func (h *proxyHandler) recordRequest(req *http.Request, statusCode int, redirect
 	})
 }
 
+func (h *proxyHandler) recordDenied(method, rawURL, reason string) {
+	if h.capture == nil {
+		return
+	}
+	h.capture.AddDenied(network.ProxyDenied{
+		Method: method,
+		URL:    captureURL(rawURL),
+		Reason: reason,
+	})
+}
+
+// limitResponse enforces the maximum response size of the egress policy. A
+// response with a known length over the limit is rejected. Otherwise the
+// body is cut off once it exceeds the limit.
+func (h *proxyHandler) limitResponse(req *http.Request, resp *http.Response) error {
+	limit := h.egress.MaxResponseSize()
+	if limit <= 0 {
+		return nil
+	}
+	if resp.ContentLength > limit {
+		reason := fmt.Sprintf("response size %d exceeds limit of %d bytes", resp.ContentLength, limit)
+		h.recordDenied(req.Method, req.URL.String(), reason)
+		return errors.New(reason)
+	}
+	resp.Body = &limitedBody{
+		ReadCloser: resp.Body,
+		remaining:  limit,
+		onExceeded: func() {
+			h.recordDenied(req.Method, req.URL.String(), fmt.Sprintf("response exceeds limit of %d bytes", limit))
+		},
+	}
+	return nil
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining  int64
+	onExceeded func()
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, errResponseTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		b.onExceeded()
+		return n + int(b.remaining), errResponseTooLarge
+	}
+	return n, err
+}
+
+var errResponseTooLarge = errors.New("response exceeds egress policy size limit")
+
 func (h *proxyHandler) recordIncomplete(req *http.Request, reason string) {
 	if h.capture == nil {
 		return
@@ -754,16 +846,49 @@ func captureURL(s string) string {
 	return redactURL(s)
 }
 
-func (h *proxyHandler) roundTrip(r *http.Request) (*http.Response, error) {
+// roundTrip sends the request upstream. If addrs is non-nil, the connection
+// is made to one of them instead of resolving the host again.
+func (h *proxyHandler) roundTrip(r *http.Request, addrs []netip.Addr) (*http.Response, error) {
+	if h.replay != nil {
+		return h.replayResponse(r)
+	}
 	stripProxyHeaders(r.Header)
 	r.Header.Del("Accept-Encoding")
 	r.RequestURI = ""
-	return h.transport.RoundTrip(r.WithContext(context.WithoutCancel(r.Context())))
+	ctx := context.WithoutCancel(r.Context())
+	tr := h.transport
+	if addrs != nil {
+		ctx = network.WithEgressAddrs(ctx, r.URL.Hostname(), addrs)
+		tr = h.egressTransport
+	}
+	return tr.RoundTrip(r.WithContext(ctx))
+}
+
+// check checks the request against the egress and source policies and the
+// replay materials. It returns the URL the source policy converted the request
+// to, if any, and the addresses the egress policy allows connecting to, if it
+// restricts them.
+func (h *proxyHandler) check(ctx context.Context, method, rawURL string) (*neturl.URL, []netip.Addr, error) {
+	target, addrs, err := h.checkPolicy(ctx, method, rawURL)
+	if err != nil || h.replay == nil {
+		return target, addrs, err
+	}
+	if target != nil {
+		rawURL = target.String()
+	}
+	if _, err := h.replayMaterial(method, rawURL); err != nil {
+		return nil, nil, err
+	}
+	return target, addrs, nil
 }
 
-func (h *proxyHandler) check(ctx context.Context, method, rawURL string) (*neturl.URL, error) {
+func (h *proxyHandler) checkPolicy(ctx context.Context, method, rawURL string) (*neturl.URL, []netip.Addr, error) {
+	addrs, err := h.checkEgress(ctx, method, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
 	if h.policy == nil {
-		return nil, nil
+		return nil, addrs, nil
 	}
 	redactedURL := redactURL(rawURL)
 	op := &pb.Op{
@@ -774,28 +899,120 @@ func (h *proxyHandler) check(ctx context.Context, method, rawURL string) (*netur
 		},
 	}
 	if _, err := h.policy.Evaluate(ctx, op); err != nil {
-		return nil, err
+		h.recordDenied(method, rawURL, err.Error())
+		return nil, nil, err
 	}
 	source := op.GetSource()
 	target := source.Identifier
 	converted := target != redactedURL || len(source.Attrs) != 0
 	if !converted {
-		return nil, nil
+		return nil, addrs, nil
 	}
 	if method != http.MethodGet {
-		return nil, errors.Errorf("source policy converted proxy request %q, but conversion is only supported for GET", redactedURL)
+		return nil, nil, errors.Errorf("source policy converted proxy request %q, but conversion is only supported for GET", redactedURL)
 	}
 	if len(source.Attrs) != 0 {
-		return nil, errors.Errorf("source policy converted proxy request %q with attrs, but proxy conversion only supports URL updates", redactedURL)
+		return nil, nil, errors.Errorf("source policy converted proxy request %q with attrs, but proxy conversion only supports URL updates", redactedURL)
 	}
 	u, err := neturl.Parse(target)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error parsing converted proxy request URL %q", redactURL(target))
+		return nil, nil, errors.Wrapf(err, "error parsing converted proxy request URL %q", redactURL(target))
 	}
 	if !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") {
-		return nil, errors.Errorf("source policy converted proxy request to unsupported URL %q", redactURL(target))
+		return nil, nil, errors.Errorf("source policy converted proxy request to unsupported URL %q", redactURL(target))
+	}
+	// the converted URL is connected to, so it has to be allowed as well
+	addrs, err = h.checkEgress(ctx, method, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, addrs, nil
+}
+
+// checkEgress checks the request against the egress policies. It returns the
+// addresses of the host the request is allowed to connect to if the policies
+// restrict them.
+func (h *proxyHandler) checkEgress(ctx context.Context, method, rawURL string) ([]netip.Addr, error) {
+	if len(h.egress) == 0 {
+		return nil, nil
+	}
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		h.recordDenied(method, rawURL, "invalid URL")
+		return nil, errors.Wrapf(err, "error parsing proxy request URL %q", redactURL(rawURL))
+	}
+	reason, addrs, err := h.egress.Allowed(ctx, method, u)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		h.recordDenied(method, rawURL, reason)
+		return nil, errors.Errorf("proxy request %s %q denied: %s", method, redactURL(rawURL), reason)
 	}
-	return u, nil
+	return addrs, nil
+}
+
+// replayMaterial returns the digest of the captured material for a request
+// in replay mode. Requests without a material are denied.
+func (h *proxyHandler) replayMaterial(method, rawURL string) (digest.Digest, error) {
+	dgst, ok := h.replay.Materials[captureURL(rawURL)]
+	if !ok || method != http.MethodGet {
+		reason := "not found in replay materials"
+		h.recordDenied(method, rawURL, reason)
+		return "", errors.Errorf("proxy request %s %q denied: %s", method, redactURL(rawURL), reason)
+	}
+	return dgst, nil
+}
+
+func (h *proxyHandler) replayResponse(r *http.Request) (*http.Response, error) {
+	if r.Body != nil {
+		_ = r.Body.Close()
+	}
+	dgst, err := h.replayMaterial(r.Method, r.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	rc, err := h.replay.Open(context.WithoutCancel(r.Context()), dgst)
+	if err != nil {
+		h.recordDenied(r.Method, r.URL.String(), fmt.Sprintf("replay material %s not available: %v", dgst, err))
+		return nil, errors.Wrapf(err, "failed to open replay material %s", dgst)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		ContentLength: -1,
+		Request:       r,
+		Body: &replayBody{
+			ReadCloser: rc,
+			verifier:   dgst.Verifier(),
+			onMismatch: func() {
+				h.recordDenied(r.Method, r.URL.String(), fmt.Sprintf("replay material does not match digest %s", dgst))
+			},
+		},
+	}, nil
+}
+
+// replayBody verifies replayed content against the digest of the material.
+type replayBody struct {
+	io.ReadCloser
+	verifier   digest.Verifier
+	onMismatch func()
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = b.verifier.Write(p[:n])
+	}
+	if errors.Is(err, io.EOF) && !b.verifier.Verified() {
+		b.onMismatch()
+		return n, errors.New("replay material digest mismatch")
+	}
+	return n, err
 }
 
 func newCA() ([]byte, *x509.Certificate, *rsa.PrivateKey, error) {
@@ -0,0 +1,69 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// ProxyReplay makes the exec proxy answer requests from previously captured
+// materials instead of the network. Requests for URLs without a material are
+// denied.
+type ProxyReplay struct {
+	// Materials maps captured URLs to the digest of their content.
+	Materials map[string]digest.Digest
+	// Open returns the content of a material.
+	Open func(context.Context, digest.Digest) (io.ReadCloser, error)
+}
+
+// ProxyReplayConfig is the JSON format of a replay request.
+type ProxyReplayConfig struct {
+	// Store is the ID of the client-side OCI layout store holding the
+	// content of the materials.
+	Store     string                `json:"store"`
+	Materials []ProxyReplayMaterial `json:"materials"`
+}
+
+type ProxyReplayMaterial struct {
+	URL    string        `json:"url"`
+	Digest digest.Digest `json:"digest"`
+}
+
+// ParseProxyReplayConfig parses and validates a JSON replay request.
+func ParseProxyReplayConfig(dt []byte) (*ProxyReplayConfig, error) {
+	var cfg ProxyReplayConfig
+	dec := json.NewDecoder(bytes.NewReader(dt))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to parse proxy replay config")
+	}
+	if cfg.Store == "" {
+		return nil, errors.New("proxy replay config requires a store")
+	}
+	if _, err := cfg.MaterialMap(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// MaterialMap returns the materials keyed by URL.
+func (cfg *ProxyReplayConfig) MaterialMap() (map[string]digest.Digest, error) {
+	m := make(map[string]digest.Digest, len(cfg.Materials))
+	for _, mat := range cfg.Materials {
+		if mat.URL == "" {
+			return nil, errors.New("invalid proxy replay material without URL")
+		}
+		if err := mat.Digest.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid digest for proxy replay material %s", mat.URL)
+		}
+		if prev, ok := m[mat.URL]; ok && prev != mat.Digest {
+			return nil, errors.Errorf("conflicting digests %s and %s for proxy replay material %s", prev, mat.Digest, mat.URL)
+		}
+		m[mat.URL] = mat.Digest
+	}
+	return m, nil
+}
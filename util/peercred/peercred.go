@@ -0,0 +1,96 @@
+// Package peercred provides gRPC server transport credentials that identify
+// the peer of a connection. TLS connections complete their handshake and
+// report the client certificate, unix socket connections report the user and
+// group of the connecting process.
+package peercred
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const securityProtocol = "peercred"
+
+// unix sockets are only reachable by local processes
+var commonAuthInfo = credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}
+
+// UnixInfo is the AuthInfo of peers connected over a unix socket.
+type UnixInfo struct {
+	credentials.CommonAuthInfo
+	UID int
+	GID int
+	PID int
+}
+
+func (UnixInfo) AuthType() string {
+	return "unix"
+}
+
+type localInfo struct {
+	credentials.CommonAuthInfo
+}
+
+func (localInfo) AuthType() string {
+	return "local"
+}
+
+// NewServerCredentials returns transport credentials for servers accepting
+// connections from listeners that may already wrap connections in TLS.
+func NewServerCredentials() credentials.TransportCredentials {
+	return &serverCredentials{}
+}
+
+type serverCredentials struct{}
+
+func (c *serverCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("peercred credentials are only supported by servers")
+}
+
+func (c *serverCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	switch conn := conn.(type) {
+	case *tls.Conn:
+		if err := conn.Handshake(); err != nil {
+			return nil, nil, err
+		}
+		return conn, credentials.TLSInfo{
+			State:          conn.ConnectionState(),
+			CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		}, nil
+	case *net.UnixConn:
+		info, err := unixInfo(conn)
+		if err != nil {
+			return nil, nil, err
+		}
+		if info != nil {
+			return conn, *info, nil
+		}
+	}
+	return conn, localInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}}, nil
+}
+
+func (c *serverCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: securityProtocol}
+}
+
+func (c *serverCredentials) Clone() credentials.TransportCredentials {
+	return &serverCredentials{}
+}
+
+func (c *serverCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// FromContext returns the unix credentials of the gRPC peer of ctx.
+func FromContext(ctx context.Context) (UnixInfo, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return UnixInfo{}, false
+	}
+	info, ok := p.AuthInfo.(UnixInfo)
+	return info, ok
+}
@@ -0,0 +1,31 @@
+package peercred
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+func unixInfo(conn *net.UnixConn) (*UnixInfo, error) {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	if err := rc.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return nil, err
+	}
+	if credErr != nil {
+		return nil, errors.Wrap(credErr, "failed to get peer credentials")
+	}
+	return &UnixInfo{
+		CommonAuthInfo: commonAuthInfo,
+		UID:            int(cred.Uid),
+		GID:            int(cred.Gid),
+		PID:            int(cred.Pid),
+	}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package peercred
+
+import "net"
+
+// unixInfo is only implemented on Linux, peers on other platforms are not
+// identified.
+func unixInfo(*net.UnixConn) (*UnixInfo, error) {
+	return nil, nil
+}
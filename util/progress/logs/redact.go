@@ -0,0 +1,137 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"slices"
+	"sync"
+)
+
+const redactedValue = "***"
+
+// minRedactSize is the minimum size of a secret value that is redacted.
+// Shorter values would redact unrelated output.
+const minRedactSize = 4
+
+// NewRedactWriter returns a writer that replaces any occurrence of the
+// secrets, and of their base64 and hex encodings, with "***" before writing
+// to w. Output that could be the start of a secret is held back until the
+// next write or Close.
+func NewRedactWriter(w io.WriteCloser, secrets [][]byte) io.WriteCloser {
+	patterns := redactPatterns(secrets)
+	if len(patterns) == 0 {
+		return w
+	}
+	return &redactWriter{w: w, patterns: patterns}
+}
+
+func redactPatterns(secrets [][]byte) [][]byte {
+	seen := map[string]struct{}{}
+	var out [][]byte
+	add := func(p []byte) {
+		if len(p) < minRedactSize {
+			return
+		}
+		if _, ok := seen[string(p)]; ok {
+			return
+		}
+		seen[string(p)] = struct{}{}
+		out = append(out, p)
+	}
+	for _, s := range secrets {
+		// the raw value is redacted without surrounding whitespace so that
+		// the output keeps a trailing newline of a secret file
+		trimmed := bytes.TrimSpace(s)
+		if len(trimmed) < minRedactSize {
+			continue
+		}
+		add(trimmed)
+		values := [][]byte{trimmed}
+		if len(trimmed) != len(s) {
+			values = append(values, s)
+		}
+		for _, v := range values {
+			// encodings of the value written by eg. echo include a newline
+			for _, v := range [][]byte{v, append(slices.Clone(v), '\n')} {
+				for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+					add([]byte(enc.EncodeToString(v)))
+				}
+				add([]byte(hex.EncodeToString(v)))
+			}
+		}
+	}
+	// match the longest pattern first so that padded encodings are replaced
+	// as a whole
+	slices.SortStableFunc(out, func(a, b []byte) int {
+		return len(b) - len(a)
+	})
+	return out
+}
+
+type redactWriter struct {
+	mu       sync.Mutex
+	w        io.WriteCloser
+	patterns [][]byte
+	buf      []byte
+}
+
+func (rw *redactWriter) Write(dt []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.buf = append(rw.buf, dt...)
+	out, n := rw.redact(rw.buf, false)
+	rw.buf = slices.Clone(rw.buf[n:])
+	if len(out) > 0 {
+		if _, err := rw.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(dt), nil
+}
+
+func (rw *redactWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if len(rw.buf) > 0 {
+		out, _ := rw.redact(rw.buf, true)
+		rw.buf = nil
+		if _, err := rw.w.Write(out); err != nil {
+			rw.w.Close()
+			return err
+		}
+	}
+	return rw.w.Close()
+}
+
+// redact returns dt with the secrets replaced and the number of bytes of dt
+// that were consumed. Unless final is set, redact stops at the first byte
+// that could be the start of a secret continuing in the next write.
+func (rw *redactWriter) redact(dt []byte, final bool) ([]byte, int) {
+	out := make([]byte, 0, len(dt))
+	i := 0
+loop:
+	for i < len(dt) {
+		rest := dt[i:]
+		for _, p := range rw.patterns {
+			if bytes.HasPrefix(rest, p) {
+				out = append(out, redactedValue...)
+				i += len(p)
+				continue loop
+			}
+		}
+		if !final {
+			for _, p := range rw.patterns {
+				if len(rest) < len(p) && bytes.HasPrefix(p, rest) {
+					break loop
+				}
+			}
+		}
+		out = append(out, dt[i])
+		i++
+	}
+	return out, i
+}
@@ -0,0 +1,95 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nopCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *nopCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRedactWriter(t *testing.T) {
+	t.Parallel()
+
+	const secret = "s3cr3t-token"
+
+	for _, tc := range []struct {
+		name     string
+		writes   []string
+		expected string
+	}{
+		{
+			name:     "plain",
+			writes:   []string{"token is " + secret + "\n"},
+			expected: "token is ***\n",
+		},
+		{
+			name:     "split",
+			writes:   []string{"token is s3c", "r3t-to", "ken\n"},
+			expected: "token is ***\n",
+		},
+		{
+			name:     "prefix only",
+			writes:   []string{"token is s3c", "ret\n"},
+			expected: "token is s3cret\n",
+		},
+		{
+			name:     "trailing prefix",
+			writes:   []string{"token is s3cr3t"},
+			expected: "token is s3cr3t",
+		},
+		{
+			name:     "base64",
+			writes:   []string{base64.StdEncoding.EncodeToString([]byte(secret)) + "\n"},
+			expected: "***\n",
+		},
+		{
+			name:     "base64 with newline",
+			writes:   []string{base64.StdEncoding.EncodeToString([]byte(secret+"\n")) + "\n"},
+			expected: "***\n",
+		},
+		{
+			name:     "hex",
+			writes:   []string{hex.EncodeToString([]byte(secret))},
+			expected: "***",
+		},
+		{
+			name:     "multiple",
+			writes:   []string{secret + secret + " " + secret},
+			expected: "****** ***",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var out nopCloser
+			w := NewRedactWriter(&out, [][]byte{[]byte(secret + "\n")})
+			for _, dt := range tc.writes {
+				n, err := w.Write([]byte(dt))
+				require.NoError(t, err)
+				require.Equal(t, len(dt), n)
+			}
+			require.NoError(t, w.Close())
+			require.True(t, out.closed)
+			require.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestRedactWriterShortSecret(t *testing.T) {
+	t.Parallel()
+
+	var out nopCloser
+	w := NewRedactWriter(&out, [][]byte{[]byte("ab"), []byte("")})
+	require.Same(t, &out, w)
+}
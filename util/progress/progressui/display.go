@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/containerd/console"
@@ -54,7 +55,36 @@ func WithDesc(text string, console string) DisplayOpt {
 }
 
 type Display struct {
-	disp display
+	disp    display
+	pauseCh chan pauseRequest
+	done    chan struct{}
+}
+
+type pauseRequest struct {
+	paused chan struct{}
+	resume chan struct{}
+}
+
+// Pause stops updating the display, e.g. while an interactive process uses
+// the terminal, until resume is called. Statuses are not read from the
+// channel passed to UpdateFrom while paused. Pause must only be called while UpdateFrom is
+// running or after it has returned.
+func (d Display) Pause() (resume func()) {
+	req := pauseRequest{
+		paused: make(chan struct{}),
+		resume: make(chan struct{}),
+	}
+	select {
+	case d.pauseCh <- req:
+		select {
+		case <-req.paused:
+		case <-d.done:
+		}
+	case <-d.done:
+	}
+	return sync.OnceFunc(func() {
+		close(req.resume)
+	})
 }
 
 type display interface {
@@ -76,6 +106,11 @@ type display interface {
 	// This method should flush any buffers and close any open
 	// resources that were opened by init.
 	done()
+
+	// pause is invoked before the display stops updating until it is
+	// resumed. Displays that redraw their previous output should start
+	// drawing below any output written while paused.
+	pause()
 }
 
 func (d Display) UpdateFrom(ctx context.Context, ch chan *client.SolveStatus) ([]client.VertexWarning, error) {
@@ -96,11 +131,24 @@ func (d Display) UpdateFrom(ctx context.Context, ch chan *client.SolveStatus) ([
 	ticker := time.NewTicker(tickerTimeout)
 	defer ticker.Stop()
 
+	if d.done != nil {
+		defer close(d.done)
+	}
+
 	var warnings []client.VertexWarning
 	for {
 		select {
 		case <-ctx.Done():
 			return nil, context.Cause(ctx)
+		case req := <-d.pauseCh:
+			d.disp.pause()
+			close(req.paused)
+			select {
+			case <-req.resume:
+			case <-ctx.Done():
+				return nil, context.Cause(ctx)
+			}
+			ticker.Reset(tickerTimeout)
 		case <-ticker.C:
 			d.disp.refresh()
 		case ss, ok := <-ch:
@@ -145,6 +193,16 @@ const (
 //
 // For TtyMode to work, the io.Writer should also implement console.File.
 func NewDisplay(out io.Writer, mode DisplayMode, opts ...DisplayOpt) (Display, error) {
+	d, err := newDisplay(out, mode, opts...)
+	if err != nil {
+		return Display{}, err
+	}
+	d.pauseCh = make(chan pauseRequest)
+	d.done = make(chan struct{})
+	return d, nil
+}
+
+func newDisplay(out io.Writer, mode DisplayMode, opts ...DisplayOpt) (Display, error) {
 	switch mode {
 	case AutoMode, TtyMode, DefaultMode:
 		if c, err := consoleFromWriter(out); err == nil {
@@ -183,6 +241,7 @@ func (d *discardDisplay) init(displayLimiter *rate.Limiter) {}
 func (d *discardDisplay) update(ss *client.SolveStatus)     {}
 func (d *discardDisplay) refresh()                          {}
 func (d *discardDisplay) done()                             {}
+func (d *discardDisplay) pause()                            {}
 
 type consoleDisplay struct {
 	t              *trace
@@ -224,6 +283,12 @@ func (d *consoleDisplay) refresh() {
 	d.disp.print(d.t.displayInfo(), d.width, d.height, false)
 }
 
+func (d *consoleDisplay) pause() {
+	d.refresh()
+	d.disp.lineCount = 0
+	d.disp.repeated = false
+}
+
 func (d *consoleDisplay) done() {
 	d.width, d.height = d.disp.getSize()
 	d.disp.print(d.t.displayInfo(), d.width, d.height, true)
@@ -277,6 +342,10 @@ func (d *plainDisplay) done() {
 	d.t.printErrorLogs(d.t.w)
 }
 
+func (d *plainDisplay) pause() {
+	d.refresh()
+}
+
 type rawJSONDisplay struct {
 	enc *json.Encoder
 	w   io.Writer
@@ -310,6 +379,10 @@ func (d *rawJSONDisplay) done() {
 	// No actions needed.
 }
 
+func (d *rawJSONDisplay) pause() {
+	// Unbuffered display doesn't have anything to flush.
+}
+
 const termPad = 10
 
 type displayInfo struct {
@@ -1,10 +1,14 @@
 package progressui
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/moby/buildkit/client"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 func mkinterval(start, stop int64) interval {
@@ -166,3 +170,48 @@ func TestMergeIntervals(t *testing.T) {
 		})
 	}
 }
+
+type recordDisplay struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (d *recordDisplay) record(call string) {
+	d.mu.Lock()
+	d.calls = append(d.calls, call)
+	d.mu.Unlock()
+}
+
+func (d *recordDisplay) init(*rate.Limiter)         {}
+func (d *recordDisplay) update(*client.SolveStatus) { d.record("update") }
+func (d *recordDisplay) refresh()                   {}
+func (d *recordDisplay) done()                      { d.record("done") }
+func (d *recordDisplay) pause()                     { d.record("pause") }
+
+func TestPause(t *testing.T) {
+	rd := &recordDisplay{}
+	d := Display{disp: rd, pauseCh: make(chan pauseRequest), done: make(chan struct{})}
+	ch := make(chan *client.SolveStatus)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := d.UpdateFrom(context.TODO(), ch)
+		errCh <- err
+	}()
+
+	ch <- &client.SolveStatus{}
+	resume := d.Pause()
+	select {
+	case ch <- &client.SolveStatus{}:
+		t.Fatal("display received status while paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+	resume()
+	resume()
+	ch <- &client.SolveStatus{}
+	close(ch)
+	require.NoError(t, <-errCh)
+	require.Equal(t, []string{"update", "pause", "update", "done"}, rd.calls)
+
+	// pausing a finished display does not block
+	d.Pause()()
+}
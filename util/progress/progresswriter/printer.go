@@ -9,10 +9,21 @@ import (
 	"github.com/moby/buildkit/util/progress/progressui"
 )
 
+// Pauser is implemented by writers that print the progress to a terminal and
+// can stop doing so while the terminal is used by something else.
+type Pauser interface {
+	Pause() (resume func())
+}
+
 type printer struct {
 	status chan *client.SolveStatus
 	done   <-chan struct{}
 	err    error
+	disp   progressui.Display
+}
+
+func (p *printer) Pause() (resume func()) {
+	return p.disp.Pause()
 }
 
 func (p *printer) Done() <-chan struct{} {
@@ -74,6 +85,8 @@ func NewPrinter(ctx context.Context, out console.File, mode string) (Writer, err
 		return nil, err
 	}
 
+	pw.disp = d
+
 	go func() {
 		// not using shared context to not disrupt display but let is finish reporting errors
 		_, pw.err = d.UpdateFrom(ctx, statusCh)
@@ -12,8 +12,8 @@ import (
 	"github.com/containerd/containerd/v2/core/remotes"
 	"github.com/distribution/reference"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/fairqueue"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
-	"golang.org/x/sync/semaphore"
 )
 
 type contextKeyT string
@@ -28,7 +28,7 @@ var Default = New(int(DefaultMaxConcurrency))
 type Group struct {
 	mu   sync.Mutex
 	size int
-	sem  map[string][2]*semaphore.Weighted
+	sem  map[string][2]*fairqueue.Semaphore
 }
 
 type req struct {
@@ -69,7 +69,7 @@ func (r *req) acquire(ctx context.Context, desc ocispecs.Descriptor) (context.Co
 func New(size int) *Group {
 	return &Group{
 		size: size,
-		sem:  make(map[string][2]*semaphore.Weighted),
+		sem:  make(map[string][2]*fairqueue.Semaphore),
 	}
 }
 
@@ -77,15 +77,18 @@ func (g *Group) req(ref string) *req {
 	return &req{g: g, ref: domain(ref)}
 }
 
-func (g *Group) getOrInit(domain string) [2]*semaphore.Weighted {
+func (g *Group) getOrInit(domain string) [2]*fairqueue.Semaphore {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	s, ok := g.sem[domain]
 	if !ok {
-		s = [2]*semaphore.Weighted{
-			semaphore.NewWeighted(int64(g.size)),
-			semaphore.NewWeighted(int64(g.size + 1)),
+		s = [2]*fairqueue.Semaphore{
+			fairqueue.New(int64(g.size)),
+			fairqueue.New(int64(g.size + 1)),
+		}
+		for _, sem := range s {
+			sem.Name = "registry"
 		}
 		g.sem[domain] = s
 	}
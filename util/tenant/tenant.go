@@ -0,0 +1,97 @@
+// Package tenant scopes requests to the control API to a tenant derived from
+// the mTLS client certificate of the caller.
+package tenant
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Any is the tenant value that applies a prune or GC policy separately to
+// every tenant that owns build cache.
+const Any = "*"
+
+type tenantKey struct{}
+
+// WithID returns a context scoped to the tenant id. An empty id removes the
+// scope of the parent context.
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" && FromContext(ctx) == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant set with WithID, or an empty string for
+// requests that are not scoped to a tenant.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
+}
+
+// FromPeer returns the tenant of the gRPC peer of ctx. The tenant is the
+// common name of the verified client certificate. Local peers connected over a
+// unix socket or named pipe are not scoped to a tenant. Other peers without
+// TLS information, e.g. because the server does not use transport credentials
+// that complete the handshake of listener-wrapped TLS connections, are
+// rejected.
+func FromPeer(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		if p.Addr != nil && (p.Addr.Network() == "unix" || p.Addr.Network() == "pipe") {
+			return "", nil
+		}
+		return "", errors.New("tenant requires the TLS connection state of the peer")
+	}
+	if len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", errors.New("tenant requires a verified client certificate")
+	}
+	return FromCertificate(info.State.VerifiedChains[0][0])
+}
+
+// FromCertificate returns the tenant of a client certificate.
+func FromCertificate(cert *x509.Certificate) (string, error) {
+	if cert.Subject.CommonName == "" {
+		return "", errors.New("client certificate has no common name to use as tenant")
+	}
+	return cert.Subject.CommonName, nil
+}
+
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id, err := FromPeer(ctx)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return handler(WithID(ctx, id), req)
+}
+
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id, err := FromPeer(ss.Context())
+	if err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if id == "" {
+		return handler(srv, ss)
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: WithID(ss.Context(), id)})
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
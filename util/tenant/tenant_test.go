@@ -0,0 +1,172 @@
+package tenant
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/util/peercred"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(chains ...[]*x509.Certificate) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)},
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: chains},
+		},
+	})
+}
+
+func TestFromPeer(t *testing.T) {
+	id, err := FromPeer(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, id)
+
+	// unix socket peers have no TLS info
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.UnixAddr{Name: "buildkitd.sock", Net: "unix"}})
+	id, err = FromPeer(ctx)
+	require.NoError(t, err)
+	require.Empty(t, id)
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "team-a", Organization: []string{"example"}}}
+	id, err = FromPeer(peerContext([]*x509.Certificate{cert}))
+	require.NoError(t, err)
+	require.Equal(t, "team-a", id)
+
+	_, err = FromPeer(peerContext())
+	require.ErrorContains(t, err, "verified client certificate")
+
+	// TCP peers must have TLS information
+	ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}})
+	_, err = FromPeer(ctx)
+	require.ErrorContains(t, err, "TLS connection state")
+
+	_, err = FromPeer(peerContext([]*x509.Certificate{{}}))
+	require.ErrorContains(t, err, "no common name")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return FromContext(ctx), nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/moby.buildkit.v1.Control/DiskUsage"}
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "team-b"}}
+	resp, err := UnaryServerInterceptor(peerContext([]*x509.Certificate{cert}), nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "team-b", resp)
+
+	_, err = UnaryServerInterceptor(peerContext(), nil, info, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestWithID(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, ctx, WithID(ctx, ""))
+
+	ctx = WithID(ctx, "a")
+	require.Equal(t, "a", FromContext(ctx))
+
+	// an empty id removes the scope
+	require.Empty(t, FromContext(WithID(ctx, "")))
+}
+
+// TestTLSListener tests the tenant of clients connecting to a server that
+// wraps its listener in TLS like buildkitd does.
+func TestTLSListener(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	newCert := func(cn string, usage x509.ExtKeyUsage, ips ...net.IP) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  ips,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+	serverCert := newCert("server", x509.ExtKeyUsageServerAuth, net.IPv4(127, 0, 0, 1))
+	clientCert := newCert("team-a", x509.ExtKeyUsageClientAuth)
+
+	serve := func(creds credentials.TransportCredentials) (string, <-chan string) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		l = tls.NewListener(l, &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientCAs:    pool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			NextProtos:   []string{"h2"},
+		})
+		tenants := make(chan string, 1)
+		server := grpc.NewServer(grpc.Creds(creds), grpc.ChainUnaryInterceptor(UnaryServerInterceptor, func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			tenants <- FromContext(ctx)
+			return handler(ctx, req)
+		}))
+		grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+		go server.Serve(l)
+		t.Cleanup(server.Stop)
+		return l.Addr().String(), tenants
+	}
+	check := func(addr string) error {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{clientCert},
+			RootCAs:      pool,
+		})))
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+
+	// the server credentials complete the handshake of the TLS connection
+	addr, tenants := serve(peercred.NewServerCredentials())
+	require.NoError(t, check(addr))
+	require.Equal(t, "team-a", <-tenants)
+
+	// without the TLS information of the peer, requests are rejected instead
+	// of not being scoped to a tenant
+	addr, _ = serve(insecure.NewCredentials())
+	err = check(addr)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "TLS connection state")
+}
@@ -2,6 +2,7 @@ package base
 
 import (
 	"context"
+	"crypto/rand"
 	stderrors "errors"
 	"fmt"
 	"os"
@@ -48,6 +49,7 @@ import (
 	"github.com/moby/buildkit/source/local"
 	"github.com/moby/buildkit/util/archutil"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/fairqueue"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/network"
 	"github.com/moby/buildkit/util/progress"
@@ -58,7 +60,6 @@ import (
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
-	"golang.org/x/sync/semaphore"
 )
 
 const labelCreatedAt = "buildkit/createdat"
@@ -86,7 +87,7 @@ type WorkerOpt struct {
 	IdentityMapping  *user.IdentityMapping
 	LeaseManager     *leaseutil.Manager
 	GarbageCollect   func(context.Context) (gc.Stats, error)
-	ParallelismSem   *semaphore.Weighted
+	ParallelismSem   *fairqueue.Semaphore
 	MetadataStore    *metadata.Store
 	MountPoolRoot    string
 	ResourceMonitor  *resources.Monitor
@@ -105,6 +106,7 @@ type Worker struct {
 	GitSource       *git.Source
 	HTTPSource      *http.Source
 	platformsMu     sync.Mutex
+	secretCacheKey  []byte
 }
 
 // NewWorker instantiates a local worker
@@ -193,6 +195,15 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 	}
 	sm.Register(ss)
 
+	// remove files left by a previous daemon that did not shut down cleanly
+	tempDir := filepath.Join(opt.Root, "tmp")
+	if err := os.RemoveAll(tempDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(tempDir, 0700); err != nil {
+		return nil, err
+	}
+
 	os, err := containerimage.NewSource(containerimage.SourceOpt{
 		Snapshotter:   opt.Snapshotter,
 		ContentStore:  opt.ContentStore,
@@ -213,11 +224,17 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 		ContentStore: opt.ContentStore,
 		Applier:      opt.Applier,
 		Differ:       opt.Differ,
+		TempDir:      tempDir,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	secretCacheKey, err := SecretCacheKey(opt.Root)
+	if err != nil {
+		return nil, err
+	}
+
 	leases, err := opt.LeaseManager.List(ctx, "labels.\"buildkit/lease.temporary\"")
 	if err != nil {
 		return nil, err
@@ -235,6 +252,7 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 		OCILayoutSource: os,
 		GitSource:       gitSource,
 		HTTPSource:      hs,
+		secretCacheKey:  secretCacheKey,
 	}, nil
 }
 
@@ -370,36 +388,51 @@ func (w *Worker) CacheManager() cache.Manager {
 type proxyPolicyExecutor struct {
 	executor.Executor
 	getProxyPolicy func() (network.ProxyPolicy, error)
+	getEgress      func() (network.EgressPolicies, error)
+	getReplay      func() (*network.ProxyReplay, error)
 }
 
 func (e *proxyPolicyExecutor) Run(ctx context.Context, id string, rootfs executor.Mount, mounts []executor.Mount, process executor.ProcessInfo, started chan<- struct{}) (resourcestypes.Recorder, error) {
 	if process.Meta.Proxy != nil {
-		policy, err := e.proxyPolicy()
-		if err != nil {
+		if err := e.setProxyPolicy(process.Meta.Proxy); err != nil {
 			return nil, err
 		}
-		process.Meta.Proxy.Policy = policy
 	}
 	return e.Executor.Run(ctx, id, rootfs, mounts, process, started)
 }
 
 func (e *proxyPolicyExecutor) Exec(ctx context.Context, id string, process executor.ProcessInfo) error {
 	if process.Meta.Proxy != nil {
-		policy, err := e.proxyPolicy()
-		if err != nil {
+		if err := e.setProxyPolicy(process.Meta.Proxy); err != nil {
 			return err
 		}
-		process.Meta.Proxy.Policy = policy
 	}
 	return e.Executor.Exec(ctx, id, process)
 }
 
-func (e *proxyPolicyExecutor) proxyPolicy() (network.ProxyPolicy, error) {
-	policy, err := e.getProxyPolicy()
-	if err != nil {
-		return nil, err
+func (e *proxyPolicyExecutor) setProxyPolicy(cfg *network.ProxyConfig) error {
+	if e.getProxyPolicy != nil {
+		policy, err := e.getProxyPolicy()
+		if err != nil {
+			return err
+		}
+		cfg.Policy = policy
 	}
-	return policy, nil
+	if e.getEgress != nil {
+		egress, err := e.getEgress()
+		if err != nil {
+			return err
+		}
+		cfg.Egress = egress
+	}
+	if e.getReplay != nil {
+		replay, err := e.getReplay()
+		if err != nil {
+			return err
+		}
+		cfg.Replay = replay
+	}
+	return nil
 }
 
 func (w *Worker) ResolveOp(v solver.Vertex, s frontend.FrontendLLBBridge, sm *session.Manager, proxyOpt worker.ProxyOpt) (solver.Op, error) {
@@ -415,11 +448,16 @@ func (w *Worker) ResolveOp(v solver.Vertex, s frontend.FrontendLLBBridge, sm *se
 			exec := w.WorkerOpt.Executor
 			proxyNetwork := proxyOpt.Network && op.Exec.Network != pb.NetMode_NONE
 			if proxyNetwork {
-				if proxyOpt.Policy != nil {
-					exec = &proxyPolicyExecutor{Executor: exec, getProxyPolicy: proxyOpt.Policy}
+				if proxyOpt.Policy != nil || proxyOpt.Egress != nil || proxyOpt.Replay != nil {
+					exec = &proxyPolicyExecutor{
+						Executor:       exec,
+						getProxyPolicy: proxyOpt.Policy,
+						getEgress:      proxyOpt.Egress,
+						getReplay:      proxyOpt.Replay,
+					}
 				}
 			}
-			return ops.NewExecOp(v, op, baseOp.Platform, w.CacheMgr, w.ParallelismSem, sm, exec, w, linuxResources, proxyNetwork)
+			return ops.NewExecOp(v, op, baseOp.Platform, w.CacheMgr, w.ParallelismSem, sm, exec, w, linuxResources, proxyNetwork, w.secretCacheKey, w.NetworkProviders[op.Exec.Network])
 		case *pb.Op_File:
 			return ops.NewFileOp(v, op, w.CacheMgr, w.ParallelismSem, w)
 		case *pb.Op_Build:
@@ -736,3 +774,32 @@ func ID(root string) (string, error) {
 	}
 	return string(b), nil
 }
+
+// SecretCacheKey reads the key used for the digests of secret values in
+// cache keys from the `secretcachekey` file. If not exist, it creates a random
+// one. Without root the key is only kept in memory.
+func SecretCacheKey(root string) ([]byte, error) {
+	if root != "" {
+		f := filepath.Join(root, "secretcachekey")
+		b, err := os.ReadFile(f)
+		if err == nil {
+			if len(b) < 16 {
+				return nil, errors.Errorf("invalid secret cache key in %s", f)
+			}
+			return b, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.WithStack(err)
+		}
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if root != "" {
+		if err := os.WriteFile(filepath.Join(root, "secretcachekey"), key, 0400); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return key, nil
+}
@@ -28,3 +28,22 @@ func TestID(t *testing.T) {
 
 	require.NotEqual(t, id0, id2)
 }
+
+func TestSecretCacheKey(t *testing.T) {
+	t.Parallel()
+	tmpdir := t.TempDir()
+
+	k0, err := SecretCacheKey(tmpdir)
+	require.NoError(t, err)
+	require.Len(t, k0, 32)
+
+	k1, err := SecretCacheKey(tmpdir)
+	require.NoError(t, err)
+	require.Equal(t, k0, k1)
+
+	// without root the key is not persisted
+	k2, err := SecretCacheKey("")
+	require.NoError(t, err)
+	require.Len(t, k2, 32)
+	require.NotEqual(t, k0, k2)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/moby/buildkit/executor/oci"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
 	"github.com/moby/buildkit/solver/llbsolver/cdidevices"
+	"github.com/moby/buildkit/util/fairqueue"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/util/winlayers"
@@ -24,7 +25,6 @@ import (
 	wlabel "github.com/moby/buildkit/worker/label"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
-	"golang.org/x/sync/semaphore"
 )
 
 type RuntimeInfo = containerdexecutor.RuntimeInfo
@@ -41,7 +41,7 @@ type WorkerOptions struct {
 	NetworkOpt      netproviders.Opt
 	ApparmorProfile string
 	Selinux         bool
-	ParallelismSem  *semaphore.Weighted
+	ParallelismSem  *fairqueue.Semaphore
 	TraceSocket     string
 	Runtime         *RuntimeInfo
 	CDIManager      *cdidevices.Manager
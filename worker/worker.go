@@ -23,6 +23,8 @@ import (
 type ProxyOpt struct {
 	Network bool
 	Policy  func() (network.ProxyPolicy, error)
+	Egress  func() (network.EgressPolicies, error)
+	Replay  func() (*network.ProxyReplay, error)
 }
 
 type Worker interface {